@@ -0,0 +1,83 @@
+package telemetry_dialout
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// maxPendingAcks bounds the notifications kept for retransmission while a
+// collector does not acknowledge them.
+const maxPendingAcks = 1024
+
+// sentUpdate is a notification waiting for its acknowledgement.
+type sentUpdate struct {
+	resp   *gpb.SubscribeResponse
+	sentAt time.Time
+}
+
+// pendingAcks holds the notifications published in bidirectional mode until
+// the collector acknowledges them. A PublishResponse acknowledges every
+// notification with the same or an earlier timestamp. Notifications still
+// unacknowledged when the connection is restarted are sent again.
+type pendingAcks struct {
+	mu   sync.Mutex
+	sent []sentUpdate
+}
+
+// add records resp as sent at now. Responses without timestamp, such as sync
+// responses, are not acknowledged.
+func (p *pendingAcks) add(resp *gpb.SubscribeResponse, now time.Time) {
+	if resp.GetUpdate().GetTimestamp() == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.sent) >= maxPendingAcks {
+		log.V(1).Infof("Dropping unacknowledged notification of %v", p.sent[0].resp.GetUpdate().GetTimestamp())
+		p.sent[0] = sentUpdate{}
+		p.sent = p.sent[1:]
+	}
+	p.sent = append(p.sent, sentUpdate{resp: resp, sentAt: now})
+}
+
+// ack removes the notifications acknowledged by a response of timestamp ts,
+// and returns how many.
+func (p *pendingAcks) ack(ts int64) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	kept := p.sent[:0]
+	for _, s := range p.sent {
+		if s.resp.GetUpdate().GetTimestamp() > ts {
+			kept = append(kept, s)
+		}
+	}
+	n := len(p.sent) - len(kept)
+	for i := len(kept); i < len(p.sent); i++ {
+		p.sent[i] = sentUpdate{}
+	}
+	p.sent = kept
+	return n
+}
+
+// expired reports whether a notification sent before now-timeout is still
+// unacknowledged.
+func (p *pendingAcks) expired(now time.Time, timeout time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.sent) > 0 && now.Sub(p.sent[0].sentAt) > timeout
+}
+
+// take removes and returns the notifications to send again, oldest first.
+func (p *pendingAcks) take() []*gpb.SubscribeResponse {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	resps := make([]*gpb.SubscribeResponse, len(p.sent))
+	for i, s := range p.sent {
+		resps[i] = s.resp
+	}
+	p.sent = nil
+	return resps
+}
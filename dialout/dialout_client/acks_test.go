@@ -0,0 +1,47 @@
+package telemetry_dialout
+
+import (
+	"testing"
+	"time"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func notification(ts int64) *gpb.SubscribeResponse {
+	return &gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_Update{Update: &gpb.Notification{Timestamp: ts}}}
+}
+
+func TestPendingAcks(t *testing.T) {
+	var p pendingAcks
+	now := time.Now()
+	p.add(&gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_SyncResponse{SyncResponse: true}}, now)
+	for ts := int64(1); ts <= 3; ts++ {
+		p.add(notification(ts), now)
+	}
+	if p.expired(now.Add(time.Second), time.Second) {
+		t.Error("Expired before the timeout")
+	}
+	if !p.expired(now.Add(2*time.Second), time.Second) {
+		t.Error("Not expired after the timeout")
+	}
+
+	// Acknowledgements are cumulative
+	if n := p.ack(2); n != 2 {
+		t.Errorf("Acknowledged %d notifications, want 2", n)
+	}
+	resent := p.take()
+	if len(resent) != 1 || resent[0].GetUpdate().GetTimestamp() != 3 {
+		t.Errorf("Unexpected notifications to resend %v", resent)
+	}
+	if len(p.take()) != 0 || p.expired(now.Add(time.Hour), time.Second) {
+		t.Error("Notifications left after take")
+	}
+
+	// The oldest notifications are dropped past the limit
+	for ts := int64(1); ts <= maxPendingAcks+1; ts++ {
+		p.add(notification(ts), now)
+	}
+	if resent = p.take(); len(resent) != maxPendingAcks || resent[0].GetUpdate().GetTimestamp() != 2 {
+		t.Errorf("Unexpected pending notifications after overflow: %d", len(resent))
+	}
+}
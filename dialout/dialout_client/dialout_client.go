@@ -17,6 +17,7 @@ import (
 
 	"github.com/Workiva/go-datastructures/queue"
 	log "github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
 	gpb "github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/ygot/ygot"
 	"github.com/redis/go-redis/v9"
@@ -74,6 +75,9 @@ var (
 	// Only one destination will be used at one time
 	destGrpNameMap = make(map[string][]Destination)
 
+	// Policy and buffering of each Destination group
+	destGrpOptionsMap = make(map[string]destGroupOptions)
+
 	// For finding clientSubscription quickly
 	ClientSubscriptionNameMap = make(map[string]*clientSubscription)
 
@@ -83,6 +87,8 @@ var (
 
 type Destination struct {
 	Addrs string
+	// tls overrides the global TLS config for the destinations of a group.
+	tls *groupTLS
 }
 
 func (d Destination) Validate() error {
@@ -116,33 +122,18 @@ type clientSubscription struct {
 
 	// Running time data
 	cMu    sync.Mutex
-	client *Client              // GNMIDialOutClient
 	dc     sdc.Client           // SONiC data client
 	stop   chan struct{}        // Inform publishRun routine to stop
 	q      *queue.PriorityQueue // for data passing among go routine
-	w      sync.WaitGroup       // Wait for all sub go routine to finish
+	links  []*destLink          // deliver the data to the destinations
 	opened bool                 // whether there is opened instance for this client subscription
 	cancel context.CancelFunc
-
-	conTryCnt uint64 //Number of time trying to connect
-	sendMsg   uint64
-	recvMsg   uint64
-	errors    uint64
 }
 
 // Client handles execution of the telemetry publish service.
 type Client struct {
-	conn *grpc.ClientConn
-
-	mu      sync.Mutex
-	client  spb.GNMIDialOutClient
-	publish spb.GNMIDialOut_PublishClient
-
-	// dataChan chan struct{} //to pass data struct pointer
-	//
-	// synced  sync.WaitGroup
-	sendMsg uint64
-	recvMsg uint64
+	conn   *grpc.ClientConn
+	client spb.GNMIDialOutClient
 }
 
 func (cs *clientSubscription) Close() {
@@ -161,10 +152,6 @@ func (cs *clientSubscription) Close() {
 			cs.q.Dispose()
 		}
 	}
-	if cs.client != nil {
-
-		cs.client.Close() // Close GNMIDialOutClient
-	}
 	cs.opened = false
 	log.V(2).Infof("Closed %v", cs)
 }
@@ -183,79 +170,133 @@ func (cs *clientSubscription) NewInstance(ctx context.Context) error {
 		log.V(2).Infof("Destination group %v doesn't exist", cs.destGroupName)
 		return fmt.Errorf("Destination group %v doesn't exist", cs.destGroupName)
 	}
-
-	target := cs.prefix.GetTarget()
-	if target == "" {
-		return fmt.Errorf("Empty target data not supported yet")
+	opts, ok := destGrpOptionsMap[cs.destGroupName]
+	if !ok {
+		opts = destGroupOptions{policy: Failover, bufferSize: defaultBufferSize}
 	}
 
 	// Connection to system data source
-	var dc sdc.Client
-	var err error
-	if target == "OTHERS" {
-		dc, err = sdc.NewNonDbClient(cs.paths, cs.prefix)
-	} else if target == "OC_YANG" {
-		dc, err = sdc.NewTranslClient(cs.prefix, cs.paths, ctx, nil, sdc.TranslWildcardOption{})
-	} else {
-		dc, err = sdc.NewDbClient(cs.paths, cs.prefix)
-	}
+	dc, err := newDataClient(ctx, cs)
 	if err != nil {
 		log.V(1).Infof("Connection to DB for %v failed: %v", *cs, err)
 		return fmt.Errorf("Connection to DB for %v failed: %v", *cs, err)
 	}
+	links, err := newLinks(cs.name, dests, opts)
+	if err != nil {
+		dc.Close()
+		log.V(1).Infof("Buffers for %v failed: %v", cs.name, err)
+		return fmt.Errorf("Buffers for %v failed: %v", cs.name, err)
+	}
 	cs.dc = dc
-	go publishRun(ctx, cs, dests)
+	cs.links = links
+	cs.stop = make(chan struct{}, 1)
+	cs.q = queue.NewPriorityQueue(1, false)
+	cs.opened = true
+	go publishRun(ctx, cs)
 	log.V(2).Infof("publishRun for %v with destination %v", cs, dests)
 	return nil
 }
 
-// send runs until process Queue returns an error.
-func (cs *clientSubscription) send(stream spb.GNMIDialOut_PublishClient) error {
+// newDataClient returns the data client of the subscription, chosen like the
+// one of a dial-in subscription. Periodic and once reports read the data with
+// Get, which SHOW also serves, while EVENTS are only streamed.
+func newDataClient(ctx context.Context, cs *clientSubscription) (sdc.Client, error) {
+	target := cs.prefix.GetTarget()
+	if cs.prefix.GetOrigin() == "" && target == "SHOW" && cs.reportType != Stream {
+		return sdc.NewShowClient(cs.paths, cs.prefix)
+	}
+	if cs.prefix.GetOrigin() == "" && target == "EVENTS" && cs.reportType != Stream {
+		return nil, fmt.Errorf("EVENTS only support the stream report_type")
+	}
+	mode := gpb.SubscriptionList_POLL
+	switch cs.reportType {
+	case Stream:
+		mode = gpb.SubscriptionList_STREAM
+	case Once:
+		mode = gpb.SubscriptionList_ONCE
+	}
+	dc, _, err := sdc.NewSubscribeClient(ctx, cs.prefix, cs.prefix.GetOrigin(), cs.paths, mode, nil, 0)
+	return dc, err
+}
+
+// forward publishes the values streamed in q until q is disposed or a value
+// reports a fatal error.
+func (cs *clientSubscription) forward(q *queue.PriorityQueue) error {
 	for {
-		items, err := cs.q.Get(1)
+		items, err := q.Get(1)
 
 		if items == nil {
 			log.V(1).Infof("%v", err)
 			return err
 		}
 		if err != nil {
-			cs.errors++
 			log.V(1).Infof("%v", err)
 			return fmt.Errorf("unexpected queue Gext(1): %v", err)
 		}
 
-		var resp *gpb.SubscribeResponse
 		switch v := items[0].(type) {
 		case sdc.Value:
-			if resp, err = sdc.ValToResp(v); err != nil {
-				cs.errors++
+			resp, err := sdc.ValToResp(v)
+			if err != nil {
 				return err
 			}
+			cs.publish(resp)
 		default:
 			log.V(1).Infof("Unknown data type %v for %s in queue", items[0], cs)
-			cs.errors++
-		}
-
-		cs.sendMsg++
-		err = stream.Send(resp)
-		if err != nil {
-			log.V(1).Infof("Client %s sending error:%v", cs, err)
-			cs.errors++
-			return err
 		}
-		log.V(5).Infof("Client %s done sending, msg count %d, msg %v", cs, cs.sendMsg, resp)
 	}
 }
 
 // String returns the target the client is querying.
 func (cs *clientSubscription) String() string {
-	return fmt.Sprintf(" %s:%s:%s prefix %v paths %v interval %v, sendMsg %v, recvMsg %v",
-		cs.name, cs.destGroupName, cs.reportType, cs.prefix.GetTarget(), cs.paths, cs.interval, cs.sendMsg, cs.recvMsg)
+	return fmt.Sprintf(" %s:%s:%s prefix %v paths %v interval %v",
+		cs.name, cs.destGroupName, cs.reportType, cs.prefix.GetTarget(), cs.paths, cs.interval)
+}
+
+// publish queues resp, in the configured encoding, for delivery to the
+// destinations of the subscription.
+func (cs *clientSubscription) publish(resp *gpb.SubscribeResponse) {
+	if err := encodeResponse(resp, clientCfg.Encoding); err != nil {
+		log.V(1).Infof("Encoding error %v for %v", err, cs.name)
+		return
+	}
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		log.V(1).Infof("Marshal error %v for %v", err, cs.name)
+		return
+	}
+	for _, l := range cs.links {
+		l.push(data)
+	}
+}
+
+// snapshot returns the current values of the paths of the subscription.
+func (cs *clientSubscription) snapshot() (*gpb.SubscribeResponse, error) {
+	spbValues, err := cs.dc.Get(nil)
+	if err != nil {
+		return nil, err
+	}
+	var updates []*gpb.Update
+	var spbValue *spb.Value
+	for _, spbValue = range spbValues {
+		update := &gpb.Update{
+			Path: spbValue.GetPath(),
+			Val:  spbValue.GetVal(),
+		}
+		updates = append(updates, update)
+	}
+	rs := &gpb.SubscribeResponse_Update{
+		Update: &gpb.Notification{
+			Timestamp: spbValue.GetTimestamp(),
+			Prefix:    cs.prefix,
+			Update:    updates,
+		},
+	}
+	return &gpb.SubscribeResponse{Response: rs}, nil
 }
 
 // newClient returns a new initialized GNMIDialout client.
 // it connects to destination and publish service
-// TODO: TLS credential support
 func newClient(ctx context.Context, dest Destination) (*Client, error) {
 	timeout := clientCfg.RetryInterval
 	ctx, cancel := context.WithTimeout(ctx, timeout)
@@ -264,8 +305,15 @@ func newClient(ctx context.Context, dest Destination) (*Client, error) {
 	opts := []grpc.DialOption{
 		grpc.WithBlock(),
 	}
-	if clientCfg.TLS != nil {
-		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(clientCfg.TLS)))
+	tlsCfg := clientCfg.TLS
+	if dest.tls != nil {
+		var err error
+		if tlsCfg, err = dest.tls.config(clientCfg.TLS); err != nil {
+			return nil, fmt.Errorf("TLS config for %s: %v", dest.Addrs, err)
+		}
+	}
+	if tlsCfg != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
 	}
 	conn, err := grpc.DialContext(ctx, dest.Addrs, opts...)
 	if err != nil {
@@ -285,125 +333,68 @@ func (c *Client) Close() error {
 	return c.conn.Close()
 }
 
-func publishRun(ctx context.Context, cs *clientSubscription, dests []Destination) {
-	var err error
-	var c *Client
-	var destNum, destIdx int
-	destNum = len(dests)
-	destIdx = 0
-
-restart: //Remote server might go down, in that case we restart with next destination in the group
-	cs.cMu.Lock()
-	cs.stop = make(chan struct{}, 1)
-	cs.q = queue.NewPriorityQueue(1, false)
-	cs.opened = true
-	cs.client = nil
-	cs.cMu.Unlock()
-
-	cs.conTryCnt++
-	dest := dests[destIdx]
-	destIdx = (destIdx + 1) % destNum
-	c, err = newClient(ctx, dest)
-	select {
-	case <-ctx.Done():
-		cs.Close()
-		log.V(1).Infof("%v: %v, cs.conTryCnt %v", cs, err, cs.conTryCnt)
-		return
-	default:
-	}
-	if err != nil {
-		log.V(1).Infof("Dialout connection for %v failed for %v, %v cs.conTryCnt %v", dest, cs.name, err, cs.conTryCnt)
-		goto restart
-	}
-
-	log.V(1).Infof("Dialout service connected to %v successfully for %v", dest, cs.name)
-	pub, err := c.client.Publish(ctx)
-	if err != nil {
-		log.V(1).Infof("Publish to %v for %v failed: %v, retrying", dest, cs.name, err)
-		c.Close()
-		cs.Close()
-		goto restart
-	}
-
-	cs.cMu.Lock()
-	if cs.client == nil {
-		cs.client = c
-	} else {
-		log.V(1).Infof("connection to %v already exists for %v, exiting publishRun", dest, cs)
-		c.Close()
-		cs.cMu.Unlock()
-		return
+// publishRun produces the notifications of the subscription according to its
+// report type, while its links deliver them, until the subscription is closed.
+func publishRun(ctx context.Context, cs *clientSubscription) {
+	for _, l := range cs.links {
+		go l.run(ctx, cs.stop)
 	}
-	cs.cMu.Unlock()
 
 	switch cs.reportType {
 	case Periodic:
 		for {
+			response, err := cs.snapshot()
+			if err != nil {
+				// TODO: need to inform
+				log.V(2).Infof("Data read error %v for %v", err, cs)
+			} else {
+				cs.publish(response)
+			}
 			select {
-			default:
-				spbValues, err := cs.dc.Get(nil)
-				if err != nil {
-					// TODO: need to inform
-					log.V(2).Infof("Data read error %v for %v", err, cs)
-					continue
-					//return nil, status.Error(codes.NotFound, err.Error())
-				}
-				var updates []*gpb.Update
-				var spbValue *spb.Value
-				for _, spbValue = range spbValues {
-					update := &gpb.Update{
-						Path: spbValue.GetPath(),
-						Val:  spbValue.GetVal(),
-					}
-					updates = append(updates, update)
-				}
-				rs := &gpb.SubscribeResponse_Update{
-					Update: &gpb.Notification{
-						Timestamp: spbValue.GetTimestamp(),
-						Prefix:    cs.prefix,
-						Update:    updates,
-					},
-				}
-				response := &gpb.SubscribeResponse{Response: rs}
-
-				log.V(6).Infof("cs %s sending \n\t%v \n To %s", cs.name, response, dest)
-				err = pub.Send(response)
-				if err != nil {
-					log.V(1).Infof("Client %v pub Send error:%v, cs.conTryCnt %v", cs.name, err, cs.conTryCnt)
-					cs.Close()
-					// Retry
-					goto restart
-				}
-				log.V(6).Infof("cs %s to  %s done", cs.name, dest)
-				cs.sendMsg++
-				c.sendMsg++
-
-				time.Sleep(cs.interval)
+			case <-time.After(cs.interval):
 			case <-cs.stop:
-				log.V(1).Infof("%v exiting publishRun routine for destination %s", cs, dest)
+				log.V(1).Infof("%v exiting publishRun routine", cs)
 				return
 			}
 		}
 	case Stream:
-		select {
-		default:
-			cs.w.Add(1)
-			go cs.dc.StreamRun(cs.q, cs.stop, &cs.w, nil)
-			time.Sleep(100 * time.Millisecond)
-			err = cs.send(pub)
-			if err != nil {
-				log.V(1).Infof("Client %v pub Send error:%v, cs.conTryCnt %v", cs.name, err, cs.conTryCnt)
+		for {
+			var w sync.WaitGroup
+			runStop := make(chan struct{})
+			cs.cMu.Lock()
+			q := cs.q
+			cs.cMu.Unlock()
+			w.Add(1)
+			go cs.dc.StreamRun(q, runStop, &w, nil)
+			err := cs.forward(q)
+			close(runStop)
+			w.Wait()
+
+			// Restart the stream after a fatal error, unless closed
+			cs.cMu.Lock()
+			if !cs.opened {
+				cs.cMu.Unlock()
+				log.V(1).Infof("%v exiting publishRun routine", cs)
+				return
 			}
-			cs.Close()
-			cs.w.Wait()
-			// Don't restart immediatly
-			time.Sleep(clientCfg.RetryInterval)
-			goto restart
-
-		case <-cs.stop:
-			log.V(1).Infof("%v exiting publishRun routine for destination %s", cs, dest)
+			q.Dispose()
+			cs.q = queue.NewPriorityQueue(1, false)
+			cs.cMu.Unlock()
+			log.V(1).Infof("Stream of %v failed: %v, restarting", cs.name, err)
+			select {
+			case <-time.After(clientCfg.RetryInterval):
+			case <-cs.stop:
+				return
+			}
+		}
+	case Once:
+		response, err := cs.snapshot()
+		if err != nil {
+			log.V(2).Infof("Data read error %v for %v", err, cs)
 			return
 		}
+		cs.publish(response)
+		cs.publish(&gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_SyncResponse{SyncResponse: true}})
 	default:
 		log.V(1).Infof("Unsupported report type %s in %v ", cs.reportType, cs)
 	}
@@ -414,25 +405,110 @@ restart: //Remote server might go down, in that case we restart with next destin
 	Key         = TELEMETRY_CLIENT|Global
 	src_ip      = IP
 	retry_interval = 1*4DIGIT     ; In second
-	encoding    = "JSON_IETF" / "ASCII" / "BYTES" / "PROTO"
-	unidirectional = "true" / "false"    ; true by default
+	encoding    = "JSON_IETF" / "JSON" / "PROTO"
+	unidirectional = "true" / "false"    ; true by default, false to expect PublishResponse acknowledgements
 
 	// Destination group
 	Key      = TELEMETRY_CLIENT|DestinationGroup_<name>
 	dst_addr   = IP1:PORT2,IP2:PORT2       ;IP addresses separated by ","
+	policy     = "failover" / "round_robin" / "broadcast"    ; failover by default
+	buffer_size = 1*8DIGIT ; notifications kept while disconnected, 1000 by default
+	buffer_dir = PATH      ; directory keeping the buffers across restarts, in memory by default
+	client_crt = PATH      ; client certificate, optional
+	client_key = PATH      ; key of client_crt, set with client_crt
+	ca_crt     = PATH      ; CA bundle verifying the destinations, optional
+	server_name = STRING   ; name verified in the destination certificate, optional
 
 	PORT = 1*5DIGIT
 	IP = dec-octet "." dec-octet "." dec-octet "." dec-octet
 
 	// Subscription group
 	Key         = TELEMETRY_CLIENT|Subscription_<name>
-	path_target = DbName
+	path_target = DbName / "OTHERS" / "OC_YANG" / "SHOW" / "EVENTS" / ...
+	path_origin = "openconfig" / "sonic-db"   ; optional, selects the data by origin instead of path_target
 	paths       = PATH1,PATH2        ;PATH separated by ","
 	dst_group   = <name>      ; // name of DestinationGroup
 	report_type = "periodic" / "stream" / "once"
 	report_interval = 1*8DIGIT      ; In millisecond,
+
+	// Status of each key above, in STATE_DB
+	Key         = TELEMETRY_CLIENT|<key>
+	status      = "ok" / "error"
+	error       = STRING      ; why the configuration was rejected
+
+	// Connection to each destination of a subscription, in STATE_DB
+	Key         = TELEMETRY_CLIENT_DESTINATION|<subscription name>|IP:PORT
+	state       = "idle" / "connecting" / "connected" / "down"
+	con_try_cnt = 1*DIGIT     ; connection attempts
+	send_msg    = 1*DIGIT     ; notifications sent
+	recv_msg    = 1*DIGIT     ; responses received
+	errors      = 1*DIGIT     ; connections lost
+	buffered    = 1*DIGIT     ; notifications waiting for delivery
+	dropped     = 1*DIGIT     ; notifications dropped from the full buffer
 */
 
+// setConfigStatus records in STATE_DB whether the configuration of key was
+// applied, and the error rejecting it otherwise.
+func setConfigStatus(stateDb *redis.Client, separator string, key string, op string, cfgErr error) {
+	ctx := context.Background()
+	tableKey := "TELEMETRY_CLIENT" + separator + key
+	var err error
+	switch {
+	case cfgErr != nil:
+		err = stateDb.HSet(ctx, tableKey, "status", "error", "error", cfgErr.Error()).Err()
+	case op == "hdel":
+		err = stateDb.Del(ctx, tableKey).Err()
+	default:
+		if err = stateDb.HSet(ctx, tableKey, "status", "ok").Err(); err == nil {
+			err = stateDb.HDel(ctx, tableKey, "error").Err()
+		}
+	}
+	if err != nil {
+		log.V(2).Infof("Failed to write status of %v to STATE_DB: %v", tableKey, err)
+	}
+}
+
+// statsInterval is how often the connection state of the destinations is
+// written to STATE_DB.
+const statsInterval = 10 * time.Second
+
+// writeDestinationStats writes the connection state and counters of the
+// destinations of every running subscription to STATE_DB, removes the entries
+// written previously for destinations gone since, and returns the keys written.
+func writeDestinationStats(stateDb *redis.Client, separator string, previous map[string]bool) map[string]bool {
+	entries := make(map[string]map[string]interface{})
+	configMu.Lock()
+	for name, cs := range ClientSubscriptionNameMap {
+		cs.cMu.Lock()
+		links, opened := cs.links, cs.opened
+		cs.cMu.Unlock()
+		if !opened {
+			continue
+		}
+		for _, l := range links {
+			for _, st := range l.stats {
+				entries["TELEMETRY_CLIENT_DESTINATION"+separator+name+separator+st.dest.Addrs] = l.fields(st)
+			}
+		}
+	}
+	configMu.Unlock()
+
+	ctx := context.Background()
+	written := make(map[string]bool)
+	for key, fields := range entries {
+		if err := stateDb.HSet(ctx, key, fields).Err(); err != nil {
+			log.V(2).Infof("Failed to write %v to STATE_DB: %v", key, err)
+		}
+		written[key] = true
+	}
+	for key := range previous {
+		if !written[key] {
+			stateDb.Del(ctx, key)
+		}
+	}
+	return written
+}
+
 // closeDestGroupClient close client instances for all clientSubscription using
 // this Destination Group
 func closeDestGroupClient(destGroupName string) {
@@ -485,26 +561,36 @@ func processTelemetryClientConfig(ctx context.Context, redisDb *redis.Client, ke
 			log.V(2).Infof("Invalid delete operation for %v", tableKey)
 			return fmt.Errorf("Invalid delete operation for %v", tableKey)
 		} else {
+			// Validate every field before changing the running config
+			cfg := *clientCfg
 			for field, value := range fv {
 				switch field {
 				case "src_ip":
-					clientCfg.SrcIp = value
+					cfg.SrcIp = value
 				case "retry_interval":
-					//TODO: check validity of the interval
 					itvl, err := strconv.ParseUint(value, 10, 64)
-					if err != nil {
-						log.V(2).Infof("Invalid retry_interval %v %v", value, err)
-						continue
+					if err != nil || itvl == 0 {
+						log.V(2).Infof("Invalid retry_interval %v", value)
+						return fmt.Errorf("Invalid retry_interval %q, expected a positive number of seconds", value)
 					}
-					clientCfg.RetryInterval = time.Second * time.Duration(itvl)
+					cfg.RetryInterval = time.Second * time.Duration(itvl)
 				case "encoding":
-					//Flexible encoding Not supported yet
-					clientCfg.Encoding = gpb.Encoding_JSON_IETF
+					encoding, err := parseEncoding(value)
+					if err != nil {
+						log.V(2).Infof("Invalid encoding %v", value)
+						return fmt.Errorf("Invalid encoding: %v", err)
+					}
+					cfg.Encoding = encoding
 				case "unidirectional":
-					// No PublishResponse supported yet
-					clientCfg.Unidirectional = true
+					unidirectional, err := strconv.ParseBool(value)
+					if err != nil {
+						log.V(2).Infof("Invalid unidirectional %v", value)
+						return fmt.Errorf("Invalid unidirectional %q, expected true or false", value)
+					}
+					cfg.Unidirectional = unidirectional
 				}
 			}
+			*clientCfg = cfg
 			// Apply changes to all running instances
 			for grpName := range destGrpNameMap {
 				closeDestGroupClient(grpName)
@@ -525,6 +611,7 @@ func processTelemetryClientConfig(ctx context.Context, redisDb *redis.Client, ke
 				return fmt.Errorf("%v is being used: %v", destGroupName, DestGrp2ClientSubMap)
 			}
 			delete(destGrpNameMap, destGroupName)
+			delete(destGrpOptionsMap, destGroupName)
 			log.V(3).Infof("Deleted  DestinationGroup %v", destGroupName)
 			return nil
 		} else {
@@ -541,12 +628,27 @@ func processTelemetryClientConfig(ctx context.Context, redisDb *redis.Client, ke
 						}
 						dests = append(dests, Destination{Addrs: addr})
 					}
+				case "client_crt", "client_key", "ca_crt", "server_name", "policy", "buffer_size", "buffer_dir":
 				default:
 					log.V(2).Infof("Invalid DestinationGroup value %v", value)
 					return fmt.Errorf("Invalid DestinationGroup value %v", value)
 				}
 			}
+			destTLS, err := newGroupTLS(fv["client_crt"], fv["client_key"], fv["ca_crt"], fv["server_name"])
+			if err != nil {
+				log.V(2).Infof("Invalid TLS settings for %v: %v", tableKey, err)
+				return fmt.Errorf("Invalid TLS settings for %v: %v", tableKey, err)
+			}
+			for i := range dests {
+				dests[i].tls = destTLS
+			}
+			opts, err := parseDestGroupOptions(fv)
+			if err != nil {
+				log.V(2).Infof("Invalid options for %v: %v", tableKey, err)
+				return fmt.Errorf("Invalid options for %v: %v", tableKey, err)
+			}
 			destGrpNameMap[destGroupName] = dests
+			destGrpOptionsMap[destGroupName] = opts
 			setupDestGroupClients(ctx, destGroupName)
 		}
 	} else if strings.HasPrefix(key, "Subscription_") {
@@ -573,6 +675,7 @@ func processTelemetryClientConfig(ctx context.Context, redisDb *redis.Client, ke
 			DestGrp2ClientSubMap[destGrpName] = csNames
 			// Delete clientSubscription from name map
 			delete(ClientSubscriptionNameMap, name)
+			closeBuffers(name)
 			log.V(3).Infof("Deleted  Client Subscription %v", name)
 			return nil
 		} else {
@@ -597,9 +700,15 @@ func processTelemetryClientConfig(ctx context.Context, redisDb *redis.Client, ke
 					}
 					cs.interval = time.Duration(intvl) * time.Millisecond
 				case "path_target":
-					cs.prefix = &gpb.Path{
-						Target: value,
+					if cs.prefix == nil {
+						cs.prefix = &gpb.Path{}
+					}
+					cs.prefix.Target = value
+				case "path_origin":
+					if cs.prefix == nil {
+						cs.prefix = &gpb.Path{}
 					}
+					cs.prefix.Origin = value
 				case "paths":
 					ps := strings.Split(value, ",")
 					newPaths := []*gpb.Path{}
@@ -643,6 +752,33 @@ func processTelemetryClientConfig(ctx context.Context, redisDb *redis.Client, ke
 	return nil
 }
 
+// newRedisClient returns a client of the database dbName, over the unix
+// socket unless sdc.UseRedisLocalTcpPort is set.
+func newRedisClient(dbName string, ns string) (*redis.Client, error) {
+	dbn, err := sdcfg.GetDbId(dbName, ns)
+	if err != nil {
+		return nil, err
+	}
+	network := "unix"
+	var addr string
+	if sdc.UseRedisLocalTcpPort == false {
+		addr, err = sdcfg.GetDbSock(dbName, ns)
+	} else {
+		network = "tcp"
+		addr, err = sdcfg.GetDbTcpAddr(dbName, ns)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return redis.NewClient(&redis.Options{
+		Network:     network,
+		Addr:        addr,
+		Password:    "", // no password set
+		DB:          dbn,
+		DialTimeout: 0,
+	}), nil
+}
+
 // read configDB data for telemetry client and start publishing service for client subscription
 func DialOutRun(ctx context.Context, ccfg *ClientConfig) error {
 	clientCfg = ccfg
@@ -652,32 +788,15 @@ func DialOutRun(ctx context.Context, ccfg *ClientConfig) error {
 		return err
 	}
 
-	var redisDb *redis.Client
-	if sdc.UseRedisLocalTcpPort == false {
-		addr, err := sdcfg.GetDbSock("CONFIG_DB", ns)
-		if err != nil {
-			return err
-		}
-		redisDb = redis.NewClient(&redis.Options{
-			Network:     "unix",
-			Addr:        addr,
-			Password:    "", // no password set
-			DB:          dbn,
-			DialTimeout: 0,
-		})
-	} else {
-		addr, err := sdcfg.GetDbTcpAddr("CONFIG_DB", ns)
-		if err != nil {
-			return err
-		}
-		redisDb = redis.NewClient(&redis.Options{
-			Network:     "tcp",
-			Addr:        addr,
-			Password:    "", // no password set
-			DB:          dbn,
-			DialTimeout: 0,
-		})
+	redisDb, err := newRedisClient("CONFIG_DB", ns)
+	if err != nil {
+		return err
 	}
+	stateDb, err := newRedisClient("STATE_DB", ns)
+	if err != nil {
+		return err
+	}
+	defer stateDb.Close()
 
 	separator, err := sdc.GetTableKeySeparator("CONFIG_DB", ns)
 	if err != nil {
@@ -711,10 +830,17 @@ func DialOutRun(ctx context.Context, ccfg *ClientConfig) error {
 	}
 	for _, dbkey := range dbkeys {
 		dbkey = dbkey[len(dbkey_prefix):]
-		processTelemetryClientConfig(ctx, redisDb, dbkey, "hset")
+		err = processTelemetryClientConfig(ctx, redisDb, dbkey, "hset")
+		setConfigStatus(stateDb, separator, dbkey, "hset", err)
 	}
 
+	var statsWritten map[string]bool
+	var statsTime time.Time
 	for {
+		if time.Since(statsTime) >= statsInterval {
+			statsWritten = writeDestinationStats(stateDb, separator, statsWritten)
+			statsTime = time.Now()
+		}
 		msgi, err := pubsub.ReceiveTimeout(context.Background(), time.Millisecond*1000)
 		if err != nil {
 			neterr, ok := err.(net.Error)
@@ -729,9 +855,11 @@ func DialOutRun(ctx context.Context, ccfg *ClientConfig) error {
 		subscr := msgi.(*redis.Message)
 		dbkey := subscr.Channel[prefixLen:]
 		if subscr.Payload == "del" || subscr.Payload == "hdel" {
-			processTelemetryClientConfig(ctx, redisDb, dbkey, "hdel")
+			err = processTelemetryClientConfig(ctx, redisDb, dbkey, "hdel")
+			setConfigStatus(stateDb, separator, dbkey, "hdel", err)
 		} else if subscr.Payload == "hset" {
-			processTelemetryClientConfig(ctx, redisDb, dbkey, "hset")
+			err = processTelemetryClientConfig(ctx, redisDb, dbkey, "hset")
+			setConfigStatus(stateDb, separator, dbkey, "hset", err)
 		} else {
 			log.V(2).Infof("Invalid psubscribe payload notification:  %v", subscr)
 			continue
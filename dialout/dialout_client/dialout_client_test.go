@@ -588,3 +588,34 @@ func TestNewInstanceOCYang(t *testing.T) {
 		}
 	}
 }
+
+// TestNewDataClientTargets tests the data clients chosen by the target and the
+// origin of a subscription, for the cases not needing any database.
+func TestNewDataClientTargets(t *testing.T) {
+	tests := []struct {
+		desc    string
+		prefix  *pb.Path
+		report  reportType
+		wantErr string
+	}{
+		{"empty target", &pb.Path{}, Periodic, "Empty target"},
+		{"unsupported origin", &pb.Path{Origin: "unknown"}, Periodic, "Unsupported origin"},
+		{"periodic events", &pb.Path{Target: "EVENTS"}, Periodic, "stream report_type"},
+		{"streamed show", &pb.Path{Target: "SHOW"}, Stream, "SHOW does not support"},
+		{"periodic show", &pb.Path{Target: "SHOW"}, Periodic, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cs := &clientSubscription{name: tt.desc, prefix: tt.prefix, reportType: tt.report}
+			dc, err := newDataClient(context.Background(), cs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("newDataClient failed: %v", err)
+				}
+				dc.Close()
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("newDataClient error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
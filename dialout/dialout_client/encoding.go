@@ -0,0 +1,155 @@
+package telemetry_dialout
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// parseEncoding returns the encoding named in the Global configuration.
+func parseEncoding(s string) (gpb.Encoding, error) {
+	switch s {
+	case "JSON_IETF":
+		return gpb.Encoding_JSON_IETF, nil
+	case "JSON":
+		return gpb.Encoding_JSON, nil
+	case "PROTO":
+		return gpb.Encoding_PROTO, nil
+	}
+	return 0, fmt.Errorf("unsupported encoding %q, expected JSON_IETF, JSON or PROTO", s)
+}
+
+// encodeResponse re-encodes the JSON_IETF updates of resp produced by the data
+// clients. JSON only changes the type of the values, while PROTO replaces each
+// update by one update per scalar leaf, below the path of the update.
+func encodeResponse(resp *gpb.SubscribeResponse, encoding gpb.Encoding) error {
+	n := resp.GetUpdate()
+	if n == nil || encoding == gpb.Encoding_JSON_IETF {
+		return nil
+	}
+	var updates []*gpb.Update
+	for _, u := range n.Update {
+		data, ok := jsonValue(u.GetVal())
+		if !ok {
+			updates = append(updates, u)
+			continue
+		}
+		switch encoding {
+		case gpb.Encoding_JSON:
+			updates = append(updates, &gpb.Update{Path: u.Path, Val: &gpb.TypedValue{Value: &gpb.TypedValue_JsonVal{JsonVal: data}}, Duplicates: u.Duplicates})
+		case gpb.Encoding_PROTO:
+			leaves, err := scalarUpdates(u.Path, data)
+			if err != nil {
+				return err
+			}
+			updates = append(updates, leaves...)
+		default:
+			return fmt.Errorf("unsupported encoding %v", encoding)
+		}
+	}
+	n.Update = updates
+	return nil
+}
+
+func jsonValue(val *gpb.TypedValue) ([]byte, bool) {
+	switch v := val.GetValue().(type) {
+	case *gpb.TypedValue_JsonIetfVal:
+		return v.JsonIetfVal, true
+	case *gpb.TypedValue_JsonVal:
+		return v.JsonVal, true
+	}
+	return nil, false
+}
+
+// scalarUpdates returns an update for every leaf of the JSON document data,
+// whose path is path followed by the object members leading to the leaf.
+func scalarUpdates(path *gpb.Path, data []byte) ([]*gpb.Update, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON value at %v: %v", path, err)
+	}
+	var updates []*gpb.Update
+	var walk func(elems []*gpb.PathElem, v interface{})
+	walk = func(elems []*gpb.PathElem, v interface{}) {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			if tv := scalarValue(v); tv != nil {
+				p := &gpb.Path{Origin: path.GetOrigin(), Target: path.GetTarget(), Elem: elems}
+				updates = append(updates, &gpb.Update{Path: p, Val: tv})
+			}
+			return
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child := append(append([]*gpb.PathElem{}, elems...), &gpb.PathElem{Name: name})
+			walk(child, obj[name])
+		}
+	}
+	walk(path.GetElem(), doc)
+	return updates, nil
+}
+
+// scalarValue returns the typed value of a JSON leaf, or nil for null.
+func scalarValue(v interface{}) *gpb.TypedValue {
+	switch v := v.(type) {
+	case string:
+		return &gpb.TypedValue{Value: &gpb.TypedValue_StringVal{StringVal: v}}
+	case bool:
+		return &gpb.TypedValue{Value: &gpb.TypedValue_BoolVal{BoolVal: v}}
+	case json.Number:
+		if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return &gpb.TypedValue{Value: &gpb.TypedValue_IntVal{IntVal: i}}
+		}
+		if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			return &gpb.TypedValue{Value: &gpb.TypedValue_UintVal{UintVal: u}}
+		}
+		if d := decimalValue(v); d != nil {
+			return &gpb.TypedValue{Value: &gpb.TypedValue_DecimalVal{DecimalVal: d}}
+		}
+		return &gpb.TypedValue{Value: &gpb.TypedValue_JsonVal{JsonVal: []byte(v.String())}}
+	case []interface{}:
+		list := &gpb.ScalarArray{}
+		for _, e := range v {
+			if tv := scalarValue(e); tv != nil {
+				list.Element = append(list.Element, tv)
+			}
+		}
+		return &gpb.TypedValue{Value: &gpb.TypedValue_LeaflistVal{LeaflistVal: list}}
+	case map[string]interface{}:
+		data, _ := json.Marshal(v)
+		return &gpb.TypedValue{Value: &gpb.TypedValue_JsonVal{JsonVal: data}}
+	}
+	return nil
+}
+
+// decimalValue returns the exact decimal of a fractional JSON number, or nil
+// if its digits do not fit in a Decimal64. The pinned gnmi proto has no
+// double_val, and float_val would truncate the number to 32 bits.
+func decimalValue(n json.Number) *gpb.Decimal64 {
+	f, err := n.Float64()
+	if err != nil {
+		return nil
+	}
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	precision := 0
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		precision = len(s) - i - 1
+		s = s[:i] + s[i+1:]
+	}
+	digits, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &gpb.Decimal64{Digits: digits, Precision: uint32(precision)}
+}
@@ -0,0 +1,82 @@
+package telemetry_dialout
+
+import (
+	"testing"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func jsonUpdate(elems []string, data string) *gpb.SubscribeResponse {
+	path := &gpb.Path{}
+	for _, e := range elems {
+		path.Elem = append(path.Elem, &gpb.PathElem{Name: e})
+	}
+	return &gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_Update{Update: &gpb.Notification{
+		Timestamp: 1,
+		Update:    []*gpb.Update{{Path: path, Val: &gpb.TypedValue{Value: &gpb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(data)}}}},
+	}}}
+}
+
+func TestParseEncoding(t *testing.T) {
+	for s, want := range map[string]gpb.Encoding{"JSON_IETF": gpb.Encoding_JSON_IETF, "JSON": gpb.Encoding_JSON, "PROTO": gpb.Encoding_PROTO} {
+		if got, err := parseEncoding(s); err != nil || got != want {
+			t.Errorf("parseEncoding(%s) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"ASCII", "BYTES", "json_ietf", ""} {
+		if _, err := parseEncoding(s); err == nil {
+			t.Errorf("parseEncoding(%q) succeeded, want error", s)
+		}
+	}
+}
+
+func TestEncodeResponse(t *testing.T) {
+	data := `{"Ethernet0":{"SAI_PORT_STAT_IF_IN_OCTETS":"100","admin":true,"speed":-1,"big":18446744073709551615,"ratio":0.5,"precise":-3.14159265358979,"huge":1e300,"lanes":["1","2"],"none":null}}`
+
+	resp := jsonUpdate([]string{"COUNTERS"}, data)
+	if err := encodeResponse(resp, gpb.Encoding_JSON_IETF); err != nil || string(resp.GetUpdate().Update[0].Val.GetJsonIetfVal()) != data {
+		t.Errorf("JSON_IETF response changed: %v %v", resp, err)
+	}
+
+	resp = jsonUpdate([]string{"COUNTERS"}, data)
+	if err := encodeResponse(resp, gpb.Encoding_JSON); err != nil || string(resp.GetUpdate().Update[0].Val.GetJsonVal()) != data {
+		t.Errorf("Unexpected JSON response: %v %v", resp, err)
+	}
+
+	resp = jsonUpdate([]string{"COUNTERS"}, data)
+	if err := encodeResponse(resp, gpb.Encoding_PROTO); err != nil {
+		t.Fatalf("encodeResponse failed: %v", err)
+	}
+	want := map[string]*gpb.TypedValue{
+		"SAI_PORT_STAT_IF_IN_OCTETS": {Value: &gpb.TypedValue_StringVal{StringVal: "100"}},
+		"admin":                      {Value: &gpb.TypedValue_BoolVal{BoolVal: true}},
+		"big":                        {Value: &gpb.TypedValue_UintVal{UintVal: 18446744073709551615}},
+		"lanes": {Value: &gpb.TypedValue_LeaflistVal{LeaflistVal: &gpb.ScalarArray{Element: []*gpb.TypedValue{
+			{Value: &gpb.TypedValue_StringVal{StringVal: "1"}},
+			{Value: &gpb.TypedValue_StringVal{StringVal: "2"}},
+		}}}},
+		"ratio":   {Value: &gpb.TypedValue_DecimalVal{DecimalVal: &gpb.Decimal64{Digits: 5, Precision: 1}}},
+		"precise": {Value: &gpb.TypedValue_DecimalVal{DecimalVal: &gpb.Decimal64{Digits: -314159265358979, Precision: 14}}},
+		"huge":    {Value: &gpb.TypedValue_JsonVal{JsonVal: []byte("1e300")}},
+		"speed":   {Value: &gpb.TypedValue_IntVal{IntVal: -1}},
+	}
+	updates := resp.GetUpdate().Update
+	if len(updates) != len(want) {
+		t.Fatalf("got %d updates, want %d: %v", len(updates), len(want), updates)
+	}
+	for _, u := range updates {
+		elems := u.Path.Elem
+		if len(elems) != 3 || elems[0].Name != "COUNTERS" || elems[1].Name != "Ethernet0" {
+			t.Errorf("Unexpected path %v", u.Path)
+			continue
+		}
+		if !proto.Equal(u.Val, want[elems[2].Name]) {
+			t.Errorf("%s = %v, want %v", elems[2].Name, u.Val, want[elems[2].Name])
+		}
+	}
+
+	if err := encodeResponse(jsonUpdate(nil, "{"), gpb.Encoding_PROTO); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
@@ -0,0 +1,368 @@
+package telemetry_dialout
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/spool"
+	spb "github.com/sonic-net/sonic-gnmi/proto"
+)
+
+// destPolicy selects the destinations of a group receiving the notifications
+// of a subscription.
+type destPolicy int
+
+const (
+	// Failover publishes to the first destination of the group that can be
+	// reached, in the configured order, starting over from the first one on
+	// every reconnection.
+	Failover destPolicy = iota
+	// RoundRobin spreads the subscriptions over the destinations of the group,
+	// each moving to the next destination when its own fails.
+	RoundRobin
+	// Broadcast publishes to every destination of the group.
+	Broadcast
+)
+
+var policyConst = map[string]destPolicy{
+	"failover":    Failover,
+	"round_robin": RoundRobin,
+	"broadcast":   Broadcast,
+}
+
+// defaultBufferSize is how many notifications are kept for a destination
+// while it cannot be reached, unless the group sets buffer_size.
+const defaultBufferSize = 1000
+
+// destGroupOptions are the settings of a destination group besides its
+// destinations.
+type destGroupOptions struct {
+	policy     destPolicy
+	bufferSize int
+	// bufferDir keeps the buffers in files instead of memory when set.
+	bufferDir string
+}
+
+// parseDestGroupOptions returns the options of a group from its fields.
+func parseDestGroupOptions(fv map[string]string) (destGroupOptions, error) {
+	opts := destGroupOptions{policy: Failover, bufferSize: defaultBufferSize}
+	if value, ok := fv["policy"]; ok {
+		policy, ok := policyConst[value]
+		if !ok {
+			return opts, fmt.Errorf("invalid policy %q, expected failover, round_robin or broadcast", value)
+		}
+		opts.policy = policy
+	}
+	if value, ok := fv["buffer_size"]; ok {
+		var size uint
+		if _, err := fmt.Sscan(value, &size); err != nil || size == 0 {
+			return opts, fmt.Errorf("invalid buffer_size %q, expected a positive number of notifications", value)
+		}
+		opts.bufferSize = int(size)
+	}
+	if value, ok := fv["buffer_dir"]; ok {
+		if info, err := os.Stat(value); err != nil || !info.IsDir() {
+			return opts, fmt.Errorf("invalid buffer_dir %q, expected an existing directory", value)
+		}
+		opts.bufferDir = value
+	}
+	return opts, nil
+}
+
+// destStats is the connection state and the counters of a destination of a
+// subscription.
+type destStats struct {
+	dest      Destination
+	state     atomic.Value // string
+	conTryCnt atomic.Uint64
+	sendMsg   atomic.Uint64
+	recvMsg   atomic.Uint64
+	errors    atomic.Uint64
+}
+
+func newDestStats(dest Destination) *destStats {
+	st := &destStats{dest: dest}
+	st.state.Store("idle")
+	return st
+}
+
+// destLink delivers the notifications of a subscription to one destination at
+// a time, among the candidates of its policy. The notifications are queued in
+// a buffer, which holds them while no destination can be reached and replays
+// them once one can.
+type destLink struct {
+	name   string
+	policy destPolicy
+	stats  []*destStats
+	// start is the index of the first destination to connect to.
+	start int
+	buf   *spool.Spool
+	acks  pendingAcks
+}
+
+// subscriptionBuffers holds the buffers of each subscription by key, so that
+// a new instance of a subscription delivers what the previous one could not.
+// It is protected by configMu.
+var subscriptionBuffers = make(map[string]map[string]*spool.Spool)
+
+// newLinks returns the links of subscription name to dests: one per
+// destination when broadcasting, or a single one otherwise. The buffers of the
+// previous instance are kept when the options still match.
+func newLinks(name string, dests []Destination, opts destGroupOptions) ([]*destLink, error) {
+	var groups [][]Destination
+	if opts.policy == Broadcast {
+		for _, dest := range dests {
+			groups = append(groups, []Destination{dest})
+		}
+	} else {
+		groups = [][]Destination{dests}
+	}
+	previous := subscriptionBuffers[name]
+	buffers := make(map[string]*spool.Spool)
+	var links []*destLink
+	for _, group := range groups {
+		l := &destLink{name: name, policy: opts.policy}
+		for _, dest := range group {
+			l.stats = append(l.stats, newDestStats(dest))
+		}
+		if opts.policy == RoundRobin {
+			h := fnv.New32a()
+			h.Write([]byte(name))
+			l.start = int(h.Sum32() % uint32(len(group)))
+		}
+		fileName := name
+		if opts.policy == Broadcast {
+			fileName += "_" + group[0].Addrs
+		}
+		fileName = strings.NewReplacer("/", "_", ":", "_").Replace(fileName) + ".spool"
+		key := fmt.Sprintf("%s/%s:%d", opts.bufferDir, fileName, opts.bufferSize)
+		if buf, ok := previous[key]; ok {
+			l.buf = buf
+		} else if opts.bufferDir == "" {
+			l.buf = spool.NewMemory(opts.bufferSize)
+		} else {
+			buf, err := spool.Open(filepath.Join(opts.bufferDir, fileName), opts.bufferSize)
+			if err != nil {
+				for key, buf := range buffers {
+					if _, ok := previous[key]; !ok {
+						buf.Close()
+					}
+				}
+				return nil, err
+			}
+			l.buf = buf
+		}
+		buffers[key] = l.buf
+		links = append(links, l)
+	}
+	for key, buf := range previous {
+		if _, ok := buffers[key]; !ok {
+			buf.Close()
+		}
+	}
+	subscriptionBuffers[name] = buffers
+	return links, nil
+}
+
+// closeBuffers releases the buffers of subscription name once deleted.
+func closeBuffers(name string) {
+	for _, buf := range subscriptionBuffers[name] {
+		buf.Close()
+	}
+	delete(subscriptionBuffers, name)
+}
+
+// push queues the notification data for delivery.
+func (l *destLink) push(data []byte) {
+	dropped, err := l.buf.Push(data)
+	if err != nil {
+		log.V(1).Infof("Failed to buffer notification of %v: %v", l.name, err)
+	} else if dropped {
+		log.V(2).Infof("Buffer of %v full, dropped oldest notification", l.name)
+	}
+}
+
+// run connects to the destinations of the link and delivers the buffered
+// notifications until stop is closed.
+func (l *destLink) run(ctx context.Context, stop <-chan struct{}) {
+	idx := l.start
+	failures := 0
+	for {
+		st := l.stats[idx]
+		st.conTryCnt.Add(1)
+		st.state.Store("connecting")
+		up, err := l.connect(ctx, stop, st)
+		select {
+		case <-ctx.Done():
+			st.state.Store("idle")
+			return
+		case <-stop:
+			st.state.Store("idle")
+			return
+		default:
+		}
+		st.errors.Add(1)
+		st.state.Store("down")
+		log.V(1).Infof("Dialout connection to %v for %v failed: %v, cs.conTryCnt %v", st.dest.Addrs, l.name, err, st.conTryCnt.Load())
+
+		if up >= clientCfg.RetryInterval {
+			failures = 0
+		}
+		failures++
+		if up > 0 && l.policy == Failover {
+			idx = 0
+		} else {
+			idx = (idx + 1) % len(l.stats)
+		}
+		// Don't retry immediately once every destination failed
+		if failures >= len(l.stats) {
+			failures = 0
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-time.After(clientCfg.RetryInterval):
+			}
+		}
+	}
+}
+
+// connect publishes to the destination of st until the connection fails or
+// stop is closed, and returns how long the connection was up.
+func (l *destLink) connect(ctx context.Context, stop <-chan struct{}, st *destStats) (time.Duration, error) {
+	c, err := newClient(ctx, st.dest)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	pub, err := c.client.Publish(ctx)
+	if err != nil {
+		return 0, err
+	}
+	connected := time.Now()
+	st.state.Store("connected")
+	log.V(1).Infof("Dialout service connected to %v successfully for %v", st.dest.Addrs, l.name)
+	err = l.deliver(ctx, pub, st)
+	return time.Since(connected), err
+}
+
+// ready is a closed channel, selected when notifications are waiting.
+var ready = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// deliver sends the buffered notifications on pub as they come, until the
+// stream fails. In bidirectional mode the notifications left unacknowledged
+// by the previous connection are sent first, and the stream fails when one
+// stays unacknowledged for longer than the retry interval.
+func (l *destLink) deliver(ctx context.Context, pub spb.GNMIDialOut_PublishClient, st *destStats) error {
+	broken := make(chan error, 1)
+	go l.receive(pub, st, broken)
+
+	var expiry <-chan time.Time
+	if !clientCfg.Unidirectional {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		expiry = ticker.C
+		for _, resp := range l.acks.take() {
+			if err := l.send(pub, resp, st); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		m, err := l.buf.Peek()
+		if err != nil {
+			return err
+		}
+		wake := l.buf.Ready()
+		if m != nil {
+			resp := &gpb.SubscribeResponse{}
+			if err := proto.Unmarshal(m.Data, resp); err != nil {
+				log.V(1).Infof("Dropping invalid buffered notification of %v: %v", l.name, err)
+			} else if err := l.send(pub, resp, st); err != nil {
+				return err
+			}
+			if err := l.buf.Pop(m.ID); err != nil {
+				return err
+			}
+			wake = ready
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-broken:
+			return err
+		case now := <-expiry:
+			if l.acks.expired(now, clientCfg.RetryInterval) {
+				return fmt.Errorf("notifications not acknowledged within %v", clientCfg.RetryInterval)
+			}
+		case <-wake:
+		}
+	}
+}
+
+func (l *destLink) send(pub spb.GNMIDialOut_PublishClient, resp *gpb.SubscribeResponse, st *destStats) error {
+	if !clientCfg.Unidirectional {
+		l.acks.add(resp, time.Now())
+	}
+	if err := pub.Send(resp); err != nil {
+		return err
+	}
+	st.sendMsg.Add(1)
+	log.V(6).Infof("cs %s sent \n\t%v \n To %s", l.name, resp, st.dest.Addrs)
+	return nil
+}
+
+// receive reads the responses of the collector until the stream ends, which
+// it reports on broken. Responses acknowledge notifications in bidirectional
+// mode.
+func (l *destLink) receive(pub spb.GNMIDialOut_PublishClient, st *destStats, broken chan<- error) {
+	for {
+		resp, err := pub.Recv()
+		if err != nil {
+			broken <- err
+			return
+		}
+		st.recvMsg.Add(1)
+		n := l.acks.ack(resp.GetTimestamp())
+		log.V(6).Infof("cs %s got acknowledgement of %v for %d notifications", l.name, resp.GetTimestamp(), n)
+	}
+}
+
+// fields returns the fields describing st in STATE_DB.
+func (l *destLink) fields(st *destStats) map[string]interface{} {
+	held, dropped := l.buf.Stats()
+	return map[string]interface{}{
+		"state":       st.state.Load().(string),
+		"con_try_cnt": st.conTryCnt.Load(),
+		"send_msg":    st.sendMsg.Load(),
+		"recv_msg":    st.recvMsg.Load(),
+		"errors":      st.errors.Load(),
+		"buffered":    held,
+		"dropped":     dropped,
+	}
+}
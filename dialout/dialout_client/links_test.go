@@ -0,0 +1,86 @@
+package telemetry_dialout
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDestGroupOptions(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := parseDestGroupOptions(map[string]string{"dst_addr": "127.0.0.1:8080"})
+	if err != nil {
+		t.Fatalf("parseDestGroupOptions failed: %v", err)
+	}
+	if opts != (destGroupOptions{policy: Failover, bufferSize: defaultBufferSize}) {
+		t.Errorf("default options = %+v", opts)
+	}
+	opts, err = parseDestGroupOptions(map[string]string{"policy": "broadcast", "buffer_size": "10", "buffer_dir": dir})
+	if err != nil {
+		t.Fatalf("parseDestGroupOptions failed: %v", err)
+	}
+	if opts != (destGroupOptions{policy: Broadcast, bufferSize: 10, bufferDir: dir}) {
+		t.Errorf("options = %+v", opts)
+	}
+
+	for _, fv := range []map[string]string{
+		{"policy": "random"},
+		{"buffer_size": "0"},
+		{"buffer_size": "-1"},
+		{"buffer_size": "many"},
+		{"buffer_dir": filepath.Join(dir, "missing")},
+		{"buffer_dir": file},
+	} {
+		if _, err := parseDestGroupOptions(fv); err == nil {
+			t.Errorf("parseDestGroupOptions(%v) succeeded", fv)
+		}
+	}
+}
+
+func TestNewLinks(t *testing.T) {
+	dests := []Destination{{Addrs: "127.0.0.1:8080"}, {Addrs: "127.0.0.1:8081"}, {Addrs: "127.0.0.1:8082"}}
+	defer closeBuffers("HS_RDMA")
+
+	links, err := newLinks("HS_RDMA", dests, destGroupOptions{policy: Failover, bufferSize: 2})
+	if err != nil {
+		t.Fatalf("newLinks failed: %v", err)
+	}
+	if len(links) != 1 || len(links[0].stats) != 3 || links[0].start != 0 {
+		t.Errorf("failover links = %d, start %d", len(links), links[0].start)
+	}
+	links[0].push([]byte("a"))
+
+	// The buffer of the previous instance is kept
+	links, err = newLinks("HS_RDMA", dests, destGroupOptions{policy: RoundRobin, bufferSize: 2})
+	if err != nil {
+		t.Fatalf("newLinks failed: %v", err)
+	}
+	if len(links) != 1 || links[0].start >= len(dests) {
+		t.Errorf("round robin links = %d, start %d", len(links), links[0].start)
+	}
+	if held, _ := links[0].buf.Stats(); held != 1 {
+		t.Errorf("buffer holds %d notifications, want 1", held)
+	}
+
+	dir := t.TempDir()
+	links, err = newLinks("HS_RDMA", dests, destGroupOptions{policy: Broadcast, bufferSize: 2, bufferDir: dir})
+	if err != nil {
+		t.Fatalf("newLinks failed: %v", err)
+	}
+	if len(links) != len(dests) {
+		t.Fatalf("broadcast links = %d, want %d", len(links), len(dests))
+	}
+	for i, l := range links {
+		if len(l.stats) != 1 || l.stats[0].dest != dests[i] {
+			t.Errorf("link %d has destinations %v", i, l.stats)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "HS_RDMA_127.0.0.1_8080.spool")); err != nil {
+		t.Errorf("buffer file not created: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package telemetry_dialout
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// groupTLS is the TLS identity and trust of a destination group, overriding
+// the global TLS config of the client. The certificate and CA files are read
+// again whenever they change, so that connections made after a renewal use
+// the new files.
+type groupTLS struct {
+	certFile   string
+	keyFile    string
+	caFile     string
+	serverName string
+
+	mu      sync.Mutex
+	certMod time.Time
+	keyMod  time.Time
+	cert    *tls.Certificate
+	caMod   time.Time
+	roots   *x509.CertPool
+}
+
+// newGroupTLS returns the TLS settings of a destination group, or nil when it
+// has none. The files are loaded once to reject invalid settings early.
+func newGroupTLS(certFile, keyFile, caFile, serverName string) (*groupTLS, error) {
+	if certFile == "" && keyFile == "" && caFile == "" && serverName == "" {
+		return nil, nil
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("client_crt and client_key must be set together")
+	}
+	g := &groupTLS{certFile: certFile, keyFile: keyFile, caFile: caFile, serverName: serverName}
+	if _, err := g.certificate(); err != nil {
+		return nil, err
+	}
+	if _, err := g.rootCAs(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// config returns the TLS config of a new connection to the group, derived
+// from base, which may be nil.
+func (g *groupTLS) config(base *tls.Config) (*tls.Config, error) {
+	var cfg *tls.Config
+	if base != nil {
+		cfg = base.Clone()
+	} else {
+		cfg = &tls.Config{}
+	}
+	// A group naming its collector or its CA wants it verified, even if
+	// the global config does not
+	if g.serverName != "" || g.caFile != "" {
+		cfg.InsecureSkipVerify = false
+	}
+	if g.serverName != "" {
+		cfg.ServerName = g.serverName
+	}
+	if g.caFile != "" {
+		roots, err := g.rootCAs()
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = roots
+	}
+	if g.certFile != "" {
+		cfg.Certificates = nil
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return g.certificate()
+		}
+	}
+	return cfg, nil
+}
+
+// certificate returns the client certificate, reloaded if its files changed.
+func (g *groupTLS) certificate() (*tls.Certificate, error) {
+	if g.certFile == "" {
+		return nil, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	certMod, err := modTime(g.certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyMod, err := modTime(g.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	if g.cert != nil && certMod.Equal(g.certMod) && keyMod.Equal(g.keyMod) {
+		return g.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(g.certFile, g.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not load client key pair: %v", err)
+	}
+	g.cert, g.certMod, g.keyMod = &cert, certMod, keyMod
+	return g.cert, nil
+}
+
+// rootCAs returns the CA bundle, reloaded if its file changed.
+func (g *groupTLS) rootCAs() (*x509.CertPool, error) {
+	if g.caFile == "" {
+		return nil, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	caMod, err := modTime(g.caFile)
+	if err != nil {
+		return nil, err
+	}
+	if g.roots != nil && caMod.Equal(g.caMod) {
+		return g.roots, nil
+	}
+	pem, err := os.ReadFile(g.caFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read CA certificate: %v", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no CA certificate found in %s", g.caFile)
+	}
+	g.roots, g.caMod = roots, caMod
+	return g.roots, nil
+}
+
+func modTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
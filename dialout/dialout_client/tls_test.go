@@ -0,0 +1,94 @@
+package telemetry_dialout
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	testcert "github.com/sonic-net/sonic-gnmi/testdata/tls"
+)
+
+// writeCert writes a new certificate and its key in dir, returning their paths.
+func writeCert(t *testing.T, dir string) (tls.Certificate, string, string) {
+	cert, err := testcert.NewCert()
+	if err != nil {
+		t.Fatalf("could not create certificate: %v", err)
+	}
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(cert.PrivateKey.(*rsa.PrivateKey))})
+	if err := os.WriteFile(certFile, certPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, certFile, keyFile
+}
+
+func TestGroupTLS(t *testing.T) {
+	if g, err := newGroupTLS("", "", "", ""); g != nil || err != nil {
+		t.Errorf("Expected no TLS settings, got %v %v", g, err)
+	}
+	if _, err := newGroupTLS("client.crt", "", "", ""); err == nil {
+		t.Error("Expected error for certificate without key")
+	}
+	if _, err := newGroupTLS("", "", "/nonexistent/ca.crt", ""); err == nil {
+		t.Error("Expected error for missing CA bundle")
+	}
+
+	dir := t.TempDir()
+	cert, certFile, keyFile := writeCert(t, dir)
+	caFile := filepath.Join(dir, "ca.crt")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0644)
+
+	g, err := newGroupTLS(certFile, keyFile, caFile, "collector.example.com")
+	if err != nil {
+		t.Fatalf("newGroupTLS failed: %v", err)
+	}
+	base := &tls.Config{ServerName: "global", InsecureSkipVerify: true}
+	cfg, err := g.config(base)
+	if err != nil {
+		t.Fatalf("config failed: %v", err)
+	}
+	if cfg.ServerName != "collector.example.com" || cfg.RootCAs == nil || cfg.InsecureSkipVerify {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+	if base.ServerName != "global" || !base.InsecureSkipVerify {
+		t.Error("Global TLS config was modified")
+	}
+	got, err := cfg.GetClientCertificate(nil)
+	if err != nil || string(got.Certificate[0]) != string(cert.Certificate[0]) {
+		t.Errorf("Unexpected client certificate: %v", err)
+	}
+
+	// Renewed files are picked up by the next handshake
+	renewed, _, _ := writeCert(t, dir)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	os.Chtimes(keyFile, future, future)
+	got, err = cfg.GetClientCertificate(nil)
+	if err != nil || string(got.Certificate[0]) != string(renewed.Certificate[0]) {
+		t.Errorf("Client certificate not reloaded: %v", err)
+	}
+
+	// Without global TLS config, the group still uses TLS
+	cfg, err = g.config(nil)
+	if err != nil || cfg.ServerName != "collector.example.com" {
+		t.Errorf("Unexpected config without global TLS: %+v %v", cfg, err)
+	}
+
+	// A group only presenting a certificate keeps the global verification
+	g, err = newGroupTLS(certFile, keyFile, "", "")
+	if err != nil {
+		t.Fatalf("newGroupTLS failed: %v", err)
+	}
+	if cfg, err = g.config(base); err != nil || !cfg.InsecureSkipVerify {
+		t.Errorf("Expected -insecure to apply to a group without ca_crt or server_name: %+v %v", cfg, err)
+	}
+}
@@ -1,11 +1,13 @@
 package dialout_server
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	log "github.com/golang/glog"
 	"github.com/google/gnxi/utils"
 	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/tunnel"
 	spb "github.com/sonic-net/sonic-gnmi/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -15,6 +17,7 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"time"
 )
 
 var (
@@ -31,6 +34,7 @@ type Server struct {
 	clients   map[string]*Client
 	sRWMu     sync.RWMutex //for protection of appending data to data store
 	dataStore interface{}  //For storing the data received
+	tunnel    *tunnel.Server
 }
 
 // Config is a collection of values for Server
@@ -38,6 +42,21 @@ type Config struct {
 	// Port for the Server to listen on. If 0 or unset the Server will pick a port
 	// for this Server.
 	Port int64
+	// Acknowledge makes the Server answer every notification with a
+	// PublishResponse, for publish clients in bidirectional mode.
+	Acknowledge bool
+	// Tunnel serves a tunnel server too, over which switches serve gNMI.
+	Tunnel bool
+	// TunnelAdded is called for every target registered over a tunnel.
+	// Optional.
+	TunnelAdded func(tunnel.Target)
+	// AllowedPeers are the patterns, in the syntax of path.Match, of the
+	// names of the client certificates allowed to publish. Every client is
+	// allowed when empty.
+	AllowedPeers []string
+	// Sinks receive every response published. The Server closes them when
+	// stopped.
+	Sinks []Sink
 }
 
 // New returns an initialized Server.
@@ -63,6 +82,13 @@ func NewServer(config *Config, opts []grpc.ServerOption) (*Server, error) {
 		return nil, fmt.Errorf("failed to open listener port %d: %v", srv.config.Port, err)
 	}
 	spb.RegisterGNMIDialOutServer(srv.s, srv)
+	if config.Tunnel {
+		if srv.tunnel, err = tunnel.NewServer(config.TunnelAdded); err != nil {
+			srv.lis.Close()
+			return nil, fmt.Errorf("failed to create tunnel server: %v", err)
+		}
+		srv.tunnel.Register(srv.s)
+	}
 	log.V(1).Infof("Created Server on %s", srv.Address())
 	return srv, nil
 }
@@ -82,6 +108,11 @@ func (srv *Server) Stop() error {
 		return fmt.Errorf("Serve() failed: not initialized")
 	}
 	srv.s.Stop()
+	for _, sink := range srv.config.Sinks {
+		if err := sink.Close(); err != nil {
+			log.Errorf("Failed to close sink %s: %v", sink.Name(), err)
+		}
+	}
 	log.V(1).Infof("Server stopped on %s", srv.Address())
 	return nil
 }
@@ -102,6 +133,77 @@ func (srv *Server) SetDataStore(dataStore interface{}) {
 	srv.dataStore = dataStore
 }
 
+// Tunnel returns the tunnel server, nil unless Config.Tunnel is set.
+func (srv *Server) Tunnel() *tunnel.Server {
+	return srv.tunnel
+}
+
+// SubscribeTunnel subscribes with req to target over its tunnel, dialed with
+// opts, and handles the responses like the notifications published, until the
+// subscription ends.
+func (srv *Server) SubscribeTunnel(ctx context.Context, target tunnel.Target, req *gpb.SubscribeRequest, opts ...grpc.DialOption) error {
+	if srv.tunnel == nil {
+		return errors.New("tunnel server not enabled")
+	}
+	cc, err := srv.tunnel.Dial(ctx, target, opts...)
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+	connectionsMetric.Inc(target.ID)
+	defer connectionsMetric.Dec(target.ID)
+	stream, err := gpb.NewGNMIClient(cc).Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(req); err != nil {
+		return err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		srv.store(target.ID, resp)
+	}
+}
+
+// store writes resp, received from device, to the sinks and keeps it in the
+// data store. It prints resp when there is neither.
+func (srv *Server) store(device string, resp *gpb.SubscribeResponse) {
+	if n := resp.GetUpdate(); n != nil {
+		notificationsMetric.Inc(device)
+		updatesMetric.Add(float64(len(n.GetUpdate())+len(n.GetDelete())), device)
+		lastNotificationMetric.Set(float64(time.Now().UnixNano())/1e9, device)
+	}
+	for _, sink := range srv.config.Sinks {
+		if err := sink.Write(device, resp); err != nil {
+			sinkErrorsMetric.Inc(sink.Name())
+			log.V(1).Infof("Failed to write response of %s to sink %s: %v", device, sink.Name(), err)
+		}
+	}
+
+	srv.sRWMu.Lock()
+	defer srv.sRWMu.Unlock()
+	if srv.dataStore == nil {
+		if len(srv.config.Sinks) > 0 {
+			return
+		}
+		fmt.Println("== subscribeResponse:")
+		utils.PrintProto(resp)
+		return
+	}
+	switch ds := srv.dataStore.(type) {
+	default:
+		log.V(1).Infof("unexpected type %T\n", srv.dataStore)
+	case *[]*gpb.SubscribeResponse:
+		*ds = append(*ds, resp)
+	}
+}
+
 // Publish implements the GNMI DialOut Publish RPC.
 func (srv *Server) Publish(stream spb.GNMIDialOut_PublishServer) error {
 	ctx := stream.Context()
@@ -115,15 +217,15 @@ func (srv *Server) Publish(stream spb.GNMIDialOut_PublishServer) error {
 		return grpc.Errorf(codes.InvalidArgument, "failed to get peer address")
 	}
 
-	/* TODO: authorize the user
-	msg, ok := credentials.AuthorizeUser(ctx)
-	if !ok {
-		log.Infof("denied a Set request: %v", msg)
-		return nil, status.Error(codes.PermissionDenied, msg)
+	if len(srv.config.AllowedPeers) > 0 && !peerAllowed(peerNames(pr), srv.config.AllowedPeers) {
+		log.Infof("denied a Publish request from %s with certificate names %v", pr.Addr, peerNames(pr))
+		return grpc.Errorf(codes.PermissionDenied, "client certificate not allowed to publish")
 	}
-	*/
 
 	c := NewClient(pr.Addr)
+	c.device = deviceName(pr)
+	connectionsMetric.Inc(c.device)
+	defer connectionsMetric.Dec(c.device)
 
 	srv.cMu.Lock()
 	if oc, ok := srv.clients[c.String()]; ok {
@@ -148,6 +250,7 @@ func (srv *Server) Publish(stream spb.GNMIDialOut_PublishServer) error {
 // Client contains information about a subscribe client that has connected to the server.
 type Client struct {
 	addr    net.Addr
+	device  string
 	sendMsg int64
 	recvMsg int64
 	errors  int64
@@ -195,23 +298,18 @@ func (c *Client) Run(srv *Server, stream spb.GNMIDialOut_PublishServer) (err err
 			return grpc.Errorf(grpc.Code(err), "received error from client")
 		}
 
-		srv.sRWMu.Lock()
-		if srv.dataStore != nil {
-			switch ds := srv.dataStore.(type) {
-			default:
-				log.V(1).Infof("unexpected type %T\n", srv.dataStore)
-			case *[]*gpb.SubscribeResponse:
-				*ds = append(*ds, subscribeResponse)
-			}
-		}
-		srv.sRWMu.Unlock()
+		srv.store(c.device, subscribeResponse)
 
-		if srv.dataStore == nil {
-			fmt.Println("== subscribeResponse:")
-			utils.PrintProto(subscribeResponse)
+		if n := subscribeResponse.GetUpdate(); n != nil && srv.config.Acknowledge {
+			ack := &spb.PublishResponse{Timestamp: n.GetTimestamp(), Prefix: n.GetPrefix()}
+			for _, u := range n.GetUpdate() {
+				ack.Path = append(ack.Path, u.GetPath())
+			}
+			if err := stream.Send(ack); err != nil {
+				return grpc.Errorf(grpc.Code(err), "failed to acknowledge notification: %v", err)
+			}
+			c.sendMsg++
 		}
-
-		// TODO: send back (m *PublishResponse))
 	}
 	return grpc.Errorf(codes.InvalidArgument, "Exiting")
 }
@@ -0,0 +1,228 @@
+package dialout_server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/metrics"
+	"github.com/sonic-net/sonic-gnmi/pkg/tunnel"
+	spb "github.com/sonic-net/sonic-gnmi/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// gnmiServer streams one notification per subscription of a Subscribe
+// request, followed by a sync response.
+type gnmiServer struct {
+	gpb.UnimplementedGNMIServer
+}
+
+func (s *gnmiServer) Subscribe(stream gpb.GNMI_SubscribeServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	for _, sub := range req.GetSubscribe().GetSubscription() {
+		if err := stream.Send(&gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_Update{Update: &gpb.Notification{
+			Timestamp: 1,
+			Update:    []*gpb.Update{{Path: sub.GetPath()}},
+		}}}); err != nil {
+			return err
+		}
+	}
+	return stream.Send(&gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_SyncResponse{SyncResponse: true}})
+}
+
+func TestSubscribeTunnel(t *testing.T) {
+	added := make(chan tunnel.Target, 1)
+	srv, err := NewServer(&Config{Tunnel: true, TunnelAdded: func(t tunnel.Target) { added <- t }}, nil)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	go srv.Serve()
+	defer srv.Stop()
+	var store []*gpb.SubscribeResponse
+	srv.SetDataStore(&store)
+
+	// The switch serves gNMI over a tunnel to the server
+	target := tunnel.Target{ID: "switch1", Type: tunnel.TargetType}
+	lis := tunnel.Listen(srv.Address(), []tunnel.Target{target}, 100*time.Millisecond, grpc.WithInsecure())
+	gs := grpc.NewServer()
+	gpb.RegisterGNMIServer(gs, &gnmiServer{})
+	go gs.Serve(lis)
+	defer gs.Stop()
+
+	select {
+	case got := <-added:
+		if got != target {
+			t.Fatalf("registered %v, want %v", got, target)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("target not registered")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req := &gpb.SubscribeRequest{Request: &gpb.SubscribeRequest_Subscribe{Subscribe: &gpb.SubscriptionList{
+		Subscription: []*gpb.Subscription{
+			{Path: &gpb.Path{Elem: []*gpb.PathElem{{Name: "a"}}}},
+			{Path: &gpb.Path{Elem: []*gpb.PathElem{{Name: "b"}}}},
+		},
+	}}}
+	if err := srv.SubscribeTunnel(ctx, target, req, grpc.WithInsecure()); err != nil {
+		t.Fatalf("SubscribeTunnel failed: %v", err)
+	}
+	var got []string
+	for _, resp := range store {
+		if n := resp.GetUpdate(); n != nil {
+			got = append(got, n.GetUpdate()[0].GetPath().GetElem()[0].GetName())
+		} else {
+			got = append(got, fmt.Sprintf("sync %v", resp.GetSyncResponse()))
+		}
+	}
+	if fmt.Sprint(got) != "[a b sync true]" {
+		t.Errorf("stored %v, want [a b sync true]", got)
+	}
+
+	if err := (&Server{}).SubscribeTunnel(ctx, target, req); err == nil {
+		t.Error("SubscribeTunnel without tunnel server succeeded")
+	}
+}
+
+// memorySink keeps the devices of the responses written.
+type memorySink struct {
+	mu      sync.Mutex
+	devices []string
+	closed  bool
+}
+
+func (m *memorySink) Name() string { return "memory" }
+
+func (m *memorySink) Write(device string, resp *gpb.SubscribeResponse) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.devices = append(m.devices, device)
+	return nil
+}
+
+func (m *memorySink) Close() error {
+	m.closed = true
+	return nil
+}
+
+func TestPeerIdentity(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "switch1"}, DNSNames: []string{"switch1.dc1.example.com"}}
+	p := &peer.Peer{Addr: addr, AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{cert}},
+	}}}
+
+	if got := peerNames(p); fmt.Sprint(got) != "[switch1 switch1.dc1.example.com]" {
+		t.Errorf("peerNames = %v", got)
+	}
+	if got := deviceName(p); got != "switch1" {
+		t.Errorf("deviceName = %s, want switch1", got)
+	}
+	for patterns, want := range map[string]bool{
+		"*.dc1.example.com":         true,
+		"switch?":                   true,
+		"*.dc2.example.com,switch2": false,
+	} {
+		if got := peerAllowed(peerNames(p), strings.Split(patterns, ",")); got != want {
+			t.Errorf("peerAllowed(%s) = %v, want %v", patterns, got, want)
+		}
+	}
+
+	// Peers without a verified certificate are named by their address
+	p = &peer.Peer{Addr: addr, AuthInfo: credentials.TLSInfo{}}
+	if got := peerNames(p); got != nil {
+		t.Errorf("peerNames without certificate = %v", got)
+	}
+	if got := deviceName(p); got != "10.0.0.1" {
+		t.Errorf("deviceName = %s, want 10.0.0.1", got)
+	}
+}
+
+func publish(t *testing.T, srv *Server, resps ...*gpb.SubscribeResponse) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cc, err := grpc.DialContext(ctx, srv.Address(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer cc.Close()
+	stream, err := spb.NewGNMIDialOutClient(cc).Publish(ctx)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	for _, resp := range resps {
+		if err := stream.Send(resp); err != nil {
+			break
+		}
+	}
+	stream.CloseSend()
+	_, err = stream.Recv()
+	return err
+}
+
+func TestPublishSinks(t *testing.T) {
+	Metrics.Enable()
+	sink := &memorySink{}
+	srv, err := NewServer(&Config{Sinks: []Sink{sink}}, nil)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	go srv.Serve()
+
+	syncResp := &gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_SyncResponse{SyncResponse: true}}
+	publish(t, srv, counterResponse(), syncResp)
+	srv.Stop()
+	if fmt.Sprint(sink.devices) != "[127.0.0.1 127.0.0.1]" {
+		t.Errorf("sink received responses of %v", sink.devices)
+	}
+	if !sink.closed {
+		t.Error("sink not closed by Stop")
+	}
+
+	var collector, server strings.Builder
+	Metrics.Write(&collector)
+	metrics.Default.Write(&server)
+	if !strings.Contains(collector.String(), `dialout_collector_notifications_total{device="127.0.0.1"}`) {
+		t.Errorf("Expected notification counter in collector metrics:\n%s", collector.String())
+	}
+	if strings.Contains(server.String(), "dialout_collector_") {
+		t.Errorf("Expected no collector metrics in the default registry:\n%s", server.String())
+	}
+}
+
+func TestPublishAllowedPeers(t *testing.T) {
+	sink := &memorySink{}
+	srv, err := NewServer(&Config{AllowedPeers: []string{"*"}, Sinks: []Sink{sink}}, nil)
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	go srv.Serve()
+	defer srv.Stop()
+
+	// Clients without a certificate match no pattern
+	err = publish(t, srv, counterResponse())
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Publish without certificate returned %v, want PermissionDenied", err)
+	}
+	if len(sink.devices) != 0 {
+		t.Errorf("sink received responses of denied clients: %v", sink.devices)
+	}
+}
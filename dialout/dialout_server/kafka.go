@@ -0,0 +1,139 @@
+package dialout_server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// KafkaRecord is a record produced to a Kafka topic.
+type KafkaRecord struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// KafkaProducer produces records to the topics of a Kafka cluster.
+type KafkaProducer interface {
+	Produce(topic string, records []KafkaRecord) error
+	Close() error
+}
+
+// KafkaSink writes one JSON record per response to a Kafka topic. Records
+// are keyed by device, so that the responses of a device stay in order
+// within a partition.
+type KafkaSink struct {
+	producer KafkaProducer
+	topic    string
+}
+
+// NewKafkaSink returns a sink producing to topic with producer.
+func NewKafkaSink(producer KafkaProducer, topic string) *KafkaSink {
+	return &KafkaSink{producer: producer, topic: topic}
+}
+
+func (s *KafkaSink) Name() string {
+	return "kafka"
+}
+
+func (s *KafkaSink) Write(device string, resp *gpb.SubscribeResponse) error {
+	return s.WriteBatch([]Response{{Device: device, Response: resp}})
+}
+
+// WriteBatch produces the records of resps in a single request.
+func (s *KafkaSink) WriteBatch(resps []Response) error {
+	records := make([]KafkaRecord, 0, len(resps))
+	for _, r := range resps {
+		data, err := marshalRecord(r.Device, r.Response)
+		if err != nil {
+			return err
+		}
+		records = append(records, KafkaRecord{Key: []byte(r.Device), Value: data})
+	}
+	return s.producer.Produce(s.topic, records)
+}
+
+func (s *KafkaSink) Close() error {
+	return s.producer.Close()
+}
+
+const (
+	kafkaRESTContentType = "application/vnd.kafka.binary.v2+json"
+	kafkaRESTAccept      = "application/vnd.kafka.v2+json"
+	kafkaRESTTimeout     = 10 * time.Second
+	// kafkaRESTOffsetSize bounds the size of the offset of a record in the
+	// response of the proxy.
+	kafkaRESTOffsetSize = 1024
+)
+
+// KafkaRESTProducer produces records through the v2 API of a Kafka REST
+// proxy, which needs no client library on the collector.
+type KafkaRESTProducer struct {
+	url    string
+	client *http.Client
+}
+
+// NewKafkaRESTProducer returns a producer posting to the REST proxy at
+// baseURL, such as http://localhost:8082.
+func NewKafkaRESTProducer(baseURL string) *KafkaRESTProducer {
+	return &KafkaRESTProducer{
+		url:    strings.TrimSuffix(baseURL, "/"),
+		client: &http.Client{Timeout: kafkaRESTTimeout},
+	}
+}
+
+type kafkaRESTOffsets struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (p *KafkaRESTProducer) Produce(topic string, records []KafkaRecord) error {
+	// Keys and values of the binary format are base64 encoded, as []byte are
+	body, err := json.Marshal(map[string][]KafkaRecord{"records": records})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.url+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaRESTContentType)
+	req.Header.Set("Accept", kafkaRESTAccept)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to produce to %s: %v", topic, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("failed to produce to %s: %s: %s", topic, resp.Status, bytes.TrimSpace(data))
+	}
+	// The response holds an offset of about 60 bytes per record
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4096+kafkaRESTOffsetSize*int64(len(records))))
+	if err != nil {
+		return fmt.Errorf("failed to read the response producing to %s: %v", topic, err)
+	}
+	var offsets kafkaRESTOffsets
+	if err := json.Unmarshal(data, &offsets); err != nil {
+		return fmt.Errorf("invalid response producing to %s: %v", topic, err)
+	}
+	for _, o := range offsets.Offsets {
+		if o.ErrorCode != nil {
+			return fmt.Errorf("failed to produce to %s: error %d: %s", topic, *o.ErrorCode, o.Error)
+		}
+	}
+	return nil
+}
+
+func (p *KafkaRESTProducer) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,41 @@
+package dialout_server
+
+import "github.com/sonic-net/sonic-gnmi/pkg/metrics"
+
+// Metrics is the registry of the collector metrics, kept apart from the
+// metrics.Default registry of the switch side so that neither endpoint
+// exports the families of the other.
+var Metrics = metrics.NewRegistry()
+
+var (
+	connectionsMetric = Metrics.NewGaugeVec(
+		"dialout_collector_connections",
+		"Number of publish streams open to the dial-out collector by device.",
+		"device",
+	)
+	notificationsMetric = Metrics.NewCounterVec(
+		"dialout_collector_notifications_total",
+		"Number of notifications received by the dial-out collector by device.",
+		"device",
+	)
+	updatesMetric = Metrics.NewCounterVec(
+		"dialout_collector_updates_total",
+		"Number of updates and deletes received by the dial-out collector by device.",
+		"device",
+	)
+	lastNotificationMetric = Metrics.NewGaugeVec(
+		"dialout_collector_last_notification_timestamp_seconds",
+		"Unix time of the last notification received by the dial-out collector by device.",
+		"device",
+	)
+	sinkErrorsMetric = Metrics.NewCounterVec(
+		"dialout_collector_sink_errors_total",
+		"Number of notifications the dial-out collector failed to write by sink.",
+		"sink",
+	)
+	sinkQueueMetric = Metrics.NewGaugeVec(
+		"dialout_collector_sink_queue_length",
+		"Number of responses waiting to be written by sink.",
+		"sink",
+	)
+)
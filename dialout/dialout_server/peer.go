@@ -0,0 +1,49 @@
+package dialout_server
+
+import (
+	"net"
+	"path"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// peerNames returns the names of the verified client certificate of p, its
+// common name first, then its DNS names.
+func peerNames(p *peer.Peer) []string {
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	cert := info.State.VerifiedChains[0][0]
+	var names []string
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	return append(names, cert.DNSNames...)
+}
+
+// peerAllowed reports whether one of names matches one of the patterns, in
+// the syntax of path.Match.
+func peerAllowed(names, patterns []string) bool {
+	for _, name := range names {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deviceName returns the name of the device publishing over the stream of
+// p: the first name of its certificate, or else its host address.
+func deviceName(p *peer.Peer) string {
+	if names := peerNames(p); len(names) > 0 {
+		return names[0]
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
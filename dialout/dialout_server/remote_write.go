@@ -0,0 +1,231 @@
+package dialout_server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const remoteWriteTimeout = 10 * time.Second
+
+// RemoteWriteSink exports the numeric values of the updates as samples to a
+// Prometheus remote-write endpoint.
+//
+// A value is numeric when it is a number or a boolean, or a string parsing as
+// a number, as the SONiC counters are. JSON values are flattened, every member
+// adding its name to the path of the value. The name of a series joins the
+// element names of the path with underscores, and its labels are the device,
+// the target of the prefix and the keys of the path.
+type RemoteWriteSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewRemoteWriteSink returns a sink posting to the remote-write endpoint at
+// url, such as http://localhost:9090/api/v1/write.
+func NewRemoteWriteSink(url string) *RemoteWriteSink {
+	return &RemoteWriteSink{url: url, client: &http.Client{Timeout: remoteWriteTimeout}}
+}
+
+func (s *RemoteWriteSink) Name() string {
+	return "remote_write"
+}
+
+// label is a label of a series.
+type label struct {
+	name, value string
+}
+
+// sample is a value of a series at a time in milliseconds.
+type sample struct {
+	labels    []label
+	value     float64
+	timestamp int64
+}
+
+func (s *RemoteWriteSink) Write(device string, resp *gpb.SubscribeResponse) error {
+	return s.WriteBatch([]Response{{Device: device, Response: resp}})
+}
+
+// WriteBatch writes the samples of resps in a single request.
+func (s *RemoteWriteSink) WriteBatch(resps []Response) error {
+	var samples []sample
+	for _, r := range resps {
+		samples = append(samples, notificationSamples(r.Device, r.Response.GetUpdate())...)
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(snappy.Encode(nil, encodeWriteRequest(samples))))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	r, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to write samples: %v", err)
+	}
+	defer r.Body.Close()
+	if r.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(r.Body, 4096))
+		return fmt.Errorf("failed to write samples: %s: %s", r.Status, bytes.TrimSpace(data))
+	}
+	return nil
+}
+
+func (s *RemoteWriteSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// notificationSamples returns the samples of the numeric values of n.
+func notificationSamples(device string, n *gpb.Notification) []sample {
+	if n == nil {
+		return nil
+	}
+	timestamp := n.GetTimestamp() / int64(time.Millisecond)
+	if timestamp == 0 {
+		timestamp = time.Now().UnixMilli()
+	}
+	var samples []sample
+	for _, u := range n.GetUpdate() {
+		var names []string
+		labels := []label{{"device", device}}
+		if target := n.GetPrefix().GetTarget(); target != "" {
+			labels = append(labels, label{"target", target})
+		}
+		for _, p := range []*gpb.Path{n.GetPrefix(), u.GetPath()} {
+			for _, e := range p.GetElem() {
+				names = append(names, e.GetName())
+				for k, v := range e.GetKey() {
+					labels = addLabel(labels, label{sanitizeName(k), v})
+				}
+			}
+		}
+		walkValue(u.GetVal(), names, func(names []string, v float64) {
+			if len(names) == 0 {
+				return
+			}
+			ls := append([]label{{"__name__", sanitizeName(strings.Join(names, "_"))}}, labels...)
+			sort.SliceStable(ls, func(i, j int) bool { return ls[i].name < ls[j].name })
+			samples = append(samples, sample{labels: ls, value: v, timestamp: timestamp})
+		})
+	}
+	return samples
+}
+
+// addLabel adds l to labels, unless they have a label of the same name.
+func addLabel(labels []label, l label) []label {
+	for _, o := range labels {
+		if o.name == l.name {
+			return labels
+		}
+	}
+	return append(labels, l)
+}
+
+// walkValue calls fn with the path and value of every numeric value of val.
+func walkValue(val *gpb.TypedValue, names []string, fn func([]string, float64)) {
+	switch v := val.GetValue().(type) {
+	case *gpb.TypedValue_IntVal:
+		fn(names, float64(v.IntVal))
+	case *gpb.TypedValue_UintVal:
+		fn(names, float64(v.UintVal))
+	case *gpb.TypedValue_FloatVal:
+		fn(names, float64(v.FloatVal))
+	case *gpb.TypedValue_BoolVal:
+		walkJSON(v.BoolVal, names, fn)
+	case *gpb.TypedValue_StringVal:
+		walkJSON(v.StringVal, names, fn)
+	case *gpb.TypedValue_JsonVal:
+		walkRawJSON(v.JsonVal, names, fn)
+	case *gpb.TypedValue_JsonIetfVal:
+		walkRawJSON(v.JsonIetfVal, names, fn)
+	}
+}
+
+func walkRawJSON(data []byte, names []string, fn func([]string, float64)) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err == nil {
+		walkJSON(v, names, fn)
+	}
+}
+
+func walkJSON(v interface{}, names []string, fn func([]string, float64)) {
+	switch v := v.(type) {
+	case float64:
+		fn(names, v)
+	case bool:
+		if v {
+			fn(names, 1)
+		} else {
+			fn(names, 0)
+		}
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			fn(names, f)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkJSON(v[k], append(names[:len(names):len(names)], k), fn)
+		}
+	}
+}
+
+// sanitizeName replaces the characters not allowed in metric and label names
+// with underscores.
+func sanitizeName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// encodeWriteRequest returns samples as a remote-write WriteRequest, with one
+// time series per sample.
+func encodeWriteRequest(samples []sample) []byte {
+	var req []byte
+	for _, s := range samples {
+		var ts []byte
+		for _, l := range s.labels {
+			var lb []byte
+			lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.name)
+			lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+			lb = protowire.AppendString(lb, l.value)
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, lb)
+		}
+		var sb []byte
+		sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+		sb = protowire.AppendFixed64(sb, math.Float64bits(s.value))
+		sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+		sb = protowire.AppendVarint(sb, uint64(s.timestamp))
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sb)
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, ts)
+	}
+	return req
+}
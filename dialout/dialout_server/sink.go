@@ -0,0 +1,177 @@
+package dialout_server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/rotatefile"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Sink receives the responses published to the collector. Write is called
+// concurrently for the streams of different devices.
+type Sink interface {
+	// Name identifies the sink in logs and metrics.
+	Name() string
+	// Write handles resp, received from device.
+	Write(device string, resp *gpb.SubscribeResponse) error
+	Close() error
+}
+
+// Response is a response received from a device.
+type Response struct {
+	Device   string
+	Response *gpb.SubscribeResponse
+}
+
+// BatchSink is a sink which also writes several responses at once, such as
+// in a single request to a remote service.
+type BatchSink interface {
+	Sink
+	WriteBatch(resps []Response) error
+}
+
+// QueuedSink writes the responses to a BatchSink from a bounded queue, so
+// that a slow sink does not hold up the Publish streams. A single goroutine
+// writes the responses in order, batching those queued while the previous
+// batch was written. Write drops the response and fails when the queue is
+// full.
+type QueuedSink struct {
+	sink      BatchSink
+	batchSize int
+	mu        sync.RWMutex
+	closed    bool
+	queue     chan Response
+	done      chan struct{}
+}
+
+// NewQueuedSink returns a sink queueing up to queueSize responses for sink,
+// written in batches of up to batchSize responses.
+func NewQueuedSink(sink BatchSink, queueSize, batchSize int) *QueuedSink {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	s := &QueuedSink{
+		sink:      sink,
+		batchSize: batchSize,
+		queue:     make(chan Response, queueSize),
+		done:      make(chan struct{}),
+	}
+	sinkQueueMetric.SetFunc(func() float64 { return float64(len(s.queue)) }, sink.Name())
+	go s.run()
+	return s
+}
+
+func (s *QueuedSink) Name() string {
+	return s.sink.Name()
+}
+
+func (s *QueuedSink) Write(device string, resp *gpb.SubscribeResponse) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return fmt.Errorf("sink %s is closed", s.sink.Name())
+	}
+	select {
+	case s.queue <- Response{Device: device, Response: resp}:
+		return nil
+	default:
+		return fmt.Errorf("queue of sink %s is full, dropped response of %s", s.sink.Name(), device)
+	}
+}
+
+// run writes the queued responses until the queue is closed and drained.
+func (s *QueuedSink) run() {
+	defer close(s.done)
+	for resp := range s.queue {
+		batch := []Response{resp}
+	fill:
+		for len(batch) < s.batchSize {
+			select {
+			case resp, ok := <-s.queue:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, resp)
+			default:
+				break fill
+			}
+		}
+		if err := s.sink.WriteBatch(batch); err != nil {
+			sinkErrorsMetric.Add(float64(len(batch)), s.sink.Name())
+			log.V(1).Infof("Failed to write %d responses to sink %s: %v", len(batch), s.sink.Name(), err)
+		}
+	}
+}
+
+// Close writes the responses left in the queue, then closes the sink.
+func (s *QueuedSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+	<-s.done
+	sinkQueueMetric.Delete(s.sink.Name())
+	return s.sink.Close()
+}
+
+// record is the JSON form of a response written by the sinks.
+type record struct {
+	Device   string          `json:"device"`
+	Received time.Time       `json:"received"`
+	Response json.RawMessage `json:"response"`
+}
+
+// marshalRecord returns resp received from device as a JSON object, with
+// resp in the canonical protobuf JSON mapping.
+func marshalRecord(device string, resp *gpb.SubscribeResponse) ([]byte, error) {
+	data, err := protojson.Marshal(proto.MessageV2(resp))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response of %s: %v", device, err)
+	}
+	return json.Marshal(&record{Device: device, Received: time.Now().UTC(), Response: data})
+}
+
+// JSONLinesSink writes one JSON record per response to a local file, rotated
+// when it grows too large.
+type JSONLinesSink struct {
+	file *rotatefile.Writer
+}
+
+// NewJSONLinesSink opens or creates the file at path for appending. The file
+// is rotated when it grows past maxSize bytes, keeping maxBackups files.
+func NewJSONLinesSink(path string, maxSize int64, maxBackups int) (*JSONLinesSink, error) {
+	file, err := rotatefile.Open(path, maxSize, maxBackups)
+	if err != nil {
+		return nil, fmt.Errorf("jsonl sink: %v", err)
+	}
+	return &JSONLinesSink{file: file}, nil
+}
+
+func (s *JSONLinesSink) Name() string {
+	return "jsonl"
+}
+
+func (s *JSONLinesSink) Write(device string, resp *gpb.SubscribeResponse) error {
+	data, err := marshalRecord(device, resp)
+	if err != nil {
+		return err
+	}
+	if err := s.file.WriteLine(data); err != nil {
+		return fmt.Errorf("jsonl sink: %v", err)
+	}
+	return nil
+}
+
+func (s *JSONLinesSink) Close() error {
+	return s.file.Close()
+}
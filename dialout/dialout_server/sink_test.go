@@ -0,0 +1,297 @@
+package dialout_server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func counterResponse() *gpb.SubscribeResponse {
+	return &gpb.SubscribeResponse{Response: &gpb.SubscribeResponse_Update{Update: &gpb.Notification{
+		Timestamp: 1500000000,
+		Prefix:    &gpb.Path{Target: "COUNTERS_DB"},
+		Update: []*gpb.Update{{
+			Path: &gpb.Path{Elem: []*gpb.PathElem{{Name: "COUNTERS"}, {Name: "Ethernet0"}}},
+			Val: &gpb.TypedValue{Value: &gpb.TypedValue_JsonIetfVal{JsonIetfVal: []byte(
+				`{"SAI_PORT_STAT_IF_IN_OCTETS":"42","oper-status":"up","admin":true}`)}},
+		}, {
+			Path: &gpb.Path{Elem: []*gpb.PathElem{{Name: "interfaces"}, {Name: "interface", Key: map[string]string{"name": "Ethernet4"}}, {Name: "mtu"}}},
+			Val:  &gpb.TypedValue{Value: &gpb.TypedValue_UintVal{UintVal: 9100}},
+		}},
+	}}}
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collector.jsonl")
+	sink, err := NewJSONLinesSink(path, 0, 0)
+	if err != nil {
+		t.Fatalf("NewJSONLinesSink failed: %v", err)
+	}
+	for _, device := range []string{"switch1", "switch2"} {
+		if err := sink.Write(device, counterResponse()); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	sink.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var devices []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r struct {
+			Device   string
+			Response struct {
+				Update struct{ Prefix struct{ Target string } }
+			}
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("invalid line %s: %v", scanner.Text(), err)
+		}
+		if r.Response.Update.Prefix.Target != "COUNTERS_DB" {
+			t.Errorf("line without notification: %s", scanner.Text())
+		}
+		devices = append(devices, r.Device)
+	}
+	if fmt.Sprint(devices) != "[switch1 switch2]" {
+		t.Errorf("devices = %v, want [switch1 switch2]", devices)
+	}
+}
+
+func TestKafkaSink(t *testing.T) {
+	var got []KafkaRecord
+	fail := false
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics/telemetry" || r.Header.Get("Content-Type") != kafkaRESTContentType {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		var body struct{ Records []KafkaRecord }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		got = append(got, body.Records...)
+		// One offset per record, the last one failing on demand
+		var offsets []string
+		for i := range body.Records {
+			if fail && i == len(body.Records)-1 {
+				offsets = append(offsets, `{"partition":null,"offset":null,"error_code":50003,"error":"timeout"}`)
+			} else {
+				offsets = append(offsets, fmt.Sprintf(`{"partition":0,"offset":%d,"error_code":null,"error":null}`, len(got)-len(body.Records)+i))
+			}
+		}
+		io.WriteString(w, `{"offsets":[`+strings.Join(offsets, ",")+`]}`)
+	}))
+	defer stub.Close()
+
+	sink := NewKafkaSink(NewKafkaRESTProducer(stub.URL+"/"), "telemetry")
+	defer sink.Close()
+	if err := sink.Write("switch1", counterResponse()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if len(got) != 1 || string(got[0].Key) != "switch1" || !strings.Contains(string(got[0].Value), `"COUNTERS_DB"`) {
+		t.Errorf("produced %q", got)
+	}
+	got = nil
+	if err := sink.WriteBatch([]Response{{"switch1", counterResponse()}, {"switch2", counterResponse()}}); err != nil {
+		t.Fatalf("WriteBatch failed: %v", err)
+	}
+	if len(got) != 2 || string(got[0].Key) != "switch1" || string(got[1].Key) != "switch2" {
+		t.Errorf("produced batch %q", got)
+	}
+	// The response to a large batch is read whole
+	got = nil
+	batch := make([]Response, 500)
+	for i := range batch {
+		batch[i] = Response{fmt.Sprintf("switch%d", i), counterResponse()}
+	}
+	if err := sink.WriteBatch(batch); err != nil || len(got) != len(batch) {
+		t.Errorf("WriteBatch of %d responses produced %d records: %v", len(batch), len(got), err)
+	}
+	fail = true
+	if err := sink.WriteBatch(batch); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("WriteBatch with a failed last record returned %v", err)
+	}
+	if err := sink.Write("switch1", counterResponse()); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("Write with a failed record returned %v", err)
+	}
+	if err := NewKafkaSink(NewKafkaRESTProducer(stub.URL), "other").Write("switch1", counterResponse()); err == nil {
+		t.Error("Write rejected by the proxy succeeded")
+	}
+}
+
+// decodeFields returns the fields of a protobuf message, by number.
+func decodeFields(t *testing.T, b []byte) map[protowire.Number][][]byte {
+	fields := map[protowire.Number][][]byte{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		var v []byte
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.Fixed64Type:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			v = b[:n]
+		case protowire.VarintType:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			v = b[:n]
+		}
+		if n < 0 {
+			t.Fatalf("invalid field %d", num)
+		}
+		fields[num] = append(fields[num], v)
+		b = b[n:]
+	}
+	return fields
+}
+
+func TestRemoteWriteSink(t *testing.T) {
+	var got []string
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("X-Prometheus-Remote-Write-Version") == "" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		req, err := snappy.Decode(nil, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, ts := range decodeFields(t, req)[1] {
+			fields := decodeFields(t, ts)
+			var labels []string
+			for _, l := range fields[1] {
+				lf := decodeFields(t, l)
+				labels = append(labels, fmt.Sprintf("%s=%s", lf[1][0], lf[2][0]))
+			}
+			sf := decodeFields(t, fields[2][0])
+			value := math.Float64frombits(binary.LittleEndian.Uint64(sf[1][0]))
+			ms, _ := protowire.ConsumeVarint(sf[2][0])
+			got = append(got, fmt.Sprintf("%s %v %d", strings.Join(labels, ","), value, ms))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer stub.Close()
+
+	sink := NewRemoteWriteSink(stub.URL)
+	defer sink.Close()
+	if err := sink.Write("switch1", counterResponse()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	sort.Strings(got)
+	want := []string{
+		"__name__=COUNTERS_Ethernet0_SAI_PORT_STAT_IF_IN_OCTETS,device=switch1,target=COUNTERS_DB 42 1500",
+		"__name__=COUNTERS_Ethernet0_admin,device=switch1,target=COUNTERS_DB 1 1500",
+		"__name__=interfaces_interface_mtu,device=switch1,name=Ethernet4,target=COUNTERS_DB 9100 1500",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("samples:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	got = nil
+	if err := sink.WriteBatch([]Response{{"switch1", counterResponse()}, {"switch2", counterResponse()}}); err != nil {
+		t.Fatalf("WriteBatch failed: %v", err)
+	}
+	if len(got) != 6 {
+		t.Errorf("batch samples:\n%s", strings.Join(got, "\n"))
+	}
+
+	// Responses without numeric values are not sent
+	if err := NewRemoteWriteSink("http://127.0.0.1:1").Write("switch1", &gpb.SubscribeResponse{}); err != nil {
+		t.Errorf("Write of a sync response failed: %v", err)
+	}
+}
+
+// blockingSink records the batches written to it, holding the first batch
+// until release is closed.
+type blockingSink struct {
+	release chan struct{}
+	mu      sync.Mutex
+	batches [][]string
+	closed  bool
+}
+
+func (s *blockingSink) Name() string {
+	return "blocking"
+}
+
+func (s *blockingSink) Write(device string, resp *gpb.SubscribeResponse) error {
+	return s.WriteBatch([]Response{{Device: device, Response: resp}})
+}
+
+func (s *blockingSink) WriteBatch(resps []Response) error {
+	<-s.release
+	var devices []string
+	for _, r := range resps {
+		devices = append(devices, r.Device)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, devices)
+	return nil
+}
+
+func (s *blockingSink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestQueuedSink(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	queued := NewQueuedSink(sink, 3, 2)
+	if queued.Name() != "blocking" {
+		t.Errorf("Name = %s, want blocking", queued.Name())
+	}
+
+	// The first response is taken by the writer, which blocks on it
+	if err := queued.Write("switch0", counterResponse()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	for len(queued.queue) != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 1; i <= 3; i++ {
+		if err := queued.Write(fmt.Sprintf("switch%d", i), counterResponse()); err != nil {
+			t.Fatalf("Write %d failed: %v", i, err)
+		}
+	}
+	if err := queued.Write("switch4", counterResponse()); err == nil || !strings.Contains(err.Error(), "full") {
+		t.Errorf("Write to a full queue returned %v", err)
+	}
+
+	close(sink.release)
+	if err := queued.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := fmt.Sprint(sink.batches); got != "[[switch0] [switch1 switch2] [switch3]]" {
+		t.Errorf("batches = %s", got)
+	}
+	if !sink.closed {
+		t.Error("sink not closed by Close")
+	}
+	if err := queued.Write("switch5", counterResponse()); err == nil {
+		t.Error("Write after Close succeeded")
+	}
+}
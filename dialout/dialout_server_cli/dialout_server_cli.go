@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"flag"
 	"io/ioutil"
+	"strings"
 
 	log "github.com/golang/glog"
+	gpb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/ygot/ygot"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 
 	ds "github.com/sonic-net/sonic-gnmi/dialout/dialout_server"
+	"github.com/sonic-net/sonic-gnmi/pkg/tunnel"
 	testcert "github.com/sonic-net/sonic-gnmi/testdata/tls"
 )
 
@@ -22,6 +27,20 @@ var (
 	serverKey         = flag.String("server_key", "", "TLS server private key")
 	insecure          = flag.Bool("insecure", false, "Skip providing TLS cert and key, for testing only!")
 	allowNoClientCert = flag.Bool("allow_no_client_auth", false, "When set, telemetry server will request but not require a client certificate.")
+	acknowledge       = flag.Bool("acknowledge", false, "When set, acknowledge every notification with a PublishResponse, for bidirectional clients.")
+	tunnelServer      = flag.Bool("tunnel", false, "When set, also serve a tunnel server, over which switches serve gNMI.")
+	tunnelPaths       = flag.String("tunnel_paths", "", "Comma separated paths subscribed in stream mode on every target registered over a tunnel.")
+	tunnelTarget      = flag.String("tunnel_path_target", "", "Target of the prefix of the tunnel subscriptions, such as COUNTERS_DB.")
+	allowedPeers      = flag.String("allowed_peers", "", "Comma separated patterns of the client certificate names allowed to publish, such as *.dc1.example.com. Every client is allowed when empty.")
+	metricsAddress    = flag.String("metrics_address", "", "Address serving the collector metrics on /metrics, as host:port or unix:/path. Disabled when empty.")
+	jsonlFile         = flag.String("jsonl_file", "", "File to which every response is written as a JSON line. Disabled when empty.")
+	jsonlMaxSize      = flag.Int64("jsonl_max_size", 100, "Size in MB after which the jsonl_file is rotated, 0 for no rotation.")
+	jsonlMaxBackups   = flag.Int("jsonl_max_backups", 5, "Number of rotated jsonl_file files kept.")
+	kafkaURL          = flag.String("kafka_rest_url", "", "URL of the Kafka REST proxy to which every response is produced, such as http://localhost:8082. Disabled when empty.")
+	kafkaTopic        = flag.String("kafka_topic", "sonic-telemetry", "Kafka topic of the responses.")
+	remoteWriteURL    = flag.String("remote_write_url", "", "Prometheus remote-write URL to which numeric values are exported, such as http://localhost:9090/api/v1/write. Disabled when empty.")
+	sinkQueueSize     = flag.Int("sink_queue_size", 10000, "Number of responses queued for the kafka_rest_url and remote_write_url sinks, past which responses are dropped.")
+	sinkBatchSize     = flag.Int("sink_batch_size", 500, "Maximum number of queued responses sent to the kafka_rest_url and remote_write_url sinks in one request.")
 )
 
 func main() {
@@ -81,7 +100,50 @@ func main() {
 	opts := []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsCfg))}
 	cfg := &ds.Config{}
 	cfg.Port = int64(*port)
-	s, err := ds.NewServer(cfg, opts)
+	cfg.Acknowledge = *acknowledge
+	if *allowedPeers != "" {
+		cfg.AllowedPeers = strings.Split(*allowedPeers, ",")
+	}
+	if *jsonlFile != "" {
+		sink, err := ds.NewJSONLinesSink(*jsonlFile, *jsonlMaxSize*1024*1024, *jsonlMaxBackups)
+		if err != nil {
+			log.Exitf("could not create jsonl sink: %v", err)
+		}
+		cfg.Sinks = append(cfg.Sinks, sink)
+	}
+	if *kafkaURL != "" {
+		sink := ds.NewKafkaSink(ds.NewKafkaRESTProducer(*kafkaURL), *kafkaTopic)
+		cfg.Sinks = append(cfg.Sinks, ds.NewQueuedSink(sink, *sinkQueueSize, *sinkBatchSize))
+	}
+	if *remoteWriteURL != "" {
+		cfg.Sinks = append(cfg.Sinks, ds.NewQueuedSink(ds.NewRemoteWriteSink(*remoteWriteURL), *sinkQueueSize, *sinkBatchSize))
+	}
+	if *metricsAddress != "" {
+		if _, err := ds.Metrics.Serve(*metricsAddress); err != nil {
+			log.Exitf("could not serve metrics: %v", err)
+		}
+	}
+	var s *ds.Server
+	if *tunnelServer {
+		req, err := tunnelRequest()
+		if err != nil {
+			log.Exitf("invalid tunnel_paths: %v", err)
+		}
+		// Switches are dialed over the tunnels with the server certificate
+		dialOpt := grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			Certificates:       []tls.Certificate{certificate},
+			RootCAs:            tlsCfg.ClientCAs,
+			InsecureSkipVerify: *insecure,
+		}))
+		cfg.Tunnel = true
+		cfg.TunnelAdded = func(target tunnel.Target) {
+			if req != nil {
+				err := s.SubscribeTunnel(context.Background(), target, req, dialOpt)
+				log.V(1).Infof("Subscription to %s over tunnel ended: %v", target.ID, err)
+			}
+		}
+	}
+	s, err = ds.NewServer(cfg, opts)
 	if err != nil {
 		log.Errorf("Failed to create gNMI server: %v", err)
 		return
@@ -91,3 +153,23 @@ func main() {
 	s.Serve() // blocks until close
 	log.Flush()
 }
+
+// tunnelRequest returns the subscription to tunnel_paths, nil when empty.
+func tunnelRequest() (*gpb.SubscribeRequest, error) {
+	if *tunnelPaths == "" {
+		return nil, nil
+	}
+	list := &gpb.SubscriptionList{
+		Prefix:   &gpb.Path{Target: *tunnelTarget},
+		Mode:     gpb.SubscriptionList_STREAM,
+		Encoding: gpb.Encoding_JSON_IETF,
+	}
+	for _, p := range strings.Split(*tunnelPaths, ",") {
+		path, err := ygot.StringToPath(p, ygot.StructuredPath)
+		if err != nil {
+			return nil, err
+		}
+		list.Subscription = append(list.Subscription, &gpb.Subscription{Path: path, Mode: gpb.SubscriptionMode_ON_CHANGE})
+	}
+	return &gpb.SubscribeRequest{Request: &gpb.SubscribeRequest_Subscribe{Subscribe: list}}, nil
+}
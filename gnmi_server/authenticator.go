@@ -0,0 +1,245 @@
+package gnmi
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/sonic-net/sonic-gnmi/common_utils"
+	"github.com/sonic-net/sonic-gnmi/pkg/tacacs"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/peer"
+)
+
+// Authenticator checks user passwords for the password mode of client_auth
+// and for the tokens issued by the gNOI JWT Authenticate RPC.
+type Authenticator interface {
+	// Name identifies the backend in logs.
+	Name() string
+	// Authenticate returns the roles of username when password is valid,
+	// and an error wrapping ErrInvalidCredentials when the backend rejects
+	// them.
+	Authenticate(ctx context.Context, username, password string) ([]string, error)
+}
+
+// ErrInvalidCredentials is returned by authenticators rejecting a user.
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// authenticator returns the backend checking passwords, PAM by default.
+func (c *Config) authenticator() Authenticator {
+	if c.Authenticator == nil {
+		return PAMAuthenticator{}
+	}
+	return c.Authenticator
+}
+
+// scopedRoles reports whether the roles returned by a follow the
+// <target>_readonly/readwrite scheme and are checked like those of client
+// certificates. PAM returns the Unix groups of local users, which keep full
+// access.
+func scopedRoles(a Authenticator) bool {
+	_, isPAM := a.(PAMAuthenticator)
+	return !isPAM
+}
+
+// PAMAuthenticator authenticates local users, whose roles are their Unix
+// groups.
+type PAMAuthenticator struct{}
+
+func (PAMAuthenticator) Name() string {
+	return "pam"
+}
+
+func (PAMAuthenticator) Authenticate(ctx context.Context, username, password string) ([]string, error) {
+	var auth common_utils.AuthInfo
+	if err := PopulateAuthStruct(username, &auth, nil); err != nil {
+		return nil, err
+	}
+	if ok, _ := UserPwAuth(username, password); !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return auth.Roles, nil
+}
+
+// fileUser is an entry of a credentials file.
+type fileUser struct {
+	hash  []byte
+	roles []string
+}
+
+// FileAuthenticator checks passwords against a local credentials file. Each
+// line holds a user, the bcrypt hash of its password and its roles separated
+// by commas, for example
+//
+//	admin:$2y$10$...:gnmi_readwrite,gnoi_readwrite
+//
+// Empty lines and lines starting with # are ignored. The file is read again
+// when it changes, an invalid file keeps the previous users.
+type FileAuthenticator struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	users   map[string]fileUser
+}
+
+// NewFileAuthenticator loads the credentials file at path.
+func NewFileAuthenticator(path string) (*FileAuthenticator, error) {
+	a := &FileAuthenticator{path: path}
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *FileAuthenticator) Name() string {
+	return "file"
+}
+
+func (a *FileAuthenticator) Authenticate(ctx context.Context, username, password string) ([]string, error) {
+	a.mu.Lock()
+	if err := a.load(); err != nil {
+		log.Errorf("Failed to reload credentials file, keeping previous users: %v", err)
+	}
+	user, ok := a.users[username]
+	a.mu.Unlock()
+
+	if !ok {
+		// Spend the time of a check so that unknown users cannot be told apart
+		bcrypt.CompareHashAndPassword(unknownUserHash(), []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword(user.hash, []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user.roles, nil
+}
+
+var (
+	unknownUserOnce      sync.Once
+	unknownUserHashValue []byte
+)
+
+func unknownUserHash() []byte {
+	unknownUserOnce.Do(func() {
+		unknownUserHashValue, _ = bcrypt.GenerateFromPassword([]byte("unknown user"), bcrypt.DefaultCost)
+	})
+	return unknownUserHashValue
+}
+
+// load reads the credentials file when it changed since the last load.
+func (a *FileAuthenticator) load() error {
+	info, err := os.Stat(a.path)
+	if err != nil {
+		return err
+	}
+	if a.users != nil && info.ModTime().Equal(a.modTime) && info.Size() == a.size {
+		return nil
+	}
+	file, err := os.Open(a.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	users := make(map[string]fileUser)
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) != 3 || fields[0] == "" {
+			return fmt.Errorf("%s:%d: expected user:hash:roles", a.path, lineNo)
+		}
+		if _, err := bcrypt.Cost([]byte(fields[1])); err != nil {
+			return fmt.Errorf("%s:%d: invalid bcrypt hash: %v", a.path, lineNo, err)
+		}
+		var roles []string
+		for _, role := range strings.Split(fields[2], ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+		users[fields[0]] = fileUser{hash: []byte(fields[1]), roles: roles}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	a.users, a.modTime, a.size = users, info.ModTime(), info.Size()
+	log.V(1).Infof("Loaded %d users from %s", len(users), a.path)
+	return nil
+}
+
+// tacacsService is the service the roles of TACACS+ users are authorized for.
+const tacacsService = "sonic-gnmi"
+
+// TacacsAuthenticator checks passwords with TACACS+ servers. The roles of a
+// user come from the authorization of service sonic-gnmi: the roles attribute
+// lists them separated by commas, otherwise priv-lvl 15 grants readwrite and
+// lower levels readonly access to gNMI and gNOI.
+type TacacsAuthenticator struct {
+	client *tacacs.Client
+}
+
+// NewTacacsAuthenticator returns an authenticator using client.
+func NewTacacsAuthenticator(client *tacacs.Client) *TacacsAuthenticator {
+	return &TacacsAuthenticator{client: client}
+}
+
+func (a *TacacsAuthenticator) Name() string {
+	return "tacacs"
+}
+
+func (a *TacacsAuthenticator) Authenticate(ctx context.Context, username, password string) ([]string, error) {
+	remAddr := ""
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		if host, _, err := net.SplitHostPort(pr.Addr.String()); err == nil {
+			remAddr = host
+		}
+	}
+	err := a.client.Authenticate(ctx, username, password, remAddr)
+	if errors.Is(err, tacacs.ErrRejected) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := a.client.Authorize(ctx, username, remAddr, []string{"service=" + tacacsService})
+	if errors.Is(err, tacacs.ErrRejected) {
+		return nil, fmt.Errorf("%w: %s not authorized for %s", ErrInvalidCredentials, username, tacacsService)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tacacsRoles(attrs), nil
+}
+
+// tacacsRoles maps the authorization attributes of a user to roles.
+func tacacsRoles(attrs map[string]string) []string {
+	if value, ok := attrs["roles"]; ok {
+		var roles []string
+		for _, role := range strings.Split(value, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				roles = append(roles, role)
+			}
+		}
+		return roles
+	}
+	mode := ReadOnlyMode
+	if level, err := strconv.Atoi(attrs["priv-lvl"]); err == nil && level >= 15 {
+		mode = WriteAccessMode
+	}
+	return []string{"gnmi_" + mode, "gnoi_" + mode}
+}
@@ -0,0 +1,129 @@
+package gnmi
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func writeCredentials(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	content := ""
+	for _, line := range lines {
+		content += line + "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write credentials: %v", err)
+	}
+}
+
+func bcryptHash(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	return string(hash)
+}
+
+func TestFileAuthenticator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	writeCredentials(t, path,
+		"# gNMI users",
+		"admin:"+bcryptHash(t, "admin-pw")+":gnmi_readwrite, gnoi_readwrite",
+		"",
+		"viewer:"+bcryptHash(t, "viewer-pw")+":gnmi_readonly")
+
+	a, err := NewFileAuthenticator(path)
+	if err != nil {
+		t.Fatalf("NewFileAuthenticator failed: %v", err)
+	}
+	roles, err := a.Authenticate(context.Background(), "admin", "admin-pw")
+	if err != nil || !reflect.DeepEqual(roles, []string{"gnmi_readwrite", "gnoi_readwrite"}) {
+		t.Errorf("Expected admin roles, got %v, %v", roles, err)
+	}
+	if _, err := a.Authenticate(context.Background(), "admin", "viewer-pw"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected wrong password to be rejected, got %v", err)
+	}
+	if _, err := a.Authenticate(context.Background(), "nobody", "admin-pw"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected unknown user to be rejected, got %v", err)
+	}
+
+	// Changes are picked up, an invalid file keeps the previous users
+	writeCredentials(t, path, "viewer:"+bcryptHash(t, "new-pw")+":gnmi_readonly,extra")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(path, future, future)
+	if roles, err := a.Authenticate(context.Background(), "viewer", "new-pw"); err != nil || len(roles) != 2 {
+		t.Errorf("Expected the changed file to be read, got %v, %v", roles, err)
+	}
+	writeCredentials(t, path, "viewer:not-a-hash:gnmi_readonly")
+	os.Chtimes(path, future.Add(time.Minute), future.Add(time.Minute))
+	if _, err := a.Authenticate(context.Background(), "viewer", "new-pw"); err != nil {
+		t.Errorf("Expected the previous users to be kept, got %v", err)
+	}
+
+	if _, err := NewFileAuthenticator(path); err == nil {
+		t.Error("Expected an invalid hash to be rejected")
+	}
+	writeCredentials(t, path, "admin-without-roles")
+	if _, err := NewFileAuthenticator(path); err == nil {
+		t.Error("Expected a line without hash and roles to be rejected")
+	}
+}
+
+func TestTacacsRoles(t *testing.T) {
+	tests := []struct {
+		attrs map[string]string
+		roles []string
+	}{
+		{map[string]string{"roles": "gnmi_config_db_readwrite, gnoi_readonly", "priv-lvl": "15"}, []string{"gnmi_config_db_readwrite", "gnoi_readonly"}},
+		{map[string]string{"priv-lvl": "15"}, []string{"gnmi_readwrite", "gnoi_readwrite"}},
+		{map[string]string{"priv-lvl": "1"}, []string{"gnmi_readonly", "gnoi_readonly"}},
+		{map[string]string{}, []string{"gnmi_readonly", "gnoi_readonly"}},
+	}
+	for _, test := range tests {
+		if roles := tacacsRoles(test.attrs); !reflect.DeepEqual(roles, test.roles) {
+			t.Errorf("tacacsRoles(%v) = %v, expected %v", test.attrs, roles, test.roles)
+		}
+	}
+}
+
+func TestAuthenticateScopedRoles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	writeCredentials(t, path,
+		"admin:"+bcryptHash(t, "admin-pw")+":gnmi_readwrite",
+		"viewer:"+bcryptHash(t, "viewer-pw")+":gnmi_readonly,gnoi_noaccess")
+	a, err := NewFileAuthenticator(path)
+	if err != nil {
+		t.Fatalf("NewFileAuthenticator failed: %v", err)
+	}
+	cfg := &Config{UserAuth: AuthTypes{"password": true}, Authenticator: a}
+	login := func(user, password string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("username", user, "password", password))
+	}
+
+	if _, err := authenticate(cfg, login("admin", "admin-pw"), "gnmi", true); err != nil {
+		t.Errorf("Expected admin to write gNMI, got %v", err)
+	}
+	if _, err := authenticate(cfg, login("viewer", "viewer-pw"), "gnmi", false); err != nil {
+		t.Errorf("Expected viewer to read gNMI, got %v", err)
+	}
+	if _, err := authenticate(cfg, login("viewer", "viewer-pw"), "gnmi", true); err == nil {
+		t.Error("Expected viewer to be denied gNMI writes")
+	}
+	if _, err := authenticate(cfg, login("viewer", "viewer-pw"), "gnoi", false); err == nil {
+		t.Error("Expected viewer to be denied gNOI")
+	}
+	if _, err := authenticate(cfg, login("admin", "wrong"), "gnmi", false); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected wrong password to be unauthenticated, got %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package gnmi
 
 import (
+	"errors"
+
 	"github.com/golang/glog"
 	"github.com/sonic-net/sonic-gnmi/common_utils"
 	"golang.org/x/net/context"
@@ -9,7 +11,7 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-func BasicAuthenAndAuthor(ctx context.Context) (context.Context, error) {
+func BasicAuthenAndAuthor(ctx context.Context, authenticator Authenticator) (context.Context, error) {
 	rc, ctx := common_utils.GetContext(ctx)
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -29,14 +31,17 @@ func BasicAuthenAndAuthor(ctx context.Context) (context.Context, error) {
 	} else {
 		return ctx, status.Errorf(codes.Unauthenticated, "No Password Provided")
 	}
-	if err := PopulateAuthStruct(username, &rc.Auth, nil); err != nil {
+	roles, err := authenticator.Authenticate(ctx, username, passwd)
+	if errors.Is(err, ErrInvalidCredentials) {
+		glog.Infof("[%s] %s authentication failed for user %s: %v", rc.ID, authenticator.Name(), username, err)
+		return ctx, status.Errorf(codes.PermissionDenied, "Invalid Password")
+	}
+	if err != nil {
 		glog.Infof("[%s] Failed to retrieve authentication information; %v", rc.ID, err)
 		return ctx, status.Errorf(codes.Unauthenticated, "")
 	}
-	auth_success, _ := UserPwAuth(username, passwd)
-	if auth_success == false {
-		return ctx, status.Errorf(codes.PermissionDenied, "Invalid Password")
-	}
+	rc.Auth.User = username
+	rc.Auth.Roles = roles
 
 	return ctx, nil
 }
@@ -42,7 +42,7 @@ func TestShowClientOptions(t *testing.T) {
 	portRatesFileName := "../testdata/PORT_RATES.txt"
 	portTableFileName := "../testdata/PORT_TABLE.txt"
 
-	showInterfaceCountersHelp := `{"options":{"display":"[display=all] No-op since no-multi-asic support","help":"[help=true]Show this message","interfaces":"[interfaces=TEXT] Filter by interfaces name","json":"[json=true] No-op since response is in json format","namespace":"UNIMPLEMENTED","period":"[period=INTEGER] Display statistics over a specified period (in seconds)","verbose":"[verbose=true] Enable verbose output"},"subcommands":null}`
+	showInterfaceCountersHelp := `{"options":{"display":"[display=frontend] Display frontend ports only, display=all also shows backplane ports","help":"[help=true]Show this message","interfaces":"[interfaces=TEXT] Filter by interfaces name","json":"[json=true] No-op since response is in json format","namespace":"[namespace=TEXT] Query a single ASIC namespace (e.g. asic0), all namespaces by default","period":"[period=INTEGER] Display statistics over a specified period (in seconds)","verbose":"[verbose=true] Enable verbose output"},"subcommands":null}`
 	interfaceCountersSelectPorts := `{"Ethernet0":{"State":"U","RxOk":"149903","RxBps":"25.12 B/s","RxUtil":"0.00%","RxErr":"0","RxDrp":"957","RxOvr":"0","TxOk":"144782","TxBps":"773.23 KB/s","TxUtil":"0.01%","TxErr":"0","TxDrp":"2","TxOvr":"0"}}`
 
 	ResetDataSetsAndMappings(t)
@@ -121,16 +121,16 @@ func TestShowClientOptions(t *testing.T) {
 			wantRetCode: codes.InvalidArgument,
 		},
 		{
-			desc:       "query SHOW interface counters[interfaces-Ethernet0][period=5][namespace=all]",
+			desc:       "query SHOW interface counters[interfaces-Ethernet0][period=5][namespace=asic0]",
 			pathTarget: "SHOW",
 			textPbPath: `
 				elem: <name: "interface" >
 				elem: <name: "counters"
 				      key: { key: "interfaces" value: "Ethernet0" }
 				      key: { key: "period" value: "5" }
-				      key: { key: "namespace" value: "all" }>
+				      key: { key: "namespace" value: "asic0" }>
 			`,
-			wantRetCode: codes.Unimplemented,
+			wantRetCode: codes.InvalidArgument,
 		},
 	}
 
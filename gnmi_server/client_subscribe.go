@@ -5,6 +5,10 @@ import (
 	"github.com/Workiva/go-datastructures/queue"
 	log "github.com/golang/glog"
 	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/ygot/ygot"
+	"github.com/sonic-net/sonic-gnmi/pkg/metrics"
+	"github.com/sonic-net/sonic-gnmi/pkg/quota"
+	"github.com/sonic-net/sonic-gnmi/pkg/session"
 	sdc "github.com/sonic-net/sonic-gnmi/sonic_data_client"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -42,6 +46,8 @@ type Client struct {
 	w        sync.WaitGroup
 	fatal    bool
 	logLevel int
+	// quotas limits the subscriptions of the authenticated user, nil if disabled.
+	quotas *quota.Manager
 }
 
 // Syslog level for error
@@ -74,7 +80,9 @@ func (c *Client) setConnectionManager(threshold int) {
 	connectionManagerMu.Lock()
 	defer connectionManagerMu.Unlock()
 
-	if connectionManager != nil && threshold == connectionManager.GetThreshold() {
+	if connectionManager != nil {
+		// Keep the open connections when the threshold is reloaded
+		connectionManager.SetThreshold(threshold)
 		return
 	}
 	connectionManager = &ConnectionManager{
@@ -135,6 +143,10 @@ func (c *Client) Run(stream gnmipb.GNMI_SubscribeServer, config *Config) (err er
 		return grpc.Errorf(codes.FailedPrecondition, "cannot start client: stream is nil")
 	}
 
+	// An administrator killing the session closes the client
+	sess := session.FromContext(ctx)
+	sess.OnKill(c.Close)
+
 	defer func() {
 		if err != nil {
 			c.errors++
@@ -182,44 +194,13 @@ func (c *Client) Run(stream gnmipb.GNMI_SubscribeServer, config *Config) (err er
 
 	defer connectionManager.Remove(connectionKey) // remove key from connection list
 
-	var dc sdc.Client
-
 	mode := c.subscribe.GetMode()
 
 	log.V(3).Infof("mode=%v, origin=%q, target=%q", mode, origin, target)
 
-	authTarget := "gnmi"
-	if origin == "openconfig" {
-		dc, err = sdc.NewTranslClient(prefix, paths, ctx, extensions, sdc.TranslWildcardOption{})
-	} else if IsNativeOrigin(origin) {
-		var targetDbName string
-		dc, err = sdc.NewMixedDbClient(paths, prefix, origin, gnmipb.Encoding_JSON_IETF, "", "", &targetDbName)
-		authTarget = "gnmi_" + targetDbName
-	} else if len(origin) != 0 {
-		return grpc.Errorf(codes.Unimplemented, "Unsupported origin: %s", origin)
-	} else if target == "" {
-		// This and subsequent conditions handle target based path identification
-		// when origin == "". As per the spec it should have been treated as "openconfig".
-		// But we take a deviation and stick to legacy logic for backward compatibility
-		return grpc.Errorf(codes.Unimplemented, "Empty target data not supported")
-	} else if target == "OTHERS" {
-		dc, err = sdc.NewNonDbClient(paths, prefix)
-		authTarget = "gnmi_others"
-	} else if target == "SHOW" {
-		return grpc.Errorf(codes.Unimplemented, "SHOW does not support subscribe operations")
-	} else if (target == "EVENTS") && (mode == gnmipb.SubscriptionList_STREAM) {
-		dc, err = sdc.NewEventClient(paths, prefix, c.logLevel)
-		authTarget = "gnmi_events"
-	} else if targetDbName, ok, _, _ := sdc.IsTargetDb(target); ok {
-		dc, err = sdc.NewDbClient(paths, prefix)
-		authTarget = "gnmi_" + targetDbName
-	} else {
-		/* For any other target or no target create new Transl Client. */
-		dc, err = sdc.NewTranslClient(prefix, paths, ctx, extensions, sdc.TranslWildcardOption{})
-	}
-
+	dc, authTarget, err := sdc.NewSubscribeClient(ctx, prefix, origin, paths, mode, extensions, c.logLevel)
 	if err != nil {
-		return grpc.Errorf(codes.NotFound, "%v", err)
+		return err
 	}
 
 	defer dc.Close()
@@ -229,6 +210,13 @@ func (c *Client) Run(stream gnmipb.GNMI_SubscribeServer, config *Config) (err er
 		return err
 	}
 
+	user, roles := authIdentity(ctx)
+	releaseQuota, err := c.quotas.AcquireSubscription(user, roles, c.subscribe)
+	if err != nil {
+		return err
+	}
+	defer releaseQuota()
+
 	switch mode {
 	case gnmipb.SubscriptionList_STREAM:
 		c.stop = make(chan struct{}, 1)
@@ -248,6 +236,18 @@ func (c *Client) Run(stream gnmipb.GNMI_SubscribeServer, config *Config) (err er
 		return grpc.Errorf(codes.InvalidArgument, "Unkown subscription mode: %q", query)
 	}
 
+	metricsTarget := target
+	if metricsTarget == "" {
+		metricsTarget = origin
+	}
+	metrics.ActiveSubscriptions.Inc(metricsTarget, mode.String())
+	defer metrics.ActiveSubscriptions.Dec(metricsTarget, mode.String())
+	metrics.SubscribeQueueDepth.SetFunc(func() float64 { return float64(c.q.Len()) }, c.String())
+	defer metrics.SubscribeQueueDepth.Delete(c.String())
+
+	sess.SetQueueDepthFunc(func() int { return int(c.q.Len()) })
+	sess.SetSubscription(target, origin, mode.String(), subscriptionPaths(c.subscribe))
+
 	log.V(1).Infof("Client %s running", c)
 	go c.recv(stream)
 	err = c.send(stream, dc)
@@ -363,3 +363,15 @@ func (c *Client) send(stream gnmipb.GNMI_SubscribeServer, dc sdc.Client) error {
 		log.V(5).Infof("Client %s done sending, msg count %d, msg %v", c, c.sendMsg, resp)
 	}
 }
+
+// subscriptionPaths returns the subscribed paths, relative to the prefix, as
+// strings for the session view.
+func subscriptionPaths(subscribe *gnmipb.SubscriptionList) []string {
+	var paths []string
+	for _, sub := range subscribe.GetSubscription() {
+		if p, err := ygot.PathToString(sub.GetPath()); err == nil {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,309 @@
+package gnmi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/golang/glog"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gnoi_debug "github.com/sonic-net/sonic-gnmi/pkg/gnoi/debug"
+	sdcfg "github.com/sonic-net/sonic-gnmi/sonic_db_config"
+)
+
+// The CONFIG_DB entry holding the reloadable server configuration.
+const (
+	reloadTable = "GNMI"
+	reloadKey   = "gnmi"
+)
+
+// reloadableFields parse the fields of the GNMI|gnmi entry into a Config.
+// Fields missing from the entry keep the value the server started with.
+var reloadableFields = map[string]func(cfg *Config, value string) error{
+	"client_auth": func(cfg *Config, value string) error {
+		// The legacy "true" and "false" only chose the flags the server
+		// was started with, which they keep applying
+		if strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+			return nil
+		}
+		auth := AuthTypes{"password": false, "cert": false, "jwt": false}
+		if err := auth.Set(value); err != nil {
+			return err
+		}
+		if auth.Enabled("cert") && cfg.CaCertFile == "" {
+			return errors.New("client_auth mode cert requires ca_crt")
+		}
+		cfg.UserAuth = auth
+		return nil
+	},
+	"threshold": func(cfg *Config, value string) error {
+		return parseNonNegative(value, &cfg.Threshold)
+	},
+	"idle_conn_duration": func(cfg *Config, value string) error {
+		return parseNonNegative(value, &cfg.IdleConnDuration)
+	},
+	"log_level": func(cfg *Config, value string) error {
+		return parseNonNegative(value, &cfg.LogLevel)
+	},
+	"gnmi_native_write": func(cfg *Config, value string) (err error) {
+		cfg.EnableNativeWrite, err = strconv.ParseBool(value)
+		return err
+	},
+	"gnmi_translib_write": func(cfg *Config, value string) (err error) {
+		cfg.EnableTranslibWrite, err = strconv.ParseBool(value)
+		return err
+	},
+}
+
+func parseNonNegative(value string, field *int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return fmt.Errorf("%d is negative", n)
+	}
+	*field = n
+	return nil
+}
+
+// Config returns the configuration in effect. A reload replaces it as a
+// whole, so an RPC reading it once keeps a consistent view until it ends.
+func (s *Server) Config() *Config {
+	if cfg := s.liveConfig.Load(); cfg != nil {
+		return cfg
+	}
+	return s.config
+}
+
+// Reload reads the GNMI|gnmi entry of CONFIG_DB and applies it, see
+// applyConfig. It is triggered by SIGHUP in addition to CONFIG_DB changes.
+func (s *Server) Reload() error {
+	if s.configWatcher == nil {
+		return errors.New("configuration reload is disabled")
+	}
+	return s.configWatcher.reload()
+}
+
+// applyConfig builds a new configuration from the one the server started
+// with, the reloadable fields and the current gNOI Debug whitelists, then
+// switches to it at once. An invalid field rejects the whole reload.
+// idle_conn_duration takes effect when the listeners are next restarted.
+func (s *Server) applyConfig(fields map[string]string) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	next := *s.config
+	var errs []string
+	for field, value := range fields {
+		parse, ok := reloadableFields[field]
+		if !ok {
+			continue
+		}
+		if err := parse(&next, value); err != nil {
+			errs = append(errs, fmt.Sprintf("%s=%q: %v", field, value, err))
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("invalid %s|%s: %s", reloadTable, reloadKey, strings.Join(errs, "; "))
+	}
+	next.DebugReadWhitelist, next.DebugWriteWhitelist = gnoi_debug.ConstructWhitelists()
+
+	changes := configChanges(s.Config(), &next)
+	s.liveConfig.Store(&next)
+	if len(changes) == 0 {
+		log.V(1).Infof("Reloaded server configuration, no changes")
+	} else {
+		log.Infof("Reloaded server configuration: %s", strings.Join(changes, ", "))
+	}
+	return nil
+}
+
+// writeServices are the services served only when the write settings of
+// the configuration in effect enable them.
+var writeServices = map[string]func(cfg *Config) bool{
+	"/gnoi.system.System/":         anyWriteEnabled,
+	"/gnoi.file.File/":             anyWriteEnabled,
+	"/gnoi.os.OS/":                 anyWriteEnabled,
+	"/gnoi.containerz.Containerz/": anyWriteEnabled,
+	"/gnoi.debug.Debug/":           anyWriteEnabled,
+	"/gnoi.healthz.Healthz/":       anyWriteEnabled,
+	"/gnoi.sonic.SonicService/": func(cfg *Config) bool {
+		return cfg.EnableTranslibWrite
+	},
+}
+
+func anyWriteEnabled(cfg *Config) bool {
+	return cfg.EnableTranslibWrite || cfg.EnableNativeWrite
+}
+
+// checkWriteService rejects the RPCs of the write services disabled by the
+// configuration in effect, as if they were not registered. With reload
+// enabled the services are all registered, since a reload may enable them.
+func (s *Server) checkWriteService(method string) error {
+	for prefix, enabled := range writeServices {
+		if strings.HasPrefix(method, prefix) && !enabled(s.Config()) {
+			return status.Errorf(codes.Unimplemented, "%s is disabled by gnmi_native_write and gnmi_translib_write", strings.Trim(prefix, "/"))
+		}
+	}
+	return nil
+}
+
+func (s *Server) writeServiceUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.checkWriteService(info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) writeServiceStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.checkWriteService(info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// configChanges describes the reloadable settings that differ between prev
+// and next, e.g. "threshold: 100 -> 50".
+func configChanges(prev, next *Config) []string {
+	var changes []string
+	add := func(name string, from, to interface{}) {
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, from, to))
+		}
+	}
+	add("client_auth", authModes(prev.UserAuth), authModes(next.UserAuth))
+	add("threshold", prev.Threshold, next.Threshold)
+	add("idle_conn_duration", prev.IdleConnDuration, next.IdleConnDuration)
+	add("log_level", prev.LogLevel, next.LogLevel)
+	add("gnmi_native_write", prev.EnableNativeWrite, next.EnableNativeWrite)
+	add("gnmi_translib_write", prev.EnableTranslibWrite, next.EnableTranslibWrite)
+	if prev.DebugReadWhitelist != nil || next.DebugReadWhitelist != nil {
+		add("debug_read_whitelist", prev.DebugReadWhitelist, next.DebugReadWhitelist)
+		add("debug_write_whitelist", prev.DebugWriteWhitelist, next.DebugWriteWhitelist)
+	}
+	return changes
+}
+
+// authModes lists the enabled modes of auth in a stable order.
+func authModes(auth AuthTypes) string {
+	if !auth.Any() {
+		return "none"
+	}
+	var modes []string
+	for mode, enabled := range auth {
+		if enabled {
+			modes = append(modes, mode)
+		}
+	}
+	sort.Strings(modes)
+	return strings.Join(modes, ",")
+}
+
+// ConfigWatcher applies the GNMI|gnmi entry of CONFIG_DB to a server
+// whenever it changes.
+type ConfigWatcher struct {
+	srv  *Server
+	rc   *redis.Client
+	ps   *redis.PubSub
+	key  string
+	done chan struct{}
+}
+
+// NewConfigWatcher applies the current GNMI|gnmi entry to srv and watches it
+// for changes.
+func NewConfigWatcher(srv *Server) (*ConfigWatcher, error) {
+	ns, _ := sdcfg.GetDbDefaultNamespace()
+	addr, err := sdcfg.GetDbTcpAddr("CONFIG_DB", ns)
+	if err != nil {
+		return nil, err
+	}
+	dbId, err := sdcfg.GetDbId("CONFIG_DB", ns)
+	if err != nil {
+		return nil, err
+	}
+	separator, err := sdcfg.GetDbSeparator("CONFIG_DB", ns)
+	if err != nil {
+		return nil, err
+	}
+
+	cw := &ConfigWatcher{
+		srv:  srv,
+		key:  reloadTable + separator + reloadKey,
+		done: make(chan struct{}),
+	}
+	cw.rc = redis.NewClient(&redis.Options{
+		Network:     "tcp",
+		Addr:        addr,
+		Password:    "",
+		DB:          dbId,
+		DialTimeout: 0,
+	})
+
+	// Subscribe before the initial load so no change is missed in between
+	cw.ps = cw.rc.Subscribe(context.Background(), fmt.Sprintf("__keyspace@%d__:%s", dbId, cw.key))
+	if _, err = cw.ps.Receive(context.Background()); err != nil {
+		cw.cleanup()
+		return nil, err
+	}
+	if err = cw.reload(); err != nil {
+		cw.cleanup()
+		return nil, err
+	}
+
+	go cw.watch(cw.ps.Channel())
+	log.V(2).Infof("Watching %s for configuration changes", cw.key)
+	return cw, nil
+}
+
+// Close stops watching CONFIG_DB. The server keeps the last configuration.
+func (cw *ConfigWatcher) Close() {
+	if cw == nil {
+		return
+	}
+	close(cw.done)
+}
+
+func (cw *ConfigWatcher) cleanup() {
+	if cw.ps != nil {
+		cw.ps.Close()
+	}
+	if cw.rc != nil {
+		cw.rc.Close()
+	}
+}
+
+func (cw *ConfigWatcher) watch(notifications <-chan *redis.Message) {
+	defer cw.cleanup()
+	for {
+		select {
+		case <-cw.done:
+			return
+		case _, ok := <-notifications:
+			if !ok {
+				return
+			}
+			if err := cw.reload(); err != nil {
+				log.Errorf("Failed to reload server configuration: %v", err)
+			}
+		}
+	}
+}
+
+// reload reads the GNMI|gnmi entry and applies it to the server.
+func (cw *ConfigWatcher) reload() error {
+	fields, err := cw.rc.HGetAll(context.Background(), cw.key).Result()
+	if err != nil {
+		return err
+	}
+	return cw.srv.applyConfig(fields)
+}
@@ -0,0 +1,138 @@
+package gnmi
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestReloadServer() *Server {
+	return &Server{config: &Config{
+		UserAuth:            AuthTypes{"password": true, "cert": false, "jwt": false},
+		Threshold:           100,
+		IdleConnDuration:    5,
+		LogLevel:            2,
+		EnableNativeWrite:   true,
+		EnableTranslibWrite: false,
+	}}
+}
+
+func TestServer_ApplyConfig(t *testing.T) {
+	srv := newTestReloadServer()
+	initial := srv.Config()
+
+	err := srv.applyConfig(map[string]string{
+		"client_auth":         "password,jwt",
+		"threshold":           "50",
+		"idle_conn_duration":  "0",
+		"gnmi_translib_write": "true",
+		"port":                "8080", // Not reloadable, ignored
+	})
+	if err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+	cfg := srv.Config()
+	if cfg == initial {
+		t.Fatal("Expected reload to replace the configuration")
+	}
+	if !cfg.UserAuth.Enabled("jwt") || cfg.Threshold != 50 || cfg.IdleConnDuration != 0 || !cfg.EnableTranslibWrite {
+		t.Errorf("Reloaded fields not applied: %+v", cfg)
+	}
+	if cfg.LogLevel != 2 || !cfg.EnableNativeWrite || cfg.DebugReadWhitelist == nil {
+		t.Errorf("Expected missing fields to keep start values: %+v", cfg)
+	}
+	// RPCs holding the old configuration are unaffected
+	if initial.Threshold != 100 || initial.UserAuth.Enabled("jwt") {
+		t.Errorf("Expected previous configuration to be unchanged: %+v", initial)
+	}
+
+	// Removing a field from the entry restores the start value
+	if err := srv.applyConfig(map[string]string{"threshold": "50"}); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+	if cfg := srv.Config(); cfg.UserAuth.Enabled("jwt") || cfg.EnableTranslibWrite {
+		t.Errorf("Expected removed fields to fall back to start values: %+v", cfg)
+	}
+}
+
+func TestServer_ApplyConfigLegacyClientAuth(t *testing.T) {
+	srv := newTestReloadServer()
+	for _, value := range []string{"true", "false", "True"} {
+		if err := srv.applyConfig(map[string]string{"client_auth": value, "threshold": "10"}); err != nil {
+			t.Fatalf("applyConfig with client_auth %q failed: %v", value, err)
+		}
+		cfg := srv.Config()
+		if !cfg.UserAuth.Enabled("password") || cfg.UserAuth.Enabled("cert") || cfg.UserAuth.Enabled("jwt") || cfg.Threshold != 10 {
+			t.Errorf("Expected client_auth %q to keep the start modes: %+v", value, cfg)
+		}
+	}
+}
+
+func TestServer_ApplyConfigInvalid(t *testing.T) {
+	srv := newTestReloadServer()
+	for _, fields := range []map[string]string{
+		{"threshold": "10", "log_level": "-1"},
+		{"threshold": "many"},
+		{"client_auth": "token"},
+		{"client_auth": "cert"}, // Requires ca_crt
+		{"gnmi_native_write": "maybe"},
+	} {
+		if err := srv.applyConfig(fields); err == nil {
+			t.Errorf("Expected %v to be rejected", fields)
+		}
+		if srv.Config() != srv.config {
+			t.Fatalf("Expected rejected reload %v to keep the configuration", fields)
+		}
+	}
+}
+
+func TestConfigChanges(t *testing.T) {
+	prev := newTestReloadServer().config
+	next := *prev
+	next.UserAuth = AuthTypes{"password": true, "cert": false, "jwt": true}
+	next.Threshold = 10
+	changes := strings.Join(configChanges(prev, &next), ", ")
+	if changes != "client_auth: password -> jwt,password, threshold: 100 -> 10" {
+		t.Errorf("Unexpected changes: %s", changes)
+	}
+	if len(configChanges(prev, prev)) != 0 {
+		t.Error("Expected no changes for the same configuration")
+	}
+}
+
+func TestServer_ReloadDisabled(t *testing.T) {
+	if err := newTestReloadServer().Reload(); err == nil {
+		t.Error("Expected Reload to fail without a configuration watcher")
+	}
+}
+
+func TestServer_CheckWriteService(t *testing.T) {
+	srv := newTestReloadServer()
+	if err := srv.checkWriteService("/gnoi.system.System/Reboot"); err != nil {
+		t.Errorf("Expected System to be served with native write, got %v", err)
+	}
+	if err := srv.checkWriteService("/gnoi.sonic.SonicService/ShowTechsupport"); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected SonicService to be disabled without translib write, got %v", err)
+	}
+
+	if err := srv.applyConfig(map[string]string{"gnmi_native_write": "false"}); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+	for _, method := range []string{"/gnoi.system.System/Reboot", "/gnoi.file.File/Put", "/gnoi.os.OS/Install"} {
+		if err := srv.checkWriteService(method); status.Code(err) != codes.Unimplemented {
+			t.Errorf("Expected %s to be disabled after reload, got %v", method, err)
+		}
+	}
+	if err := srv.checkWriteService("/gnmi.gNMI/Get"); err != nil {
+		t.Errorf("Expected gNMI to be served, got %v", err)
+	}
+
+	if err := srv.applyConfig(map[string]string{"gnmi_translib_write": "true"}); err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+	if err := srv.checkWriteService("/gnoi.sonic.SonicService/ShowTechsupport"); err != nil {
+		t.Errorf("Expected SonicService to be served after reload, got %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sonic-net/sonic-gnmi/pkg/session"
 	sdcfg "github.com/sonic-net/sonic-gnmi/sonic_db_config"
 
 	log "github.com/golang/glog"
@@ -15,8 +16,16 @@ import (
 
 const table = "TELEMETRY_CONNECTIONS"
 
+// sessionTable holds one hash per live session, see pkg/session.
+const sessionTable = "TELEMETRY_SESSIONS"
+
+// sessionRefreshInterval is how often session counters are republished.
+const sessionRefreshInterval = 10 * time.Second
+
 var rclient *redis.Client
 
+var sessionPublisherOnce sync.Once
+
 type ConnectionManager struct {
 	connections map[string]struct{}
 	mu          sync.RWMutex
@@ -29,6 +38,14 @@ func (cm *ConnectionManager) GetThreshold() int {
 	return cm.threshold
 }
 
+// SetThreshold changes the maximum number of connections. Connections open
+// beyond a lowered threshold are kept, new ones are refused until below it.
+func (cm *ConnectionManager) SetThreshold(threshold int) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.threshold = threshold
+}
+
 func (cm *ConnectionManager) PrepareRedis() {
 	ns, _ := sdcfg.GetDbDefaultNamespace()
 	addr, err := sdcfg.GetDbTcpAddr("STATE_DB", ns)
@@ -49,6 +66,12 @@ func (cm *ConnectionManager) PrepareRedis() {
 		DialTimeout: 0,
 	})
 
+	separator, err := sdcfg.GetDbSeparator("STATE_DB", ns)
+	if err != nil {
+		separator = "|"
+	}
+	prepareSessionStore(rclient, separator)
+
 	res, _ := rclient.HGetAll(context.Background(), "TELEMETRY_CONNECTIONS").Result()
 
 	if res == nil {
@@ -60,6 +83,33 @@ func (cm *ConnectionManager) PrepareRedis() {
 	}
 }
 
+// prepareSessionStore removes sessions left over by a previous run and makes
+// the session registry publish to STATE_DB.
+func prepareSessionStore(client *redis.Client, separator string) {
+	store := &redisSessionStore{client: client, prefix: sessionTable + separator}
+	if keys, err := client.Keys(context.Background(), store.prefix+"*").Result(); err == nil && len(keys) > 0 {
+		client.Del(context.Background(), keys...)
+	}
+	session.Default.SetStore(store)
+	sessionPublisherOnce.Do(func() {
+		go session.Default.Run(context.Background(), sessionRefreshInterval)
+	})
+}
+
+// redisSessionStore publishes sessions as TELEMETRY_SESSIONS|<id> hashes.
+type redisSessionStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func (s *redisSessionStore) Set(id string, fields map[string]string) error {
+	return s.client.HSet(context.Background(), s.prefix+id, fields).Err()
+}
+
+func (s *redisSessionStore) Delete(id string) error {
+	return s.client.Del(context.Background(), s.prefix+id).Err()
+}
+
 func (cm *ConnectionManager) Add(addr net.Addr, query string) (string, bool) {
 	cm.mu.RLock()                                                 // reading
 	if len(cm.connections) >= cm.threshold && cm.threshold != 0 { // 0 is defined as no threshold
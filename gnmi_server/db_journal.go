@@ -1,12 +1,11 @@
 package gnmi
 
 import (
-	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"io"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -15,22 +14,31 @@ import (
 	"github.com/redis/go-redis/v9"
 
 	"github.com/Azure/sonic-mgmt-common/translib/db"
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/configjournal"
 	sdcfg "github.com/sonic-net/sonic-gnmi/sonic_db_config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
+// Default retention of a journal, see Config.ConfigDbJournalMaxSize and
+// Config.ConfigDbJournalMaxBackups.
 const (
-	maxFileSize = 2000000 // Bytes
-	maxBackups  = 1
+	DefaultJournalMaxSize    = 2000000
+	DefaultJournalMaxBackups = 1
 )
 
+// requestIDKey is the metadata carrying the ID of a Set request, given by the
+// client or generated and returned in the response header.
+const requestIDKey = "x-request-id"
+
 type DbJournal struct {
 	database      string
 	rc            *redis.Client
 	ps            *redis.PubSub
 	notifications <-chan *redis.Message
 	cache         map[string]map[string]string
-	file          *os.File
-	fileName      string
+	journal       *configjournal.Journal
 	done          chan bool
 }
 
@@ -39,20 +47,29 @@ var dbNums = map[string]db.DBNum{
 	"STATE_DB":  db.StateDB,
 }
 
-// NewDbJournal returns a new DbJournal for the specified database.
+// NewDbJournal returns a new DbJournal for the specified database, with the
+// default retention. The journal of CONFIG_DB becomes the default journal,
+// served on the OPERATIONAL target and attributed to the Set requests of the
+// server.
 func NewDbJournal(database string) (*DbJournal, error) {
+	return NewDbJournalWithRetention(database, DefaultJournalMaxSize, DefaultJournalMaxBackups)
+}
+
+// NewDbJournalWithRetention returns a new DbJournal like NewDbJournal, rotated
+// past maxSize bytes, 0 never rotating it, and keeping maxBackups backups.
+func NewDbJournalWithRetention(database string, maxSize int64, maxBackups int) (*DbJournal, error) {
 	var err error
-	journal := &DbJournal{}
-	journal.database = database
-	dbNum, ok := dbNums[journal.database]
+	dbj := &DbJournal{}
+	dbj.database = database
+	dbNum, ok := dbNums[dbj.database]
 	if !ok {
 		return nil, errors.New("Invalid database passed into NewDbJournal")
 	}
 
 	ns, _ := sdcfg.GetDbDefaultNamespace()
-	addr, _ := sdcfg.GetDbTcpAddr(journal.database, ns)
-	dbId, _ := sdcfg.GetDbId(journal.database, ns)
-	journal.rc = db.TransactionalRedisClientWithOpts(&redis.Options{
+	addr, _ := sdcfg.GetDbTcpAddr(dbj.database, ns)
+	dbId, _ := sdcfg.GetDbId(dbj.database, ns)
+	dbj.rc = db.TransactionalRedisClientWithOpts(&redis.Options{
 		Network:     "tcp",
 		Addr:        addr,
 		Password:    "",
@@ -60,29 +77,36 @@ func NewDbJournal(database string) (*DbJournal, error) {
 		DialTimeout: 0,
 	})
 
-	if err = journal.init(); err != nil {
+	if err = dbj.init(); err != nil {
 		return nil, err
 	}
 
 	keyspace := fmt.Sprintf("__keyspace@%d__:*", dbNum)
 	keyevent := fmt.Sprintf("__keyevent@%d__:*", dbNum)
-	journal.ps = journal.rc.PSubscribe(context.Background(), keyspace, keyevent)
-	if _, err = journal.ps.Receive(context.Background()); err != nil {
+	dbj.ps = dbj.rc.PSubscribe(context.Background(), keyspace, keyevent)
+	if _, err = dbj.ps.Receive(context.Background()); err != nil {
 		return nil, err
 	}
 
-	journal.notifications = journal.ps.Channel()
+	dbj.notifications = dbj.ps.Channel()
 
-	journal.fileName = filepath.Join(HostVarLogPath, strings.ToLower(journal.database)+".txt")
-	if journal.file, err = os.OpenFile(journal.fileName, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644); err != nil {
+	dbj.journal, err = configjournal.Open(configjournal.Options{
+		Path:       filepath.Join(HostVarLogPath, strings.ToLower(dbj.database)+".txt"),
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+	})
+	if err != nil {
 		return nil, err
 	}
+	if dbj.database == "CONFIG_DB" {
+		configjournal.SetDefault(dbj.journal)
+	}
 
-	journal.done = make(chan bool, 1)
+	dbj.done = make(chan bool, 1)
 
-	go journal.journal()
-	log.V(2).Infof("Successfully started the DbJournal for %v", journal.database)
-	return journal, nil
+	go dbj.run()
+	log.V(2).Infof("Successfully started the DbJournal for %v", dbj.database)
+	return dbj, nil
 }
 
 // Close closes the redis objects and the journal file.
@@ -104,8 +128,11 @@ func (dbj *DbJournal) cleanup() {
 		db.CloseRedisClient(dbj.rc)
 		dbj.rc = nil
 	}
-	if dbj.file != nil {
-		dbj.file.Close()
+	if dbj.journal != nil {
+		if configjournal.Default() == dbj.journal {
+			configjournal.SetDefault(nil)
+		}
+		dbj.journal.Close()
 	}
 	if dbj.cache != nil {
 		dbj.cache = map[string]map[string]string{}
@@ -133,8 +160,9 @@ func (dbj *DbJournal) init() error {
 	return nil
 }
 
-// journal monitors the database notifications and logs events to the file.
-func (dbj *DbJournal) journal() {
+// run monitors the database notifications and records the changes in the
+// journal.
+func (dbj *DbJournal) run() {
 	if dbj == nil {
 		return
 	}
@@ -147,31 +175,23 @@ func (dbj *DbJournal) journal() {
 			if len(event) != 2 {
 				continue
 			}
-			op := event[0]
-			table := event[1]
-			entry := fmt.Sprintf("%v: %v %v", time.Now().Format("2006-01-02.15:04:05.000000"), op, table)
-			diff, dErr := dbj.updateCache(event)
-			if dErr != nil {
-				log.V(0).Infof("Shutting down %v Journal: %v", dbj.database, dErr)
+			now := time.Now()
+			record, changed, err := dbj.updateCache(event)
+			if err != nil {
+				log.V(0).Infof("Shutting down %v Journal: %v", dbj.database, err)
 				return
 			}
 			event = []string{}
 
-			if diff != "" {
-				entry += " " + diff
-			}
 			// If no fields were changed or the operation is a set on a table that contains the DB name, don't log the event.
-			if (diff == "" && (op == "hset" || op == "hdel")) || (op == "set" && strings.Contains(table, dbj.database)) {
+			if (!changed && (record.Op == "hset" || record.Op == "hdel")) || (record.Op == "set" && strings.Contains(record.Key, dbj.database)) {
 				continue
 			}
 
-			if err := dbj.rotateFile(); err != nil {
-				log.V(0).Infof("Shutting down DbJournal, failed to manage file rotation: %v", err)
-				return
-			}
-			_, writeErr := dbj.file.Write([]byte(entry + "\n"))
-			if writeErr != nil {
-				log.V(0).Infof("Failed to write to DbJournal file: %v", writeErr)
+			record.Time = now
+			record.Database = dbj.database
+			if err := dbj.journal.Write(record); err != nil {
+				log.V(0).Infof("Failed to write to DbJournal file: %v", err)
 			}
 		case <-dbj.done:
 			return
@@ -179,115 +199,88 @@ func (dbj *DbJournal) journal() {
 	}
 }
 
-// updateCache updates the cache with the latest database entry and returns the diff.
-func (dbj *DbJournal) updateCache(event []string) (string, error) {
-	op := event[0]
-	table := event[1]
+// updateCache updates the cache with the latest database entry and returns
+// the record of the event, with the entry before and after it, and whether
+// any field changed.
+func (dbj *DbJournal) updateCache(event []string) (configjournal.Record, bool, error) {
+	record := configjournal.Record{Op: event[0], Key: event[1]}
 	if dbj == nil || dbj.cache == nil || dbj.rc == nil {
-		return "", errors.New("nil members present in DbJournal")
+		return record, false, errors.New("nil members present in DbJournal")
 	}
-	oldEntry, ok := dbj.cache[table]
+	oldEntry, ok := dbj.cache[record.Key]
 	if !ok {
 		oldEntry = map[string]string{}
 	}
-	newEntry, err := dbj.rc.HGetAll(context.Background(), table).Result()
+	newEntry, err := dbj.rc.HGetAll(context.Background(), record.Key).Result()
 	if err != nil {
 		newEntry = map[string]string{}
 	}
 	// Update the cache
-	dbj.cache[table] = newEntry
-
-	if op == "del" {
-		return "", nil
+	dbj.cache[record.Key] = newEntry
+	if len(newEntry) == 0 {
+		delete(dbj.cache, record.Key)
 	}
+	record.Before, record.After = oldEntry, newEntry
 
-	diff := ""
-	// Find deleted and changed fields
+	changed := len(oldEntry) != len(newEntry)
 	for k, v := range oldEntry {
-		newVal, ok := newEntry[k]
-		if !ok {
-			diff += "-" + k + " "
-			continue
-		}
-		if newVal != v {
-			diff += k + "=" + newVal + " "
+		if newVal, ok := newEntry[k]; !ok || newVal != v {
+			changed = true
+			break
 		}
 	}
+	return record, changed, nil
+}
 
-	// Find added fields
-	for k, v := range newEntry {
-		if _, ok := oldEntry[k]; !ok {
-			diff += "+" + k + ":" + v + " "
-		}
+// attributeJournal attributes the changes of keys made while a Set request
+// runs to its user and request ID, and returns the function to call once the
+// request is done. Nil keys attribute every change, see
+// configjournal.Journal.Attribute.
+func attributeJournal(ctx context.Context, keys []string) func() {
+	journal := configjournal.Default()
+	if journal == nil {
+		return func() {}
 	}
-
-	return diff, nil
+	user, _ := authIdentity(ctx)
+	return journal.Attribute(configjournal.Author{User: user, RequestID: requestID(ctx)}, keys)
 }
 
-// rotateFile makes sure the journal file is opened correctly and rotates it
-// if it exceeds the maximum size.
-func (dbj *DbJournal) rotateFile() error {
-	if dbj == nil {
-		return errors.New("Couldn't rotate file, DbJournal is nil")
+// configDbKeys returns the CONFIG_DB keys the native paths of a Set request
+// may change. The paths are /CONFIG_DB/<namespace>/<table>/<key>/<field>.
+func configDbKeys(prefix *gnmipb.Path, paths []*gnmipb.Path) []string {
+	ns, _ := sdcfg.GetDbDefaultNamespace()
+	separator, err := sdcfg.GetDbSeparator("CONFIG_DB", ns)
+	if err != nil {
+		separator = "|"
 	}
-	fileStat, err := os.Stat(dbj.fileName)
-	if err != nil || dbj.file == nil {
-		// File does not exist or it is closed, create/open it
-		if dbj.file, err = os.OpenFile(dbj.fileName, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644); err != nil {
-			return err
+	keys := []string{}
+	for _, path := range paths {
+		elems := append(append([]*gnmipb.PathElem{}, prefix.GetElem()...), path.GetElem()...)
+		switch {
+		case len(elems) <= 2:
+			return []string{"*"}
+		case len(elems) == 3:
+			keys = append(keys, elems[2].GetName()+separator+"*")
+		default:
+			keys = append(keys, elems[2].GetName()+separator+elems[3].GetName())
 		}
-		return nil
 	}
+	return keys
+}
 
-	if fileStat.Size() >= maxFileSize {
-		// Close the journal file and open it as read-only to copy it
-		dbj.file.Close()
-		if dbj.file, err = os.OpenFile(dbj.fileName, os.O_RDONLY, 0644); err != nil {
-			return err
-		}
-
-		// Remove a rotated, zipped file if the maxBackups limit is reached
-		files, err := os.ReadDir(HostVarLogPath)
-		if err != nil {
-			return err
-		}
-		var count uint
-		var oldest string
-		for _, file := range files {
-			if strings.HasPrefix(file.Name(), strings.ToLower(dbj.database)) && strings.HasSuffix(file.Name(), ".gz") {
-				count++
-				if strings.Compare(file.Name(), oldest) == -1 || oldest == "" {
-					oldest = file.Name()
-				}
-			}
-		}
-		if count >= maxBackups {
-			if err := os.Remove(filepath.Join(HostVarLogPath, oldest)); err != nil {
-				return err
-			}
-		}
-
-		// Compress the file
-		zipName := filepath.Join(HostVarLogPath, strings.ToLower(dbj.database)+"_"+time.Now().Format("20060102150405")+".gz")
-		zipFile, err := os.Create(zipName)
-		if err != nil {
-			return err
-		}
-		defer zipFile.Close()
-		zipWriter := gzip.NewWriter(zipFile)
-		defer zipWriter.Close()
-
-		if _, err = io.Copy(zipWriter, dbj.file); err != nil {
-			return err
-		}
-		if err = zipWriter.Flush(); err != nil {
-			return err
-		}
-
-		// Recreate the journal file
-		if dbj.file, err = os.Create(dbj.fileName); err != nil {
-			return err
+// requestID returns the ID the client gave to the request, or generates one
+// and returns it to the client in the response header.
+func requestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDKey); len(ids) > 0 && ids[0] != "" {
+			return ids[0]
 		}
 	}
-	return nil
+	id := make([]byte, 8)
+	rand.Read(id)
+	generated := hex.EncodeToString(id)
+	if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, generated)); err != nil {
+		log.V(2).Infof("Failed to return request ID %s: %v", generated, err)
+	}
+	return generated
 }
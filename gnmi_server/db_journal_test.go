@@ -1,12 +1,13 @@
 package gnmi
 
 import (
-	"os"
-	"strings"
+	"context"
+	"fmt"
 	"testing"
-	"time"
 
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/redis/go-redis/v9"
+	sdcfg "github.com/sonic-net/sonic-gnmi/sonic_db_config"
 )
 
 func TestNewDbJournal(t *testing.T) {
@@ -128,7 +129,7 @@ func TestDbJournalUpdateCache(t *testing.T) {
 				}
 				defer test.dbj.Close()
 			}
-			_, err = test.dbj.updateCache(test.event)
+			_, _, err = test.dbj.updateCache(test.event)
 
 			if test.wantErr != (err != nil) {
 				t.Fatalf("init did not return the expected error - wantErr=%v, err=%v", test.wantErr, err)
@@ -137,65 +138,52 @@ func TestDbJournalUpdateCache(t *testing.T) {
 	}
 }
 
-func TestDbJournalRotateFile(t *testing.T) {
-	// Set up a DbJournal
-	dbj, err := NewDbJournal("CONFIG_DB")
+func TestDbJournalUpdateCacheRecord(t *testing.T) {
+	// Without a running journal, so that only the test updates the cache
+	ns, _ := sdcfg.GetDbDefaultNamespace()
+	rc := getConfigDbClient(t, ns)
+	defer rc.Close()
+	dbj := &DbJournal{database: "CONFIG_DB", rc: rc, cache: map[string]map[string]string{}}
+
+	ctx := context.Background()
+	rc.Del(ctx, "DB_JOURNAL|Record")
+	defer rc.Del(ctx, "DB_JOURNAL|Record")
+	dbj.cache["DB_JOURNAL|Record"] = map[string]string{"old": "1", "kept": "2"}
+	rc.HSet(ctx, "DB_JOURNAL|Record", "kept", "2", "new", "3")
+
+	record, changed, err := dbj.updateCache([]string{"hset", "DB_JOURNAL|Record"})
 	if err != nil {
-		t.Fatalf("Failed to create NewDbJournal: %v", err)
+		t.Fatalf("updateCache failed: %v", err)
 	}
-	defer dbj.Close()
-
-	// If DbJournal has a nil file pointer, it should be handled by rotateFile()
-	dbj.file = nil
-	if err := dbj.rotateFile(); err != nil {
-		t.Fatalf("Rotate failed because of nil file pointer: %v", err)
+	if !changed {
+		t.Errorf("updateCache did not report the change")
+	}
+	if record.Op != "hset" || record.Key != "DB_JOURNAL|Record" {
+		t.Errorf("Incorrect event in record: %+v", record)
+	}
+	if len(record.Before) != 2 || record.Before["old"] != "1" {
+		t.Errorf("Incorrect entry before the change: %v", record.Before)
+	}
+	if len(record.After) != 2 || record.After["new"] != "3" {
+		t.Errorf("Incorrect entry after the change: %v", record.After)
 	}
 
-	// Fill the file a few times to make sure rotate is working correctly
-	for i := 0; i < maxBackups+2; i++ {
-		// Make sure the file was created and open it
-		file, err := os.OpenFile(HostVarLogPath+"/config_db.txt", os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
-		if err != nil {
-			t.Fatalf("Failed to open DbJournal file: %v", err)
-		}
-
-		// Fill the file to reach 5MB
-		if err := file.Truncate(maxFileSize); err != nil {
-			t.Fatalf("Failed to write to DbJournal file: %v", err)
-		}
-
-		if err := dbj.rotateFile(); err != nil {
-			t.Fatalf("rotateFile failed: %v", err)
-		}
-
-		time.Sleep(1 * time.Second)
-
-		// Make sure the file was rotated
-		fileStat, err := os.Stat(HostVarLogPath + "/config_db.txt")
-		if err != nil {
-			t.Fatalf("Couldn't find DbJournal file: %v", err)
-		}
-		if fileStat.Size() >= 10000 {
-			t.Fatalf("DbJournal file was not rotated: size=%v", fileStat.Size())
-		}
+	if _, changed, _ = dbj.updateCache([]string{"hset", "DB_JOURNAL|Record"}); changed {
+		t.Errorf("updateCache reported a change of an unchanged entry")
 	}
+}
 
-	zippedFiles := 0
-	journalFiles := 0
-	files, err := os.ReadDir(HostVarLogPath)
-	if err != nil {
-		t.Fatalf("Failed to read HostVarLog dir: %v", err)
+func TestConfigDbKeys(t *testing.T) {
+	prefix := &gnmipb.Path{Elem: []*gnmipb.PathElem{{Name: "CONFIG_DB"}, {Name: "localhost"}}}
+	paths := []*gnmipb.Path{
+		{Elem: []*gnmipb.PathElem{{Name: "PORT"}, {Name: "Ethernet0"}, {Name: "mtu"}}},
+		{Elem: []*gnmipb.PathElem{{Name: "VLAN_MEMBER"}, {Name: "Vlan100|Ethernet4"}}},
+		{Elem: []*gnmipb.PathElem{{Name: "ACL_TABLE"}}},
 	}
-	for _, file := range files {
-		if file.Name() == "config_db.txt" {
-			journalFiles++
-		}
-		if strings.HasPrefix(file.Name(), "config_db") && strings.HasSuffix(file.Name(), ".gz") {
-			zippedFiles++
-		}
+	if got := fmt.Sprint(configDbKeys(prefix, paths)); got != "[PORT|Ethernet0 VLAN_MEMBER|Vlan100|Ethernet4 ACL_TABLE|*]" {
+		t.Errorf("configDbKeys = %s", got)
 	}
-	if journalFiles != 1 || zippedFiles != maxBackups {
-		t.Fatalf("Files not rotated correctly: journalFiles=%v, zippedFiles=%v", journalFiles, zippedFiles)
+	if got := fmt.Sprint(configDbKeys(prefix, []*gnmipb.Path{{}})); got != "[*]" {
+		t.Errorf("configDbKeys of the whole database = %s", got)
 	}
-
 }
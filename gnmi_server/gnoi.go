@@ -3,14 +3,12 @@ package gnmi
 import (
 	"context"
 	"encoding/json"
-	jwt "github.com/dgrijalva/jwt-go"
 	log "github.com/golang/glog"
 	spb "github.com/sonic-net/sonic-gnmi/proto/gnoi"
 	spb_jwt "github.com/sonic-net/sonic-gnmi/proto/gnoi/jwt"
 	transutil "github.com/sonic-net/sonic-gnmi/transl_utils"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"os/user"
 	"time"
 )
 
@@ -20,37 +18,32 @@ const (
 
 func (srv *Server) Authenticate(ctx context.Context, req *spb_jwt.AuthenticateRequest) (*spb_jwt.AuthenticateResponse, error) {
 	// Can't enforce normal authentication here.. maybe only enforce client cert auth if enabled?
-	// ctx,err := authenticate(srv.config, ctx, false)
+	// ctx,err := authenticate(srv.Config(), ctx, false)
 	// if err != nil {
 	// 	return nil, err
 	// }
 	log.V(1).Info("gNOI: Sonic Authenticate")
 
-	if !srv.config.UserAuth.Enabled("jwt") {
+	if !srv.Config().UserAuth.Enabled("jwt") {
 		return nil, status.Errorf(codes.Unimplemented, "")
 	}
-	auth_success, _ := UserPwAuth(req.Username, req.Password)
-	if auth_success {
-		usr, err := user.Lookup(req.Username)
-		if err == nil {
-			roles, err := GetUserRoles(usr)
-			if err == nil {
-				return &spb_jwt.AuthenticateResponse{Token: tokenResp(req.Username, roles)}, nil
-			}
-		}
-
+	authenticator := srv.Config().authenticator()
+	roles, err := authenticator.Authenticate(ctx, req.Username, req.Password)
+	if err != nil {
+		log.V(1).Infof("gNOI: %s authentication failed for user %s: %v", authenticator.Name(), req.Username, err)
+		return nil, status.Errorf(codes.PermissionDenied, "Invalid Username or Password")
 	}
-	return nil, status.Errorf(codes.PermissionDenied, "Invalid Username or Password")
+	return &spb_jwt.AuthenticateResponse{Token: tokenResp(req.Username, roles)}, nil
 
 }
 func (srv *Server) Refresh(ctx context.Context, req *spb_jwt.RefreshRequest) (*spb_jwt.RefreshResponse, error) {
-	ctx, err := authenticate(srv.config, ctx, "gnoi", true)
+	ctx, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return nil, err
 	}
 	log.V(1).Info("gNOI: Sonic Refresh")
 
-	if !srv.config.UserAuth.Enabled("jwt") {
+	if !srv.Config().UserAuth.Enabled("jwt") {
 		return nil, status.Errorf(codes.Unimplemented, "")
 	}
 
@@ -59,20 +52,27 @@ func (srv *Server) Refresh(ctx context.Context, req *spb_jwt.RefreshRequest) (*s
 		return nil, err
 	}
 
-	claims := &Claims{}
-	jwt.ParseWithClaims(token.AccessToken, claims, func(token *jwt.Token) (interface{}, error) {
-		return hmacSampleSecret, nil
-	})
+	claims, err := parseJWT(token.AccessToken)
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "%s", err.Error())
+	}
 	if time.Unix(claims.ExpiresAt, 0).Sub(time.Now()) > JwtRefreshInt {
 		return nil, status.Errorf(codes.InvalidArgument, "Invalid JWT Token")
 	}
 
-	return &spb_jwt.RefreshResponse{Token: tokenResp(claims.Username, claims.Roles)}, nil
+	// The new token replaces the old one, which can no longer be refreshed
+	resp := &spb_jwt.RefreshResponse{Token: tokenResp(claims.Username, claims.Roles)}
+	if claims.Id != "" {
+		if err := revokeJWT(claims); err != nil {
+			log.Errorf("gNOI: Failed to revoke refreshed JWT %s: %v", claims.Id, err)
+		}
+	}
+	return resp, nil
 
 }
 
 func (srv *Server) ClearNeighbors(ctx context.Context, req *spb.ClearNeighborsRequest) (*spb.ClearNeighborsResponse, error) {
-	ctx, err := authenticate(srv.config, ctx, "gnoi", true)
+	ctx, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return nil, err
 	}
@@ -103,7 +103,7 @@ func (srv *Server) ClearNeighbors(ctx context.Context, req *spb.ClearNeighborsRe
 }
 
 func (srv *Server) CopyConfig(ctx context.Context, req *spb.CopyConfigRequest) (*spb.CopyConfigResponse, error) {
-	ctx, err := authenticate(srv.config, ctx, "gnoi", true)
+	ctx, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +132,7 @@ func (srv *Server) CopyConfig(ctx context.Context, req *spb.CopyConfigRequest) (
 }
 
 func (srv *Server) ShowTechsupport(ctx context.Context, req *spb.TechsupportRequest) (*spb.TechsupportResponse, error) {
-	ctx, err := authenticate(srv.config, ctx, "gnoi", false)
+	ctx, err := authenticate(srv.Config(), ctx, "gnoi", false)
 	if err != nil {
 		return nil, err
 	}
@@ -161,7 +161,7 @@ func (srv *Server) ShowTechsupport(ctx context.Context, req *spb.TechsupportRequ
 }
 
 func (srv *Server) ImageInstall(ctx context.Context, req *spb.ImageInstallRequest) (*spb.ImageInstallResponse, error) {
-	ctx, err := authenticate(srv.config, ctx, "gnoi", true)
+	ctx, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return nil, err
 	}
@@ -190,7 +190,7 @@ func (srv *Server) ImageInstall(ctx context.Context, req *spb.ImageInstallReques
 }
 
 func (srv *Server) ImageRemove(ctx context.Context, req *spb.ImageRemoveRequest) (*spb.ImageRemoveResponse, error) {
-	ctx, err := authenticate(srv.config, ctx, "gnoi", true)
+	ctx, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +218,7 @@ func (srv *Server) ImageRemove(ctx context.Context, req *spb.ImageRemoveRequest)
 }
 
 func (srv *Server) ImageDefault(ctx context.Context, req *spb.ImageDefaultRequest) (*spb.ImageDefaultResponse, error) {
-	ctx, err := authenticate(srv.config, ctx, "gnoi", true)
+	ctx, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return nil, err
 	}
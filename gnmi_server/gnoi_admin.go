@@ -0,0 +1,168 @@
+package gnmi
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/sonic-net/sonic-gnmi/common_utils"
+	"github.com/sonic-net/sonic-gnmi/pkg/session"
+	spb_admin "github.com/sonic-net/sonic-gnmi/proto/gnoi/admin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// adminAuthTarget is the auth target of the admin service, granted by the
+// gnoi_admin_readonly and gnoi_admin_readwrite roles.
+const adminAuthTarget = "gnoi_admin"
+
+// AdminServer implements the SONiC admin service over the session registry.
+type AdminServer struct {
+	*Server
+	registry *session.Registry
+	spb_admin.UnimplementedSonicAdminServiceServer
+}
+
+// authorize authenticates the caller of an admin RPC and requires the
+// explicit gnoi_admin_readwrite role, or gnoi_admin_readonly for reads,
+// whatever the authentication method. Sessions are only unrestricted when
+// no authentication is enabled.
+func (srv *AdminServer) authorize(ctx context.Context, write bool) error {
+	ctx, err := authenticate(srv.Config(), ctx, adminAuthTarget, write)
+	if err != nil {
+		return err
+	}
+	rc, _ := common_utils.GetContext(ctx)
+	if !rc.Auth.AuthEnabled {
+		return nil
+	}
+	for _, role := range rc.Auth.Roles {
+		switch strings.ToLower(strings.TrimSpace(role)) {
+		case adminAuthTarget + "_" + WriteAccessMode:
+			return nil
+		case adminAuthTarget + "_" + ReadOnlyMode:
+			if !write {
+				return nil
+			}
+		}
+	}
+	if write {
+		return status.Errorf(codes.PermissionDenied, "%s requires the %s_%s role", rc.Auth.User, adminAuthTarget, WriteAccessMode)
+	}
+	return status.Errorf(codes.PermissionDenied, "%s requires a %s role", rc.Auth.User, adminAuthTarget)
+}
+
+// NewAdminServer creates an AdminServer managing the sessions of the server.
+func NewAdminServer(srv *Server) *AdminServer {
+	return &AdminServer{Server: srv, registry: session.Default}
+}
+
+// ListSessions returns every live streaming session.
+func (srv *AdminServer) ListSessions(ctx context.Context, req *spb_admin.ListSessionsRequest) (*spb_admin.ListSessionsResponse, error) {
+	if err := srv.authorize(ctx, false); err != nil {
+		return nil, err
+	}
+	log.V(1).Info("gNOI: Sonic Admin ListSessions")
+
+	resp := &spb_admin.ListSessionsResponse{}
+	for _, info := range srv.registry.List() {
+		resp.Sessions = append(resp.Sessions, sessionToProto(info))
+	}
+	return resp, nil
+}
+
+// KillSession terminates the sessions matching all the selectors of the request.
+func (srv *AdminServer) KillSession(ctx context.Context, req *spb_admin.KillSessionRequest) (*spb_admin.KillSessionResponse, error) {
+	if err := srv.authorize(ctx, true); err != nil {
+		return nil, err
+	}
+	log.V(1).Infof("gNOI: Sonic Admin KillSession id=%q peer=%q user=%q", req.GetId(), req.GetPeer(), req.GetUser())
+
+	if req.GetId() == "" && req.GetPeer() == "" && req.GetUser() == "" {
+		return nil, status.Error(codes.InvalidArgument, "at least one of id, peer or user must be set")
+	}
+	killed := srv.registry.Kill(func(info session.Info) bool {
+		return matchSession(req, info)
+	})
+	if len(killed) == 0 {
+		return nil, status.Error(codes.NotFound, "no matching session")
+	}
+
+	resp := &spb_admin.KillSessionResponse{}
+	for _, info := range killed {
+		log.Infof("Killed session %s of user %q from %s (%s)", info.ID, info.User, info.Peer, info.Method)
+		resp.Killed = append(resp.Killed, sessionToProto(info))
+	}
+	return resp, nil
+}
+
+// maxDrainDeadline bounds the time DrainServer waits for sessions to finish.
+const maxDrainDeadline = time.Hour
+
+// DrainServer refuses new sessions and waits for the live ones to finish
+// within the deadline of the request, then terminates the remaining ones.
+// New sessions are refused until a request with resume set.
+func (srv *AdminServer) DrainServer(ctx context.Context, req *spb_admin.DrainServerRequest) (*spb_admin.DrainServerResponse, error) {
+	if err := srv.authorize(ctx, true); err != nil {
+		return nil, err
+	}
+	if req.GetResume() {
+		log.Infof("gNOI: Sonic Admin DrainServer, resume")
+		srv.registry.Undrain()
+		return &spb_admin.DrainServerResponse{}, nil
+	}
+	deadline := time.Duration(req.GetDeadlineSeconds()) * time.Second
+	if deadline <= 0 || deadline > maxDrainDeadline {
+		return nil, status.Errorf(codes.InvalidArgument, "deadline_seconds must be between 1 and %d", int(maxDrainDeadline/time.Second))
+	}
+	log.Infof("gNOI: Sonic Admin DrainServer, deadline %v", deadline)
+
+	// The drain outlives a caller that gives up waiting
+	drainCtx, cancel := context.WithTimeout(context.Background(), deadline)
+	defer cancel()
+	completed, killed := srv.registry.Drain(drainCtx)
+	log.Infof("Server drained: %d sessions completed, %d killed", completed, killed)
+
+	return &spb_admin.DrainServerResponse{
+		Completed: uint32(completed),
+		Killed:    uint32(killed),
+	}, nil
+}
+
+// matchSession reports whether info matches every selector set in req. A
+// peer without port matches all the sessions of that host.
+func matchSession(req *spb_admin.KillSessionRequest, info session.Info) bool {
+	if req.GetId() != "" && req.GetId() != info.ID {
+		return false
+	}
+	if req.GetUser() != "" && req.GetUser() != info.User {
+		return false
+	}
+	if peer := req.GetPeer(); peer != "" && peer != info.Peer {
+		host, _, err := net.SplitHostPort(info.Peer)
+		if err != nil || host != peer {
+			return false
+		}
+	}
+	return true
+}
+
+func sessionToProto(info session.Info) *spb_admin.Session {
+	return &spb_admin.Session{
+		Id:           info.ID,
+		Peer:         info.Peer,
+		Method:       info.Method,
+		User:         info.User,
+		Roles:        info.Roles,
+		Target:       info.Target,
+		Origin:       info.Origin,
+		Mode:         info.Mode,
+		Paths:        info.Paths,
+		StartTime:    info.StartTime.UnixNano(),
+		MessagesSent: info.MessagesSent,
+		Errors:       info.Errors,
+		QueueDepth:   int64(info.QueueDepth),
+	}
+}
@@ -0,0 +1,171 @@
+package gnmi
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sonic-net/sonic-gnmi/pkg/interceptors"
+	"github.com/sonic-net/sonic-gnmi/pkg/session"
+	spb_admin "github.com/sonic-net/sonic-gnmi/proto/gnoi/admin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestAdminServer() *AdminServer {
+	return &AdminServer{
+		Server:   &Server{config: &Config{}},
+		registry: session.NewRegistry(),
+	}
+}
+
+func TestAdminServer_ListAndKillSessions(t *testing.T) {
+	srv := newTestAdminServer()
+	ctx := context.Background()
+
+	collector := srv.registry.Start("10.0.0.1:5000", "/gnmi.gNMI/Subscribe")
+	collector.SetUser("collector", nil)
+	collectorKilled := false
+	collector.OnKill(func() { collectorKilled = true })
+	controller := srv.registry.Start("10.0.0.2:6000", "/gnmi.gNMI/Subscribe")
+	controller.SetUser("controller", nil)
+
+	resp, err := srv.ListSessions(ctx, &spb_admin.ListSessionsRequest{})
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(resp.Sessions) != 2 || resp.Sessions[0].User != "collector" || resp.Sessions[0].StartTime == 0 {
+		t.Errorf("Unexpected sessions: %v", resp.Sessions)
+	}
+
+	if _, err := srv.KillSession(ctx, &spb_admin.KillSessionRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without selector, got %v", err)
+	}
+	if _, err := srv.KillSession(ctx, &spb_admin.KillSessionRequest{Peer: "10.0.0.1", User: "controller"}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound when selectors do not match together, got %v", err)
+	}
+
+	killResp, err := srv.KillSession(ctx, &spb_admin.KillSessionRequest{Peer: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("KillSession failed: %v", err)
+	}
+	if len(killResp.Killed) != 1 || killResp.Killed[0].Id != collector.ID() || !collectorKilled {
+		t.Errorf("Expected collector session to be killed, got %v", killResp.Killed)
+	}
+}
+
+func TestAdminServer_DrainServer(t *testing.T) {
+	srv := newTestAdminServer()
+
+	finishing := srv.registry.Start("10.0.0.1:5000", "/gnoi.file.File/Get")
+	stuck := srv.registry.Start("10.0.0.2:6000", "/gnmi.gNMI/Subscribe")
+	stuck.OnKill(func() { srv.registry.End(stuck) })
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		srv.registry.End(finishing)
+	}()
+
+	resp, err := srv.DrainServer(context.Background(), &spb_admin.DrainServerRequest{DeadlineSeconds: 1})
+	if err != nil {
+		t.Fatalf("DrainServer failed: %v", err)
+	}
+	if resp.Completed != 1 || resp.Killed != 1 {
+		t.Errorf("Expected 1 completed and 1 killed, got %v", resp)
+	}
+	if !srv.registry.Draining() {
+		t.Error("Expected registry to refuse new sessions")
+	}
+}
+
+func TestAdminServer_DrainServerDeadline(t *testing.T) {
+	srv := newTestAdminServer()
+	for _, deadline := range []uint32{0, 3601} {
+		_, err := srv.DrainServer(context.Background(), &spb_admin.DrainServerRequest{DeadlineSeconds: deadline})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument for deadline %d, got %v", deadline, err)
+		}
+	}
+	if srv.registry.Draining() {
+		t.Error("Expected an invalid drain to leave the registry accepting sessions")
+	}
+}
+
+func TestAdminServer_DrainServerResume(t *testing.T) {
+	srv := newTestAdminServer()
+	interceptor := interceptors.NewSessionInterceptor(srv.registry).StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/gnmi.gNMI/Subscribe"}
+	handler := func(interface{}, grpc.ServerStream) error { return nil }
+
+	if _, err := srv.DrainServer(context.Background(), &spb_admin.DrainServerRequest{DeadlineSeconds: 1}); err != nil {
+		t.Fatalf("DrainServer failed: %v", err)
+	}
+	if err := interceptor(nil, nil, info, handler); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable while drained, got %v", err)
+	}
+
+	resp, err := srv.DrainServer(context.Background(), &spb_admin.DrainServerRequest{Resume: true})
+	if err != nil {
+		t.Fatalf("DrainServer resume failed: %v", err)
+	}
+	if resp.Completed != 0 || resp.Killed != 0 || srv.registry.Draining() {
+		t.Errorf("Expected resume to accept sessions again, got %v", resp)
+	}
+	if err := interceptor(nil, nil, info, handler); err != nil {
+		t.Errorf("Expected new stream to be accepted after resume, got %v", err)
+	}
+}
+
+func TestAdminServer_Authorization(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	writeCredentials(t, path,
+		"admin:"+bcryptHash(t, "admin-pw")+":gnoi_admin_readwrite",
+		"auditor:"+bcryptHash(t, "auditor-pw")+":gnoi_admin_readonly",
+		"operator:"+bcryptHash(t, "operator-pw")+":gnoi_readwrite,gnmi_readwrite",
+		"nobody:"+bcryptHash(t, "nobody-pw")+":")
+	a, err := NewFileAuthenticator(path)
+	if err != nil {
+		t.Fatalf("NewFileAuthenticator failed: %v", err)
+	}
+	srv := newTestAdminServer()
+	srv.config = &Config{UserAuth: AuthTypes{"password": true}, Authenticator: a}
+	login := func(user, password string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("username", user, "password", password))
+	}
+	kill := &spb_admin.KillSessionRequest{User: "nobody"}
+	drain := &spb_admin.DrainServerRequest{Resume: true}
+
+	for _, user := range []string{"operator", "nobody"} {
+		ctx := login(user, user+"-pw")
+		if _, err := srv.ListSessions(ctx, &spb_admin.ListSessionsRequest{}); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("Expected ListSessions of %s to be denied, got %v", user, err)
+		}
+		if _, err := srv.KillSession(ctx, kill); err == nil || status.Code(err) == codes.NotFound {
+			t.Errorf("Expected KillSession of %s to be denied, got %v", user, err)
+		}
+		if _, err := srv.DrainServer(ctx, drain); err == nil {
+			t.Errorf("Expected DrainServer of %s to be denied", user)
+		}
+	}
+
+	ctx := login("auditor", "auditor-pw")
+	if _, err := srv.ListSessions(ctx, &spb_admin.ListSessionsRequest{}); err != nil {
+		t.Errorf("Expected auditor to list sessions, got %v", err)
+	}
+	if _, err := srv.KillSession(ctx, kill); err == nil || status.Code(err) == codes.NotFound {
+		t.Errorf("Expected KillSession of auditor to be denied, got %v", err)
+	}
+	if _, err := srv.DrainServer(ctx, drain); err == nil {
+		t.Error("Expected DrainServer of auditor to be denied")
+	}
+
+	ctx = login("admin", "admin-pw")
+	if _, err := srv.KillSession(ctx, kill); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected admin to reach the sessions, got %v", err)
+	}
+	if _, err := srv.DrainServer(ctx, drain); err != nil {
+		t.Errorf("Expected admin to resume the server, got %v", err)
+	}
+}
@@ -23,7 +23,7 @@ func (c *ContainerzServer) Deploy(stream gnoi_containerz_pb.Containerz_DeploySer
 	ctx := stream.Context()
 
 	// Authenticate the client using the server's config.
-	_, err := authenticate(c.server.config, ctx, "gnoi", true)
+	_, err := authenticate(c.server.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return err
 	}
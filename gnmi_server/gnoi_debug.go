@@ -13,19 +13,25 @@ func (srv *DebugServer) Debug(req *gnoi_debug_pb.DebugRequest, stream gnoi_debug
 	common_utils.GetUsername(stream.Context(), &username)
 	log.Infof("gNOI Debug RPC called by '%s': %+v", username, req)
 
-	_, readAccessErr := authenticate(srv.config, stream.Context(), "gnoi", false)
+	config := srv.Config()
+	readWhitelist, writeWhitelist := srv.readWhitelist, srv.writeWhitelist
+	if config.DebugReadWhitelist != nil {
+		readWhitelist, writeWhitelist = config.DebugReadWhitelist, config.DebugWriteWhitelist
+	}
+
+	_, readAccessErr := authenticate(config, stream.Context(), "gnoi", false)
 	if readAccessErr != nil {
 		// User cannot do anything, abort
 		log.Errorf("authentication failed in Debug RPC: %v", readAccessErr)
 		return readAccessErr
 	}
 
-	_, writeAccessErr := authenticate(srv.config, stream.Context(), "gnoi", true)
+	_, writeAccessErr := authenticate(config, stream.Context(), "gnoi", true)
 	if writeAccessErr != nil {
 		// User has read-only access
-		return gnoi_debug.HandleCommandRequest(req, stream, srv.readWhitelist)
+		return gnoi_debug.HandleCommandRequest(req, stream, readWhitelist)
 	}
 
 	// Otherwise, this user has write access
-	return gnoi_debug.HandleCommandRequest(req, stream, srv.writeWhitelist)
+	return gnoi_debug.HandleCommandRequest(req, stream, writeWhitelist)
 }
@@ -189,7 +189,7 @@ func getDebugData(p *types.Path) (*healthz.GetResponse, error) {
 // Get implements the corresponding RPC.
 func (srv *HealthzServer) Get(ctx context.Context, req *healthz.GetRequest) (*healthz.GetResponse, error) {
 	log.V(1).Infof("Get RPC request Path: %v\n", req.GetPath())
-	ctx, err := authenticate(srv.config, ctx, "gnoi", false)
+	ctx, err := authenticate(srv.Config(), ctx, "gnoi", false)
 	if err != nil {
 		log.Errorf("Healthz.Get authentication failed: %v", err)
 		return nil, err
@@ -298,7 +298,7 @@ func (srv *HealthzServer) Artifact(req *healthz.ArtifactRequest, stream healthz.
 // Acknowledge implements the corresponding RPC.
 func (srv *HealthzServer) Acknowledge(ctx context.Context, req *healthz.AcknowledgeRequest) (*healthz.AcknowledgeResponse, error) {
 	log.V(1).Infof("Acknowledge RPC Get request ID: %+v", req.GetId())
-	ctx, err := authenticate(srv.config, ctx, "gnoi", false)
+	ctx, err := authenticate(srv.Config(), ctx, "gnoi", false)
 	if err != nil {
 		log.Errorf("Healthz.Acknowledge authentication failed: %v", err)
 		return nil, err
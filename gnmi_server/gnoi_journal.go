@@ -0,0 +1,152 @@
+package gnmi
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/redis/go-redis/v9"
+	"github.com/sonic-net/sonic-gnmi/pkg/configjournal"
+	spb_journal "github.com/sonic-net/sonic-gnmi/proto/gnoi/journal"
+	sdc "github.com/sonic-net/sonic-gnmi/sonic_data_client"
+	sdcfg "github.com/sonic-net/sonic-gnmi/sonic_db_config"
+	ssc "github.com/sonic-net/sonic-gnmi/sonic_service_client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// JournalServer implements the SONiC journal service over the CONFIG_DB
+// journal.
+type JournalServer struct {
+	*Server
+	spb_journal.UnimplementedSonicJournalServiceServer
+}
+
+// NewJournalServer creates a JournalServer.
+func NewJournalServer(srv *Server) *JournalServer {
+	return &JournalServer{Server: srv}
+}
+
+// Rollback undoes the CONFIG_DB changes journaled after the requested point,
+// or only returns the patch undoing them on a dry run.
+func (srv *JournalServer) Rollback(ctx context.Context, req *spb_journal.RollbackRequest) (*spb_journal.RollbackResponse, error) {
+	_, err := authenticate(srv.Config(), ctx, "gnoi", false)
+	if err != nil {
+		return nil, err
+	}
+	// Applying the rollback rewrites CONFIG_DB, authorize it as a native Set
+	if !req.GetDryRun() {
+		if _, err := authenticate(srv.Config(), ctx, "gnmi_CONFIG_DB", true); err != nil {
+			return nil, err
+		}
+	}
+	log.V(1).Infof("gNOI: Sonic Journal Rollback timestamp=%d sequence=%d dry_run=%v", req.GetTimestamp(), req.GetSequence(), req.GetDryRun())
+
+	journal := configjournal.Default()
+	if journal == nil {
+		return nil, status.Error(codes.FailedPrecondition, "CONFIG_DB journal is not enabled, see -enable_config_db_journal")
+	}
+	var point configjournal.Point
+	switch {
+	case req.GetSequence() != 0:
+		point.Seq = req.GetSequence()
+	case req.GetTimestamp() != 0:
+		point.Time = time.Unix(0, req.GetTimestamp())
+	default:
+		return nil, status.Error(codes.InvalidArgument, "timestamp or sequence must be set")
+	}
+	records, err := journal.After(point)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	rc, err := newConfigDbClient()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	defer rc.Close()
+	patch, err := configjournal.InversePatch(records, func(key string) (map[string]string, error) {
+		return rc.HGetAll(ctx, key).Result()
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &spb_journal.RollbackResponse{Patch: "[]", Records: uint32(len(records))}
+	if len(patch) != 0 {
+		text, err := json.Marshal(patch)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		resp.Patch = string(text)
+	}
+	if req.GetDryRun() || len(patch) == 0 {
+		return resp, nil
+	}
+	if !srv.Config().EnableNativeWrite {
+		return nil, status.Error(codes.Unimplemented, "GNMI native write is disabled")
+	}
+
+	keys := make([]string, len(records))
+	for i, r := range records {
+		keys[i] = r.Key
+	}
+	defer attributeJournal(ctx, keys)()
+	if err := applyConfigPatch(patch); err != nil {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	log.Infof("Rolled back %d CONFIG_DB changes", len(records))
+	resp.Applied = true
+	return resp, nil
+}
+
+// newConfigDbClient returns a client of CONFIG_DB in the default namespace.
+func newConfigDbClient() (*redis.Client, error) {
+	ns, _ := sdcfg.GetDbDefaultNamespace()
+	addr, err := sdcfg.GetDbTcpAddr("CONFIG_DB", ns)
+	if err != nil {
+		return nil, err
+	}
+	dbId, err := sdcfg.GetDbId("CONFIG_DB", ns)
+	if err != nil {
+		return nil, err
+	}
+	return redis.NewClient(&redis.Options{
+		Network:     "tcp",
+		Addr:        addr,
+		Password:    "",
+		DB:          dbId,
+		DialTimeout: 0,
+	}), nil
+}
+
+// applyConfigPatch applies a JSON patch of CONFIG_DB through GCU, like native
+// writes, and saves the configuration.
+func applyConfigPatch(patch []configjournal.PatchOp) error {
+	sc, err := ssc.NewDbusClient()
+	if err != nil {
+		return err
+	}
+	multiNs, err := sdcfg.CheckDbMultiNamespace()
+	if err != nil {
+		return err
+	}
+	if multiNs {
+		// Default name space for GCU is localhost
+		prefixed := make([]configjournal.PatchOp, len(patch))
+		for i, op := range patch {
+			op.Path = "/" + sdc.HOSTNAME + op.Path
+			prefixed[i] = op
+		}
+		patch = prefixed
+	}
+	text, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	if err := sc.ApplyPatchDb(string(text)); err != nil {
+		return err
+	}
+	return sc.ConfigSave("/etc/sonic/config_db.json")
+}
@@ -0,0 +1,78 @@
+package gnmi
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/sonic-net/sonic-gnmi/pkg/configjournal"
+	spb_journal "github.com/sonic-net/sonic-gnmi/proto/gnoi/journal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestJournalServer_Rollback(t *testing.T) {
+	srv := NewJournalServer(&Server{config: &Config{}})
+	ctx := context.Background()
+
+	if _, err := srv.Rollback(ctx, &spb_journal.RollbackRequest{Sequence: 1}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition without journal, got %v", err)
+	}
+
+	journal, err := configjournal.Open(configjournal.Options{Path: filepath.Join(t.TempDir(), "config_db.txt")})
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer journal.Close()
+	configjournal.SetDefault(journal)
+	defer configjournal.SetDefault(nil)
+
+	if _, err := srv.Rollback(ctx, &spb_journal.RollbackRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without point, got %v", err)
+	}
+
+	rclient := getConfigDbClient(t, "")
+	defer rclient.Close()
+	key := "JOURNAL_TEST|Ethernet0"
+	rclient.HSet(ctx, key, "mtu", "9100")
+	defer rclient.Del(ctx, key)
+	journal.Write(configjournal.Record{Database: "CONFIG_DB", Op: "hset", Key: key, After: map[string]string{"mtu": "1500"}})
+	journal.Write(configjournal.Record{Database: "CONFIG_DB", Op: "hset", Key: key, Before: map[string]string{"mtu": "1500"}, After: map[string]string{"mtu": "9100"}})
+
+	resp, err := srv.Rollback(ctx, &spb_journal.RollbackRequest{Sequence: 1, DryRun: true})
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	want := `[{"op":"replace","path":"/JOURNAL_TEST/Ethernet0/mtu","value":"1500"}]`
+	if resp.Patch != want || resp.Records != 1 || resp.Applied {
+		t.Errorf("Unexpected dry run response %v, want patch %s", resp, want)
+	}
+
+	if _, err := srv.Rollback(ctx, &spb_journal.RollbackRequest{Sequence: 5, DryRun: true}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition past the journal, got %v", err)
+	}
+	if _, err := srv.Rollback(ctx, &spb_journal.RollbackRequest{Sequence: 1}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented without native write, got %v", err)
+	}
+}
+
+func TestJournalServer_RollbackAuthorization(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	writeCredentials(t, path,
+		"operator:"+bcryptHash(t, "operator-pw")+":gnoi_readwrite,gnmi_config_db_readonly")
+	a, err := NewFileAuthenticator(path)
+	if err != nil {
+		t.Fatalf("NewFileAuthenticator failed: %v", err)
+	}
+	srv := NewJournalServer(&Server{config: &Config{UserAuth: AuthTypes{"password": true}, Authenticator: a}})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("username", "operator", "password", "operator-pw"))
+
+	// The dry run only needs gNOI access and fails later without a journal
+	if _, err := srv.Rollback(ctx, &spb_journal.RollbackRequest{Sequence: 1, DryRun: true}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected dry run to pass authorization, got %v", err)
+	}
+	if _, err := srv.Rollback(ctx, &spb_journal.RollbackRequest{Sequence: 1}); err == nil || status.Code(err) == codes.FailedPrecondition {
+		t.Errorf("Expected apply to be denied without CONFIG_DB write access, got %v", err)
+	}
+}
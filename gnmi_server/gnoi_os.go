@@ -243,7 +243,7 @@ func (srv *OSServer) removeIncompleteTransfer(imgPath string) {
 // Install implements correspondig RPC
 func (srv *OSServer) Install(stream ospb.OS_InstallServer) error {
 	ctx := stream.Context()
-	ctx, err := authenticate(srv.config, ctx, "gnoi", false)
+	ctx, err := authenticate(srv.Config(), ctx, "gnoi", false)
 	if err != nil {
 		return err
 	}
@@ -389,7 +389,7 @@ func (srv *OSServer) Install(stream ospb.OS_InstallServer) error {
 }
 
 func (srv *OSServer) Activate(ctx context.Context, req *ospb.ActivateRequest) (*ospb.ActivateResponse, error) {
-	_, err := authenticate(srv.config, ctx, "gnoi" /*writeAccess=*/, true)
+	_, err := authenticate(srv.Config(), ctx, "gnoi" /*writeAccess=*/, true)
 	if err != nil {
 		log.Errorf("Failed to authenticate: %v", err)
 		return nil, err
@@ -439,7 +439,7 @@ func (srv *OSServer) Activate(ctx context.Context, req *ospb.ActivateRequest) (*
 }
 
 func (srv *OSServer) Verify(ctx context.Context, req *ospb.VerifyRequest) (*ospb.VerifyResponse, error) {
-	_, err := authenticate(srv.config, ctx, "gnoi", false)
+	_, err := authenticate(srv.Config(), ctx, "gnoi", false)
 	if err != nil {
 		log.V(2).Infof("Failed to authenticate: %v", err)
 		return nil, err
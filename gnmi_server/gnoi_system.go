@@ -70,7 +70,7 @@ func KillOrRestartProcess(restart bool, serviceName string) error {
 }
 
 func (srv *Server) KillProcess(ctx context.Context, req *syspb.KillProcessRequest) (*syspb.KillProcessResponse, error) {
-	_, err := authenticate(srv.config, ctx, "gnoi", true)
+	_, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return nil, err
 	}
@@ -191,7 +191,7 @@ func sendRebootReqOnNotifCh(ctx context.Context, req proto.Message, sc *redis.Cl
 
 // Reboot implements the corresponding RPC.
 func (srv *Server) Reboot(ctx context.Context, req *syspb.RebootRequest) (*syspb.RebootResponse, error) {
-	_, err := authenticate(srv.config, ctx, "gnoi", true)
+	_, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return nil, err
 	}
@@ -239,7 +239,7 @@ func (srv *Server) Reboot(ctx context.Context, req *syspb.RebootRequest) (*syspb
 
 // RebootStatus implements the corresponding RPC.
 func (srv *Server) RebootStatus(ctx context.Context, req *syspb.RebootStatusRequest) (*syspb.RebootStatusResponse, error) {
-	_, err := authenticate(srv.config, ctx, "gnoi", true)
+	_, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return nil, err
 	}
@@ -268,7 +268,7 @@ func (srv *Server) RebootStatus(ctx context.Context, req *syspb.RebootStatusRequ
 
 // CancelReboot RPC implements the corresponding RPC.
 func (srv *Server) CancelReboot(ctx context.Context, req *syspb.CancelRebootRequest) (*syspb.CancelRebootResponse, error) {
-	_, err := authenticate(srv.config, ctx, "gnoi", true)
+	_, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return nil, err
 	}
@@ -294,7 +294,7 @@ func (srv *Server) CancelReboot(ctx context.Context, req *syspb.CancelRebootRequ
 // Ping is unimplemented.
 func (srv *Server) Ping(req *syspb.PingRequest, stream syspb.System_PingServer) error {
 	ctx := stream.Context()
-	_, err := authenticate(srv.config, ctx, "gnoi", true)
+	_, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return err
 	}
@@ -305,7 +305,7 @@ func (srv *Server) Ping(req *syspb.PingRequest, stream syspb.System_PingServer)
 // Traceroute is unimplemented.
 func (srv *Server) Traceroute(req *syspb.TracerouteRequest, stream syspb.System_TracerouteServer) error {
 	ctx := stream.Context()
-	_, err := authenticate(srv.config, ctx, "gnoi", true)
+	_, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return err
 	}
@@ -316,7 +316,7 @@ func (srv *Server) Traceroute(req *syspb.TracerouteRequest, stream syspb.System_
 func (srv *Server) SetPackage(rs syspb.System_SetPackageServer) error {
 	ctx := rs.Context()
 
-	_, err := authenticate(srv.config, ctx, "gnoi", true)
+	_, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		log.Errorf("Authentication failed: %v", err)
 		return status.Errorf(codes.PermissionDenied, "authentication failed: %v", err)
@@ -329,7 +329,7 @@ func (srv *Server) SetPackage(rs syspb.System_SetPackageServer) error {
 
 // SwitchControlProcessor implements the corresponding RPC.
 func (srv *Server) SwitchControlProcessor(ctx context.Context, req *syspb.SwitchControlProcessorRequest) (*syspb.SwitchControlProcessorResponse, error) {
-	_, err := authenticate(srv.config, ctx, "gnoi", true)
+	_, err := authenticate(srv.Config(), ctx, "gnoi", true)
 	if err != nil {
 		return nil, err
 	}
@@ -339,7 +339,7 @@ func (srv *Server) SwitchControlProcessor(ctx context.Context, req *syspb.Switch
 
 // Time implements the corresponding RPC.
 func (srv *Server) Time(ctx context.Context, req *syspb.TimeRequest) (*syspb.TimeResponse, error) {
-	_, err := authenticate(srv.config, ctx, "gnoi", false)
+	_, err := authenticate(srv.Config(), ctx, "gnoi", false)
 	if err != nil {
 		return nil, err
 	}
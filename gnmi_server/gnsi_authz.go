@@ -66,7 +66,7 @@ func NewGNSIAuthzServer(srv *Server) *GNSIAuthzServer {
 func (srv *GNSIAuthzServer) Rotate(stream authz.Authz_RotateServer) error {
 	ctx := stream.Context()
 	log.Infof("GNSI Authz Rotate RPC")
-	_, err := authenticateFunc(srv.config, ctx, "gnoi", false)
+	_, err := authenticateFunc(srv.Config(), ctx, "gnoi", false)
 	if err != nil {
 		log.Errorf("authentication failed in Rotate RPC: %v", err)
 		return err
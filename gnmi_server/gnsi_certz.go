@@ -224,7 +224,7 @@ func (srv *GNSICertzServer) bootstrapDefaultProfile() *profile {
 // Rotate implements corresponding RPC.
 func (srv *GNSICertzServer) Rotate(stream certz.Certz_RotateServer) error {
 	ctx := stream.Context()
-	_, err := authenticate(srv.config, ctx, "gnoi", false)
+	_, err := authenticate(srv.Config(), ctx, "gnoi", false)
 	if err != nil {
 		return err
 	}
@@ -181,7 +181,7 @@ func (srv *GNSIPathzServer) commitChanges() error {
 func (srv *GNSIPathzServer) Rotate(stream pathz.Pathz_RotateServer) error {
 	log.V(2).Info("gNSI pathz Rotate RPC")
 	ctx := stream.Context()
-	ctx, err := authenticateFunc(srv.config, ctx, "gnoi", false)
+	ctx, err := authenticateFunc(srv.Config(), ctx, "gnoi", false)
 	if err != nil {
 		return err
 	}
@@ -42,11 +42,13 @@ func TestGetShowInterfaceFecStatus(t *testing.T) {
 	fullData := `[{"Interface": "Ethernet0", "FEC Oper": "rs", "FEC Admin": "rs"},{"Interface": "Ethernet40", "FEC Oper": "N/A", "FEC Admin": "rs"},{"Interface": "Ethernet80", "FEC Oper": "rs", "FEC Admin": "rs"}]`
 	mixedData := `[{"Interface": "Ethernet0", "FEC Oper": "N/A", "FEC Admin": "rs"},{"Interface": "Ethernet40", "FEC Oper": "N/A", "FEC Admin": "rs"},{"Interface": "Ethernet80", "FEC Oper": "N/A", "FEC Admin": "N/A"}]`
 	oneIntfData := `[{"Interface": "Ethernet0", "FEC Oper": "rs", "FEC Admin": "rs"}]`
+	backplaneIntfData := `[{"Interface": "Ethernet-BP0", "FEC Oper": "N/A", "FEC Admin": "N/A"}]`
 
 	portsFileName := "../testdata/PORTS.txt"
 	portTableFileName := "../testdata/PORT_TABLE.txt"
 	operDownPortTableFileName := "../testdata/OPER_DOWN_PORT_TABLE.json"
 	stateDBPortTableFileName := "../testdata/STATE_PORT_TABLE.json"
+	backplanePortsFileName := "../testdata/BACKPLANE_PORTS.txt"
 
 	tests := []struct {
 		desc        string
@@ -147,6 +149,81 @@ func TestGetShowInterfaceFecStatus(t *testing.T) {
 				AddDataSet(t, StateDbNum, stateDBPortTableFileName)
 			},
 		},
+		{
+			desc:       "query SHOW interface fec status - backplane ports hidden",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "fec" >
+				elem: <name: "status" >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(fullData),
+			valTest:     true,
+			testInit: func() {
+				FlushDataSet(t, ConfigDbNum)
+				FlushDataSet(t, ApplDbNum)
+				FlushDataSet(t, StateDbNum)
+				AddDataSet(t, ConfigDbNum, portsFileName)
+				AddDataSet(t, ConfigDbNum, backplanePortsFileName)
+				AddDataSet(t, ApplDbNum, portTableFileName)
+				AddDataSet(t, StateDbNum, stateDBPortTableFileName)
+			},
+		},
+		{
+			desc:       "query SHOW interface fec status - backplane interface in frontend display",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "fec" >
+				elem: <name: "status" key: { key: "interface" value: "Ethernet-BP0" } >
+			`,
+			wantRetCode: codes.NotFound,
+		},
+		{
+			desc:       "query SHOW interface fec status - backplane interface with display all",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "fec" >
+				elem: <name: "status" key: { key: "interface" value: "Ethernet-BP0" } key: { key: "display" value: "all" } >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(backplaneIntfData),
+			valTest:     true,
+		},
+		{
+			desc:       "query SHOW interface fec status - invalid display",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "fec" >
+				elem: <name: "status" key: { key: "display" value: "backend" } >
+			`,
+			wantRetCode: codes.InvalidArgument,
+		},
+		{
+			desc:       "query SHOW interface fec status - namespace on single asic",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "fec" >
+				elem: <name: "status" key: { key: "namespace" value: "asic0" } >
+			`,
+			wantRetCode: codes.InvalidArgument,
+		},
+		{
+			desc:       "query SHOW interface fec status - namespace all on single asic",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "fec" >
+				elem: <name: "status" key: { key: "namespace" value: "all" } >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(fullData),
+			valTest:     true,
+		},
 	}
 
 	for _, test := range tests {
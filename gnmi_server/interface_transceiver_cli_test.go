@@ -2,7 +2,7 @@ package gnmi
 
 // interface_transceiver_cli_test.go
 
-// Tests SHOW interface transceiver commands
+// Tests SHOW interface transceiver commands: error-status, eeprom, presence, lpmode, status and pm
 
 import (
 	"crypto/tls"
@@ -115,3 +115,160 @@ func TestGetTransceiverErrorStatus(t *testing.T) {
 
 	}
 }
+
+func TestGetTransceiverData(t *testing.T) {
+	s := createServer(t, ServerPort)
+	go runServer(t, s)
+	defer s.ForceStop()
+	defer ResetDataSetsAndMappings(t)
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+
+	conn, err := grpc.Dial(TargetAddr, opts...)
+	if err != nil {
+		t.Fatalf("Dialing to %q failed: %v", TargetAddr, err)
+	}
+	defer conn.Close()
+
+	gClient := pb.NewGNMIClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout*time.Second)
+	defer cancel()
+
+	portsFileName := "../testdata/PORTS.txt"
+	transceiverDataFileName := "../testdata/TRANSCEIVER_DATA.txt"
+	transceiverEeprom := `{"Ethernet0":{"presence":"Present","info":{"type":"QSFP28 or later","vendor_name":"Arista Networks","serial":"XYL1234567","model":"QSFP-100G-CR4","vendor_rev":"A1","cable_type":"Length Cable Assembly(m)","cable_length":"1.0"}},"Ethernet40":{"presence":"Present","info":{"type":"QSFP-DD Double Density 8X Pluggable Transceiver","vendor_name":"Arista Networks","serial":"XYL7654321","model":"QDD-400G-DR4","vendor_rev":"B2"}},"Ethernet80":{"presence":"Not present"}}`
+	transceiverEepromDom := `{"Ethernet0":{"presence":"Present","info":{"type":"QSFP28 or later","vendor_name":"Arista Networks","serial":"XYL1234567","model":"QSFP-100G-CR4","vendor_rev":"A1","cable_type":"Length Cable Assembly(m)","cable_length":"1.0"},"dom":{"temperature":"30.5","voltage":"3.29","rx1power":"-1.5","tx1bias":"6.7","tx1power":"-0.9"},"threshold":{"temphighalarm":"75.0","templowalarm":"-5.0","vcchighalarm":"3.63","vcclowalarm":"2.97"}}}`
+	transceiverPresence := `[{"Port":"Ethernet80","Presence":"Not present"}]`
+	transceiverLpmode := `[{"Port":"Ethernet0","Low-power Mode":"Off"},{"Port":"Ethernet40","Low-power Mode":"On"},{"Port":"Ethernet80","Low-power Mode":"N/A"}]`
+	transceiverStatus := `{"Ethernet0":{"lpmode":"False","module_state":"ModuleReady","module_fault_cause":"No Fault detected","DP1State":"DataPathActivated","rxlos1":"False"}}`
+	transceiverStatusVerbose := `{"Ethernet0":{"lpmode":"False","module_state":"ModuleReady","module_fault_cause":"No Fault detected","DP1State":"DataPathActivated","rxlos1":"False","tx1OutputPowerHAlarm":"False","temphighwarning_flag":"False"}}`
+	transceiverPm := `{"Ethernet0":{"prefec_ber":{"min":"1.5e-07","avg":"2.0e-07","max":"3.1e-07"},"cd":{"min":"-5","avg":"0","max":"5"},"interval":{"value":"60"}}}`
+	transceiverPmVerbose := `{"Ethernet0":{"prefec_ber":{"min":"1.5e-07","avg":"2.0e-07","max":"3.1e-07","high_alarm":"1.25e-02"},"cd":{"min":"-5","avg":"0","max":"5"},"interval":{"value":"60"}}}`
+
+	ResetDataSetsAndMappings(t)
+	AddDataSet(t, ConfigDbNum, portsFileName)
+	AddDataSet(t, StateDbNum, transceiverDataFileName)
+
+	tests := []struct {
+		desc        string
+		pathTarget  string
+		textPbPath  string
+		wantRetCode codes.Code
+		wantRespVal interface{}
+		valTest     bool
+	}{
+		{
+			desc:       "query SHOW interface transceiver eeprom",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "transceiver" >
+				elem: <name: "eeprom" >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(transceiverEeprom),
+			valTest:     true,
+		},
+		{
+			desc:       "query SHOW interface transceiver eeprom dom verbose",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "transceiver" >
+				elem: <name: "eeprom" key: { key: "interface" value: "Ethernet0" } key: { key: "dom" value: "true" } key: { key: "verbose" value: "true" }>
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(transceiverEepromDom),
+			valTest:     true,
+		},
+		{
+			desc:       "query SHOW interface transceiver eeprom unknown interface",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "transceiver" >
+				elem: <name: "eeprom" key: { key: "interface" value: "Ethernet999" }>
+			`,
+			wantRetCode: codes.NotFound,
+		},
+		{
+			desc:       "query SHOW interface transceiver presence",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "transceiver" >
+				elem: <name: "presence" key: { key: "interface" value: "Ethernet80" }>
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(transceiverPresence),
+			valTest:     true,
+		},
+		{
+			desc:       "query SHOW interface transceiver lpmode",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "transceiver" >
+				elem: <name: "lpmode" >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(transceiverLpmode),
+			valTest:     true,
+		},
+		{
+			desc:       "query SHOW interface transceiver status",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "transceiver" >
+				elem: <name: "status" key: { key: "interface" value: "Ethernet0" }>
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(transceiverStatus),
+			valTest:     true,
+		},
+		{
+			desc:       "query SHOW interface transceiver status verbose",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "transceiver" >
+				elem: <name: "status" key: { key: "interface" value: "Ethernet0" } key: { key: "verbose" value: "true" }>
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(transceiverStatusVerbose),
+			valTest:     true,
+		},
+		{
+			desc:       "query SHOW interface transceiver pm",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "transceiver" >
+				elem: <name: "pm" >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(transceiverPm),
+			valTest:     true,
+		},
+		{
+			desc:       "query SHOW interface transceiver pm verbose",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "interface" >
+				elem: <name: "transceiver" >
+				elem: <name: "pm" key: { key: "verbose" value: "true" }>
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(transceiverPmVerbose),
+			valTest:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			runTestGet(t, ctx, gClient, test.pathTarget, test.textPbPath, test.wantRetCode, test.wantRespVal, test.valTest)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package gnmi
+
+// ip_route_cli_test.go
+
+// Tests SHOW ip route, SHOW ip route summary and SHOW ipv6 route
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+)
+
+func TestGetIPRoute(t *testing.T) {
+	s := createServer(t, ServerPort)
+	go runServer(t, s)
+	defer s.ForceStop()
+	defer ResetDataSetsAndMappings(t)
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+
+	conn, err := grpc.Dial(TargetAddr, opts...)
+	if err != nil {
+		t.Fatalf("Dialing to %q failed: %v", TargetAddr, err)
+	}
+	defer conn.Close()
+
+	gClient := pb.NewGNMIClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout*time.Second)
+	defer cancel()
+
+	ipRouteFileName := "../testdata/VTYSH_SHOW_IP_ROUTE_JSON.txt"
+	ipv6RouteFileName := "../testdata/VTYSH_SHOW_IPV6_ROUTE_JSON.txt"
+	ipRouteSummaryFileName := "../testdata/VTYSH_SHOW_IP_ROUTE_SUMMARY_JSON.txt"
+	ipRoute := `{"total_count":3,"offset":0,"count":3,"routes":{"0.0.0.0/0":[{"prefix":"0.0.0.0/0","protocol":"bgp","selected":true,"distance":20,"metric":0,"installed":true,"uptime":"4d03h44m","nexthops":[{"fib":true,"ip":"10.0.0.57","afi":"ipv4","interfaceName":"PortChannel101","active":true}]}],"10.0.0.56/31":[{"prefix":"10.0.0.56/31","protocol":"connected","selected":true,"distance":0,"metric":0,"installed":true,"uptime":"4d03h45m","nexthops":[{"fib":true,"directlyConnected":true,"interfaceName":"PortChannel101","active":true}]}],"192.168.0.0/21":[{"prefix":"192.168.0.0/21","protocol":"connected","selected":true,"distance":0,"metric":0,"installed":true,"uptime":"4d03h45m","nexthops":[{"fib":true,"directlyConnected":true,"interfaceName":"Vlan1000","active":true}]}]}}`
+	ipRouteSecondPage := `{"total_count":3,"offset":1,"count":1,"routes":{"10.0.0.56/31":[{"prefix":"10.0.0.56/31","protocol":"connected","selected":true,"distance":0,"metric":0,"installed":true,"uptime":"4d03h45m","nexthops":[{"fib":true,"directlyConnected":true,"interfaceName":"PortChannel101","active":true}]}]}}`
+	ipv6Route := `{"total_count":2,"offset":0,"count":2,"routes":{"::/0":[{"prefix":"::/0","protocol":"bgp","selected":true,"distance":20,"metric":0,"installed":true,"uptime":"4d03h44m","nexthops":[{"fib":true,"ip":"fc00::72","afi":"ipv6","interfaceName":"PortChannel101","active":true}]}],"fc00::70/126":[{"prefix":"fc00::70/126","protocol":"connected","selected":true,"distance":0,"metric":0,"installed":true,"uptime":"4d03h45m","nexthops":[{"fib":true,"directlyConnected":true,"interfaceName":"PortChannel101","active":true}]}]}}`
+	ipRouteSummary := `{"protocols":{"connected":{"rib":4,"fib":4},"kernel":{"rib":1,"fib":1},"ebgp":{"rib":6400,"fib":6400},"static":{"rib":2,"fib":0}},"total":{"rib":6407,"fib":6405}}`
+
+	ResetDataSetsAndMappings(t)
+
+	tests := []struct {
+		desc           string
+		pathTarget     string
+		textPbPath     string
+		wantRetCode    codes.Code
+		wantRespVal    interface{}
+		valTest        bool
+		mockOutputFile string
+	}{
+		{
+			desc:       "query SHOW ip route read error",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "ip" >
+				elem: <name: "route" >
+			`,
+			wantRetCode: codes.NotFound,
+		},
+		{
+			desc:       "query SHOW ip route invalid vtysh output",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "ip" >
+				elem: <name: "route" >
+			`,
+			wantRetCode:    codes.NotFound,
+			mockOutputFile: "../testdata/INVALID_JSON.txt",
+		},
+		{
+			desc:       "query SHOW ip route",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "ip" >
+				elem: <name: "route" >
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(ipRoute),
+			valTest:        true,
+			mockOutputFile: ipRouteFileName,
+		},
+		{
+			desc:       "query SHOW ip route with pagination",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "ip" >
+				elem: <name: "route" key: { key: "limit" value: "1" } key: { key: "offset" value: "1" }>
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(ipRouteSecondPage),
+			valTest:        true,
+			mockOutputFile: ipRouteFileName,
+		},
+		{
+			desc:       "query SHOW ip route limit above cap",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "ip" >
+				elem: <name: "route" key: { key: "limit" value: "100000" }>
+			`,
+			wantRetCode:    codes.InvalidArgument,
+			mockOutputFile: ipRouteFileName,
+		},
+		{
+			desc:       "query SHOW ip route invalid prefix",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "ip" >
+				elem: <name: "route" key: { key: "prefix" value: "10.0.0.1; reboot" }>
+			`,
+			wantRetCode:    codes.InvalidArgument,
+			mockOutputFile: ipRouteFileName,
+		},
+		{
+			desc:       "query SHOW ip route ipv6 prefix",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "ip" >
+				elem: <name: "route" key: { key: "prefix" value: "fc00::70/126" }>
+			`,
+			wantRetCode:    codes.InvalidArgument,
+			mockOutputFile: ipRouteFileName,
+		},
+		{
+			desc:       "query SHOW ip route invalid vrf",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "ip" >
+				elem: <name: "route" key: { key: "vrf" value: "Vrf01 json; reboot" }>
+			`,
+			wantRetCode:    codes.InvalidArgument,
+			mockOutputFile: ipRouteFileName,
+		},
+		{
+			desc:       "query SHOW ip route vrf and prefix",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "ip" >
+				elem: <name: "route" key: { key: "vrf" value: "Vrf01" } key: { key: "prefix" value: "10.0.0.56/31" }>
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(ipRoute),
+			valTest:        true,
+			mockOutputFile: ipRouteFileName,
+		},
+		{
+			desc:       "query SHOW ip route summary",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "ip" >
+				elem: <name: "route" >
+				elem: <name: "summary" >
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(ipRouteSummary),
+			valTest:        true,
+			mockOutputFile: ipRouteSummaryFileName,
+		},
+		{
+			desc:       "query SHOW ipv6 route",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "ipv6" >
+				elem: <name: "route" >
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(ipv6Route),
+			valTest:        true,
+			mockOutputFile: ipv6RouteFileName,
+		},
+	}
+
+	for _, test := range tests {
+		var patches *gomonkey.Patches
+		if test.mockOutputFile != "" {
+			patches = MockNSEnterBGPSummary(t, test.mockOutputFile)
+		}
+
+		t.Run(test.desc, func(t *testing.T) {
+			runTestGet(t, ctx, gClient, test.pathTarget, test.textPbPath, test.wantRetCode, test.wantRespVal, test.valTest)
+		})
+		if patches != nil {
+			patches.Reset()
+		}
+	}
+}
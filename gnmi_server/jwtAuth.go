@@ -2,9 +2,14 @@ package gnmi
 
 import (
 	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync/atomic"
+
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/golang/glog"
 	"github.com/sonic-net/sonic-gnmi/common_utils"
+	"github.com/sonic-net/sonic-gnmi/pkg/jwtkeys"
 	spb "github.com/sonic-net/sonic-gnmi/proto/gnoi/jwt"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
@@ -14,9 +19,12 @@ import (
 )
 
 var (
-	JwtRefreshInt    time.Duration
-	JwtValidInt      time.Duration
-	hmacSampleSecret = make([]byte, 16)
+	JwtRefreshInt time.Duration
+	JwtValidInt   time.Duration
+	// jwtKeys signs tokens, a process-local HMAC key until SetJwtKeys.
+	jwtKeys atomic.Pointer[jwtkeys.KeyRing]
+	// jwtRevoked lists the tokens revoked before they expire.
+	jwtRevoked atomic.Pointer[jwtkeys.RevocationList]
 )
 
 type Credentials struct {
@@ -30,26 +38,67 @@ type Claims struct {
 	jwt.StandardClaims
 }
 
+// SetJwtKeys makes the server sign tokens with keys and check revocations
+// against revoked, for example both kept on disk so that tokens survive
+// restarts.
+func SetJwtKeys(keys *jwtkeys.KeyRing, revoked *jwtkeys.RevocationList) {
+	jwtKeys.Store(keys)
+	jwtRevoked.Store(revoked)
+}
+
+// currentJwtKeys returns the key ring, creating a process-local HMAC key on
+// first use.
+func currentJwtKeys() *jwtkeys.KeyRing {
+	if keys := jwtKeys.Load(); keys != nil {
+		return keys
+	}
+	keys, err := jwtkeys.NewEphemeral("HS256")
+	if err != nil {
+		glog.Fatalf("Failed to generate JWT key: %v", err)
+	}
+	jwtKeys.CompareAndSwap(nil, keys)
+	return jwtKeys.Load()
+}
+
+// currentJwtRevoked returns the revocation list, held in memory until
+// SetJwtKeys.
+func currentJwtRevoked() *jwtkeys.RevocationList {
+	if revoked := jwtRevoked.Load(); revoked != nil {
+		return revoked
+	}
+	jwtRevoked.CompareAndSwap(nil, jwtkeys.NewRevocationList())
+	return jwtRevoked.Load()
+}
+
 func generateJWT(username string, roles []string, expire_dt time.Time) string {
-	// Create a new token object, specifying signing method and the claims
-	// you would like it to contain.
+	id := make([]byte, 16)
+	rand.Read(id)
 	claims := &Claims{
 		Username: username,
 		Roles:    roles,
 		StandardClaims: jwt.StandardClaims{
-			// In JWT, the expiry time is expressed as unix milliseconds
+			Id:        hex.EncodeToString(id),
+			IssuedAt:  time.Now().Unix(),
 			ExpiresAt: expire_dt.Unix(),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign and get the complete encoded token as a string using the secret
-	tokenString, _ := token.SignedString(hmacSampleSecret)
-
+	tokenString, err := currentJwtKeys().Sign(claims)
+	if err != nil {
+		glog.Errorf("Failed to sign JWT for %s: %v", username, err)
+	}
 	return tokenString
 }
+
+// GenerateJwtSecretKey replaces the signing key with a new process-local HMAC
+// key, invalidating the tokens issued so far. It is meant for servers without
+// SetJwtKeys.
 func GenerateJwtSecretKey() {
-	rand.Read(hmacSampleSecret)
+	keys, err := jwtkeys.NewEphemeral("HS256")
+	if err != nil {
+		glog.Errorf("Failed to generate JWT key: %v", err)
+		return
+	}
+	jwtKeys.Store(keys)
 }
 
 func tokenResp(username string, roles []string) *spb.JwtToken {
@@ -58,6 +107,27 @@ func tokenResp(username string, roles []string) *spb.JwtToken {
 	return &token
 }
 
+// parseJWT validates the signature, expiry and revocation of a token.
+func parseJWT(accessToken string) (*Claims, error) {
+	claims := &Claims{}
+	tkn, err := jwt.ParseWithClaims(accessToken, claims, currentJwtKeys().Keyfunc)
+	if err != nil {
+		return nil, err
+	}
+	if !tkn.Valid {
+		return nil, errors.New("Invalid JWT Token")
+	}
+	if claims.Id != "" && currentJwtRevoked().Revoked(claims.Id) {
+		return nil, errors.New("JWT Token revoked")
+	}
+	return claims, nil
+}
+
+// revokeJWT revokes a token until it expires.
+func revokeJWT(claims *Claims) error {
+	return currentJwtRevoked().Revoke(claims.Id, time.Unix(claims.ExpiresAt, 0))
+}
+
 func JwtAuthenAndAuthor(ctx context.Context) (*spb.JwtToken, context.Context, error) {
 	rc, ctx := common_utils.GetContext(ctx)
 	var token spb.JwtToken
@@ -72,16 +142,10 @@ func JwtAuthenAndAuthor(ctx context.Context) (*spb.JwtToken, context.Context, er
 		return nil, ctx, status.Errorf(codes.Unauthenticated, "No JWT Token Provided")
 	}
 
-	claims := &Claims{}
-	tkn, err := jwt.ParseWithClaims(token.AccessToken, claims, func(token *jwt.Token) (interface{}, error) {
-		return hmacSampleSecret, nil
-	})
+	claims, err := parseJWT(token.AccessToken)
 	if err != nil {
 		return &token, ctx, status.Errorf(codes.Unauthenticated, "%s", err.Error())
 	}
-	if !tkn.Valid {
-		return &token, ctx, status.Errorf(codes.Unauthenticated, "Invalid JWT Token")
-	}
 	if err := PopulateAuthStruct(claims.Username, &rc.Auth, claims.Roles); err != nil {
 		glog.Infof("[%s] Failed to retrieve authentication information; %v", rc.ID, err)
 		return &token, ctx, status.Errorf(codes.Unauthenticated, "")
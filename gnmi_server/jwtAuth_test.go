@@ -1,8 +1,12 @@
 package gnmi
 
 import (
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/sonic-net/sonic-gnmi/common_utils"
+	"github.com/sonic-net/sonic-gnmi/pkg/jwtkeys"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -27,3 +31,71 @@ func TestJwtAuthenAndAuthor_InvalidToken(t *testing.T) {
 		t.Errorf("Expected Unauthenticated, got %v", st.Code())
 	}
 }
+
+func jwtContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("access_token", token))
+}
+
+func TestJwtKeysAndRevocation(t *testing.T) {
+	savedKeys, savedRevoked := jwtKeys.Load(), jwtRevoked.Load()
+	savedValid := JwtValidInt
+	defer func() {
+		jwtKeys.Store(savedKeys)
+		jwtRevoked.Store(savedRevoked)
+		JwtValidInt = savedValid
+	}()
+	JwtValidInt = time.Hour
+
+	dir := t.TempDir()
+	keys, err := jwtkeys.Open(dir, "ES256", JwtValidInt)
+	if err != nil {
+		t.Fatalf("Failed to open keys: %v", err)
+	}
+	revoked, _ := jwtkeys.OpenRevocationList(filepath.Join(dir, "revoked.json"))
+	SetJwtKeys(keys, revoked)
+
+	token := tokenResp("admin", []string{"gnmi_readwrite"}).AccessToken
+	_, ctx, err := JwtAuthenAndAuthor(jwtContext(token))
+	if err != nil {
+		t.Fatalf("Expected token to be valid, got %v", err)
+	}
+	rc, _ := common_utils.GetContext(ctx)
+	if rc.Auth.User != "admin" || len(rc.Auth.Roles) != 1 {
+		t.Errorf("Unexpected auth info %+v", rc.Auth)
+	}
+
+	// Tokens of the previous key stay valid after a rotation
+	if err := keys.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if _, _, err := JwtAuthenAndAuthor(jwtContext(token)); err != nil {
+		t.Errorf("Expected token of the previous key to be valid, got %v", err)
+	}
+
+	// Tokens survive a restart with the same key directory
+	reopened, _ := jwtkeys.Open(dir, "ES256", JwtValidInt)
+	SetJwtKeys(reopened, revoked)
+	if _, _, err := JwtAuthenAndAuthor(jwtContext(token)); err != nil {
+		t.Errorf("Expected token to be valid after reopening the keys, got %v", err)
+	}
+
+	claims, err := parseJWT(token)
+	if err != nil || claims.Id == "" {
+		t.Fatalf("Expected token with an ID, got %+v, %v", claims, err)
+	}
+	if err := revokeJWT(claims); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	_, _, err = JwtAuthenAndAuthor(jwtContext(token))
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected revoked token to be rejected, got %v", err)
+	}
+
+	// Tokens of an unrelated key are rejected
+	GenerateJwtSecretKey()
+	other := tokenResp("admin", []string{"gnmi_readwrite"}).AccessToken
+	SetJwtKeys(reopened, revoked)
+	if _, _, err := JwtAuthenAndAuthor(jwtContext(other)); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected token of another key to be rejected, got %v", err)
+	}
+}
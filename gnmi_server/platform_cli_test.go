@@ -0,0 +1,194 @@
+package gnmi
+
+// platform_cli_test.go
+
+// Tests SHOW platform commands:
+// - SHOW platform summary
+// - SHOW platform psustatus
+// - SHOW platform fan
+// - SHOW platform temperature
+// - SHOW platform syseeprom
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	sdc "github.com/sonic-net/sonic-gnmi/sonic_data_client"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+)
+
+func TestGetShowPlatform(t *testing.T) {
+	s := createServer(t, ServerPort)
+	go runServer(t, s)
+	defer s.ForceStop()
+	defer ResetDataSetsAndMappings(t)
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+
+	conn, err := grpc.Dial(TargetAddr, opts...)
+	if err != nil {
+		t.Fatalf("Dialing to %q failed: %v", TargetAddr, err)
+	}
+	defer conn.Close()
+
+	gClient := pb.NewGNMIClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout*time.Second)
+	defer cancel()
+
+	deviceMetadataFileName := "../testdata/DEVICE_METADATA.txt"
+	psuInfoFileName := "../testdata/PLATFORM_PSU_INFO.txt"
+	fanInfoFileName := "../testdata/PLATFORM_FAN_INFO.txt"
+	temperatureInfoFileName := "../testdata/PLATFORM_TEMPERATURE_INFO.txt"
+	eepromInfoFileName := "../testdata/PLATFORM_EEPROM_INFO.txt"
+	eepromNotReadyFileName := "../testdata/PLATFORM_EEPROM_INFO_NOT_READY.txt"
+
+	platformSummary := `{"platform":"x86_64-arista_7050_qx32s","hwsku":"Arista-7050-QX32S","asic":"broadcom","asic_count":1,"serial":"JPE12345678","model":"DCS-7050QX-32S","revision":"A01"}`
+	platformSummaryEmpty := `{"platform":"N/A","hwsku":"N/A","asic":"broadcom","asic_count":1,"serial":"N/A","model":"N/A","revision":"N/A"}`
+	psuStatus := `[
+		{"index":1,"name":"PSU 1","presence":"true","status":"OK","led_status":"green","model":"PWR-2422-HV-RED","serial":"6A011010142349Q","revision":"A1","voltage":"12.09","current":"10.07","power":"121.50","voltage_status":"OK","temperature":"32.5","temperature_status":"OK"},
+		{"index":2,"name":"PSU 2","presence":"true","status":"NOT OK","led_status":"red","model":"PWR-2422-HV-RED","serial":"6A011010142350Q","revision":"A1","voltage":"10.90","current":"0.00","power":"0.00","voltage_status":"LOW","temperature":"30.0","temperature_status":"OK"},
+		{"index":3,"name":"PSU 3","presence":"false","status":"NOT PRESENT","led_status":"N/A","model":"N/A","serial":"N/A","revision":"N/A","voltage":"N/A","current":"N/A","power":"N/A","voltage_status":"UNKNOWN","temperature":"N/A","temperature_status":"UNKNOWN"}
+	]`
+	fanStatus := `[
+		{"drawer":"drawer1","led_status":"green","name":"fan1","speed":"34%","direction":"intake","presence":"Present","status":"OK","timestamp":"20251015 08:17:39"},
+		{"drawer":"drawer1","led_status":"red","name":"fan2","speed":"0%","direction":"intake","presence":"Present","status":"NOT OK","timestamp":"20251015 08:17:39"},
+		{"drawer":"drawer5","led_status":"N/A","name":"fan10","speed":"N/A","direction":"N/A","presence":"Not Present","status":"N/A","timestamp":"20251015 08:17:39"}
+	]`
+	temperatureStatus := `[
+		{"sensor":"Board Sensor","temperature":"97.5","high_threshold":"95.0","low_threshold":"0.0","critical_high_threshold":"110.0","critical_low_threshold":"-10.0","warning":"True","threshold_status":"HIGH","timestamp":"20251015 08:17:40"},
+		{"sensor":"CPU Core","temperature":"42.0","high_threshold":"95.0","low_threshold":"N/A","critical_high_threshold":"105.0","critical_low_threshold":"N/A","warning":"False","threshold_status":"OK","timestamp":"20251015 08:17:40"},
+		{"sensor":"Inlet Sensor","temperature":"N/A","high_threshold":"N/A","low_threshold":"N/A","critical_high_threshold":"N/A","critical_low_threshold":"N/A","warning":"False","threshold_status":"UNKNOWN","timestamp":"20251015 08:17:40"}
+	]`
+	syseeprom := `{"id_string":"TlvInfo","version":"1","total_length":"527","tlvs":[
+		{"code":"0x21","name":"Product Name","length":"15","value":"DCS-7050QX-32S"},
+		{"code":"0x23","name":"Serial Number","length":"11","value":"JPE12345678"},
+		{"code":"0x27","name":"Label Revision","length":"3","value":"A01"},
+		{"code":"0xfd","name":"Vendor Extension","length":"4","value":"0x00"},
+		{"code":"0xfd","name":"Vendor Extension","length":"4","value":"0x01"}
+	],"checksum_valid":true}`
+
+	ResetDataSetsAndMappings(t)
+	sdc.InvalidateVersionFileStash()
+	MockReadFile(sdc.SonicVersionFilePath, "build_version: '12345678.90'\nasic_type: broadcom", nil)
+	defer sdc.InvalidateVersionFileStash()
+
+	tests := []struct {
+		desc        string
+		pathTarget  string
+		textPbPath  string
+		wantRetCode codes.Code
+		wantRespVal interface{}
+		valTest     bool
+		testInit    func()
+	}{
+		{
+			desc:       "query SHOW platform summary NO data",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "platform" >
+				elem: <name: "summary" >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(platformSummaryEmpty),
+			valTest:     true,
+		},
+		{
+			desc:       "query SHOW platform summary",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "platform" >
+				elem: <name: "summary" >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(platformSummary),
+			valTest:     true,
+			testInit: func() {
+				AddDataSet(t, ConfigDbNum, deviceMetadataFileName)
+				AddDataSet(t, StateDbNum, eepromInfoFileName)
+			},
+		},
+		{
+			desc:       "query SHOW platform psustatus",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "platform" >
+				elem: <name: "psustatus" >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(psuStatus),
+			valTest:     true,
+			testInit: func() {
+				AddDataSet(t, StateDbNum, psuInfoFileName)
+			},
+		},
+		{
+			desc:       "query SHOW platform fan",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "platform" >
+				elem: <name: "fan" >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(fanStatus),
+			valTest:     true,
+			testInit: func() {
+				AddDataSet(t, StateDbNum, fanInfoFileName)
+			},
+		},
+		{
+			desc:       "query SHOW platform temperature",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "platform" >
+				elem: <name: "temperature" >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(temperatureStatus),
+			valTest:     true,
+			testInit: func() {
+				AddDataSet(t, StateDbNum, temperatureInfoFileName)
+			},
+		},
+		{
+			desc:       "query SHOW platform syseeprom",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "platform" >
+				elem: <name: "syseeprom" >
+			`,
+			wantRetCode: codes.OK,
+			wantRespVal: []byte(syseeprom),
+			valTest:     true,
+		},
+		{
+			desc:       "query SHOW platform syseeprom not initialized",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "platform" >
+				elem: <name: "syseeprom" >
+			`,
+			wantRetCode: codes.Unavailable,
+			testInit: func() {
+				FlushDataSet(t, StateDbNum)
+				AddDataSet(t, StateDbNum, eepromNotReadyFileName)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		if test.testInit != nil {
+			test.testInit()
+		}
+
+		t.Run(test.desc, func(t *testing.T) {
+			runTestGet(t, ctx, gClient, test.pathTarget, test.textPbPath, test.wantRetCode, test.wantRespVal, test.valTest)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package gnmi
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/golang/glog"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sonic-net/sonic-gnmi/common_utils"
+	"github.com/sonic-net/sonic-gnmi/pkg/quota"
+	sdcfg "github.com/sonic-net/sonic-gnmi/sonic_db_config"
+)
+
+// QuotaWatcher keeps a quota.Manager in sync with the GNMI_QUOTA table of
+// CONFIG_DB, reloading the whole table whenever one of its keys changes.
+type QuotaWatcher struct {
+	manager   *quota.Manager
+	rc        *redis.Client
+	ps        *redis.PubSub
+	separator string
+	done      chan struct{}
+}
+
+// NewQuotaWatcher loads the quotas from CONFIG_DB and watches them for changes.
+func NewQuotaWatcher() (*QuotaWatcher, error) {
+	ns, _ := sdcfg.GetDbDefaultNamespace()
+	addr, err := sdcfg.GetDbTcpAddr("CONFIG_DB", ns)
+	if err != nil {
+		return nil, err
+	}
+	dbId, err := sdcfg.GetDbId("CONFIG_DB", ns)
+	if err != nil {
+		return nil, err
+	}
+	separator, err := sdcfg.GetDbSeparator("CONFIG_DB", ns)
+	if err != nil {
+		return nil, err
+	}
+
+	qw := &QuotaWatcher{
+		manager:   quota.NewManager(),
+		separator: separator,
+		done:      make(chan struct{}),
+	}
+	qw.rc = redis.NewClient(&redis.Options{
+		Network:     "tcp",
+		Addr:        addr,
+		Password:    "",
+		DB:          dbId,
+		DialTimeout: 0,
+	})
+
+	// Subscribe before the initial load so no change is missed in between
+	pattern := fmt.Sprintf("__keyspace@%d__:%s%s*", dbId, quota.Table, separator)
+	qw.ps = qw.rc.PSubscribe(context.Background(), pattern)
+	if _, err = qw.ps.Receive(context.Background()); err != nil {
+		qw.cleanup()
+		return nil, err
+	}
+	if err = qw.reload(); err != nil {
+		qw.cleanup()
+		return nil, err
+	}
+
+	go qw.watch(qw.ps.Channel())
+	log.V(2).Infof("Watching %s for quota changes", quota.Table)
+	return qw, nil
+}
+
+// Manager returns the manager enforcing the current quotas.
+func (qw *QuotaWatcher) Manager() *quota.Manager {
+	if qw == nil {
+		return nil
+	}
+	return qw.manager
+}
+
+// Close stops watching CONFIG_DB. The manager keeps the last loaded quotas.
+func (qw *QuotaWatcher) Close() {
+	if qw == nil {
+		return
+	}
+	close(qw.done)
+}
+
+func (qw *QuotaWatcher) cleanup() {
+	if qw.ps != nil {
+		qw.ps.Close()
+	}
+	if qw.rc != nil {
+		qw.rc.Close()
+	}
+}
+
+func (qw *QuotaWatcher) watch(notifications <-chan *redis.Message) {
+	defer qw.cleanup()
+	for {
+		select {
+		case <-qw.done:
+			return
+		case _, ok := <-notifications:
+			if !ok {
+				return
+			}
+			if err := qw.reload(); err != nil {
+				log.Errorf("Failed to reload %s: %v", quota.Table, err)
+			}
+		}
+	}
+}
+
+// reload reads the whole GNMI_QUOTA table and replaces the quotas.
+func (qw *QuotaWatcher) reload() error {
+	ctx := context.Background()
+	prefix := quota.Table + qw.separator
+	keys, err := qw.rc.Keys(ctx, prefix+"*").Result()
+	if err != nil {
+		return err
+	}
+	entries := make(map[string]map[string]string, len(keys))
+	for _, key := range keys {
+		fields, err := qw.rc.HGetAll(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		entries[key[len(prefix):]] = fields
+	}
+
+	config, errs := quota.ParseConfig(entries, qw.separator)
+	for _, err := range errs {
+		log.Errorf("Ignoring quota entry %v", err)
+	}
+	qw.manager.SetConfig(config)
+	log.V(2).Infof("Loaded %d quota entries, default %v", len(entries)-len(errs), config.Default)
+	return nil
+}
+
+// authIdentity returns the authenticated user and roles of a request.
+func authIdentity(ctx context.Context) (string, []string) {
+	rc, _ := common_utils.GetContext(ctx)
+	return rc.Auth.User, rc.Auth.Roles
+}
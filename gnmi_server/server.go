@@ -15,16 +15,22 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/Azure/sonic-mgmt-common/translib"
 	gnsi_pathz_pb "github.com/openconfig/gnsi/pathz"
 	"github.com/sonic-net/sonic-gnmi/common_utils"
+	"github.com/sonic-net/sonic-gnmi/pkg/audit"
 	"github.com/sonic-net/sonic-gnmi/pkg/bypass"
 	operationalhandler "github.com/sonic-net/sonic-gnmi/pkg/server/operational-handler"
+	"github.com/sonic-net/sonic-gnmi/pkg/session"
+	"github.com/sonic-net/sonic-gnmi/pkg/tunnel"
 	spb "github.com/sonic-net/sonic-gnmi/proto"
 	spb_gnoi "github.com/sonic-net/sonic-gnmi/proto/gnoi"
+	spb_admin_gnoi "github.com/sonic-net/sonic-gnmi/proto/gnoi/admin"
+	spb_journal_gnoi "github.com/sonic-net/sonic-gnmi/proto/gnoi/journal"
 	spb_jwt_gnoi "github.com/sonic-net/sonic-gnmi/proto/gnoi/jwt"
 	_ "github.com/sonic-net/sonic-gnmi/show_client"
 	sdc "github.com/sonic-net/sonic-gnmi/sonic_data_client"
@@ -37,6 +43,7 @@ import (
 	gnoi_containerz_pb "github.com/openconfig/gnoi/containerz"
 	"github.com/openconfig/gnoi/factory_reset"
 	gnoi_system_pb "github.com/openconfig/gnoi/system"
+	"github.com/openconfig/ygot/ygot"
 	"google.golang.org/grpc/credentials"
 
 	gnoi_file_pb "github.com/openconfig/gnoi/file"
@@ -106,6 +113,16 @@ type Server struct {
 	ConnectionManager *ConnectionManager
 	// DB Journals
 	configDbJournal *DbJournal
+	// quotaWatcher loads per-user quotas from CONFIG_DB, nil if disabled.
+	quotaWatcher *QuotaWatcher
+	// liveConfig is the configuration after the last reload, nil until then.
+	liveConfig atomic.Pointer[Config]
+	reloadMu   sync.Mutex
+	// configWatcher reloads the configuration from CONFIG_DB, nil if disabled.
+	configWatcher *ConfigWatcher
+	// tunnelListeners accept the connections of collectors over tunnels, served
+	// by the TCP server.
+	tunnelListeners []net.Listener
 }
 
 // handleOperationalGet handles OPERATIONAL target requests directly with standard gNMI types
@@ -116,11 +133,15 @@ func (s *Server) handleOperationalGet(ctx context.Context, req *gnmipb.GetReques
 	// what we need. This allows reusing gnoi_readonly/gnoi_readwrite roles
 	// for operational data access control.
 	authTarget := "gnoi"
-	ctx, err := authenticate(s.config, ctx, authTarget, false)
+	ctx, err := authenticate(s.Config(), ctx, authTarget, false)
 	if err != nil {
 		common_utils.IncCounter(common_utils.GNMI_GET_FAIL)
 		return nil, err
 	}
+	if err := s.quotaWatcher.Manager().AllowGet(authIdentity(ctx)); err != nil {
+		common_utils.IncCounter(common_utils.GNMI_GET_FAIL)
+		return nil, err
+	}
 
 	// Create operational handler
 	operationalHandler, err := operationalhandler.NewOperationalHandler(paths, prefix)
@@ -244,12 +265,43 @@ type Config struct {
 	PathzPolicyFile          string // Path to gNMI pathz policy file.
 	PathzMetaFile            string // Path to JSON file with pathz metadata.
 	EnableStreamMultiplexing bool   // Allow multiple Subscribe RPCs on a single TCP connection.
+	// Authenticator checks the passwords of the password and jwt modes of
+	// client_auth, PAM when nil.
+	Authenticator Authenticator
+	// EnableConfigReload applies the GNMI|gnmi entry of CONFIG_DB on top of
+	// this configuration at start, on changes and on Reload.
+	EnableConfigReload bool
+	// DebugReadWhitelist and DebugWriteWhitelist replace the gNOI Debug
+	// whitelists read at start when not nil. Set by reloads.
+	DebugReadWhitelist  []string
+	DebugWriteWhitelist []string
 	// BindAddress is the network address to bind the TCP listener.
 	// When empty, binds to all interfaces (0.0.0.0). Use "127.0.0.1" to
 	// restrict to localhost only (e.g. when running without TLS).
 	BindAddress string
+	// TunnelAddresses are the tunnel servers of collectors to which the server
+	// opens gRPC tunnels, serving the services of the TCP listener over them.
+	TunnelAddresses []string
+	// TunnelTarget is the target registered over the tunnels, the hostname
+	// when empty.
+	TunnelTarget string
+	// TunnelDialOptions are the options dialing the tunnel servers.
+	TunnelDialOptions []grpc.DialOption
+	// EnableQuota enforces the per-user and per-role quotas of the GNMI_QUOTA
+	// table of CONFIG_DB.
+	EnableQuota bool
+	// ConfigDbJournalMaxSize is the size in bytes past which the CONFIG_DB
+	// journal is rotated, 0 never rotating it.
+	ConfigDbJournalMaxSize int64
+	// ConfigDbJournalMaxBackups is the number of compressed backups of the
+	// CONFIG_DB journal kept.
+	ConfigDbJournalMaxBackups int
 }
 
+// tunnelRetryInterval is how long the server waits before opening a tunnel
+// again after it failed.
+const tunnelRetryInterval = 10 * time.Second
+
 // DBusOSBackend is a concrete implementation of OSBackend
 type DBusOSBackend struct{}
 
@@ -338,14 +390,17 @@ func (i AuthTypes) Unset(mode string) error {
 // registerAllServices registers all gNMI and gNOI services on the given gRPC server.
 func registerAllServices(s *grpc.Server, srv *Server, fileSrv *FileServer,
 	osSrv *OSServer, containerzSrv *ContainerzServer,
-	debugSrv *DebugServer, healthzSrv *HealthzServer, certzSrv *GNSICertzServer, authzSrv *GNSIAuthzServer, pathzSrv *GNSIPathzServer) {
+	debugSrv *DebugServer, healthzSrv *HealthzServer, certzSrv *GNSICertzServer, authzSrv *GNSIAuthzServer, pathzSrv *GNSIPathzServer,
+	adminSrv *AdminServer, journalSrv *JournalServer) {
 	gnmipb.RegisterGNMIServer(s, srv)
 	factory_reset.RegisterFactoryResetServer(s, srv)
 	gnsi_certz_pb.RegisterCertzServer(s, certzSrv)
 	gnsi_authz_pb.RegisterAuthzServer(s, authzSrv)
 	gnsi_pathz_pb.RegisterPathzServer(s, pathzSrv)
 	spb_jwt_gnoi.RegisterSonicJwtServiceServer(s, srv)
-	if srv.config.EnableTranslibWrite || srv.config.EnableNativeWrite {
+	spb_admin_gnoi.RegisterSonicAdminServiceServer(s, adminSrv)
+	spb_journal_gnoi.RegisterSonicJournalServiceServer(s, journalSrv)
+	if srv.config.EnableConfigReload || srv.config.EnableTranslibWrite || srv.config.EnableNativeWrite {
 		gnoi_system_pb.RegisterSystemServer(s, srv)
 		gnoi_file_pb.RegisterFileServer(s, fileSrv)
 		gnoi_os_pb.RegisterOSServer(s, osSrv)
@@ -353,7 +408,7 @@ func registerAllServices(s *grpc.Server, srv *Server, fileSrv *FileServer,
 		gnoi_debug_pb.RegisterDebugServer(s, debugSrv)
 		gnoi_healthz_pb.RegisterHealthzServer(s, healthzSrv)
 	}
-	if srv.config.EnableTranslibWrite {
+	if srv.config.EnableConfigReload || srv.config.EnableTranslibWrite {
 		spb_gnoi.RegisterSonicServiceServer(s, srv)
 	}
 	spb_gnoi.RegisterDebugServer(s, srv)
@@ -560,6 +615,13 @@ func NewServer(config *Config, tlsOpts []grpc.ServerOption, commonOpts []grpc.Se
 		authzWatcher:  authzWatcher,
 	}
 
+	if config.EnableConfigReload {
+		// Reloads may change the write settings, see checkWriteService
+		commonOpts = append(commonOpts,
+			grpc.ChainUnaryInterceptor(srv.writeServiceUnaryInterceptor),
+			grpc.ChainStreamInterceptor(srv.writeServiceStreamInterceptor))
+	}
+
 	// Create service servers (shared between TCP and UDS)
 	fileSrv := &FileServer{Server: srv}
 	osBackend := &DBusOSBackend{}
@@ -582,6 +644,8 @@ func NewServer(config *Config, tlsOpts []grpc.ServerOption, commonOpts []grpc.Se
 	}
 	certzSrv := NewGNSICertzServer(srv)
 	srv.gnsiCertz = certzSrv
+	adminSrv := NewAdminServer(srv)
+	journalSrv := NewJournalServer(srv)
 
 	var err error
 
@@ -603,7 +667,25 @@ func NewServer(config *Config, tlsOpts []grpc.ServerOption, commonOpts []grpc.Se
 			srv.s.Stop()
 			srv.s = nil
 		} else {
-			registerAllServices(srv.s, srv, fileSrv, osSrv, containerzSrv, debugSrv, healthzSrv, certzSrv, authzSrv, pathzSrv)
+			registerAllServices(srv.s, srv, fileSrv, osSrv, containerzSrv, debugSrv, healthzSrv, certzSrv, authzSrv, pathzSrv, adminSrv, journalSrv)
+		}
+	}
+
+	// Tunnels, served like the TCP listener
+	if len(config.TunnelAddresses) > 0 {
+		if srv.s == nil {
+			log.Warningf("Tunnels to %v require the TCP listener; disabling tunnels", config.TunnelAddresses)
+		} else {
+			target := config.TunnelTarget
+			if target == "" {
+				if target, err = os.Hostname(); err != nil {
+					return nil, fmt.Errorf("failed to get the tunnel target: %v", err)
+				}
+			}
+			targets := []tunnel.Target{{ID: target, Type: tunnel.TargetType}}
+			for _, addr := range config.TunnelAddresses {
+				srv.tunnelListeners = append(srv.tunnelListeners, tunnel.Listen(addr, targets, tunnelRetryInterval, config.TunnelDialOptions...))
+			}
 		}
 	}
 
@@ -637,7 +719,7 @@ func NewServer(config *Config, tlsOpts []grpc.ServerOption, commonOpts []grpc.Se
 					srv.udsServer.Stop()
 					srv.udsServer = nil
 				} else {
-					registerAllServices(srv.udsServer, srv, fileSrv, osSrv, containerzSrv, debugSrv, healthzSrv, certzSrv, authzSrv, pathzSrv)
+					registerAllServices(srv.udsServer, srv, fileSrv, osSrv, containerzSrv, debugSrv, healthzSrv, certzSrv, authzSrv, pathzSrv, adminSrv, journalSrv)
 				}
 			}
 		}
@@ -649,11 +731,23 @@ func NewServer(config *Config, tlsOpts []grpc.ServerOption, commonOpts []grpc.Se
 	}
 
 	if *enableConfigDbJournal {
-		srv.configDbJournal, err = NewDbJournal("CONFIG_DB")
+		srv.configDbJournal, err = NewDbJournalWithRetention("CONFIG_DB", config.ConfigDbJournalMaxSize, config.ConfigDbJournalMaxBackups)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create CONFIG_DB Journal: %v", err)
 		}
 	}
+	if config.EnableQuota {
+		srv.quotaWatcher, err = NewQuotaWatcher()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load quotas: %v", err)
+		}
+	}
+	if config.EnableConfigReload {
+		srv.configWatcher, err = NewConfigWatcher(srv)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration from CONFIG_DB: %v", err)
+		}
+	}
 	log.V(1).Infof("Created Server on %s, read-only: %t", srv.Address(), !srv.config.EnableTranslibWrite)
 	return srv, nil
 }
@@ -686,6 +780,21 @@ func (srv *Server) Serve() error {
 		}()
 	}
 
+	// Serve the tunnels with the TCP server
+	for _, lis := range srv.tunnelListeners {
+		wg.Add(1)
+		go func(lis net.Listener) {
+			defer wg.Done()
+			log.V(1).Infof("Starting tunnel server to %s", lis.Addr().String())
+			if err := srv.s.Serve(lis); err != nil {
+				log.Errorf("Tunnel server error: %v", err)
+				mu.Lock()
+				errs = append(errs, fmt.Sprintf("Tunnel server: %v", err))
+				mu.Unlock()
+			}
+		}(lis)
+	}
+
 	// Start UDS server if configured
 	if srv.udsServer != nil && srv.udsListener != nil {
 		wg.Add(1)
@@ -714,6 +823,7 @@ func (srv *Server) ForceStop() {
 	if srv.s != nil {
 		srv.s.Stop()
 	}
+	srv.closeTunnels()
 	if srv.udsServer != nil {
 		srv.udsServer.Stop()
 	}
@@ -728,15 +838,61 @@ func (srv *Server) Stop() {
 	if srv.s != nil {
 		srv.s.GracefulStop()
 	}
+	srv.closeTunnels()
 	if srv.udsServer != nil {
 		srv.udsServer.GracefulStop()
 	}
+	srv.quotaWatcher.Close()
+	srv.configWatcher.Close()
 	// Cleanup UDS socket file
 	if srv.config != nil && srv.config.UnixSocket != "" {
 		os.Remove(srv.config.UnixSocket)
 	}
 }
 
+// closeTunnels closes the tunnels, which the TCP server only closes once
+// serving them.
+func (srv *Server) closeTunnels() {
+	for _, lis := range srv.tunnelListeners {
+		lis.Close()
+	}
+}
+
+// subscribeMethod is the full gRPC method name of gNMI Subscribe.
+const subscribeMethod = "/gnmi.gNMI/Subscribe"
+
+// Drain stops the server within timeout. New connections and RPCs are
+// refused at once. Subscribe streams never end on their own, so they are
+// ended right away with Unavailable and a retry-after trailer telling their
+// collectors to reconnect after retryAfter. Other RPCs get until timeout to
+// complete, then the remaining connections are closed. A drain is final, the
+// session registry keeps refusing new streams afterwards.
+func (srv *Server) Drain(timeout, retryAfter time.Duration) {
+	log.Infof("Draining server, timeout %v", timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.Stop()
+		close(stopped)
+	}()
+
+	session.Default.SetRetryAfter(retryAfter)
+	completed, killed := session.Default.DrainNotify(ctx, func(info session.Info) bool {
+		return info.Method == subscribeMethod
+	})
+	log.Infof("Server drained: %d sessions completed, %d ended", completed, killed)
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Warningf("RPCs still running after %v, closing connections", timeout)
+		srv.ForceStop()
+		<-stopped
+	}
+}
+
 // Address returns the addresses the Server is listening on.
 func (srv *Server) Address() string {
 	var addrs []string
@@ -757,7 +913,7 @@ func (srv *Server) Port() int64 {
 
 // Auth - Authenticate
 func (srv *Server) Auth(ctx context.Context) (context.Context, error) {
-	return authenticate(srv.config, ctx, "gnmi", false)
+	return authenticate(srv.Config(), ctx, "gnmi", false)
 }
 
 func authenticate(config *Config, ctx context.Context, target string, writeAccess bool) (context.Context, error) {
@@ -781,56 +937,33 @@ func authenticate(config *Config, ctx context.Context, target string, writeAcces
 	}
 
 	rc.Auth.AuthEnabled = true
+	// Roles of password and JWT users are checked when the authenticator
+	// issues scoped roles, those of certificates with a config table
+	checkRoles := false
 	if config.UserAuth.Enabled("password") {
-		ctx, err = BasicAuthenAndAuthor(ctx)
+		ctx, err = BasicAuthenAndAuthor(ctx, config.authenticator())
 		if err == nil {
 			success = true
+			checkRoles = scopedRoles(config.authenticator())
 		}
 	}
 	if !success && config.UserAuth.Enabled("jwt") {
 		_, ctx, err = JwtAuthenAndAuthor(ctx)
 		if err == nil {
 			success = true
+			checkRoles = scopedRoles(config.authenticator())
 		}
 	}
 	if !success && config.UserAuth.Enabled("cert") {
 		ctx, err = ClientCertAuthenAndAuthor(ctx, config.ConfigTableName, config.EnableCrl)
 		if err == nil {
 			success = true
+			checkRoles = config.ConfigTableName != ""
 		}
-		// role must be readwrite to support write access
-		if success && config.ConfigTableName != "" {
-			match := false
-			target = strings.ToLower(target)
-			for _, role := range rc.Auth.Roles {
-				role = strings.TrimSpace(role)
-				if strings.HasPrefix(role, target) {
-					// Extract the postfix from the role
-					// e.g. role=gnmi_config_db_readwrite
-					// e.g. role=gnoi_readonly
-					postfix := strings.TrimPrefix(role, target)
-					postfix = strings.TrimPrefix(postfix, "_")
-					// Check if the role postfix indicates no access, and deny access if true.
-					if postfix == NoAccessMode {
-						return ctx, fmt.Errorf("%s does not have access, target %s, role %s", rc.Auth.User, target, role)
-					} else if postfix == ReadOnlyMode {
-						// ReadOnlyMode is allowed for read access
-						if writeAccess {
-							return ctx, fmt.Errorf("%s does not have access, target %s, role %s", rc.Auth.User, target, role)
-						} else {
-							match = true
-							break
-						}
-					} else if postfix == WriteAccessMode {
-						// WriteAccessMode is allowed for read/write access
-						match = true
-						break
-					}
-				}
-			}
-			if !match && writeAccess {
-				return ctx, fmt.Errorf("%s does not have write access, target %s", rc.Auth.User, target)
-			}
+	}
+	if success && checkRoles {
+		if err := authorizeRoles(&rc.Auth, target, writeAccess); err != nil {
+			return ctx, err
 		}
 	}
 
@@ -840,10 +973,46 @@ func authenticate(config *Config, ctx context.Context, target string, writeAcces
 		return ctx, status.Error(codes.Unauthenticated, "Unauthenticated")
 	}
 	log.V(5).Infof("authenticate user %v, roles %v", rc.Auth.User, rc.Auth.Roles)
+	session.FromContext(ctx).SetUser(rc.Auth.User, rc.Auth.Roles)
+	audit.FromContext(ctx).SetUser(rc.Auth.User, rc.Auth.Roles)
 
 	return ctx, nil
 }
 
+// authorizeRoles checks that the <target>_<mode> roles of auth allow access
+// to target, e.g. gnmi_config_db_readwrite or gnoi_readonly. Write access
+// requires a readwrite role, a noaccess role denies any access.
+func authorizeRoles(auth *common_utils.AuthInfo, target string, writeAccess bool) error {
+	target = strings.ToLower(target)
+	for _, role := range auth.Roles {
+		role = strings.TrimSpace(role)
+		if strings.HasPrefix(role, target) {
+			// Extract the postfix from the role
+			// e.g. role=gnmi_config_db_readwrite
+			// e.g. role=gnoi_readonly
+			postfix := strings.TrimPrefix(role, target)
+			postfix = strings.TrimPrefix(postfix, "_")
+			// Check if the role postfix indicates no access, and deny access if true.
+			if postfix == NoAccessMode {
+				return fmt.Errorf("%s does not have access, target %s, role %s", auth.User, target, role)
+			} else if postfix == ReadOnlyMode {
+				// ReadOnlyMode is allowed for read access
+				if writeAccess {
+					return fmt.Errorf("%s does not have access, target %s, role %s", auth.User, target, role)
+				}
+				return nil
+			} else if postfix == WriteAccessMode {
+				// WriteAccessMode is allowed for read/write access
+				return nil
+			}
+		}
+	}
+	if writeAccess {
+		return fmt.Errorf("%s does not have write access, target %s", auth.User, target)
+	}
+	return nil
+}
+
 // Subscribe implements the gNMI Subscribe RPC.
 func (s *Server) Subscribe(stream gnmipb.GNMI_SubscribeServer) error {
 	ctx := stream.Context()
@@ -864,11 +1033,14 @@ func (s *Server) Subscribe(stream gnmipb.GNMI_SubscribeServer) error {
 	}
 	*/
 
+	// The stream keeps the configuration it started with across reloads
+	config := s.Config()
 	c := NewClient(pr.Addr)
-	c.enableStreamMultiplexing = s.config.EnableStreamMultiplexing
+	c.enableStreamMultiplexing = config.EnableStreamMultiplexing
 
-	c.setLogLevel(s.config.LogLevel)
-	c.setConnectionManager(s.config.Threshold)
+	c.setLogLevel(config.LogLevel)
+	c.setConnectionManager(config.Threshold)
+	c.quotas = s.quotaWatcher.Manager()
 
 	clientKey := c.Key()
 
@@ -883,7 +1055,7 @@ func (s *Server) Subscribe(stream gnmipb.GNMI_SubscribeServer) error {
 	log.V(1).Infof("Client %s registered (total active: %d)", c.String(), len(s.clients))
 	s.cMu.Unlock()
 
-	err := c.Run(stream, s.config)
+	err := c.Run(stream, config)
 	s.cMu.Lock()
 	log.V(1).Infof("Client %s completed, removing (total active: %d)", c.String(), len(s.clients)-1)
 	delete(s.clients, clientKey)
@@ -927,7 +1099,7 @@ func ParseOrigin(paths []*gnmipb.Path) (string, error) {
 }
 
 func IsNativeOrigin(origin string) bool {
-	return origin == "sonic-db"
+	return sdc.IsNativeOrigin(origin)
 }
 
 // Get implements the Get RPC in gNMI spec.
@@ -1022,11 +1194,18 @@ func (s *Server) Get(ctx context.Context, req *gnmipb.GetRequest) (*gnmipb.GetRe
 	}
 	defer dc.Close()
 
-	ctx, err = authenticate(s.config, ctx, authTarget, false)
+	ctx, err = authenticate(s.Config(), ctx, authTarget, false)
 	if err != nil {
 		common_utils.IncCounter(common_utils.GNMI_GET_FAIL)
 		return nil, err
 	}
+	if err = s.quotaWatcher.Manager().AllowGet(authIdentity(ctx)); err != nil {
+		common_utils.IncCounter(common_utils.GNMI_GET_FAIL)
+		return nil, err
+	}
+	if mc, ok := dc.(*sdc.MixedDbClient); ok {
+		mc.SetContext(ctx)
+	}
 	spbValues, err := dc.Get(nil)
 	if err != nil {
 		common_utils.IncCounter(common_utils.GNMI_GET_FAIL)
@@ -1071,6 +1250,31 @@ func SaveOnSetEnabled() error {
 // SaveOnSetDisabeld does nothing.
 func saveOnSetDisabled() error { return nil }
 
+// auditSetPaths adds the paths of a SetRequest, joined with its prefix, to
+// the audit record of the RPC.
+func auditSetPaths(rec *audit.Record, req *gnmipb.SetRequest) {
+	if rec == nil {
+		return
+	}
+	toString := func(path *gnmipb.Path) string {
+		full := &gnmipb.Path{Elem: append(append([]*gnmipb.PathElem{}, req.GetPrefix().GetElem()...), path.GetElem()...)}
+		str, err := ygot.PathToString(full)
+		if err != nil {
+			return path.String()
+		}
+		return str
+	}
+	for _, path := range req.GetDelete() {
+		rec.AddPaths("delete", toString(path))
+	}
+	for _, update := range req.GetReplace() {
+		rec.AddPaths("replace", toString(update.GetPath()))
+	}
+	for _, update := range req.GetUpdate() {
+		rec.AddPaths("update", toString(update.GetPath()))
+	}
+}
+
 func (s *Server) Set(ctx context.Context, req *gnmipb.SetRequest) (*gnmipb.SetResponse, error) {
 	e := s.ReqFromMaster(req, &s.masterEID)
 	if e != nil {
@@ -1078,7 +1282,8 @@ func (s *Server) Set(ctx context.Context, req *gnmipb.SetRequest) (*gnmipb.SetRe
 	}
 
 	common_utils.IncCounter(common_utils.GNMI_SET)
-	if s.config.EnableTranslibWrite == false && s.config.EnableNativeWrite == false {
+	config := s.Config()
+	if config.EnableTranslibWrite == false && config.EnableNativeWrite == false {
 		common_utils.IncCounter(common_utils.GNMI_SET_FAIL)
 		return nil, grpc.Errorf(codes.Unimplemented, "GNMI is in read-only mode")
 	}
@@ -1123,6 +1328,7 @@ func (s *Server) Set(ctx context.Context, req *gnmipb.SetRequest) (*gnmipb.SetRe
 	for _, path := range req.GetUpdate() {
 		paths = append(paths, path.GetPath())
 	}
+	auditSetPaths(audit.FromContext(ctx), req)
 	if origin == "" {
 		origin, err = ParseOrigin(paths)
 		if err != nil {
@@ -1130,8 +1336,10 @@ func (s *Server) Set(ctx context.Context, req *gnmipb.SetRequest) (*gnmipb.SetRe
 		}
 	}
 	authTarget := "gnmi"
+	// The CONFIG_DB keys the request may change, nil when unknown
+	var journalKeys []string
 	if check := IsNativeOrigin(origin); check {
-		if s.config.EnableNativeWrite == false {
+		if config.EnableNativeWrite == false {
 			common_utils.IncCounter(common_utils.GNMI_SET_FAIL)
 			return nil, grpc.Errorf(codes.Unimplemented, "GNMI native write is disabled")
 		}
@@ -1144,14 +1352,19 @@ func (s *Server) Set(ctx context.Context, req *gnmipb.SetRequest) (*gnmipb.SetRe
 				return nil, status.Error(codes.Internal, err.Error())
 			}
 			common_utils.IncCounter(common_utils.GNMI_SET_BYPASS)
+			audit.FromContext(ctx).SetBypass()
 			return resp, nil
 		}
 
 		var targetDbName string
 		dc, err = sdc.NewMixedDbClient(paths, prefix, origin, encoding, s.config.ZmqPort, s.config.Vrf, &targetDbName)
 		authTarget = "gnmi_" + targetDbName
+		journalKeys = []string{}
+		if targetDbName == "CONFIG_DB" {
+			journalKeys = configDbKeys(prefix, paths)
+		}
 	} else {
-		if s.config.EnableTranslibWrite == false {
+		if config.EnableTranslibWrite == false {
 			common_utils.IncCounter(common_utils.GNMI_SET_FAIL)
 			return nil, grpc.Errorf(codes.Unimplemented, "Translib write is disabled")
 		}
@@ -1165,11 +1378,16 @@ func (s *Server) Set(ctx context.Context, req *gnmipb.SetRequest) (*gnmipb.SetRe
 	}
 	defer dc.Close()
 
-	ctx, err = authenticate(s.config, ctx, authTarget, true)
+	ctx, err = authenticate(config, ctx, authTarget, true)
 	if err != nil {
 		common_utils.IncCounter(common_utils.GNMI_SET_FAIL)
 		return nil, err
 	}
+	if err = s.quotaWatcher.Manager().AllowSet(authIdentity(ctx)); err != nil {
+		common_utils.IncCounter(common_utils.GNMI_SET_FAIL)
+		return nil, err
+	}
+	defer attributeJournal(ctx, journalKeys)()
 	/* DELETE */
 	for _, path := range req.GetDelete() {
 		log.V(2).Infof("Delete path: %v", path)
@@ -1206,6 +1424,9 @@ func (s *Server) Set(ctx context.Context, req *gnmipb.SetRequest) (*gnmipb.SetRe
 		/* Add to Set response results. */
 		results = append(results, &res)
 	}
+	if mc, ok := dc.(*sdc.MixedDbClient); ok {
+		mc.SetContext(ctx)
+	}
 	err = dc.Set(req.GetDelete(), req.GetReplace(), req.GetUpdate())
 	if err != nil {
 		common_utils.IncCounter(common_utils.GNMI_SET_FAIL)
@@ -1221,7 +1442,7 @@ func (s *Server) Set(ctx context.Context, req *gnmipb.SetRequest) (*gnmipb.SetRe
 }
 
 func (s *Server) Capabilities(ctx context.Context, req *gnmipb.CapabilityRequest) (*gnmipb.CapabilityResponse, error) {
-	ctx, err := authenticate(s.config, ctx, "gnmi", false)
+	ctx, err := authenticate(s.Config(), ctx, "gnmi", false)
 	if err != nil {
 		return nil, err
 	}
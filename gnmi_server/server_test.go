@@ -29,6 +29,7 @@ import (
 
 	"github.com/Azure/sonic-mgmt-common/translib/db"
 	"github.com/sonic-net/sonic-gnmi/common_utils"
+	"github.com/sonic-net/sonic-gnmi/pkg/configjournal"
 	spb "github.com/sonic-net/sonic-gnmi/proto"
 	sgpb "github.com/sonic-net/sonic-gnmi/proto/gnoi"
 	spb_jwt "github.com/sonic-net/sonic-gnmi/proto/gnoi/jwt"
@@ -6672,35 +6673,51 @@ func TestConfigDbJournal(t *testing.T) {
 			cmd: func() {
 				rclient.HSet(context.Background(), "DB_JOURNAL|Test", "new", "test")
 			},
-			expectedEntry: "hset DB_JOURNAL|Test +new:test",
+			expectedEntry: `"op":"hset","key":"DB_JOURNAL|Test","after":{"new":"test"}`,
 		},
 		{
 			desc: "HSetExisting",
 			cmd: func() {
 				rclient.HSet(context.Background(), "DB_JOURNAL|Test", "new", "already exists")
 			},
-			expectedEntry: "hset DB_JOURNAL|Test new=already exists",
+			expectedEntry: `"op":"hset","key":"DB_JOURNAL|Test","before":{"new":"test"},"after":{"new":"already exists"}`,
 		},
 		{
 			desc: "HDel",
 			cmd: func() {
 				rclient.HDel(context.Background(), "DB_JOURNAL|Test", "new")
 			},
-			expectedEntry: "hdel DB_JOURNAL|Test -new",
+			expectedEntry: `"op":"hdel","key":"DB_JOURNAL|Test","before":{"new":"already exists"}`,
 		},
 		{
 			desc: "Set",
 			cmd: func() {
 				rclient.Set(context.Background(), "NEW_DBJOURNAL_TABLE", "TEST", 0)
 			},
-			expectedEntry: "set NEW_DBJOURNAL_TABLE",
+			expectedEntry: `"op":"set","key":"NEW_DBJOURNAL_TABLE"`,
 		},
 		{
 			desc: "Del",
 			cmd: func() {
 				rclient.Del(context.Background(), "NEW_DBJOURNAL_TABLE")
 			},
-			expectedEntry: "del NEW_DBJOURNAL_TABLE",
+			expectedEntry: `"op":"del","key":"NEW_DBJOURNAL_TABLE"`,
+		},
+		{
+			desc: "Attributed",
+			cmd: func() {
+				done := configjournal.Default().Attribute(configjournal.Author{User: "admin", RequestID: "req-1"}, []string{"DB_JOURNAL|*"})
+				defer done()
+				rclient.HSet(context.Background(), "DB_JOURNAL|Test", "author", "admin")
+			},
+			expectedEntry: `"after":{"author":"admin"},"user":"admin","request_id":"req-1"`,
+		},
+		{
+			desc: "Cleanup",
+			cmd: func() {
+				rclient.Del(context.Background(), "DB_JOURNAL|Test")
+			},
+			expectedEntry: `"op":"del","key":"DB_JOURNAL|Test"`,
 		},
 	}
 
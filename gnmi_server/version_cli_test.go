@@ -0,0 +1,280 @@
+package gnmi
+
+// version_cli_test.go
+
+// Tests SHOW version, SHOW services and SHOW feature status
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/openconfig/gnmi/proto/gnmi"
+	show_client "github.com/sonic-net/sonic-gnmi/show_client"
+	sdc "github.com/sonic-net/sonic-gnmi/sonic_data_client"
+
+	"github.com/agiledragon/gomonkey/v2"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+)
+
+func TestGetShowVersion(t *testing.T) {
+	s := createServer(t, ServerPort)
+	go runServer(t, s)
+	defer s.ForceStop()
+	defer ResetDataSetsAndMappings(t)
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+
+	conn, err := grpc.Dial(TargetAddr, opts...)
+	if err != nil {
+		t.Fatalf("Dialing to %q failed: %v", TargetAddr, err)
+	}
+	defer conn.Close()
+
+	gClient := pb.NewGNMIClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout*time.Second)
+	defer cancel()
+
+	deviceMetadataFileName := "../testdata/DEVICE_METADATA.txt"
+	eepromInfoFileName := "../testdata/PLATFORM_EEPROM_INFO.txt"
+	versionYml := `build_version: 'master.123456-abcdef01'
+sonic_os_version: 12
+debian_version: '12.7'
+kernel_version: '6.1.0-22-2-amd64'
+asic_type: broadcom
+commit_id: 'abcdef01'
+build_date: Tue Oct 14 08:00:00 UTC 2025
+built_by: sonicbld@sonic-build
+`
+	versionInfo := `{"sonic_software_version":"SONiC.master.123456-abcdef01","sonic_os_version":"12","distribution":"Debian 12.7","kernel":"6.1.0-22-2-amd64","build_commit":"abcdef01","build_date":"Tue Oct 14 08:00:00 UTC 2025","built_by":"sonicbld@sonic-build","platform":"x86_64-arista_7050_qx32s","hwsku":"Arista-7050-QX32S","asic":"broadcom","asic_count":1,"serial":"JPE12345678","model":"DCS-7050QX-32S","revision":"A01","uptime":"up 2 days, 3 hours, 4 minutes","docker_images":[{"repository":"docker-orchagent","tag":"latest","image_id":"0b8d3a6f2e6c","size":"343MB"},{"repository":"docker-fpm-frr","tag":"latest","image_id":"5f8a2c1e9b7d","size":"362MB"},{"repository":"docker-database","tag":"latest","image_id":"a1b2c3d4e5f6","size":"301MB"}]}`
+	versionInfoNA := `{"sonic_software_version":"N/A","sonic_os_version":"12","distribution":"N/A","kernel":"6.1.0-22-2-amd64","build_commit":"abcdef01","build_date":"Tue Oct 14 08:00:00 UTC 2025","built_by":"sonicbld@sonic-build","platform":"x86_64-arista_7050_qx32s","hwsku":"Arista-7050-QX32S","asic":"broadcom","asic_count":1,"serial":"JPE12345678","model":"DCS-7050QX-32S","revision":"A01","uptime":"up 2 days, 3 hours, 4 minutes","docker_images":[{"repository":"docker-orchagent","tag":"latest","image_id":"0b8d3a6f2e6c","size":"343MB"},{"repository":"docker-fpm-frr","tag":"latest","image_id":"5f8a2c1e9b7d","size":"362MB"},{"repository":"docker-database","tag":"latest","image_id":"a1b2c3d4e5f6","size":"301MB"}]}`
+
+	ResetDataSetsAndMappings(t)
+	sdc.InvalidateVersionFileStash()
+	sdc.ImplIoutilReadFile = func(filePath string) ([]byte, error) {
+		switch filePath {
+		case sdc.SonicVersionFilePath:
+			return []byte(versionYml), nil
+		case show_client.ProcUptimeFilePath:
+			return []byte("183845.52 700000.10\n"), nil
+		}
+		return ioutil.ReadFile(filePath)
+	}
+	defer sdc.InvalidateVersionFileStash()
+
+	tests := []struct {
+		desc           string
+		pathTarget     string
+		textPbPath     string
+		wantRetCode    codes.Code
+		wantRespVal    interface{}
+		valTest        bool
+		mockOutputFile string
+		testInit       func()
+	}{
+		{
+			desc:       "query SHOW version docker command error",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "version" >
+			`,
+			wantRetCode: codes.NotFound,
+		},
+		{
+			desc:       "query SHOW version",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "version" >
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(versionInfo),
+			valTest:        true,
+			mockOutputFile: "../testdata/DOCKER_IMAGES.txt",
+			testInit: func() {
+				AddDataSet(t, ConfigDbNum, deviceMetadataFileName)
+				AddDataSet(t, StateDbNum, eepromInfoFileName)
+			},
+		},
+		{
+			desc:       "query SHOW version without build and debian versions",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "version" >
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(versionInfoNA),
+			valTest:        true,
+			mockOutputFile: "../testdata/DOCKER_IMAGES.txt",
+			testInit: func() {
+				versionYml = strings.Replace(versionYml, "build_version: 'master.123456-abcdef01'\n", "", 1)
+				versionYml = strings.Replace(versionYml, "debian_version: '12.7'\n", "", 1)
+				sdc.InvalidateVersionFileStash()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		if test.testInit != nil {
+			test.testInit()
+		}
+		var patches *gomonkey.Patches
+		if test.mockOutputFile != "" {
+			patches = MockNSEnterBGPSummary(t, test.mockOutputFile)
+		}
+
+		t.Run(test.desc, func(t *testing.T) {
+			runTestGet(t, ctx, gClient, test.pathTarget, test.textPbPath, test.wantRetCode, test.wantRespVal, test.valTest)
+		})
+		if patches != nil {
+			patches.Reset()
+		}
+	}
+}
+
+func TestGetShowServicesAndFeatureStatus(t *testing.T) {
+	s := createServer(t, ServerPort)
+	go runServer(t, s)
+	defer s.ForceStop()
+	defer ResetDataSetsAndMappings(t)
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}
+
+	conn, err := grpc.Dial(TargetAddr, opts...)
+	if err != nil {
+		t.Fatalf("Dialing to %q failed: %v", TargetAddr, err)
+	}
+	defer conn.Close()
+
+	gClient := pb.NewGNMIClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout*time.Second)
+	defer cancel()
+
+	featureConfigFileName := "../testdata/FEATURE_CONFIG.txt"
+	featureStateFileName := "../testdata/FEATURE_STATE.txt"
+	dockerPsFileName := "../testdata/DOCKER_PS.txt"
+
+	services := `[
+		{"name":"bgp","state":"running","status":"Up 2 days","image":"docker-fpm-frr:latest"},
+		{"name":"database","state":"running","status":"Up 2 days","image":"docker-database:latest"},
+		{"name":"lldp","state":"exited","status":"Exited (0) 5 hours ago","image":"docker-lldp:latest"},
+		{"name":"swss","state":"running","status":"Up 2 days","image":"docker-orchagent:latest"}
+	]`
+	featureStatus := `[
+		{"feature":"bgp","state":"enabled","auto_restart":"enabled","system_state":"up","update_time":"2025-10-15 08:17:39","container_id":"bgp","container_version":"1.0.0","set_owner":"local","current_owner":"local","remote_state":"none","container_state":"running"},
+		{"feature":"lldp","state":"enabled","auto_restart":"enabled","system_state":"down","update_time":"2025-10-15 03:10:02","container_id":"lldp","container_version":"1.0.0","set_owner":"local","current_owner":"local","remote_state":"none","container_state":"exited"},
+		{"feature":"swss","state":"enabled","auto_restart":"enabled","system_state":"up","update_time":"2025-10-15 08:17:20","container_id":"swss","container_version":"1.0.0","set_owner":"local","current_owner":"local","remote_state":"none","container_state":"running"},
+		{"feature":"telemetry","state":"disabled","auto_restart":"enabled","system_state":"N/A","update_time":"N/A","container_id":"N/A","container_version":"N/A","set_owner":"local","current_owner":"N/A","remote_state":"N/A","container_state":"not created"}
+	]`
+	featureStatusBgp := `[
+		{"feature":"bgp","state":"enabled","auto_restart":"enabled","system_state":"up","update_time":"2025-10-15 08:17:39","container_id":"bgp","container_version":"1.0.0","set_owner":"local","current_owner":"local","remote_state":"none","container_state":"running"}
+	]`
+
+	ResetDataSetsAndMappings(t)
+
+	tests := []struct {
+		desc           string
+		pathTarget     string
+		textPbPath     string
+		wantRetCode    codes.Code
+		wantRespVal    interface{}
+		valTest        bool
+		mockOutputFile string
+		testInit       func()
+	}{
+		{
+			desc:       "query SHOW services docker command error",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "services" >
+			`,
+			wantRetCode: codes.NotFound,
+		},
+		{
+			desc:       "query SHOW services",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "services" >
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(services),
+			valTest:        true,
+			mockOutputFile: dockerPsFileName,
+		},
+		{
+			desc:       "query SHOW feature status NO data",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "feature" >
+				elem: <name: "status" >
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(`[]`),
+			valTest:        true,
+			mockOutputFile: dockerPsFileName,
+		},
+		{
+			desc:       "query SHOW feature status",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "feature" >
+				elem: <name: "status" >
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(featureStatus),
+			valTest:        true,
+			mockOutputFile: dockerPsFileName,
+			testInit: func() {
+				AddDataSet(t, ConfigDbNum, featureConfigFileName)
+				AddDataSet(t, StateDbNum, featureStateFileName)
+			},
+		},
+		{
+			desc:       "query SHOW feature status feature option",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "feature" >
+				elem: <name: "status" key: { key: "feature" value: "bgp" }>
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(featureStatusBgp),
+			valTest:        true,
+			mockOutputFile: dockerPsFileName,
+		},
+		{
+			desc:       "query SHOW feature status unknown feature",
+			pathTarget: "SHOW",
+			textPbPath: `
+				elem: <name: "feature" >
+				elem: <name: "status" key: { key: "feature" value: "nonexistent" }>
+			`,
+			wantRetCode:    codes.OK,
+			wantRespVal:    []byte(`[]`),
+			valTest:        true,
+			mockOutputFile: dockerPsFileName,
+		},
+	}
+
+	for _, test := range tests {
+		if test.testInit != nil {
+			test.testInit()
+		}
+		var patches *gomonkey.Patches
+		if test.mockOutputFile != "" {
+			patches = MockNSEnterBGPSummary(t, test.mockOutputFile)
+		}
+
+		t.Run(test.desc, func(t *testing.T) {
+			runTestGet(t, ctx, gClient, test.pathTarget, test.textPbPath, test.wantRetCode, test.wantRespVal, test.valTest)
+		})
+		if patches != nil {
+			patches.Reset()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sonic-net/sonic-gnmi/gnoi_client/config"
+	"github.com/sonic-net/sonic-gnmi/gnoi_client/utils"
+	pb "github.com/sonic-net/sonic-gnmi/proto/gnoi/admin"
+	"google.golang.org/grpc"
+)
+
+func ListSessions(conn *grpc.ClientConn, ctx context.Context) {
+	fmt.Println("Sonic Admin ListSessions")
+	ctx = utils.SetUserCreds(ctx)
+	sc := pb.NewSonicAdminServiceClient(conn)
+	req := &pb.ListSessionsRequest{}
+	json.Unmarshal([]byte(*config.Args), req)
+
+	resp, err := sc.ListSessions(ctx, req)
+	if err != nil {
+		panic(err.Error())
+	}
+	respstr, err := json.Marshal(resp)
+	if err != nil {
+		panic(err.Error())
+	}
+	fmt.Println(string(respstr))
+}
+
+func KillSession(conn *grpc.ClientConn, ctx context.Context) {
+	fmt.Println("Sonic Admin KillSession")
+	ctx = utils.SetUserCreds(ctx)
+	sc := pb.NewSonicAdminServiceClient(conn)
+	req := &pb.KillSessionRequest{}
+	json.Unmarshal([]byte(*config.Args), req)
+
+	resp, err := sc.KillSession(ctx, req)
+	if err != nil {
+		panic(err.Error())
+	}
+	respstr, err := json.Marshal(resp)
+	if err != nil {
+		panic(err.Error())
+	}
+	fmt.Println(string(respstr))
+}
+
+func DrainServer(conn *grpc.ClientConn, ctx context.Context) {
+	fmt.Println("Sonic Admin DrainServer")
+	ctx = utils.SetUserCreds(ctx)
+	sc := pb.NewSonicAdminServiceClient(conn)
+	req := &pb.DrainServerRequest{}
+	json.Unmarshal([]byte(*config.Args), req)
+
+	resp, err := sc.DrainServer(ctx, req)
+	if err != nil {
+		panic(err.Error())
+	}
+	respstr, err := json.Marshal(resp)
+	if err != nil {
+		panic(err.Error())
+	}
+	fmt.Println(string(respstr))
+}
@@ -7,11 +7,13 @@ import (
 
 	"github.com/google/gnxi/utils/credentials"
 	factory_reset_pb "github.com/openconfig/gnoi/factory_reset"
+	"github.com/sonic-net/sonic-gnmi/gnoi_client/admin"
 	"github.com/sonic-net/sonic-gnmi/gnoi_client/config"
 	"github.com/sonic-net/sonic-gnmi/gnoi_client/containerz"
 	"github.com/sonic-net/sonic-gnmi/gnoi_client/factory_reset"
 	"github.com/sonic-net/sonic-gnmi/gnoi_client/file"
 	gnoi_healthz "github.com/sonic-net/sonic-gnmi/gnoi_client/healthz"
+	"github.com/sonic-net/sonic-gnmi/gnoi_client/journal"
 	gnoi_os "github.com/sonic-net/sonic-gnmi/gnoi_client/os" // So it does not collide with os.
 	"github.com/sonic-net/sonic-gnmi/gnoi_client/sonic"
 	"github.com/sonic-net/sonic-gnmi/gnoi_client/system"
@@ -122,6 +124,24 @@ func main() {
 		default:
 			panic("Invalid RPC Name")
 		}
+	case "Admin":
+		switch *config.Rpc {
+		case "ListSessions":
+			admin.ListSessions(conn, ctx)
+		case "KillSession":
+			admin.KillSession(conn, ctx)
+		case "DrainServer":
+			admin.DrainServer(conn, ctx)
+		default:
+			panic("Invalid RPC Name")
+		}
+	case "Journal":
+		switch *config.Rpc {
+		case "Rollback":
+			journal.Rollback(conn, ctx)
+		default:
+			panic("Invalid RPC Name")
+		}
 	case "Containerz":
 		switch *config.Rpc {
 		case "Deploy":
@@ -0,0 +1,29 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sonic-net/sonic-gnmi/gnoi_client/config"
+	"github.com/sonic-net/sonic-gnmi/gnoi_client/utils"
+	pb "github.com/sonic-net/sonic-gnmi/proto/gnoi/journal"
+	"google.golang.org/grpc"
+)
+
+func Rollback(conn *grpc.ClientConn, ctx context.Context) {
+	fmt.Println("Sonic Journal Rollback")
+	ctx = utils.SetUserCreds(ctx)
+	sc := pb.NewSonicJournalServiceClient(conn)
+	req := &pb.RollbackRequest{}
+	json.Unmarshal([]byte(*config.Args), req)
+
+	resp, err := sc.Rollback(ctx, req)
+	if err != nil {
+		panic(err.Error())
+	}
+	respstr, err := json.Marshal(resp)
+	if err != nil {
+		panic(err.Error())
+	}
+	fmt.Println(string(respstr))
+}
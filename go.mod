@@ -17,6 +17,7 @@ require (
 	github.com/golang/glog v1.2.4
 	github.com/golang/mock v1.6.0
 	github.com/golang/protobuf v1.5.4
+	github.com/golang/snappy v0.0.4
 	github.com/google/gnxi v0.0.0-20181220173256-89f51f0ce1e2
 	github.com/google/go-cmp v0.7.0
 	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510
@@ -26,6 +27,7 @@ require (
 	github.com/openconfig/gnmi v0.14.1
 	github.com/openconfig/gnoi v0.3.0
 	github.com/openconfig/gnsi v1.9.0
+	github.com/openconfig/grpctunnel v0.2.0
 	github.com/openconfig/ygot v0.29.20
 	github.com/redis/go-redis/v9 v9.14.1
 	github.com/stretchr/testify v1.10.0
@@ -0,0 +1,170 @@
+// Package audit records one structured entry per write-class RPC served by
+// the gNMI server: who did what, from where, on which paths and with which
+// result. Entries are JSON objects written to every configured sink, such as
+// syslog and a rotating local file.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+)
+
+// Record is the audit entry of a single RPC. Handlers complete it while the
+// RPC runs, all methods are safe to call on a nil Record.
+type Record struct {
+	mu sync.Mutex
+
+	Time       time.Time `json:"time"`
+	User       string    `json:"user"`
+	Roles      []string  `json:"roles,omitempty"`
+	Peer       string    `json:"peer"`
+	RPC        string    `json:"rpc"`
+	Paths      []string  `json:"paths,omitempty"`
+	Op         string    `json:"op"`
+	Code       string    `json:"code"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs float64   `json:"duration_ms"`
+	Bypass     bool      `json:"bypass"`
+
+	ops map[string]bool
+}
+
+// SetUser records the authenticated user and roles.
+func (r *Record) SetUser(user string, roles []string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.User = user
+	r.Roles = append([]string{}, roles...)
+}
+
+// AddPaths records paths written with op, e.g. "update", "replace" or
+// "delete". The op of the record lists all distinct ops.
+func (r *Record) AddPaths(op string, paths ...string) {
+	if r == nil || len(paths) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Paths = append(r.Paths, paths...)
+	if r.ops == nil {
+		r.ops = make(map[string]bool)
+	}
+	r.ops[op] = true
+}
+
+// SetBypass records that the write skipped validation.
+func (r *Record) SetBypass() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Bypass = true
+}
+
+// marshal finalizes the op and encodes the record.
+func (r *Record) marshal() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.ops) > 0 {
+		ops := make([]string, 0, len(r.ops))
+		for op := range r.ops {
+			ops = append(ops, op)
+		}
+		sort.Strings(ops)
+		r.Op = ops[0]
+		for _, op := range ops[1:] {
+			r.Op += "," + op
+		}
+	}
+	return json.Marshal(r)
+}
+
+// Sink receives encoded audit records.
+type Sink interface {
+	Write(record []byte) error
+	Close() error
+}
+
+// Logger writes records to a set of sinks.
+type Logger struct {
+	mu    sync.RWMutex
+	sinks []Sink
+}
+
+// Default is the audit logger of the server. It has no sinks until
+// configured, in which case records are dropped.
+var Default = NewLogger()
+
+// NewLogger returns a logger without sinks.
+func NewLogger() *Logger {
+	return &Logger{}
+}
+
+// AddSink makes the logger write every record to sink.
+func (l *Logger) AddSink(sink Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, sink)
+}
+
+// Enabled reports whether the logger has any sink.
+func (l *Logger) Enabled() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.sinks) > 0
+}
+
+// Log writes r to all sinks. Sink failures are logged and never fail the RPC.
+func (l *Logger) Log(r *Record) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if len(l.sinks) == 0 {
+		return
+	}
+	data, err := r.marshal()
+	if err != nil {
+		log.Errorf("Failed to encode audit record: %v", err)
+		return
+	}
+	for _, sink := range l.sinks {
+		if err := sink.Write(data); err != nil {
+			log.Errorf("Failed to write audit record: %v", err)
+		}
+	}
+}
+
+// Close closes and removes all sinks.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var firstErr error
+	for _, sink := range l.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	l.sinks = nil
+	return firstErr
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying r.
+func NewContext(ctx context.Context, r *Record) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the record carried by ctx, or nil.
+func FromContext(ctx context.Context) *Record {
+	r, _ := ctx.Value(contextKey{}).(*Record)
+	return r
+}
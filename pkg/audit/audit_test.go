@@ -0,0 +1,116 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type memorySink struct {
+	mu      sync.Mutex
+	records [][]byte
+	err     error
+	closed  bool
+}
+
+func (m *memorySink) Write(record []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, record)
+	return m.err
+}
+
+func (m *memorySink) Close() error {
+	m.closed = true
+	return nil
+}
+
+func TestLogger_Record(t *testing.T) {
+	logger := NewLogger()
+	if logger.Enabled() {
+		t.Error("Expected logger without sinks to be disabled")
+	}
+	sink := &memorySink{}
+	failing := &memorySink{err: errors.New("disk full")}
+	logger.AddSink(sink)
+	logger.AddSink(failing)
+
+	r := &Record{RPC: "/gnmi.gNMI/Set", Peer: "10.0.0.1:5000", Code: "OK"}
+	r.SetUser("admin", []string{"gnmi_readwrite"})
+	r.AddPaths("update", "/CONFIG_DB/PORT/Ethernet0")
+	r.AddPaths("delete", "/CONFIG_DB/VLAN/Vlan10", "/CONFIG_DB/VLAN/Vlan20")
+	r.AddPaths("update")
+	r.SetBypass()
+	logger.Log(r)
+
+	if len(sink.records) != 1 || len(failing.records) != 1 {
+		t.Fatalf("Expected the record on every sink, got %d and %d", len(sink.records), len(failing.records))
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(sink.records[0], &got); err != nil {
+		t.Fatalf("Record is not JSON: %v", err)
+	}
+	for field, want := range map[string]interface{}{
+		"user":   "admin",
+		"peer":   "10.0.0.1:5000",
+		"rpc":    "/gnmi.gNMI/Set",
+		"op":     "delete,update",
+		"code":   "OK",
+		"bypass": true,
+	} {
+		if got[field] != want {
+			t.Errorf("Field %s: expected %v, got %v", field, want, got[field])
+		}
+	}
+	if paths, _ := got["paths"].([]interface{}); len(paths) != 3 {
+		t.Errorf("Expected 3 paths, got %v", got["paths"])
+	}
+
+	if err := logger.Close(); err != nil || !sink.closed || logger.Enabled() {
+		t.Errorf("Expected close to close and remove sinks, got %v", err)
+	}
+}
+
+func TestNilRecord(t *testing.T) {
+	r := FromContext(context.Background())
+	if r != nil {
+		t.Fatal("Expected no record in empty context")
+	}
+	r.SetUser("admin", nil)
+	r.AddPaths("update", "/a")
+	r.SetBypass()
+
+	r = &Record{}
+	if FromContext(NewContext(context.Background(), r)) != r {
+		t.Error("Expected record from context")
+	}
+}
+
+func TestFileSink_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path, 0, 0)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	sink.Write([]byte("first"))
+	sink.Close()
+
+	sink, err = NewFileSink(path, 0, 0)
+	if err != nil {
+		t.Fatalf("NewFileSink failed: %v", err)
+	}
+	sink.Write([]byte("second"))
+	sink.Close()
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "first\nsecond\n" {
+		t.Errorf("Expected records to be appended, got %q", data)
+	}
+	if _, err := NewFileSink(filepath.Join(path, "invalid"), 0, 0); err == nil {
+		t.Error("Expected error for invalid path")
+	}
+}
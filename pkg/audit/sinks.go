@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"github.com/sonic-net/sonic-gnmi/pkg/rotatefile"
+)
+
+// syslogTag identifies audit records in syslog.
+const syslogTag = "gnmi_audit"
+
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// NewSyslogSink returns a sink writing records to the local syslog daemon.
+func NewSyslogSink() (Sink, error) {
+	writer, err := syslog.New(syslog.LOG_LOCAL4|syslog.LOG_INFO, syslogTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %v", err)
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+func (s *syslogSink) Write(record []byte) error {
+	return s.writer.Info(string(record))
+}
+
+func (s *syslogSink) Close() error {
+	return s.writer.Close()
+}
+
+// FileSink writes one record per line to a local file, rotating it when it
+// grows past maxSize bytes. Up to maxBackups rotated files are kept as
+// <path>.1 (newest) to <path>.<maxBackups> (oldest).
+type FileSink struct {
+	file *rotatefile.Writer
+}
+
+// NewFileSink opens or creates the file at path for appending.
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	file, err := rotatefile.Open(path, maxSize, maxBackups)
+	if err != nil {
+		return nil, fmt.Errorf("audit log: %v", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Write(record []byte) error {
+	if err := s.file.WriteLine(record); err != nil {
+		return fmt.Errorf("audit log: %v", err)
+	}
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,109 @@
+package configjournal
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// authorGrace is how long after a Set request ends its changes may still be
+// journaled, since keyspace notifications arrive asynchronously.
+const authorGrace = 2 * time.Second
+
+// pending is a Set request that may have made the changes being journaled.
+type pending struct {
+	author Author
+	keys   []string
+	start  time.Time
+	end    time.Time
+}
+
+// mayChange reports whether the request may have changed key. A request
+// without keys may have changed any.
+func (p *pending) mayChange(key string) bool {
+	if p.keys == nil {
+		return true
+	}
+	for _, k := range p.keys {
+		if k == key {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(k, "*"); ok && strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// authors tracks the Set requests in progress. Redis notifications do not
+// tell who made a change, so a change is attributed to the request running
+// when it was seen that may change its key, and left unattributed when
+// several were.
+type authors struct {
+	mu      sync.Mutex
+	pending []*pending
+}
+
+// Attribute declares that the request of author is about to change the keys
+// of the database, and returns the function to call once it is done. Each of
+// keys is a key or a key prefix ending with "*". With nil keys every change
+// made while the request runs is attributed to it, marked as inferred. It is
+// a no-op on a nil journal.
+func (j *Journal) Attribute(author Author, keys []string) func() {
+	if j == nil || author == (Author{}) {
+		return func() {}
+	}
+	return j.authors.begin(author, keys, time.Now())
+}
+
+func (a *authors) begin(author Author, keys []string, now time.Time) func() {
+	p := &pending{author: author, keys: keys, start: now}
+	if keys == nil {
+		p.author.Attribution = AttributionInferred
+	}
+	a.mu.Lock()
+	a.prune(now)
+	a.pending = append(a.pending, p)
+	a.mu.Unlock()
+	return func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		p.end = time.Now()
+	}
+}
+
+// at returns the author of a change of key seen at t, or no author when none
+// or several requests could have made it.
+func (a *authors) at(t time.Time, key string) Author {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.prune(t)
+	var found *pending
+	for _, p := range a.pending {
+		if t.Before(p.start) || (!p.end.IsZero() && t.After(p.end.Add(authorGrace))) || !p.mayChange(key) {
+			continue
+		}
+		if found != nil && found.author != p.author {
+			return Author{}
+		}
+		found = p
+	}
+	if found == nil {
+		return Author{}
+	}
+	return found.author
+}
+
+// prune drops the requests that ended too long before now.
+func (a *authors) prune(now time.Time) {
+	kept := a.pending[:0]
+	for _, p := range a.pending {
+		if p.end.IsZero() || now.Sub(p.end) <= authorGrace {
+			kept = append(kept, p)
+		}
+	}
+	for i := len(kept); i < len(a.pending); i++ {
+		a.pending[i] = nil
+	}
+	a.pending = kept
+}
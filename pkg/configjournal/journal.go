@@ -0,0 +1,488 @@
+// Package configjournal keeps the journal of the changes made to a Redis
+// database such as CONFIG_DB. Each change of a key is a JSON record on its
+// own line, holding the content of the key before and after the change and,
+// when the change came from a gNMI Set served by this process, the user and
+// request that made it. The journal file is rotated into compressed backups,
+// can be queried across them, and streams new records to subscribers.
+package configjournal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+)
+
+// backupTimeFormat names backups after the time they were rotated.
+const backupTimeFormat = "20060102150405.000"
+
+// maxLineSize bounds the size of a record read back from the journal.
+const maxLineSize = 4 * 1024 * 1024
+
+// subscriberBuffer is the number of records a subscriber may lag behind.
+const subscriberBuffer = 1024
+
+// Record is the change of one key.
+type Record struct {
+	// Seq numbers the records of a journal from 1, in the order written.
+	Seq      uint64    `json:"seq"`
+	Time     time.Time `json:"time"`
+	Database string    `json:"database"`
+	// Op is the Redis operation, e.g. hset, hdel, del or set.
+	Op     string            `json:"op"`
+	Key    string            `json:"key"`
+	Before map[string]string `json:"before,omitempty"`
+	After  map[string]string `json:"after,omitempty"`
+	Author
+}
+
+// Author identifies the gNMI request that made a change.
+type Author struct {
+	User      string `json:"user,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// Attribution is AttributionInferred when the change was attributed only
+	// because it was made while the request ran.
+	Attribution string `json:"attribution,omitempty"`
+}
+
+// AttributionInferred marks the changes attributed to a request that did not
+// tell which keys it changes.
+const AttributionInferred = "inferred"
+
+// Options configure the file of a journal.
+type Options struct {
+	// Path is the journal file. Backups are kept next to it as
+	// <name>_<time>.gz, where name is the file name without extension.
+	Path string
+	// MaxSize is the size in bytes past which the file is rotated, 0 never
+	// rotates it.
+	MaxSize int64
+	// MaxBackups is the number of backups kept, older ones are removed.
+	MaxBackups int
+}
+
+// Journal appends records to a file and hands them to subscribers.
+type Journal struct {
+	opts   Options
+	prefix string
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	seq  uint64
+	subs map[chan Record]struct{}
+
+	authors authors
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultJournal *Journal
+)
+
+// SetDefault makes j the journal of CONFIG_DB, which the server serves and
+// attributes its Set requests to. nil disables it.
+func SetDefault(j *Journal) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultJournal = j
+}
+
+// Default returns the journal of CONFIG_DB, or nil when it is disabled.
+// Attribute is safe to call on a nil journal.
+func Default() *Journal {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultJournal
+}
+
+// Open opens or creates the journal file described by opts.
+func Open(opts Options) (*Journal, error) {
+	if opts.Path == "" {
+		return nil, errors.New("journal path is empty")
+	}
+	name := filepath.Base(opts.Path)
+	j := &Journal{
+		opts:   opts,
+		prefix: strings.TrimSuffix(name, filepath.Ext(name)) + "_",
+		subs:   make(map[chan Record]struct{}),
+	}
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+	if err := j.loadSeq(); err != nil {
+		j.file.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// loadSeq continues the sequence of the records in the file, or in the
+// newest backup when the file is empty.
+func (j *Journal) loadSeq() error {
+	last := func(r Record) {
+		if r.Seq > j.seq {
+			j.seq = r.Seq
+		}
+	}
+	if err := readFile(j.opts.Path, last); err != nil {
+		return err
+	}
+	if j.seq > 0 {
+		return nil
+	}
+	backups, err := j.backups()
+	if err != nil || len(backups) == 0 {
+		return err
+	}
+	if err := readBackup(backups[len(backups)-1], last); err != nil {
+		log.Warningf("Failed to read journal backup %s: %v", backups[len(backups)-1], err)
+	}
+	return nil
+}
+
+func (j *Journal) open() error {
+	file, err := os.OpenFile(j.opts.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal %s: %v", j.opts.Path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat journal %s: %v", j.opts.Path, err)
+	}
+	j.file, j.size = file, info.Size()
+	return nil
+}
+
+// Write numbers r, appends it to the journal and sends it to the
+// subscribers. A record without author is attributed to the Set request in
+// progress when it was made that may change its key, if there is exactly one.
+func (j *Journal) Write(r Record) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	if r.Author == (Author{}) {
+		r.Author = j.authors.at(r.Time, r.Key)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	r.Seq = j.seq + 1
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	j.seq = r.Seq
+	for ch := range j.subs {
+		select {
+		case ch <- r:
+		default:
+			// Ending the subscription beats silently losing records
+			log.Warningf("Config journal subscriber fell behind, closing it")
+			delete(j.subs, ch)
+			close(ch)
+		}
+	}
+	if err := j.prepare(int64(len(data)) + 1); err != nil {
+		return err
+	}
+	n, err := j.file.Write(append(data, '\n'))
+	j.size += int64(n)
+	return err
+}
+
+// prepare makes sure the file is open, reopening it when it was removed, and
+// rotates it when n more bytes would exceed the maximum size.
+func (j *Journal) prepare(n int64) error {
+	if j.file != nil {
+		if _, err := os.Stat(j.opts.Path); err != nil {
+			j.file.Close()
+			j.file = nil
+		}
+	}
+	if j.file == nil {
+		if err := j.open(); err != nil {
+			return err
+		}
+	}
+	if j.opts.MaxSize > 0 && j.size > 0 && j.size+n > j.opts.MaxSize {
+		return j.rotate()
+	}
+	return nil
+}
+
+// rotate compresses the file into a new backup, removes the backups past the
+// retention and starts a new file.
+func (j *Journal) rotate() error {
+	j.file.Close()
+	j.file = nil
+	if j.opts.MaxBackups > 0 {
+		backup := filepath.Join(filepath.Dir(j.opts.Path), j.prefix+time.Now().Format(backupTimeFormat)+".gz")
+		if err := compress(j.opts.Path, backup); err != nil {
+			return fmt.Errorf("failed to rotate journal %s: %v", j.opts.Path, err)
+		}
+	}
+	if err := os.Remove(j.opts.Path); err != nil {
+		return fmt.Errorf("failed to rotate journal %s: %v", j.opts.Path, err)
+	}
+	backups, err := j.backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > j.opts.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return j.open()
+}
+
+// compress writes the gzip of the file at src to dst.
+func compress(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// backups returns the paths of the backups, oldest first.
+func (j *Journal) backups() ([]string, error) {
+	dir := filepath.Dir(j.opts.Path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		if name := entry.Name(); strings.HasPrefix(name, j.prefix) && strings.HasSuffix(name, ".gz") {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// Close closes the file and ends all subscriptions.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for ch := range j.subs {
+		delete(j.subs, ch)
+		close(ch)
+	}
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// Subscribe returns a channel receiving the records written from now on,
+// and a function ending the subscription. The channel is closed when the
+// subscription ends, including when the subscriber falls too far behind.
+func (j *Journal) Subscribe() (<-chan Record, func()) {
+	ch := make(chan Record, subscriberBuffer)
+	j.mu.Lock()
+	j.subs[ch] = struct{}{}
+	j.mu.Unlock()
+	return ch, func() {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if _, ok := j.subs[ch]; ok {
+			delete(j.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Query selects records of the journal.
+type Query struct {
+	// Since and Until bound the time of the records, when not zero.
+	Since time.Time
+	Until time.Time
+	// Key selects the records of keys starting with it, e.g. "PORT|".
+	Key string
+	// User selects the records made by a user.
+	User string
+	// Limit keeps the newest records only, when not zero.
+	Limit int
+}
+
+// ParseQuery returns the query described by the keys of a gNMI path element,
+// e.g. config-journal[since=2024-05-01T00:00:00Z][key=PORT|]. since and
+// until are RFC 3339 times, nanoseconds since the epoch or durations before
+// now such as 1h; limit is a number of records.
+func ParseQuery(keys map[string]string) (Query, error) {
+	var q Query
+	var err error
+	for name, value := range keys {
+		switch name {
+		case "since":
+			q.Since, err = parseTime(value)
+		case "until":
+			q.Until, err = parseTime(value)
+		case "key":
+			q.Key = value
+		case "user":
+			q.User = value
+		case "limit":
+			q.Limit, err = strconv.Atoi(value)
+			if err == nil && q.Limit < 0 {
+				err = errors.New("must not be negative")
+			}
+		default:
+			return q, fmt.Errorf("unsupported key %q", name)
+		}
+		if err != nil {
+			return q, fmt.Errorf("invalid %s %q: %v", name, value, err)
+		}
+	}
+	return q, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if ns, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(0, ns), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, errors.New("expected an RFC 3339 time, nanoseconds since the epoch or a duration")
+}
+
+// Match reports whether r is selected by q, ignoring the limit.
+func (q Query) Match(r Record) bool {
+	if !q.Since.IsZero() && r.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && r.Time.After(q.Until) {
+		return false
+	}
+	if q.User != "" && r.User != q.User {
+		return false
+	}
+	return strings.HasPrefix(r.Key, q.Key)
+}
+
+// Query returns the records selected by q from the backups and the file,
+// oldest first. Lines that are not records, such as entries written by
+// older versions, are skipped.
+func (j *Journal) Query(q Query) ([]Record, error) {
+	var records []Record
+	err := j.scan(q.Since, func(r Record) {
+		if !q.Match(r) {
+			return
+		}
+		records = append(records, r)
+		if q.Limit > 0 && len(records) > 2*q.Limit {
+			records = append(records[:0], records[len(records)-q.Limit:]...)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[len(records)-q.Limit:]
+	}
+	return records, nil
+}
+
+// scan calls fn with the records of the backups and the file, oldest first,
+// skipping the backups rotated before since.
+func (j *Journal) scan(since time.Time, fn func(Record)) error {
+	j.mu.Lock()
+	backups, err := j.backups()
+	j.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups {
+		// A backup only holds records older than its rotation
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(backup), j.prefix), ".gz")
+		if rotated, err := time.ParseInLocation(backupTimeFormat, name, time.Local); err == nil && rotated.Before(since) {
+			continue
+		}
+		if err := readBackup(backup, fn); err != nil {
+			// The backup may have been removed by a rotation meanwhile
+			log.V(2).Infof("Skipping journal backup %s: %v", backup, err)
+		}
+	}
+	if err := readFile(j.opts.Path, fn); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func readBackup(path string, fn func(Record)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	return readRecords(zr, fn)
+}
+
+func readFile(path string, fn func(Record)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return readRecords(file, fn)
+}
+
+func readRecords(r io.Reader, fn func(Record)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			continue
+		}
+		fn(record)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,261 @@
+package configjournal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openJournal(t *testing.T, opts Options) *Journal {
+	t.Helper()
+	if opts.Path == "" {
+		opts.Path = filepath.Join(t.TempDir(), "config_db.txt")
+	}
+	j, err := Open(opts)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { j.Close() })
+	return j
+}
+
+func TestJournal_WriteAndQuery(t *testing.T) {
+	j := openJournal(t, Options{})
+	// Lines of older versions are skipped
+	if err := os.WriteFile(j.opts.Path, []byte("2024-01-01.00:00:00.000000: hset PORT|Ethernet0 +mtu:9100\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Now()
+	records := []Record{
+		{Time: base, Database: "CONFIG_DB", Op: "hset", Key: "PORT|Ethernet0", After: map[string]string{"mtu": "9100"}, Author: Author{User: "admin", RequestID: "r1"}},
+		{Time: base.Add(time.Second), Database: "CONFIG_DB", Op: "hset", Key: "VLAN|Vlan10", After: map[string]string{"vlanid": "10"}},
+		{Time: base.Add(2 * time.Second), Database: "CONFIG_DB", Op: "del", Key: "PORT|Ethernet0", Before: map[string]string{"mtu": "9100"}, Author: Author{User: "bob"}},
+	}
+	for _, r := range records {
+		if err := j.Write(r); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		desc  string
+		query Query
+		want  []string
+	}{
+		{"All", Query{}, []string{"hset PORT|Ethernet0", "hset VLAN|Vlan10", "del PORT|Ethernet0"}},
+		{"Since", Query{Since: base.Add(500 * time.Millisecond)}, []string{"hset VLAN|Vlan10", "del PORT|Ethernet0"}},
+		{"Until", Query{Until: base.Add(500 * time.Millisecond)}, []string{"hset PORT|Ethernet0"}},
+		{"Key", Query{Key: "PORT|"}, []string{"hset PORT|Ethernet0", "del PORT|Ethernet0"}},
+		{"User", Query{User: "admin"}, []string{"hset PORT|Ethernet0"}},
+		{"Limit", Query{Limit: 1}, []string{"del PORT|Ethernet0"}},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := j.Query(test.query)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			var ops []string
+			for _, r := range got {
+				ops = append(ops, r.Op+" "+r.Key)
+			}
+			if strings.Join(ops, ",") != strings.Join(test.want, ",") {
+				t.Errorf("got %v, want %v", ops, test.want)
+			}
+		})
+	}
+
+	got, _ := j.Query(Query{User: "admin"})
+	if len(got) != 1 || got[0].RequestID != "r1" || got[0].After["mtu"] != "9100" {
+		t.Errorf("record not read back intact: %+v", got)
+	}
+}
+
+func TestJournal_Retention(t *testing.T) {
+	dir := t.TempDir()
+	j := openJournal(t, Options{Path: filepath.Join(dir, "config_db.txt"), MaxSize: 200, MaxBackups: 2})
+	big := strings.Repeat("x", 100)
+	for i := 0; i < 8; i++ {
+		if err := j.Write(Record{Op: "hset", Key: "T|k", After: map[string]string{"f": big}}); err != nil {
+			t.Fatalf("Write %d failed: %v", i, err)
+		}
+		// Backups are named after the millisecond they were rotated
+		time.Sleep(2 * time.Millisecond)
+	}
+	backups, err := j.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("got %d backups, want 2: %v", len(backups), backups)
+	}
+	got, err := j.Query(Query{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	// One record per file: two backups and the current file
+	if len(got) != 3 {
+		t.Errorf("got %d records across backups, want 3", len(got))
+	}
+
+	// Backups rotated before since are not read
+	got, _ = j.Query(Query{Since: time.Now().Add(time.Hour)})
+	if len(got) != 0 {
+		t.Errorf("got %d records after since, want 0", len(got))
+	}
+}
+
+func TestJournal_ReopensRemovedFile(t *testing.T) {
+	j := openJournal(t, Options{})
+	j.Write(Record{Op: "hset", Key: "T|a"})
+	if err := os.Remove(j.opts.Path); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Write(Record{Op: "hset", Key: "T|b"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got, _ := j.Query(Query{})
+	if len(got) != 1 || got[0].Key != "T|b" {
+		t.Errorf("got %+v, want only T|b", got)
+	}
+}
+
+func TestJournal_Subscribe(t *testing.T) {
+	j := openJournal(t, Options{})
+	ch, cancel := j.Subscribe()
+	j.Write(Record{Op: "hset", Key: "T|a"})
+	select {
+	case r := <-ch:
+		if r.Key != "T|a" {
+			t.Errorf("got %s, want T|a", r.Key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("record not received")
+	}
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Error("channel not closed by cancel")
+	}
+	cancel()
+
+	// A subscriber falling behind is closed
+	ch, cancel = j.Subscribe()
+	defer cancel()
+	for i := 0; i <= subscriberBuffer; i++ {
+		j.Write(Record{Op: "hset", Key: "T|a"})
+	}
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("received %d records before close, want %d", n, subscriberBuffer)
+	}
+}
+
+func TestJournal_Attribute(t *testing.T) {
+	j := openJournal(t, Options{})
+	ch, cancel := j.Subscribe()
+	defer cancel()
+
+	done := j.Attribute(Author{User: "admin", RequestID: "r1"}, []string{"T|*"})
+	j.Write(Record{Op: "hset", Key: "T|a"})
+	if r := <-ch; r.User != "admin" || r.RequestID != "r1" || r.Attribution != "" {
+		t.Errorf("got author %+v, want admin/r1", r.Author)
+	}
+
+	// A concurrent change of a key the request does not change is not its
+	j.Write(Record{Op: "hset", Key: "PORT|Ethernet0"})
+	if r := <-ch; r.Author != (Author{}) {
+		t.Errorf("got author %+v for a foreign change, want none", r.Author)
+	}
+
+	// Overlapping requests leave changes unattributed
+	done2 := j.Attribute(Author{User: "bob", RequestID: "r2"}, []string{"T|b"})
+	j.Write(Record{Op: "hset", Key: "T|b"})
+	if r := <-ch; r.Author != (Author{}) {
+		t.Errorf("got author %+v, want none", r.Author)
+	}
+	j.Write(Record{Op: "hset", Key: "T|bb"})
+	if r := <-ch; r.User != "admin" {
+		t.Errorf("got author %+v, want admin", r.Author)
+	}
+	done()
+	done2()
+
+	// Requests without keys get every change, marked as inferred
+	done3 := j.Attribute(Author{User: "dave", RequestID: "r3"}, nil)
+	j.Write(Record{Op: "hset", Key: "PORT|Ethernet0"})
+	if r := <-ch; r.User != "dave" || r.Attribution != AttributionInferred {
+		t.Errorf("got author %+v, want dave inferred", r.Author)
+	}
+	done3()
+
+	// Explicit authors are kept
+	j.Write(Record{Op: "hset", Key: "T|c", Author: Author{User: "carol"}})
+	if r := <-ch; r.User != "carol" {
+		t.Errorf("got author %+v, want carol", r.Author)
+	}
+
+	var a authors
+	end := a.begin(Author{User: "admin"}, []string{"T|a"}, time.Now())
+	end()
+	if got := a.at(time.Now().Add(time.Second), "T|a"); got.User != "admin" {
+		t.Errorf("change within grace got %+v, want admin", got)
+	}
+	if got := a.at(time.Now().Add(authorGrace+time.Second), "T|a"); got != (Author{}) {
+		t.Errorf("change after grace got %+v, want none", got)
+	}
+	if len(a.pending) != 0 {
+		t.Errorf("ended request not pruned")
+	}
+
+	// A nil journal ignores attributions
+	var nilJournal *Journal
+	nilJournal.Attribute(Author{User: "admin"}, nil)()
+}
+
+func TestDefault(t *testing.T) {
+	if Default() != nil {
+		t.Fatal("journal enabled by default")
+	}
+	j := openJournal(t, Options{})
+	SetDefault(j)
+	defer SetDefault(nil)
+	if Default() != j {
+		t.Error("Default did not return the journal set")
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	q, err := ParseQuery(map[string]string{"since": "2024-05-01T00:00:00Z", "until": "1714521600000000000", "key": "PORT|", "user": "admin", "limit": "10"})
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	want := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	if !q.Since.Equal(want) || !q.Until.Equal(want) || q.Key != "PORT|" || q.User != "admin" || q.Limit != 10 {
+		t.Errorf("got %+v", q)
+	}
+
+	q, err = ParseQuery(map[string]string{"since": "1h"})
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	if ago := time.Since(q.Since); ago < time.Hour || ago > time.Hour+time.Minute {
+		t.Errorf("since 1h is %v ago", ago)
+	}
+
+	for _, keys := range []map[string]string{
+		{"since": "yesterday"},
+		{"limit": "-1"},
+		{"limit": "ten"},
+		{"path": "x"},
+	} {
+		if _, err := ParseQuery(keys); err == nil {
+			t.Errorf("ParseQuery(%v) succeeded, want error", keys)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package configjournal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Point is a point of the journal to roll back to: the record numbered Seq,
+// or when Seq is 0 the time Time.
+type Point struct {
+	Time time.Time
+	Seq  uint64
+}
+
+// after reports whether r was written after p.
+func (p Point) after(r Record) bool {
+	if p.Seq != 0 {
+		return r.Seq > p.Seq
+	}
+	return r.Time.After(p.Time)
+}
+
+// PatchOp is an operation of a JSON patch (RFC 6902) of CONFIG_DB, whose
+// paths are /<table>/<key> and /<table>/<key>/<field>.
+type PatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// After returns the records written after p, oldest first. It fails when
+// the journal no longer holds all of them, because older records were
+// rotated out of its retention.
+func (j *Journal) After(p Point) ([]Record, error) {
+	if p.Seq == 0 && p.Time.IsZero() {
+		return nil, errors.New("no point in the journal given")
+	}
+	j.mu.Lock()
+	last := j.seq
+	j.mu.Unlock()
+	if p.Seq > last {
+		return nil, fmt.Errorf("journal has no record %d, the last one is %d", p.Seq, last)
+	}
+
+	// The sequence must continue from the last record at or before p
+	kept := p.Seq
+	var records []Record
+	err := j.scan(time.Time{}, func(r Record) {
+		if r.Seq == 0 {
+			return
+		}
+		if p.after(r) {
+			records = append(records, r)
+		} else if p.Seq == 0 {
+			kept = r.Seq
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 && records[0].Seq != kept+1 {
+		return nil, fmt.Errorf("journal no longer holds records %d to %d", kept+1, records[0].Seq-1)
+	}
+	return records, nil
+}
+
+// InversePatch returns the patch undoing records, the records written after
+// a point oldest first, given current which reads the current fields of a
+// key. Each key changed after the point gets back the fields it had before
+// its first change. Keys without table, and string keys set with SET, are
+// left alone.
+func InversePatch(records []Record, current func(key string) (map[string]string, error)) ([]PatchOp, error) {
+	target := make(map[string]map[string]string)
+	for _, r := range records {
+		if _, ok := target[r.Key]; ok || r.Op == "set" || keyPath(r.Key) == "" {
+			continue
+		}
+		target[r.Key] = r.Before
+	}
+	keys := make([]string, 0, len(target))
+	for key := range target {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var patch []PatchOp
+	for _, key := range keys {
+		want := target[key]
+		have, err := current(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", key, err)
+		}
+		path := keyPath(key)
+		switch {
+		case len(want) == 0 && len(have) == 0:
+		case len(want) == 0:
+			patch = append(patch, PatchOp{Op: "remove", Path: path})
+		case len(have) == 0:
+			patch = append(patch, PatchOp{Op: "add", Path: path, Value: configDbEntry(want)})
+		default:
+			patch = append(patch, fieldPatch(path, configDbEntry(want), configDbEntry(have))...)
+		}
+	}
+	return patch, nil
+}
+
+// configDbEntry returns the ConfigDB JSON of the Redis fields of an entry,
+// as MixedDbClient renders them: list fields such as ports@ become the list
+// ports, and the NULL placeholder field is dropped.
+func configDbEntry(fields map[string]string) map[string]interface{} {
+	entry := make(map[string]interface{}, len(fields))
+	for field, value := range fields {
+		if field == "NULL" {
+			continue
+		} else if name := strings.TrimSuffix(field, "@"); name != field {
+			entry[name] = strings.Split(value, ",")
+		} else {
+			entry[field] = value
+		}
+	}
+	return entry
+}
+
+// fieldPatch returns the operations turning the fields have of the entry at
+// path into want.
+func fieldPatch(path string, want, have map[string]interface{}) []PatchOp {
+	fields := make([]string, 0, len(want)+len(have))
+	for field := range want {
+		fields = append(fields, field)
+	}
+	for field := range have {
+		if _, ok := want[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	var patch []PatchOp
+	for _, field := range fields {
+		fieldPath := path + "/" + escapePointer(field)
+		wantValue, wanted := want[field]
+		haveValue, had := have[field]
+		switch {
+		case !wanted:
+			patch = append(patch, PatchOp{Op: "remove", Path: fieldPath})
+		case !had:
+			patch = append(patch, PatchOp{Op: "add", Path: fieldPath, Value: wantValue})
+		case !reflect.DeepEqual(wantValue, haveValue):
+			patch = append(patch, PatchOp{Op: "replace", Path: fieldPath, Value: wantValue})
+		}
+	}
+	return patch
+}
+
+// keyPath returns the JSON pointer of a CONFIG_DB key such as
+// INTERFACE|Ethernet0|10.0.0.1/31, or "" when it has no table.
+func keyPath(key string) string {
+	table, name, ok := strings.Cut(key, "|")
+	if !ok || table == "" || name == "" {
+		return ""
+	}
+	return "/" + escapePointer(table) + "/" + escapePointer(name)
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapePointer(s string) string {
+	return pointerEscaper.Replace(s)
+}
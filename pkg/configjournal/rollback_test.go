@@ -0,0 +1,124 @@
+package configjournal
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal_After(t *testing.T) {
+	j := openJournal(t, Options{})
+	base := time.Now()
+	for i, key := range []string{"T|a", "T|b", "T|c"} {
+		if err := j.Write(Record{Time: base.Add(time.Duration(i) * time.Second), Op: "hset", Key: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys := func(records []Record) string {
+		s := ""
+		for _, r := range records {
+			s += r.Key + ","
+		}
+		return s
+	}
+	tests := []struct {
+		desc    string
+		point   Point
+		want    string
+		wantErr bool
+	}{
+		{"Seq", Point{Seq: 1}, "T|b,T|c,", false},
+		{"LastSeq", Point{Seq: 3}, "", false},
+		{"Time", Point{Time: base.Add(time.Second)}, "T|c,", false},
+		{"TimeBeforeAll", Point{Time: base.Add(-time.Hour)}, "T|a,T|b,T|c,", false},
+		{"SeqBeyondJournal", Point{Seq: 4}, "", true},
+		{"NoPoint", Point{}, "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			records, err := j.After(test.point)
+			if test.wantErr != (err != nil) {
+				t.Fatalf("After(%+v) error = %v, wantErr %v", test.point, err, test.wantErr)
+			}
+			if got := keys(records); got != test.want {
+				t.Errorf("After(%+v) = %s, want %s", test.point, got, test.want)
+			}
+		})
+	}
+}
+
+func TestJournal_AfterRotatedOut(t *testing.T) {
+	dir := t.TempDir()
+	j := openJournal(t, Options{Path: filepath.Join(dir, "config_db.txt"), MaxSize: 100, MaxBackups: 1})
+	for i := 0; i < 5; i++ {
+		j.Write(Record{Op: "hset", Key: "T|a", After: map[string]string{"f": "0123456789012345678901234567890123456789"}})
+		time.Sleep(2 * time.Millisecond)
+	}
+	if _, err := j.After(Point{Seq: 1}); err == nil {
+		t.Error("expected error for records rotated out of the journal")
+	}
+	if _, err := j.After(Point{Seq: 4}); err != nil {
+		t.Errorf("After(4) failed: %v", err)
+	}
+
+	// Numbering continues after a restart
+	j.Close()
+	j = openJournal(t, Options{Path: filepath.Join(dir, "config_db.txt"), MaxSize: 100, MaxBackups: 1})
+	ch, cancel := j.Subscribe()
+	defer cancel()
+	j.Write(Record{Op: "hset", Key: "T|b"})
+	if r := <-ch; r.Seq != 6 {
+		t.Errorf("record numbered %d after restart, want 6", r.Seq)
+	}
+}
+
+func TestInversePatch(t *testing.T) {
+	records := []Record{
+		// Changed twice, back to the fields before the first change
+		{Op: "hset", Key: "PORT|Ethernet0", Before: map[string]string{"mtu": "9100", "speed": "100000"}, After: map[string]string{"mtu": "1500", "speed": "100000"}},
+		{Op: "hset", Key: "PORT|Ethernet0", Before: map[string]string{"mtu": "1500", "speed": "100000"}, After: map[string]string{"mtu": "1500", "speed": "100000", "fec": "rs"}},
+		// Created
+		{Op: "hset", Key: "VLAN|Vlan10", After: map[string]string{"vlanid": "10"}},
+		// Deleted
+		{Op: "del", Key: "INTERFACE|Ethernet0|10.0.0.1/31", Before: map[string]string{"NULL": "NULL"}},
+		// List fields are ConfigDB lists
+		{Op: "hset", Key: "PORTCHANNEL|PortChannel1", Before: map[string]string{"members@": "Ethernet0,Ethernet4", "mtu": "9100"}, After: map[string]string{"members@": "Ethernet0", "mtu": "9100"}},
+		{Op: "del", Key: "VLAN|Vlan30", Before: map[string]string{"vlanid": "30", "dhcp_servers@": "10.0.0.1,10.0.0.2"}},
+		// Created then deleted
+		{Op: "hset", Key: "VLAN|Vlan20", After: map[string]string{"vlanid": "20"}},
+		{Op: "del", Key: "VLAN|Vlan20", Before: map[string]string{"vlanid": "20"}},
+		// Ignored
+		{Op: "set", Key: "STRING|key"},
+		{Op: "hset", Key: "NO_TABLE", After: map[string]string{"f": "v"}},
+	}
+	db := map[string]map[string]string{
+		"PORT|Ethernet0":           {"mtu": "1500", "speed": "100000", "fec": "rs"},
+		"VLAN|Vlan10":              {"vlanid": "10"},
+		"PORTCHANNEL|PortChannel1": {"members@": "Ethernet0", "mtu": "9100"},
+	}
+	patch, err := InversePatch(records, func(key string) (map[string]string, error) {
+		return db[key], nil
+	})
+	if err != nil {
+		t.Fatalf("InversePatch failed: %v", err)
+	}
+	got, _ := json.Marshal(patch)
+	want := `[{"op":"add","path":"/INTERFACE/Ethernet0|10.0.0.1~131","value":{}},` +
+		`{"op":"replace","path":"/PORTCHANNEL/PortChannel1/members","value":["Ethernet0","Ethernet4"]},` +
+		`{"op":"remove","path":"/PORT/Ethernet0/fec"},` +
+		`{"op":"replace","path":"/PORT/Ethernet0/mtu","value":"9100"},` +
+		`{"op":"remove","path":"/VLAN/Vlan10"},` +
+		`{"op":"add","path":"/VLAN/Vlan30","value":{"dhcp_servers":["10.0.0.1","10.0.0.2"],"vlanid":"30"}}]`
+	if string(got) != want {
+		t.Errorf("got patch %s\nwant %s", got, want)
+	}
+
+	if _, err := InversePatch(records, func(string) (map[string]string, error) {
+		return nil, errors.New("redis down")
+	}); err == nil {
+		t.Error("expected error when the current fields cannot be read")
+	}
+}
@@ -0,0 +1,142 @@
+package interceptors
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sonic-net/sonic-gnmi/pkg/audit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// readOnlyMethods are the RPCs that do not change the state of the device.
+// Every other RPC is write-class and audited, so new services are audited
+// unless listed here.
+var readOnlyMethods = map[string]bool{
+	"/gnmi.gNMI/Capabilities":                          true,
+	"/gnmi.gNMI/Get":                                   true,
+	"/gnmi.gNMI/Subscribe":                             true,
+	"/gnoi.system.System/Ping":                         true,
+	"/gnoi.system.System/RebootStatus":                 true,
+	"/gnoi.system.System/Time":                         true,
+	"/gnoi.system.System/Traceroute":                   true,
+	"/gnoi.file.File/Get":                              true,
+	"/gnoi.file.File/Stat":                             true,
+	"/gnoi.os.OS/Verify":                               true,
+	"/gnoi.healthz.Healthz/Artifact":                   true,
+	"/gnoi.healthz.Healthz/Get":                        true,
+	"/gnoi.healthz.Healthz/List":                       true,
+	"/gnoi.containerz.Containerz/List":                 true,
+	"/gnoi.containerz.Containerz/Log":                  true,
+	"/gnoi.sonic.Debug/GetSubscribePreferences":        true,
+	"/gnoi.sonic_admin.SonicAdminService/ListSessions": true,
+	"/gnoi.sonic_jwt.SonicJwtService/Authenticate":     true,
+	"/gnoi.sonic_jwt.SonicJwtService/Refresh":          true,
+	"/gnsi.authz.v1.Authz/Get":                         true,
+	"/gnsi.authz.v1.Authz/Probe":                       true,
+	"/gnsi.certz.v1.Certz/CanGenerateCSR":              true,
+	"/gnsi.certz.v1.Certz/GetIntegrityManifest":        true,
+	"/gnsi.certz.v1.Certz/GetProfileList":              true,
+	"/gnsi.pathz.v1.Pathz/Get":                         true,
+	"/gnsi.pathz.v1.Pathz/Probe":                       true,
+}
+
+// readOnlyServicePrefixes are whole services that never change the device.
+var readOnlyServicePrefixes = []string{
+	"/grpc.reflection.",
+	"/grpc.health.",
+}
+
+// IsWriteMethod reports whether the RPC of fullMethod is write-class.
+func IsWriteMethod(fullMethod string) bool {
+	if readOnlyMethods[fullMethod] {
+		return false
+	}
+	for _, prefix := range readOnlyServicePrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// AuditInterceptor writes one audit record per write-class RPC. Handlers
+// find the record in the context to add the user, paths and bypass flag.
+type AuditInterceptor struct {
+	logger *audit.Logger
+}
+
+// NewAuditInterceptor creates an interceptor writing to logger.
+func NewAuditInterceptor(logger *audit.Logger) *AuditInterceptor {
+	return &AuditInterceptor{logger: logger}
+}
+
+// begin returns a record for the RPC if it needs auditing, and the start
+// time of the RPC. Unlike the UTC time of the record, the start time keeps
+// the monotonic clock reading, so that the duration ignores clock changes.
+func (a *AuditInterceptor) begin(ctx context.Context, fullMethod string) (*audit.Record, time.Time) {
+	if !a.logger.Enabled() || !IsWriteMethod(fullMethod) {
+		return nil, time.Time{}
+	}
+	start := time.Now()
+	r := &audit.Record{Time: start.UTC(), RPC: fullMethod}
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		r.Peer = pr.Addr.String()
+	}
+	return r, start
+}
+
+func (a *AuditInterceptor) end(r *audit.Record, start time.Time, err error) {
+	if r.Op == "" {
+		r.Op = path.Base(r.RPC)
+	}
+	r.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	r.Code = status.Code(err).String()
+	if err != nil {
+		r.Error = status.Convert(err).Message()
+	}
+	a.logger.Log(r)
+}
+
+// UnaryInterceptor returns a grpc.UnaryServerInterceptor for unary RPCs.
+func (a *AuditInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		r, start := a.begin(ctx, info.FullMethod)
+		if r == nil {
+			return handler(ctx, req)
+		}
+		resp, err := handler(audit.NewContext(ctx, r), req)
+		a.end(r, start, err)
+		return resp, err
+	}
+}
+
+// StreamInterceptor returns a grpc.StreamServerInterceptor for streaming RPCs.
+func (a *AuditInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := context.Background()
+		if ss != nil {
+			ctx = ss.Context()
+		}
+		r, start := a.begin(ctx, info.FullMethod)
+		if r == nil {
+			return handler(srv, ss)
+		}
+		err := handler(srv, &auditStream{ServerStream: ss, ctx: audit.NewContext(ctx, r)})
+		a.end(r, start, err)
+		return err
+	}
+}
+
+// auditStream carries the audit record in its context.
+type auditStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (as *auditStream) Context() context.Context {
+	return as.ctx
+}
@@ -0,0 +1,137 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/sonic-net/sonic-gnmi/pkg/audit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type recordSink struct {
+	records []map[string]interface{}
+}
+
+func (r *recordSink) Write(record []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(record, &m); err != nil {
+		return err
+	}
+	r.records = append(r.records, m)
+	return nil
+}
+
+func (r *recordSink) Close() error {
+	return nil
+}
+
+func newTestAuditLogger() (*audit.Logger, *recordSink) {
+	logger := audit.NewLogger()
+	sink := &recordSink{}
+	logger.AddSink(sink)
+	return logger, sink
+}
+
+func TestIsWriteMethod(t *testing.T) {
+	for method, want := range map[string]bool{
+		"/gnmi.gNMI/Set":                                   true,
+		"/gnmi.gNMI/Get":                                   false,
+		"/gnmi.gNMI/Subscribe":                             false,
+		"/gnoi.system.System/Reboot":                       true,
+		"/gnoi.file.File/Put":                              true,
+		"/gnoi.file.File/Stat":                             false,
+		"/grpc.health.v1.Health/Check":                     false,
+		"/some.new.Service/Method":                         true,
+		"/gnsi.certz.v1.Certz/Rotate":                      true,
+		"/gnoi.sonic_admin.SonicAdminService/ListSessions": false,
+	} {
+		if got := IsWriteMethod(method); got != want {
+			t.Errorf("IsWriteMethod(%s): expected %v, got %v", method, want, got)
+		}
+	}
+}
+
+func TestAuditInterceptor_Unary(t *testing.T) {
+	logger, sink := newTestAuditLogger()
+	interceptor := NewAuditInterceptor(logger).UnaryInterceptor()
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		r := audit.FromContext(ctx)
+		r.SetUser("admin", []string{"gnmi_readwrite"})
+		r.AddPaths("update", "/CONFIG_DB/PORT/Ethernet0")
+		return nil, status.Error(codes.PermissionDenied, "denied")
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/gnmi.gNMI/Set"}
+	if _, err := interceptor(ctx, nil, info, handler); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected handler error to pass through, got %v", err)
+	}
+	if len(sink.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(sink.records))
+	}
+	for field, want := range map[string]interface{}{
+		"user":  "admin",
+		"peer":  "10.0.0.1:5000",
+		"rpc":   "/gnmi.gNMI/Set",
+		"op":    "update",
+		"code":  "PermissionDenied",
+		"error": "denied",
+	} {
+		if got := sink.records[0][field]; got != want {
+			t.Errorf("Field %s: expected %v, got %v", field, want, got)
+		}
+	}
+
+	// Read-only RPCs are not audited
+	info = &grpc.UnaryServerInfo{FullMethod: "/gnmi.gNMI/Get"}
+	handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+		if audit.FromContext(ctx) != nil {
+			t.Error("Expected no record for a read-only RPC")
+		}
+		return nil, nil
+	}
+	interceptor(ctx, nil, info, handler)
+	if len(sink.records) != 1 {
+		t.Errorf("Expected no record for a read-only RPC, got %d records", len(sink.records))
+	}
+}
+
+func TestAuditInterceptor_Stream(t *testing.T) {
+	logger, sink := newTestAuditLogger()
+	interceptor := NewAuditInterceptor(logger).StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/gnoi.file.File/Put"}
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		if audit.FromContext(stream.Context()) == nil {
+			t.Error("Expected record in stream context")
+		}
+		return nil
+	}
+	if err := interceptor(nil, ss, info, handler); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if len(sink.records) != 1 || sink.records[0]["op"] != "Put" || sink.records[0]["code"] != "OK" {
+		t.Errorf("Unexpected records: %v", sink.records)
+	}
+}
+
+func TestAuditInterceptor_Disabled(t *testing.T) {
+	interceptor := NewAuditInterceptor(audit.NewLogger()).UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/gnmi.gNMI/Set"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if audit.FromContext(ctx) != nil {
+			t.Error("Expected no record without sinks")
+		}
+		return "response", nil
+	}
+	if resp, _ := interceptor(context.Background(), nil, info, handler); resp != "response" {
+		t.Errorf("Expected handler response to pass through, got %v", resp)
+	}
+}
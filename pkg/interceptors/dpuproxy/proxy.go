@@ -10,6 +10,7 @@ import (
 	"github.com/golang/glog"
 	gnoi_file_pb "github.com/openconfig/gnoi/file"
 	system "github.com/openconfig/gnoi/system"
+	"github.com/sonic-net/sonic-gnmi/pkg/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
@@ -240,8 +241,8 @@ func (p *DPUProxy) forwardTimeRequest(ctx context.Context, conn *grpc.ClientConn
 	// Create System client
 	client := system.NewSystemClient(conn)
 
-	// Forward the request to DPU
-	resp, err := client.Time(ctx, timeReq)
+	// Forward the request to DPU, as part of the trace of the request
+	resp, err := client.Time(tracing.Inject(ctx), timeReq)
 	if err != nil {
 		glog.Errorf("[DPUProxy] Error forwarding Time request to DPU: %v", err)
 		return nil, err
@@ -254,6 +255,9 @@ func (p *DPUProxy) forwardTimeRequest(ctx context.Context, conn *grpc.ClientConn
 // forwardStream forwards a streaming RPC to the DPU.
 // This implements bidirectional streaming proxy between client and DPU.
 func (p *DPUProxy) forwardStream(ctx context.Context, conn *grpc.ClientConn, ss grpc.ServerStream, info *grpc.StreamServerInfo) error {
+	// Let the DPU continue the trace of the request
+	ctx = tracing.Inject(ctx)
+
 	// For File.Put, we need to handle the streaming RPC
 	if info.FullMethod == "/gnoi.file.File/Put" {
 		return p.forwardFilePutStream(ctx, conn, ss)
@@ -0,0 +1,51 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/sonic-net/sonic-gnmi/pkg/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsInterceptor records the count, status code and latency of every RPC.
+type MetricsInterceptor struct {
+	requests *metrics.CounterVec
+	duration *metrics.HistogramVec
+}
+
+// NewMetricsInterceptor creates an interceptor recording into the server
+// metrics of the default registry.
+func NewMetricsInterceptor() *MetricsInterceptor {
+	return &MetricsInterceptor{
+		requests: metrics.RPCRequests,
+		duration: metrics.RPCDuration,
+	}
+}
+
+func (m *MetricsInterceptor) observe(method string, start time.Time, err error) {
+	m.requests.Inc(method, status.Code(err).String())
+	m.duration.Observe(time.Since(start).Seconds(), method)
+}
+
+// UnaryInterceptor returns a grpc.UnaryServerInterceptor for unary RPCs.
+func (m *MetricsInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamInterceptor returns a grpc.StreamServerInterceptor for streaming RPCs.
+// The latency of a stream is its whole lifetime.
+func (m *MetricsInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observe(info.FullMethod, start, err)
+		return err
+	}
+}
@@ -0,0 +1,85 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sonic-net/sonic-gnmi/pkg/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestMetricsInterceptor() (*MetricsInterceptor, *metrics.Registry) {
+	registry := metrics.NewRegistry()
+	registry.Enable()
+	return &MetricsInterceptor{
+		requests: registry.NewCounterVec("rpc_total", "RPCs.", "method", "code"),
+		duration: registry.NewHistogramVec("rpc_seconds", "RPC latency.", metrics.DefaultBuckets, "method"),
+	}, registry
+}
+
+func renderRegistry(t *testing.T, registry *metrics.Registry) string {
+	var buf bytes.Buffer
+	if err := registry.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestMetricsInterceptor_Unary(t *testing.T) {
+	m, registry := newTestMetricsInterceptor()
+	interceptor := m.UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/gnmi.gNMI/Get"}
+
+	okHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+	errHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	}
+
+	resp, err := interceptor(context.Background(), "request", info, okHandler)
+	if err != nil || resp != "response" {
+		t.Errorf("Expected handler response to pass through, got %v, %v", resp, err)
+	}
+	if _, err := interceptor(context.Background(), "request", info, errHandler); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound to pass through, got %v", err)
+	}
+
+	out := renderRegistry(t, registry)
+	for _, want := range []string{
+		`rpc_total{method="/gnmi.gNMI/Get",code="OK"} 1`,
+		`rpc_total{method="/gnmi.gNMI/Get",code="NotFound"} 1`,
+		`rpc_seconds_count{method="/gnmi.gNMI/Get"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetricsInterceptor_Stream(t *testing.T) {
+	m, registry := newTestMetricsInterceptor()
+	interceptor := m.StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/gnmi.gNMI/Subscribe"}
+
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		return status.Error(codes.Canceled, "client went away")
+	}
+	if err := interceptor(nil, nil, info, handler); status.Code(err) != codes.Canceled {
+		t.Errorf("Expected Canceled to pass through, got %v", err)
+	}
+
+	out := renderRegistry(t, registry)
+	for _, want := range []string{
+		`rpc_total{method="/gnmi.gNMI/Subscribe",code="Canceled"} 1`,
+		`rpc_seconds_count{method="/gnmi.gNMI/Subscribe"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package interceptors
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/sonic-net/sonic-gnmi/pkg/session"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// SessionInterceptor registers every streaming RPC as a session, counting the
+// messages sent and errors seen. Handlers find the session in the stream
+// context to record the authenticated user and what is streamed. Killing a
+// session cancels its stream context, and new streams are refused while the
+// registry drains. A session killed by a drain ends with Unavailable and,
+// when the registry has one, a retry-after trailer giving the seconds to wait
+// before reconnecting. Unary RPCs are short-lived and are not tracked.
+type SessionInterceptor struct {
+	registry *session.Registry
+}
+
+// NewSessionInterceptor creates an interceptor tracking sessions in registry.
+func NewSessionInterceptor(registry *session.Registry) *SessionInterceptor {
+	return &SessionInterceptor{registry: registry}
+}
+
+// UnaryInterceptor returns a pass-through grpc.UnaryServerInterceptor.
+func (si *SessionInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor returns a grpc.StreamServerInterceptor for streaming RPCs.
+func (si *SessionInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := context.Background()
+		if ss != nil {
+			ctx = ss.Context()
+		}
+		peerAddr := ""
+		if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+			peerAddr = pr.Addr.String()
+		}
+
+		if si.registry.Draining() {
+			return status.Error(codes.Unavailable, "server is draining, not accepting new sessions")
+		}
+
+		s := si.registry.Start(peerAddr, info.FullMethod)
+		defer si.registry.End(s)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		s.OnKill(cancel)
+
+		err := handler(srv, &sessionStream{ServerStream: ss, ctx: session.NewContext(ctx, s), session: s})
+		if s.Killed() && si.registry.Draining() {
+			err = si.drained(ss)
+		}
+		if err != nil {
+			s.IncErrors()
+		}
+		return err
+	}
+}
+
+// retryAfterTrailer is the trailer telling the peer of a drained session how
+// many seconds to wait before reconnecting.
+const retryAfterTrailer = "retry-after"
+
+// drained returns the status ending a session killed by a drain.
+func (si *SessionInterceptor) drained(ss grpc.ServerStream) error {
+	if d := si.registry.RetryAfter(); d > 0 && ss != nil {
+		seconds := int64((d + time.Second - 1) / time.Second)
+		ss.SetTrailer(metadata.Pairs(retryAfterTrailer, strconv.FormatInt(seconds, 10)))
+	}
+	return status.Error(codes.Unavailable, "server is shutting down, reconnect later")
+}
+
+// sessionStream carries the session in its context and counts sent messages.
+type sessionStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	session *session.Session
+}
+
+func (ss *sessionStream) Context() context.Context {
+	return ss.ctx
+}
+
+func (ss *sessionStream) SendMsg(m interface{}) error {
+	if err := ss.ServerStream.SendMsg(m); err != nil {
+		ss.session.IncErrors()
+		return err
+	}
+	ss.session.IncSent()
+	return nil
+}
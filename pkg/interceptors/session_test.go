@@ -0,0 +1,160 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sonic-net/sonic-gnmi/pkg/session"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	sendErr error
+	trailer metadata.MD
+}
+
+func (f *fakeServerStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeServerStream) SendMsg(m interface{}) error {
+	return f.sendErr
+}
+
+func (f *fakeServerStream) SetTrailer(md metadata.MD) {
+	f.trailer = metadata.Join(f.trailer, md)
+}
+
+func TestSessionInterceptor_Stream(t *testing.T) {
+	registry := session.NewRegistry()
+	interceptor := NewSessionInterceptor(registry).StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/gnmi.gNMI/Subscribe"}
+
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}
+	ss := &fakeServerStream{ctx: peer.NewContext(context.Background(), &peer.Peer{Addr: addr})}
+
+	var seen session.Info
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		s := session.FromContext(stream.Context())
+		if s == nil {
+			t.Fatal("Expected session in stream context")
+		}
+		s.SetUser("admin", []string{"gnmi_readonly"})
+		stream.SendMsg("one")
+		stream.SendMsg("two")
+		seen = registry.List()[0]
+		return errors.New("stream closed")
+	}
+
+	if err := interceptor(nil, ss, info, handler); err == nil {
+		t.Error("Expected handler error to pass through")
+	}
+	if seen.Peer != "10.0.0.1:5000" || seen.Method != "/gnmi.gNMI/Subscribe" || seen.User != "admin" {
+		t.Errorf("Unexpected session: %+v", seen)
+	}
+	if seen.MessagesSent != 2 {
+		t.Errorf("Expected 2 messages sent, got %d", seen.MessagesSent)
+	}
+	if len(registry.List()) != 0 {
+		t.Error("Expected session to end with the RPC")
+	}
+}
+
+func TestSessionInterceptor_SendError(t *testing.T) {
+	registry := session.NewRegistry()
+	interceptor := NewSessionInterceptor(registry).StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/gnoi.file.File/Get"}
+	ss := &fakeServerStream{ctx: context.Background(), sendErr: errors.New("broken pipe")}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		stream.SendMsg("chunk")
+		got := registry.List()[0]
+		if got.MessagesSent != 0 || got.Errors != 1 {
+			t.Errorf("Expected 0 sent and 1 error, got %+v", got)
+		}
+		return nil
+	}
+	if err := interceptor(nil, ss, info, handler); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestSessionInterceptor_UnaryNotTracked(t *testing.T) {
+	registry := session.NewRegistry()
+	interceptor := NewSessionInterceptor(registry).UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/gnmi.gNMI/Get"}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if session.FromContext(ctx) != nil || len(registry.List()) != 0 {
+			t.Error("Expected unary RPCs not to be tracked")
+		}
+		return "response", nil
+	}
+	if resp, err := interceptor(context.Background(), "request", info, handler); resp != "response" || err != nil {
+		t.Errorf("Expected handler response to pass through, got %v, %v", resp, err)
+	}
+}
+
+func TestSessionInterceptor_KillAndDrain(t *testing.T) {
+	registry := session.NewRegistry()
+	interceptor := NewSessionInterceptor(registry).StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/gnmi.gNMI/Subscribe"}
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		session.FromContext(stream.Context()).Kill()
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	}
+	if err := interceptor(nil, ss, info, handler); err != context.Canceled {
+		t.Errorf("Expected killed session to cancel the stream context, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	registry.Drain(ctx)
+	called := false
+	handler = func(srv interface{}, stream grpc.ServerStream) error {
+		called = true
+		return nil
+	}
+	if err := interceptor(nil, ss, info, handler); status.Code(err) != codes.Unavailable || called {
+		t.Errorf("Expected Unavailable while draining, got %v", err)
+	}
+}
+
+func TestSessionInterceptor_DrainNotify(t *testing.T) {
+	registry := session.NewRegistry()
+	registry.SetRetryAfter(1500 * time.Millisecond)
+	interceptor := NewSessionInterceptor(registry).StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/gnmi.gNMI/Subscribe"}
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	started := make(chan struct{})
+	result := make(chan error)
+	go func() {
+		result <- interceptor(nil, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+			close(started)
+			<-stream.Context().Done()
+			return stream.Context().Err()
+		})
+	}()
+	<-started
+	registry.DrainNotify(context.Background(), func(session.Info) bool { return true })
+
+	if err := <-result; status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected Unavailable for a session ended by a drain, got %v", err)
+	}
+	if got := ss.trailer.Get("retry-after"); len(got) != 1 || got[0] != "2" {
+		t.Errorf("Expected retry-after trailer rounded up to 2 seconds, got %v", got)
+	}
+}
@@ -1,7 +1,10 @@
 package interceptors
 
 import (
+	"github.com/sonic-net/sonic-gnmi/pkg/audit"
 	"github.com/sonic-net/sonic-gnmi/pkg/interceptors/dpuproxy"
+	"github.com/sonic-net/sonic-gnmi/pkg/session"
+	"github.com/sonic-net/sonic-gnmi/pkg/tracing"
 	"google.golang.org/grpc"
 )
 
@@ -12,7 +15,9 @@ type ServerChain struct {
 }
 
 // NewServerChain creates a complete interceptor chain for the gNMI server.
-// Currently includes DPU proxy interceptor with Redis-based DPU resolution.
+// Currently includes the tracing, metrics, session and audit interceptors, so
+// that proxied RPCs are traced, measured, listed and audited as well, followed
+// by DPU proxy interceptor with Redis-based DPU resolution.
 // Returns the chain and a cleanup function that must be called during shutdown.
 func NewServerChain() (*ServerChain, error) {
 	// Create Redis clients for DPU info resolution from both StateDB and ConfigDB
@@ -27,8 +32,9 @@ func NewServerChain() (*ServerChain, error) {
 	dpuProxy := dpuproxy.NewDPUProxy(dpuResolver)
 	dpuproxy.SetDefaultProxy(dpuProxy)
 
-	// Create interceptor chain with DPU proxy
-	chain := NewChain(dpuProxy)
+	// Create interceptor chain with tracing, metrics, sessions, audit and DPU proxy
+	chain := NewChain(NewTracingInterceptor(tracing.Default), NewMetricsInterceptor(),
+		NewSessionInterceptor(session.Default), NewAuditInterceptor(audit.Default), dpuProxy)
 
 	// Create cleanup function to close Redis clients
 	cleanup := func() error {
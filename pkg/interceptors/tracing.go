@@ -0,0 +1,88 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sonic-net/sonic-gnmi/pkg/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// TracingInterceptor starts a server span per RPC, continuing the trace of
+// the caller when its metadata carries one. Handlers find the span in the
+// context and start child spans from it.
+type TracingInterceptor struct {
+	tracer *tracing.Tracer
+}
+
+// NewTracingInterceptor creates an interceptor starting spans with tracer.
+func NewTracingInterceptor(tracer *tracing.Tracer) *TracingInterceptor {
+	return &TracingInterceptor{tracer: tracer}
+}
+
+// start returns the context and span of an RPC, or a nil span when tracing
+// is disabled.
+func (t *TracingInterceptor) start(ctx context.Context, fullMethod string) (context.Context, *tracing.Span) {
+	if !t.tracer.Enabled() {
+		return ctx, nil
+	}
+	ctx, span := t.tracer.StartServer(tracing.Extract(ctx), fullMethod)
+	span.SetAttribute("rpc.system", "grpc")
+	if service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/"); ok {
+		span.SetAttribute("rpc.service", service)
+		span.SetAttribute("rpc.method", method)
+	}
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		span.SetAttribute("client.address", pr.Addr.String())
+	}
+	return ctx, span
+}
+
+func (t *TracingInterceptor) end(span *tracing.Span, err error) {
+	span.SetAttribute("rpc.grpc.status_code", int64(status.Code(err)))
+	span.RecordError(err)
+	span.End()
+}
+
+// UnaryInterceptor returns a grpc.UnaryServerInterceptor for unary RPCs.
+func (t *TracingInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := t.start(ctx, info.FullMethod)
+		if span == nil {
+			return handler(ctx, req)
+		}
+		resp, err := handler(ctx, req)
+		t.end(span, err)
+		return resp, err
+	}
+}
+
+// StreamInterceptor returns a grpc.StreamServerInterceptor for streaming RPCs.
+// The span of a stream covers its whole lifetime.
+func (t *TracingInterceptor) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := context.Background()
+		if ss != nil {
+			ctx = ss.Context()
+		}
+		ctx, span := t.start(ctx, info.FullMethod)
+		if span == nil {
+			return handler(srv, ss)
+		}
+		err := handler(srv, &tracingStream{ServerStream: ss, ctx: ctx})
+		t.end(span, err)
+		return err
+	}
+}
+
+// tracingStream carries the span of the RPC in its context.
+type tracingStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (ts *tracingStream) Context() context.Context {
+	return ts.ctx
+}
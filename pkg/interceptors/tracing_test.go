@@ -0,0 +1,125 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/sonic-net/sonic-gnmi/pkg/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type spanExporter struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (e *spanExporter) Export(spans []tracing.SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *spanExporter) Close() error {
+	return nil
+}
+
+func newTestTracer(t *testing.T) (*tracing.Tracer, *spanExporter) {
+	tracer := tracing.NewTracer("test")
+	exporter := &spanExporter{}
+	tracer.SetExporter(exporter)
+	t.Cleanup(func() { tracer.Shutdown() })
+	return tracer, exporter
+}
+
+func spanAttributes(span tracing.SpanData) map[string]interface{} {
+	attrs := make(map[string]interface{})
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+func TestTracingInterceptor_Unary(t *testing.T) {
+	tracer, exporter := newTestTracer(t)
+	interceptor := NewTracingInterceptor(tracer).UnaryInterceptor()
+
+	addr, _ := net.ResolveTCPAddr("tcp", "10.0.0.1:50000")
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/gnmi.gNMI/Set"}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, child := tracing.Start(ctx, "MixedDbClient.Set")
+		if child == nil {
+			t.Error("Expected handler context to carry the RPC span")
+		}
+		child.End()
+		return nil, status.Error(codes.InvalidArgument, "bad path")
+	}
+	if _, err := interceptor(ctx, nil, info, handler); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected handler error, got %v", err)
+	}
+	tracer.Flush()
+
+	if len(exporter.spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(exporter.spans))
+	}
+	child, server := exporter.spans[0], exporter.spans[1]
+	if server.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || server.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("Expected span to continue the trace of the caller, got %+v", server)
+	}
+	if child.ParentSpanID != server.SpanID {
+		t.Errorf("Expected child of the RPC span, got %+v", child)
+	}
+	attrs := spanAttributes(server)
+	if attrs["rpc.service"] != "gnmi.gNMI" || attrs["rpc.method"] != "Set" || attrs["client.address"] != "10.0.0.1:50000" ||
+		attrs["rpc.grpc.status_code"] != int64(codes.InvalidArgument) {
+		t.Errorf("Unexpected attributes: %v", attrs)
+	}
+	if !server.Failed || server.StatusMessage == "" {
+		t.Errorf("Expected failed span, got %+v", server)
+	}
+}
+
+func TestTracingInterceptor_Stream(t *testing.T) {
+	tracer, exporter := newTestTracer(t)
+	interceptor := NewTracingInterceptor(tracer).StreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/gnmi.gNMI/Subscribe"}
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		if tracing.FromContext(stream.Context()) == nil {
+			t.Error("Expected stream context to carry the RPC span")
+		}
+		return nil
+	}
+	if err := interceptor(nil, ss, info, handler); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tracer.Flush()
+
+	if len(exporter.spans) != 1 || exporter.spans[0].ParentSpanID != "" || exporter.spans[0].Failed {
+		t.Fatalf("Expected one root span, got %+v", exporter.spans)
+	}
+}
+
+func TestTracingInterceptor_Disabled(t *testing.T) {
+	interceptor := NewTracingInterceptor(tracing.NewTracer("test")).UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/gnmi.gNMI/Get"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if tracing.FromContext(ctx) != nil {
+			t.Error("Expected no span while tracing is disabled")
+		}
+		return "ok", nil
+	}
+	if resp, err := interceptor(context.Background(), nil, info, handler); resp != "ok" || err != nil {
+		t.Errorf("Expected passthrough, got %v, %v", resp, err)
+	}
+}
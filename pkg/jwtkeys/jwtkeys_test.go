@@ -0,0 +1,202 @@
+package jwtkeys
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+func sign(t *testing.T, k *KeyRing) string {
+	t.Helper()
+	token, err := k.Sign(jwt.StandardClaims{Subject: "admin", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	return token
+}
+
+func valid(k *KeyRing, token string) bool {
+	tkn, err := jwt.ParseWithClaims(token, &jwt.StandardClaims{}, k.Keyfunc)
+	return err == nil && tkn.Valid
+}
+
+func TestEphemeral(t *testing.T) {
+	k, err := NewEphemeral("HS256")
+	if err != nil {
+		t.Fatalf("NewEphemeral failed: %v", err)
+	}
+	token := sign(t, k)
+	if !valid(k, token) {
+		t.Error("Expected token to be valid")
+	}
+	other, _ := NewEphemeral("HS256")
+	if valid(other, token) {
+		t.Error("Expected token to be invalid with another key")
+	}
+	k.Rotate()
+	if valid(k, token) {
+		t.Error("Expected ephemeral key to be dropped by rotation")
+	}
+	if _, err := NewEphemeral("none"); err == nil {
+		t.Error("Expected unknown algorithm to be rejected")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	for _, alg := range []string{"RS256", "ES256"} {
+		dir := filepath.Join(t.TempDir(), "keys")
+		k, err := Open(dir, alg, time.Hour)
+		if err != nil {
+			t.Fatalf("Open %s failed: %v", alg, err)
+		}
+		token := sign(t, k)
+		parsed, _ := jwt.Parse(token, k.Keyfunc)
+		if parsed.Header["kid"] != k.CurrentID() || parsed.Header["alg"] != alg {
+			t.Errorf("Unexpected header %v", parsed.Header)
+		}
+
+		// The keys survive a restart
+		reopened, err := Open(dir, alg, time.Hour)
+		if err != nil || reopened.CurrentID() != k.CurrentID() || !valid(reopened, token) {
+			t.Errorf("Expected %s key to be reloaded, got %v", alg, err)
+		}
+
+		info, err := os.Stat(filepath.Join(dir, k.CurrentID()+".pem"))
+		if err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Expected private key only readable by owner, got %v, %v", info, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, k.CurrentID()+".pub.pem")); err != nil {
+			t.Errorf("Expected public key to be written: %v", err)
+		}
+	}
+
+	if _, err := Open(t.TempDir(), "HS256", time.Hour); err == nil {
+		t.Error("Expected symmetric keys not to be stored")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	k, err := Open(dir, "ES256", time.Hour)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	first := k.CurrentID()
+	oldToken := sign(t, k)
+	if err := k.Rotate(); err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if k.CurrentID() == first {
+		t.Fatal("Expected a new key")
+	}
+	if !valid(k, oldToken) || !valid(k, sign(t, k)) {
+		t.Error("Expected tokens of the previous and current keys to be valid")
+	}
+
+	// Switching algorithm rotates to a key of the new one
+	rsaRing, err := Open(dir, "RS256", time.Hour)
+	if err != nil || !valid(rsaRing, oldToken) {
+		t.Fatalf("Expected previous key to stay valid after switching algorithm, got %v", err)
+	}
+
+	// A token naming a key with another algorithm is refused
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{})
+	forged.Header["kid"] = first
+	forgedToken, _ := forged.SignedString([]byte("secret"))
+	if valid(k, forgedToken) {
+		t.Error("Expected algorithm mismatch to be refused")
+	}
+
+	// Keys retired for longer than the retention are dropped
+	short, err := Open(t.TempDir(), "ES256", 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	token := sign(t, short)
+	retired := short.CurrentID()
+	short.Rotate()
+	if valid(short, token) {
+		t.Error("Expected key retired past the retention to be refused")
+	}
+	time.Sleep(time.Millisecond)
+	short.Rotate()
+	if _, err := os.Stat(filepath.Join(short.dir, retired+".pem")); !os.IsNotExist(err) {
+		t.Errorf("Expected expired key to be deleted, got %v", err)
+	}
+}
+
+func TestRevocationList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revoked.json")
+	r, err := OpenRevocationList(path)
+	if err != nil {
+		t.Fatalf("OpenRevocationList failed: %v", err)
+	}
+	if r.Revoked("abc") {
+		t.Error("Expected empty list")
+	}
+	if err := r.Revoke("abc", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	r.Revoke("expired", time.Now().Add(-time.Minute))
+	if !r.Revoked("abc") {
+		t.Error("Expected token to be revoked")
+	}
+
+	reopened, _ := OpenRevocationList(path)
+	if !reopened.Revoked("abc") {
+		t.Error("Expected revocation to be persisted")
+	}
+
+	// Tokens revoked by editing the file
+	data, _ := json.Marshal(map[string]time.Time{"abc": time.Now().Add(time.Hour), "def": time.Now().Add(time.Hour)})
+	os.WriteFile(path, data, 0600)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(path, future, future)
+	if !r.Revoked("def") {
+		t.Error("Expected edited file to be read")
+	}
+	stored := map[string]time.Time{}
+	r.Revoke("ghi", time.Now().Add(time.Hour))
+	data, _ = os.ReadFile(path)
+	json.Unmarshal(data, &stored)
+	if _, ok := stored["expired"]; ok || len(stored) != 3 {
+		t.Errorf("Expected expired entries to be dropped, got %v", stored)
+	}
+
+	memory := NewRevocationList()
+	memory.Revoke("abc", time.Now().Add(time.Hour))
+	if !memory.Revoked("abc") || memory.Revoked("def") {
+		t.Error("Unexpected in-memory revocations")
+	}
+	if err := memory.Revoke("", time.Now()); err == nil {
+		t.Error("Expected a token without ID to be refused")
+	}
+}
+
+func TestRun(t *testing.T) {
+	k, err := Open(t.TempDir(), "ES256", time.Hour)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	first := k.CurrentID()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		k.Run(ctx, 50*time.Millisecond)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for k.CurrentID() == first && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if k.CurrentID() == first {
+		t.Error("Expected the key to be rotated")
+	}
+}
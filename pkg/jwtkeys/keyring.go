@@ -0,0 +1,357 @@
+// Package jwtkeys manages the keys signing the JWT tokens issued by the gNOI
+// JWT service, and the tokens revoked before they expire.
+//
+// A KeyRing signs with its current key and validates with the current key
+// and the keys it replaced, until those have been retired for longer than the
+// lifetime of the tokens they signed. Every token carries the ID of its key in
+// the kid header. Asymmetric keys can be kept in a directory, so that tokens
+// survive restarts and the public keys can validate them elsewhere.
+package jwtkeys
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	log "github.com/golang/glog"
+)
+
+// indexFile lists the keys of a key directory.
+const indexFile = "keys.json"
+
+// rsaKeyBits is the size of generated RSA keys.
+const rsaKeyBits = 2048
+
+// key is a signing key and the time it stopped signing.
+type key struct {
+	id      string
+	method  jwt.SigningMethod
+	signKey interface{}
+	// verifyKey is the public key, or the secret of HMAC keys.
+	verifyKey interface{}
+	created   time.Time
+	retired   time.Time
+}
+
+// indexEntry describes a key in the index file.
+type indexEntry struct {
+	ID      string    `json:"kid"`
+	Alg     string    `json:"alg"`
+	Created time.Time `json:"created"`
+	Retired time.Time `json:"retired,omitempty"`
+}
+
+// index is the content of the index file.
+type index struct {
+	Current string       `json:"current"`
+	Keys    []indexEntry `json:"keys"`
+}
+
+// KeyRing signs tokens and validates their signature.
+type KeyRing struct {
+	dir    string
+	method jwt.SigningMethod
+	// retain is how long retired keys keep validating tokens.
+	retain time.Duration
+
+	mu      sync.RWMutex
+	current *key
+	retired []*key
+}
+
+// NewEphemeral returns a key ring with a single key held in memory, for
+// example an HS256 secret that is only valid for the life of the process.
+func NewEphemeral(alg string) (*KeyRing, error) {
+	method := jwt.GetSigningMethod(alg)
+	if method == nil {
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", alg)
+	}
+	k := &KeyRing{method: method}
+	if err := k.Rotate(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Open returns the key ring kept in dir, creating dir and a first key if
+// needed. alg is RS256 or ES256; a current key of another algorithm is
+// replaced. Retired keys keep validating tokens for retain.
+func Open(dir, alg string, retain time.Duration) (*KeyRing, error) {
+	method := jwt.GetSigningMethod(alg)
+	switch method {
+	case jwt.SigningMethodRS256, jwt.SigningMethodES256:
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q, expected RS256 or ES256", alg)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	k := &KeyRing{dir: dir, method: method, retain: retain}
+	if err := k.load(); err != nil {
+		return nil, err
+	}
+	if k.current == nil || k.current.method != method {
+		if err := k.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// CurrentID returns the ID of the key signing new tokens.
+func (k *KeyRing) CurrentID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current.id
+}
+
+// Sign returns the signed token of claims, naming the signing key in the kid
+// header.
+func (k *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	current := k.current
+	k.mu.RUnlock()
+	token := jwt.NewWithClaims(current.method, claims)
+	token.Header["kid"] = current.id
+	return token.SignedString(current.signKey)
+}
+
+// Keyfunc returns the key validating token, for jwt.Parse. The token must
+// name a key of the ring that is still valid and use the algorithm of that
+// key.
+func (k *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	candidates := append([]*key{k.current}, k.retired...)
+	for _, c := range candidates {
+		if c.id != id {
+			continue
+		}
+		if c != k.current && time.Since(c.retired) > k.retain {
+			return nil, fmt.Errorf("key %s expired", id)
+		}
+		if token.Method.Alg() != c.method.Alg() {
+			return nil, fmt.Errorf("key %s does not use %s", id, token.Method.Alg())
+		}
+		return c.verifyKey, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", id)
+}
+
+// Rotate makes a new key sign tokens. The previous key keeps validating the
+// tokens it signed for the retention of the ring.
+func (k *KeyRing) Rotate() error {
+	next, err := generate(k.method)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	retired := k.retired
+	if k.current != nil {
+		k.current.retired = next.created
+		retired = append([]*key{k.current}, retired...)
+	}
+	var kept, expired []*key
+	for _, r := range retired {
+		if next.created.Sub(r.retired) > k.retain {
+			expired = append(expired, r)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	if k.dir != "" {
+		if err := k.store(next, kept); err != nil {
+			return err
+		}
+		for _, r := range expired {
+			os.Remove(filepath.Join(k.dir, r.id+".pem"))
+			os.Remove(filepath.Join(k.dir, r.id+".pub.pem"))
+		}
+	}
+	k.current, k.retired = next, kept
+	log.Infof("JWT signing key is now %s (%s)", next.id, next.method.Alg())
+	return nil
+}
+
+// Run rotates the keys until ctx is done, whenever the current key is older
+// than interval. The age of a key loaded from disk counts, so restarts do not
+// postpone rotations.
+func (k *KeyRing) Run(ctx context.Context, interval time.Duration) {
+	for {
+		k.mu.RLock()
+		wait := interval - time.Since(k.current.created)
+		k.mu.RUnlock()
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		if err := k.Rotate(); err != nil {
+			log.Errorf("Failed to rotate JWT signing key: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Minute):
+			}
+		}
+	}
+}
+
+// generate creates a key for method, identified by the hash of its public
+// key or secret.
+func generate(method jwt.SigningMethod) (*key, error) {
+	k := &key{method: method, created: time.Now()}
+	var public []byte
+	switch method {
+	case jwt.SigningMethodRS256:
+		private, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		k.signKey, k.verifyKey = private, &private.PublicKey
+		public = x509.MarshalPKCS1PublicKey(&private.PublicKey)
+	case jwt.SigningMethodES256:
+		private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		k.signKey, k.verifyKey = private, &private.PublicKey
+		public = elliptic.Marshal(elliptic.P256(), private.PublicKey.X, private.PublicKey.Y)
+	case jwt.SigningMethodHS256:
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+		k.signKey, k.verifyKey = secret, secret
+		public = secret
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", method.Alg())
+	}
+	sum := sha256.Sum256(public)
+	k.id = hex.EncodeToString(sum[:8])
+	return k, nil
+}
+
+// store writes next and the index of the ring to the key directory. The
+// private key is only readable by the owner, the public key by everyone.
+func (k *KeyRing) store(next *key, retired []*key) error {
+	private, err := x509.MarshalPKCS8PrivateKey(next.signKey)
+	if err != nil {
+		return err
+	}
+	public, err := x509.MarshalPKIXPublicKey(next.verifyKey)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(k.dir, next.id+".pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private}), 0600); err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(k.dir, next.id+".pub.pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}), 0644); err != nil {
+		return err
+	}
+	idx := index{Current: next.id}
+	for _, c := range append([]*key{next}, retired...) {
+		idx.Keys = append(idx.Keys, indexEntry{ID: c.id, Alg: c.method.Alg(), Created: c.created, Retired: c.retired})
+	}
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(k.dir, indexFile), data, 0644)
+}
+
+// load reads the keys listed in the index of the key directory. Keys retired
+// for longer than the retention are skipped.
+func (k *KeyRing) load() error {
+	data, err := os.ReadFile(filepath.Join(k.dir, indexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return fmt.Errorf("invalid %s: %v", filepath.Join(k.dir, indexFile), err)
+	}
+	for _, entry := range idx.Keys {
+		if entry.ID != idx.Current && time.Since(entry.Retired) > k.retain {
+			continue
+		}
+		c, err := k.loadKey(entry)
+		if err != nil {
+			return err
+		}
+		if entry.ID == idx.Current {
+			k.current = c
+		} else {
+			k.retired = append(k.retired, c)
+		}
+	}
+	if k.current == nil {
+		return fmt.Errorf("current key %q missing from %s", idx.Current, k.dir)
+	}
+	return nil
+}
+
+func (k *KeyRing) loadKey(entry indexEntry) (*key, error) {
+	path := filepath.Join(k.dir, entry.ID+".pem")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", path)
+	}
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	c := &key{id: entry.ID, method: jwt.GetSigningMethod(entry.Alg), signKey: private, created: entry.Created, retired: entry.Retired}
+	switch p := private.(type) {
+	case *rsa.PrivateKey:
+		c.verifyKey = &p.PublicKey
+	case *ecdsa.PrivateKey:
+		c.verifyKey = &p.PublicKey
+	default:
+		return nil, fmt.Errorf("%s: unsupported key type %T", path, private)
+	}
+	if c.method == nil {
+		return nil, fmt.Errorf("%s: unsupported JWT algorithm %q", path, entry.Alg)
+	}
+	return c, nil
+}
+
+// writeFile replaces the file at path atomically.
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,110 @@
+package jwtkeys
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+)
+
+// RevocationList holds the IDs (jti) of tokens revoked before they expire.
+// A list kept in a file is read again when the file changes, so tokens can
+// also be revoked by editing it. The file is a JSON object mapping each
+// revoked ID to the expiry of its token, after which the entry is dropped.
+type RevocationList struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	revoked map[string]time.Time
+}
+
+// NewRevocationList returns a list held in memory.
+func NewRevocationList() *RevocationList {
+	return &RevocationList{revoked: make(map[string]time.Time)}
+}
+
+// OpenRevocationList returns the list kept in the file at path, which is
+// created on the first revocation.
+func OpenRevocationList(path string) (*RevocationList, error) {
+	r := &RevocationList{path: path, revoked: make(map[string]time.Time)}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Revoke revokes the token with ID jti until it expires.
+func (r *RevocationList) Revoke(jti string, expires time.Time) error {
+	if jti == "" {
+		return errors.New("token has no ID")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.reload(); err != nil {
+		log.Errorf("Failed to reload revoked tokens: %v", err)
+	}
+	r.revoked[jti] = expires
+	now := time.Now()
+	for id, exp := range r.revoked {
+		if exp.Before(now) {
+			delete(r.revoked, id)
+		}
+	}
+	if r.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.revoked, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(r.path, data, 0600); err != nil {
+		return err
+	}
+	if info, err := os.Stat(r.path); err == nil {
+		r.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// Revoked reports whether the token with ID jti is revoked.
+func (r *RevocationList) Revoked(jti string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.reload(); err != nil {
+		log.Errorf("Failed to reload revoked tokens, keeping previous list: %v", err)
+	}
+	_, ok := r.revoked[jti]
+	return ok
+}
+
+// reload reads the file when it changed since it was last read.
+func (r *RevocationList) reload() error {
+	if r.path == "" {
+		return nil
+	}
+	info, err := os.Stat(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.ModTime().Equal(r.modTime) {
+		return nil
+	}
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+	revoked := make(map[string]time.Time)
+	if err := json.Unmarshal(data, &revoked); err != nil {
+		return fmt.Errorf("invalid %s: %v", r.path, err)
+	}
+	r.revoked, r.modTime = revoked, info.ModTime()
+	return nil
+}
@@ -0,0 +1,347 @@
+// Package metrics provides a small in-process metrics registry for the gNMI
+// server. Metrics are exposed over HTTP in the Prometheus text exposition
+// format, which OpenMetrics scrapers accept as well.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBuckets are latency buckets in seconds, from 1ms to 60s.
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds a set of metric families. Collection is disabled until
+// Enable is called so instrumented code paths cost next to nothing when no
+// metrics endpoint is configured.
+type Registry struct {
+	mu         sync.Mutex
+	names      map[string]bool
+	collectors []collector
+	enabled    atomic.Bool
+}
+
+// NewRegistry returns an empty, disabled registry.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// Enable turns on collection for every metric of the registry.
+func (r *Registry) Enable() {
+	r.enabled.Store(true)
+}
+
+// Enabled reports whether the registry collects metrics.
+func (r *Registry) Enabled() bool {
+	return r.enabled.Load()
+}
+
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic(fmt.Sprintf("metric %s registered twice", name))
+	}
+	r.names[name] = true
+	r.collectors = append(r.collectors, c)
+}
+
+// Write renders all metrics of the registry in the text exposition format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector{}, r.collectors...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler returns an http.Handler serving the metrics of the registry.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		r.Write(w)
+	})
+}
+
+// desc is the static part of a metric family shared by all its series.
+type desc struct {
+	registry   *Registry
+	name       string
+	help       string
+	metricType string
+	labels     []string
+}
+
+func (d *desc) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", d.name, d.metricType)
+}
+
+func (d *desc) key(labelValues []string) string {
+	if len(labelValues) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", d.name, len(d.labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+// labelString formats the label pairs of a series, extra is appended as is
+// (e.g. le="0.5" for histogram buckets).
+func (d *desc) labelString(labelValues []string, extra string) string {
+	if len(d.labels) == 0 && extra == "" {
+		return ""
+	}
+	pairs := make([]string, 0, len(d.labels)+1)
+	for i, label := range d.labels {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", label, escapeLabelValue(labelValues[i])))
+	}
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	valueFunc   func() float64
+}
+
+// seriesMap holds the series of a counter or gauge family keyed by label values.
+type seriesMap struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func (m *seriesMap) get(labelValues []string) *series {
+	key := m.key(labelValues)
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string{}, labelValues...)}
+		m.series[key] = s
+	}
+	return s
+}
+
+func (m *seriesMap) write(w *bufio.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.writeHeader(w)
+	for _, key := range sortedKeys(m.series) {
+		s := m.series[key]
+		value := s.value
+		if s.valueFunc != nil {
+			value = s.valueFunc()
+		}
+		fmt.Fprintf(w, "%s%s %s\n", m.name, m.labelString(s.labelValues, ""), formatFloat(value))
+	}
+}
+
+// CounterVec is a family of monotonically increasing counters.
+type CounterVec struct {
+	seriesMap
+}
+
+// NewCounterVec registers a counter family with the given label names.
+func (r *Registry) NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	c := &CounterVec{seriesMap{desc: desc{r, name, help, "counter", labels}, series: make(map[string]*series)}}
+	r.register(name, c)
+	return c
+}
+
+// Inc increments the counter of the label values by one.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter of the label values, negative values are ignored.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if !c.registry.Enabled() || v < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.get(labelValues).value += v
+}
+
+// GaugeVec is a family of values that can go up and down.
+type GaugeVec struct {
+	seriesMap
+}
+
+// NewGaugeVec registers a gauge family with the given label names.
+func (r *Registry) NewGaugeVec(name string, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{seriesMap{desc: desc{r, name, help, "gauge", labels}, series: make(map[string]*series)}}
+	r.register(name, g)
+	return g
+}
+
+// Set sets the gauge of the label values.
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	if !g.registry.Enabled() {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.get(labelValues).value = v
+}
+
+// Add adds v, which may be negative, to the gauge of the label values.
+func (g *GaugeVec) Add(v float64, labelValues ...string) {
+	if !g.registry.Enabled() {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.get(labelValues).value += v
+}
+
+// Inc increments the gauge of the label values by one.
+func (g *GaugeVec) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec decrements the gauge of the label values by one.
+func (g *GaugeVec) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// SetFunc makes the gauge of the label values report the result of fn at
+// collection time, e.g. the current length of a queue.
+func (g *GaugeVec) SetFunc(fn func() float64, labelValues ...string) {
+	if !g.registry.Enabled() {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.get(labelValues).valueFunc = fn
+}
+
+// Delete removes the series of the label values.
+func (g *GaugeVec) Delete(labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.series, g.key(labelValues))
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // per bucket, not cumulative
+	count       uint64
+	sum         float64
+}
+
+// HistogramVec is a family of histograms, typically used for latencies.
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+// NewHistogramVec registers a histogram family with the given upper bounds
+// and label names.
+func (r *Registry) NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+	h := &HistogramVec{
+		desc:    desc{r, name, help, "histogram", labels},
+		buckets: sorted,
+		series:  make(map[string]*histogramSeries),
+	}
+	r.register(name, h)
+	return h
+}
+
+// Observe records v in the histogram of the label values.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	if !h.registry.Enabled() {
+		return
+	}
+	key := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{
+			labelValues: append([]string{}, labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writeHeader(w)
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			le := fmt.Sprintf("le=\"%s\"", formatFloat(bound))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelString(s.labelValues, le), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelString(s.labelValues, "le=\"+Inf\""), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelString(s.labelValues, ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelString(s.labelValues, ""), s.count)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelReplacer.Replace(s)
+}
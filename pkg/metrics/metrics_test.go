@@ -0,0 +1,201 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, r *Registry) string {
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestRegistry_DisabledByDefault(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("test_total", "Test counter.", "method")
+	c.Inc("Get")
+
+	out := render(t, r)
+	if strings.Contains(out, `test_total{method="Get"}`) {
+		t.Errorf("Expected no series while disabled, got:\n%s", out)
+	}
+	if !strings.Contains(out, "# TYPE test_total counter") {
+		t.Errorf("Expected family header, got:\n%s", out)
+	}
+}
+
+func TestCounterVec(t *testing.T) {
+	r := NewRegistry()
+	r.Enable()
+	c := r.NewCounterVec("rpc_total", "RPC count.", "method", "code")
+	c.Inc("/gnmi.gNMI/Get", "OK")
+	c.Inc("/gnmi.gNMI/Get", "OK")
+	c.Add(3, "/gnmi.gNMI/Set", "NotFound")
+	c.Add(-1, "/gnmi.gNMI/Set", "NotFound")
+
+	out := render(t, r)
+	for _, want := range []string{
+		"# HELP rpc_total RPC count.\n",
+		`rpc_total{method="/gnmi.gNMI/Get",code="OK"} 2` + "\n",
+		`rpc_total{method="/gnmi.gNMI/Set",code="NotFound"} 3` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestGaugeVec(t *testing.T) {
+	r := NewRegistry()
+	r.Enable()
+	g := r.NewGaugeVec("active", "Active subscriptions.", "target", "mode")
+	g.Inc("COUNTERS_DB", "STREAM")
+	g.Inc("COUNTERS_DB", "STREAM")
+	g.Dec("COUNTERS_DB", "STREAM")
+	g.Set(7, "APPL_DB", "POLL")
+
+	depth := 0
+	q := r.NewGaugeVec("depth", "Queue depth.", "client")
+	q.SetFunc(func() float64 { return float64(depth) }, "10.0.0.1:1234#1")
+	depth = 42
+
+	out := render(t, r)
+	for _, want := range []string{
+		`active{target="COUNTERS_DB",mode="STREAM"} 1` + "\n",
+		`active{target="APPL_DB",mode="POLL"} 7` + "\n",
+		`depth{client="10.0.0.1:1234#1"} 42` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+
+	q.Delete("10.0.0.1:1234#1")
+	if out := render(t, r); strings.Contains(out, "depth{") {
+		t.Errorf("Expected deleted series to be gone:\n%s", out)
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	r := NewRegistry()
+	r.Enable()
+	h := r.NewHistogramVec("latency_seconds", "Latency.", []float64{1, 0.1}, "method")
+	h.Observe(0.05, "Get")
+	h.Observe(0.1, "Get")
+	h.Observe(0.5, "Get")
+	h.Observe(5, "Get")
+
+	out := render(t, r)
+	for _, want := range []string{
+		"# TYPE latency_seconds histogram\n",
+		`latency_seconds_bucket{method="Get",le="0.1"} 2` + "\n",
+		`latency_seconds_bucket{method="Get",le="1"} 3` + "\n",
+		`latency_seconds_bucket{method="Get",le="+Inf"} 4` + "\n",
+		`latency_seconds_sum{method="Get"} 5.65` + "\n",
+		`latency_seconds_count{method="Get"} 4` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestLabelEscaping(t *testing.T) {
+	r := NewRegistry()
+	r.Enable()
+	c := r.NewCounterVec("escaped_total", "Help with \\ and\nnewline.", "path")
+	c.Inc(`a"b\c`)
+
+	out := render(t, r)
+	if !strings.Contains(out, `escaped_total{path="a\"b\\c"} 1`) {
+		t.Errorf("Expected escaped label value:\n%s", out)
+	}
+	if !strings.Contains(out, `# HELP escaped_total Help with \\ and\nnewline.`) {
+		t.Errorf("Expected escaped help:\n%s", out)
+	}
+}
+
+func TestWrongLabelCount(t *testing.T) {
+	r := NewRegistry()
+	r.Enable()
+	c := r.NewCounterVec("labels_total", "Labels.", "a", "b")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on wrong number of label values")
+		}
+	}()
+	c.Inc("only-one")
+}
+
+func TestDuplicateRegistration(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("dup_total", "Dup.")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on duplicate registration")
+		}
+	}()
+	r.NewGaugeVec("dup_total", "Dup.")
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Enable()
+	r.NewCounterVec("handler_total", "Handler.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "handler_total 1\n") {
+		t.Errorf("Unexpected body:\n%s", rec.Body.String())
+	}
+}
+
+func TestServe_Unix(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "metrics.sock")
+	srv, err := Serve(unixAddressPrefix + socket)
+	if err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+	defer srv.Close()
+
+	if !Default.Enabled() {
+		t.Error("Expected Serve to enable the default registry")
+	}
+	RPCRequests.Inc("/gnmi.gNMI/Capabilities", "OK")
+
+	client := http.Client{Transport: &http.Transport{
+		Dial: func(network, addr string) (net.Conn, error) {
+			return net.Dial("unix", socket)
+		},
+	}}
+	resp, err := client.Get("http://unix/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `gnmi_rpc_requests_total{method="/gnmi.gNMI/Capabilities",code="OK"}`) {
+		t.Errorf("Expected RPC counter in response:\n%s", body)
+	}
+}
+
+func TestServe_InvalidAddress(t *testing.T) {
+	if _, err := Serve("256.0.0.1:-1"); err == nil {
+		t.Error("Expected error for invalid address")
+	}
+}
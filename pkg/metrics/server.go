@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	log "github.com/golang/glog"
+)
+
+const unixAddressPrefix = "unix:"
+
+// Default is the registry shared by the server, the data clients and the
+// service client.
+var Default = NewRegistry()
+
+var (
+	RPCRequests = Default.NewCounterVec(
+		"gnmi_rpc_requests_total",
+		"Number of completed RPCs by full method name and gRPC status code.",
+		"method", "code",
+	)
+	RPCDuration = Default.NewHistogramVec(
+		"gnmi_rpc_duration_seconds",
+		"Duration of RPCs by full method name.",
+		DefaultBuckets,
+		"method",
+	)
+	ActiveSubscriptions = Default.NewGaugeVec(
+		"gnmi_subscriptions_active",
+		"Number of running Subscribe RPCs by target and subscription mode.",
+		"target", "mode",
+	)
+	SubscribeQueueDepth = Default.NewGaugeVec(
+		"gnmi_subscribe_queue_depth",
+		"Number of updates waiting to be sent to a subscribe client.",
+		"client",
+	)
+	DbusCallDuration = Default.NewHistogramVec(
+		"sonic_dbus_call_duration_seconds",
+		"Duration of D-Bus calls to the host service by method.",
+		DefaultBuckets,
+		"method",
+	)
+	DbusCallErrors = Default.NewCounterVec(
+		"sonic_dbus_call_errors_total",
+		"Number of failed D-Bus calls to the host service by method.",
+		"method",
+	)
+	RedisCommandDuration = Default.NewHistogramVec(
+		"sonic_redis_command_duration_seconds",
+		"Round-trip time of Redis commands by database and command.",
+		DefaultBuckets,
+		"db", "command",
+	)
+)
+
+// Serve enables collection on the Default registry and serves it on
+// /metrics. The address is either host:port, which should be a localhost
+// address, or unix:/path/to/socket.
+func Serve(address string) (*http.Server, error) {
+	return Default.Serve(address)
+}
+
+// Serve enables collection on r and serves it on /metrics, like the package
+// level Serve does for the Default registry.
+func (r *Registry) Serve(address string) (*http.Server, error) {
+	var listener net.Listener
+	var err error
+	if path := strings.TrimPrefix(address, unixAddressPrefix); path != address {
+		os.Remove(path) // Remove stale socket
+		if listener, err = net.Listen("unix", path); err != nil {
+			return nil, fmt.Errorf("failed to listen on %v: %v", address, err)
+		}
+		if err := os.Chmod(path, 0660); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("failed to set permissions on %v: %v", path, err)
+		}
+	} else if listener, err = net.Listen("tcp", address); err != nil {
+		return nil, fmt.Errorf("failed to listen on %v: %v", address, err)
+	}
+
+	r.Enable()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r.Handler())
+	srv := &http.Server{Handler: mux}
+	go func() {
+		log.V(1).Infof("Metrics endpoint serving on %v", address)
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Errorf("Metrics endpoint on %v stopped: %v", address, err)
+		}
+	}()
+	return srv, nil
+}
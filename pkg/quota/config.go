@@ -0,0 +1,102 @@
+package quota
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Table is the CONFIG_DB table holding the quotas. Its keys are "default",
+// "user|<name>" and "role|<name>", e.g.
+//
+//	GNMI_QUOTA|role|gnmi_readonly
+//	    max_subscriptions: 4
+//	    get_rate: 10
+//	    min_sample_interval_ms: 1000
+const Table = "GNMI_QUOTA"
+
+const (
+	defaultKey = "default"
+	userKey    = "user"
+	roleKey    = "role"
+
+	fieldMaxSubscriptions  = "max_subscriptions"
+	fieldGetRate           = "get_rate"
+	fieldSetRate           = "set_rate"
+	fieldMaxPaths          = "max_paths"
+	fieldMinSampleInterval = "min_sample_interval_ms"
+)
+
+// ParseLimits parses the fields of a GNMI_QUOTA entry. Missing fields are
+// unlimited.
+func ParseLimits(fields map[string]string) (Limits, error) {
+	var l Limits
+	for field, value := range fields {
+		var err error
+		switch field {
+		case fieldMaxSubscriptions:
+			l.MaxSubscriptions, err = parseCount(value)
+		case fieldMaxPaths:
+			l.MaxPaths, err = parseCount(value)
+		case fieldGetRate:
+			l.GetRate, err = parseRate(value)
+		case fieldSetRate:
+			l.SetRate, err = parseRate(value)
+		case fieldMinSampleInterval:
+			var ms int
+			ms, err = parseCount(value)
+			l.MinSampleInterval = time.Duration(ms) * time.Millisecond
+		default:
+			err = fmt.Errorf("unknown field")
+		}
+		if err != nil {
+			return Limits{}, fmt.Errorf("invalid %s %q: %v", field, value, err)
+		}
+	}
+	return l, nil
+}
+
+func parseCount(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err == nil && n < 0 {
+		err = fmt.Errorf("must not be negative")
+	}
+	return n, err
+}
+
+func parseRate(value string) (float64, error) {
+	r, err := strconv.ParseFloat(value, 64)
+	if err == nil && (r < 0 || math.IsNaN(r)) {
+		err = fmt.Errorf("must not be negative")
+	}
+	return r, err
+}
+
+// ParseConfig builds a Config from the entries of the GNMI_QUOTA table keyed
+// without the table name. Invalid entries are skipped and reported in errs so
+// a single typo does not drop every other quota.
+func ParseConfig(entries map[string]map[string]string, separator string) (config Config, errs []error) {
+	config.Users = make(map[string]Limits)
+	config.Roles = make(map[string]Limits)
+	for key, fields := range entries {
+		limits, err := ParseLimits(fields)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s%s: %v", Table, separator, key, err))
+			continue
+		}
+		kind, name, _ := strings.Cut(key, separator)
+		switch {
+		case key == defaultKey:
+			config.Default = limits
+		case kind == userKey && name != "":
+			config.Users[name] = limits
+		case kind == roleKey && name != "":
+			config.Roles[name] = limits
+		default:
+			errs = append(errs, fmt.Errorf("%s%s%s: invalid key", Table, separator, key))
+		}
+	}
+	return config, errs
+}
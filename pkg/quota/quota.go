@@ -0,0 +1,244 @@
+// Package quota enforces per-user and per-role limits on gNMI clients so a
+// single misbehaving collector cannot crowd out the others. Limits cover
+// concurrent subscriptions, Get and Set request rates, the number of paths of
+// a subscription and the minimum sample interval.
+//
+// Quotas are keyed by the authenticated user name. Requests without an
+// authenticated user are not limited.
+package quota
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Limits is a set of quotas. A zero value means unlimited.
+type Limits struct {
+	// MaxSubscriptions is the number of concurrent Subscribe RPCs.
+	MaxSubscriptions int
+	// GetRate is the number of Get requests per second.
+	GetRate float64
+	// SetRate is the number of Set requests per second.
+	SetRate float64
+	// MaxPaths is the number of paths in a single subscription.
+	MaxPaths int
+	// MinSampleInterval is the smallest sample interval a subscription may ask for.
+	MinSampleInterval time.Duration
+}
+
+// Config holds the limits of users and roles. User limits take precedence
+// over role limits, which take precedence over Default.
+type Config struct {
+	Default Limits
+	Users   map[string]Limits
+	Roles   map[string]Limits
+}
+
+// LimitsFor resolves the limits of a user holding roles. When several roles
+// have limits, the most permissive value of each limit applies.
+func (c Config) LimitsFor(user string, roles []string) Limits {
+	if l, ok := c.Users[user]; ok {
+		return l
+	}
+	var matched []Limits
+	for _, role := range roles {
+		if l, ok := c.Roles[role]; ok {
+			matched = append(matched, l)
+		}
+	}
+	if len(matched) == 0 {
+		return c.Default
+	}
+	l := matched[0]
+	for _, m := range matched[1:] {
+		l.MaxSubscriptions = maxLimit(l.MaxSubscriptions, m.MaxSubscriptions)
+		l.MaxPaths = maxLimit(l.MaxPaths, m.MaxPaths)
+		l.GetRate = maxRate(l.GetRate, m.GetRate)
+		l.SetRate = maxRate(l.SetRate, m.SetRate)
+		if m.MinSampleInterval < l.MinSampleInterval {
+			l.MinSampleInterval = m.MinSampleInterval
+		}
+	}
+	return l
+}
+
+func maxLimit(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func maxRate(a, b float64) float64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return math.Max(a, b)
+}
+
+// bucket is a token bucket refilled at rate tokens per second, holding at
+// most one second worth of tokens.
+type bucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) allow(rate float64, now time.Time) bool {
+	burst := math.Max(1, rate)
+	if b.rate != rate {
+		// New or reloaded limit, start with a full bucket
+		b.rate = rate
+		b.tokens = burst
+		b.last = now
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+type userState struct {
+	subscriptions int
+	get           bucket
+	set           bucket
+}
+
+// Manager tracks the usage of every user against the configured limits.
+// All methods are safe to call on a nil Manager, which enforces nothing.
+type Manager struct {
+	mu     sync.Mutex
+	config Config
+	users  map[string]*userState
+	now    func() time.Time
+}
+
+// NewManager returns a Manager without limits.
+func NewManager() *Manager {
+	return &Manager{
+		users: make(map[string]*userState),
+		now:   time.Now,
+	}
+}
+
+// SetConfig replaces the limits. Running subscriptions are kept and count
+// against the new limits.
+func (m *Manager) SetConfig(config Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config = config
+}
+
+// Config returns the current limits.
+func (m *Manager) Config() Config {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.config
+}
+
+func (m *Manager) state(user string) *userState {
+	s, ok := m.users[user]
+	if !ok {
+		s = &userState{}
+		m.users[user] = s
+	}
+	return s
+}
+
+// AllowGet consumes a Get request from the quota of user.
+func (m *Manager) AllowGet(user string, roles []string) error {
+	return m.allow(user, roles, "Get")
+}
+
+// AllowSet consumes a Set request from the quota of user.
+func (m *Manager) AllowSet(user string, roles []string) error {
+	return m.allow(user, roles, "Set")
+}
+
+func (m *Manager) allow(user string, roles []string, op string) error {
+	if m == nil || user == "" {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	limits := m.config.LimitsFor(user, roles)
+	rate, b := limits.GetRate, &m.state(user).get
+	if op == "Set" {
+		rate, b = limits.SetRate, &m.state(user).set
+	}
+	if rate == 0 || b.allow(rate, m.now()) {
+		return nil
+	}
+	return status.Errorf(codes.ResourceExhausted, "quota exceeded for user %s: more than %g %s requests per second", user, rate, op)
+}
+
+// AcquireSubscription checks a subscription of user against the limits and
+// counts it as running. The returned release function must be called when
+// the subscription ends.
+func (m *Manager) AcquireSubscription(user string, roles []string, subscribe *gnmipb.SubscriptionList) (func(), error) {
+	if m == nil || user == "" {
+		return func() {}, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	limits := m.config.LimitsFor(user, roles)
+
+	subscriptions := subscribe.GetSubscription()
+	if limits.MaxPaths != 0 && len(subscriptions) > limits.MaxPaths {
+		return nil, status.Errorf(codes.ResourceExhausted, "quota exceeded for user %s: %d paths in subscription (max %d)", user, len(subscriptions), limits.MaxPaths)
+	}
+	if limits.MinSampleInterval != 0 && subscribe.GetMode() == gnmipb.SubscriptionList_STREAM {
+		for _, sub := range subscriptions {
+			interval := time.Duration(sub.GetSampleInterval())
+			if interval != 0 && interval < limits.MinSampleInterval {
+				return nil, status.Errorf(codes.ResourceExhausted, "quota exceeded for user %s: sample interval %v is below the minimum of %v", user, interval, limits.MinSampleInterval)
+			}
+		}
+	}
+
+	state := m.state(user)
+	if limits.MaxSubscriptions != 0 && state.subscriptions >= limits.MaxSubscriptions {
+		return nil, status.Errorf(codes.ResourceExhausted, "quota exceeded for user %s: %d concurrent subscriptions (max %d)", user, state.subscriptions, limits.MaxSubscriptions)
+	}
+	state.subscriptions++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			state.subscriptions--
+		})
+	}, nil
+}
+
+// Subscriptions returns the number of running subscriptions of user.
+func (m *Manager) Subscriptions(user string) int {
+	if m == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.users[user]; ok {
+		return s.subscriptions
+	}
+	return 0
+}
+
+// String describes the limits for logging.
+func (l Limits) String() string {
+	return fmt.Sprintf("subscriptions=%d get_rate=%g set_rate=%g paths=%d min_sample_interval=%v",
+		l.MaxSubscriptions, l.GetRate, l.SetRate, l.MaxPaths, l.MinSampleInterval)
+}
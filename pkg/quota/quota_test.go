@@ -0,0 +1,188 @@
+package quota
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func subscriptionList(mode gnmipb.SubscriptionList_Mode, intervals ...uint64) *gnmipb.SubscriptionList {
+	sl := &gnmipb.SubscriptionList{Mode: mode}
+	for _, interval := range intervals {
+		sl.Subscription = append(sl.Subscription, &gnmipb.Subscription{
+			Path:           &gnmipb.Path{Elem: []*gnmipb.PathElem{{Name: "COUNTERS"}}},
+			Mode:           gnmipb.SubscriptionMode_SAMPLE,
+			SampleInterval: interval,
+		})
+	}
+	return sl
+}
+
+func expectExhausted(t *testing.T, err error, contains string) {
+	t.Helper()
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted, got %v", err)
+	}
+	if !strings.Contains(err.Error(), contains) {
+		t.Errorf("Expected %q in error %q", contains, err.Error())
+	}
+}
+
+func TestLimitsFor(t *testing.T) {
+	config := Config{
+		Default: Limits{MaxSubscriptions: 1},
+		Users:   map[string]Limits{"controller": {}},
+		Roles: map[string]Limits{
+			"gnmi_readonly":  {MaxSubscriptions: 2, GetRate: 5, MinSampleInterval: time.Second},
+			"gnmi_readwrite": {MaxSubscriptions: 4, GetRate: 0, MinSampleInterval: 2 * time.Second},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		user  string
+		roles []string
+		want  Limits
+	}{
+		{"user overrides roles", "controller", []string{"gnmi_readonly"}, Limits{}},
+		{"single role", "collector", []string{"gnmi_readonly"}, config.Roles["gnmi_readonly"]},
+		{"most permissive of roles", "collector", []string{"gnmi_readonly", "gnmi_readwrite"},
+			Limits{MaxSubscriptions: 4, GetRate: 0, MinSampleInterval: time.Second}},
+		{"default", "collector", []string{"other"}, config.Default},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.LimitsFor(tt.user, tt.roles); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestManager_Subscriptions(t *testing.T) {
+	m := NewManager()
+	m.SetConfig(Config{Default: Limits{MaxSubscriptions: 2, MaxPaths: 2, MinSampleInterval: time.Second}})
+
+	release1, err := m.AcquireSubscription("collector", nil, subscriptionList(gnmipb.SubscriptionList_STREAM, 0))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	release2, err := m.AcquireSubscription("collector", nil, subscriptionList(gnmipb.SubscriptionList_STREAM, uint64(time.Second)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, err = m.AcquireSubscription("collector", nil, subscriptionList(gnmipb.SubscriptionList_STREAM, 0))
+	expectExhausted(t, err, "2 concurrent subscriptions (max 2)")
+
+	// Other users are not affected
+	if _, err := m.AcquireSubscription("controller", nil, subscriptionList(gnmipb.SubscriptionList_STREAM, 0)); err != nil {
+		t.Errorf("Unexpected error for another user: %v", err)
+	}
+
+	release1()
+	release1() // Releasing twice must not free another slot
+	if got := m.Subscriptions("collector"); got != 1 {
+		t.Errorf("Expected 1 running subscription, got %d", got)
+	}
+	release2()
+
+	_, err = m.AcquireSubscription("collector", nil, subscriptionList(gnmipb.SubscriptionList_STREAM, 0, 0, 0))
+	expectExhausted(t, err, "3 paths in subscription (max 2)")
+
+	_, err = m.AcquireSubscription("collector", nil, subscriptionList(gnmipb.SubscriptionList_STREAM, uint64(100*time.Millisecond)))
+	expectExhausted(t, err, "sample interval 100ms is below the minimum of 1s")
+
+	// The sample interval only applies to streaming subscriptions
+	if _, err := m.AcquireSubscription("collector", nil, subscriptionList(gnmipb.SubscriptionList_POLL, uint64(100*time.Millisecond))); err != nil {
+		t.Errorf("Unexpected error for POLL subscription: %v", err)
+	}
+}
+
+func TestManager_Rates(t *testing.T) {
+	now := time.Unix(1000, 0)
+	m := NewManager()
+	m.now = func() time.Time { return now }
+	m.SetConfig(Config{Default: Limits{GetRate: 2, SetRate: 0.5}})
+
+	for i := 0; i < 2; i++ {
+		if err := m.AllowGet("collector", nil); err != nil {
+			t.Fatalf("Get %d: unexpected error: %v", i, err)
+		}
+	}
+	expectExhausted(t, m.AllowGet("collector", nil), "more than 2 Get requests per second")
+
+	now = now.Add(500 * time.Millisecond)
+	if err := m.AllowGet("collector", nil); err != nil {
+		t.Errorf("Expected a token after 500ms, got %v", err)
+	}
+
+	if err := m.AllowSet("collector", nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectExhausted(t, m.AllowSet("collector", nil), "more than 0.5 Set requests per second")
+	now = now.Add(2 * time.Second)
+	if err := m.AllowSet("collector", nil); err != nil {
+		t.Errorf("Expected a token after 2s, got %v", err)
+	}
+
+	// Reloading with a new rate starts with a full bucket
+	m.SetConfig(Config{Default: Limits{GetRate: 1}})
+	if err := m.AllowGet("collector", nil); err != nil {
+		t.Errorf("Expected a token after reload, got %v", err)
+	}
+}
+
+func TestManager_Unlimited(t *testing.T) {
+	var nilManager *Manager
+	if err := nilManager.AllowGet("collector", nil); err != nil {
+		t.Errorf("Expected nil manager to allow, got %v", err)
+	}
+	release, err := nilManager.AcquireSubscription("collector", nil, nil)
+	if err != nil {
+		t.Errorf("Expected nil manager to allow, got %v", err)
+	}
+	release()
+
+	m := NewManager()
+	m.SetConfig(Config{Default: Limits{GetRate: 1, MaxSubscriptions: 1}})
+	for i := 0; i < 5; i++ {
+		if err := m.AllowGet("", nil); err != nil {
+			t.Errorf("Expected unauthenticated requests not to be limited, got %v", err)
+		}
+		if _, err := m.AcquireSubscription("", nil, nil); err != nil {
+			t.Errorf("Expected unauthenticated requests not to be limited, got %v", err)
+		}
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	entries := map[string]map[string]string{
+		"default":            {"max_subscriptions": "2", "get_rate": "10"},
+		"user|controller":    {},
+		"role|gnmi_readonly": {"set_rate": "0.5", "max_paths": "100", "min_sample_interval_ms": "1000"},
+		"role|bad":           {"max_paths": "-1"},
+		"user|typo":          {"get_rte": "1"},
+		"group|x":            {"get_rate": "1"},
+	}
+	config, errs := ParseConfig(entries, "|")
+	if len(errs) != 3 {
+		t.Errorf("Expected 3 errors, got %v", errs)
+	}
+	if config.Default != (Limits{MaxSubscriptions: 2, GetRate: 10}) {
+		t.Errorf("Unexpected default: %v", config.Default)
+	}
+	if l, ok := config.Users["controller"]; !ok || l != (Limits{}) {
+		t.Errorf("Expected unlimited controller, got %v, %v", l, ok)
+	}
+	want := Limits{SetRate: 0.5, MaxPaths: 100, MinSampleInterval: time.Second}
+	if config.Roles["gnmi_readonly"] != want {
+		t.Errorf("Expected %v, got %v", want, config.Roles["gnmi_readonly"])
+	}
+	if _, ok := config.Roles["bad"]; ok {
+		t.Error("Expected invalid entry to be skipped")
+	}
+}
@@ -0,0 +1,95 @@
+// Package rotatefile appends lines to a local file, rotating it when it grows
+// past a maximum size. It backs the local files written by the audit log and
+// the dial-out collector.
+package rotatefile
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Writer writes one record per line to a local file, rotating it when it
+// grows past maxSize bytes. Up to maxBackups rotated files are kept as
+// <path>.1 (newest) to <path>.<maxBackups> (oldest).
+type Writer struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// Open opens or creates the file at path for appending.
+func Open(path string, maxSize int64, maxBackups int) (*Writer, error) {
+	w := &Writer{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	file, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", w.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat %s: %v", w.path, err)
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups by one and starts a new file.
+func (w *Writer) rotate() error {
+	w.file.Close()
+	w.file = nil
+	if w.maxBackups > 0 {
+		for i := w.maxBackups - 1; i > 0; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+		}
+		if err := os.Rename(w.path, w.path+".1"); err != nil {
+			return fmt.Errorf("failed to rotate %s: %v", w.path, err)
+		}
+	} else if err := os.Remove(w.path); err != nil {
+		return fmt.Errorf("failed to rotate %s: %v", w.path, err)
+	}
+	return w.open()
+}
+
+// WriteLine appends record and a newline to the file, rotating it first if
+// the line would take it past the maximum size.
+func (w *Writer) WriteLine(record []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		// A previous rotation failed, try again
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(record))+1 > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := w.file.Write(append(record, '\n'))
+	w.size += int64(n)
+	return err
+}
+
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
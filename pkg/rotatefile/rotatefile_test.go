@@ -0,0 +1,66 @@
+package rotatefile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriter_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.log")
+	w, err := Open(path, 64, 2)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer w.Close()
+
+	record := []byte(strings.Repeat("x", 40))
+	for i := 0; i < 4; i++ {
+		if err := w.WriteLine(record); err != nil {
+			t.Fatalf("WriteLine %d failed: %v", i, err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Expected %s to exist: %v", name, err)
+		}
+		if string(data) != string(record)+"\n" {
+			t.Errorf("Expected one record in %s, got %q", name, data)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected at most 2 backups")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestWriter_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.log")
+	w, err := Open(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	w.WriteLine([]byte("first"))
+	w.Close()
+
+	w, err = Open(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	w.WriteLine([]byte("second"))
+	w.Close()
+
+	data, _ := os.ReadFile(path)
+	if string(data) != "first\nsecond\n" {
+		t.Errorf("Expected records to be appended, got %q", data)
+	}
+	_, err = Open(filepath.Join(path, "invalid"), 0, 0)
+	if err == nil || !strings.Contains(err.Error(), "failed to open "+filepath.Join(path, "invalid")) {
+		t.Errorf("Expected open error naming the path, got %v", err)
+	}
+}
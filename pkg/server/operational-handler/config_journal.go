@@ -0,0 +1,66 @@
+package operationalhandler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/configjournal"
+)
+
+// defaultJournalLimit bounds the records returned when the path sets no limit.
+const defaultJournalLimit = 1000
+
+// ConfigJournalHandler implements PathHandler for the journal of CONFIG_DB
+// changes, e.g. /sonic/config-journal[since=1h][key=PORT|].
+type ConfigJournalHandler struct {
+	journal func() *configjournal.Journal
+}
+
+// NewConfigJournalHandler creates a new ConfigJournalHandler over the journal
+// of the server.
+func NewConfigJournalHandler() *ConfigJournalHandler {
+	return &ConfigJournalHandler{
+		journal: configjournal.Default,
+	}
+}
+
+// SupportedPaths returns the list of paths this handler supports.
+func (h *ConfigJournalHandler) SupportedPaths() []string {
+	return []string{
+		"config-journal",
+	}
+}
+
+// HandleGet returns the journal records selected by the keys of the path,
+// oldest first. The keys are those of configjournal.ParseQuery; without a
+// limit the newest 1000 records are returned.
+func (h *ConfigJournalHandler) HandleGet(path *gnmipb.Path) ([]byte, error) {
+	elems := path.GetElem()
+	if len(elems) == 0 {
+		return nil, fmt.Errorf("path elements cannot be empty")
+	}
+	journal := h.journal()
+	if journal == nil {
+		return nil, fmt.Errorf("config journal is disabled")
+	}
+	query, err := configjournal.ParseQuery(elems[len(elems)-1].GetKey())
+	if err != nil {
+		return nil, err
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultJournalLimit
+	}
+	records, err := journal.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config journal: %v", err)
+	}
+	if records == nil {
+		records = []configjournal.Record{}
+	}
+	jsonData, err := json.Marshal(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config journal: %v", err)
+	}
+	return jsonData, nil
+}
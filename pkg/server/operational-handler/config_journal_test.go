@@ -0,0 +1,81 @@
+package operationalhandler
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/configjournal"
+)
+
+func configJournalPath(key map[string]string) *gnmipb.Path {
+	return &gnmipb.Path{
+		Elem: []*gnmipb.PathElem{
+			{Name: "sonic"},
+			{Name: "config-journal", Key: key},
+		},
+	}
+}
+
+func TestConfigJournalHandler_HandleGet(t *testing.T) {
+	journal, err := configjournal.Open(configjournal.Options{Path: filepath.Join(t.TempDir(), "config_db.txt")})
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	defer journal.Close()
+	handler := &ConfigJournalHandler{journal: func() *configjournal.Journal { return journal }}
+
+	old := time.Now().Add(-2 * time.Hour)
+	journal.Write(configjournal.Record{Time: old, Database: "CONFIG_DB", Op: "hset", Key: "PORT|Ethernet0", After: map[string]string{"mtu": "9100"}})
+	journal.Write(configjournal.Record{Database: "CONFIG_DB", Op: "hset", Key: "INTERFACE|Ethernet0|10.0.0.1/31", Author: configjournal.Author{User: "admin", RequestID: "r1"}})
+
+	data, err := handler.HandleGet(configJournalPath(nil))
+	if err != nil {
+		t.Fatalf("HandleGet failed: %v", err)
+	}
+	var records []configjournal.Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatalf("failed to unmarshal records: %v", err)
+	}
+	if len(records) != 2 || records[0].Key != "PORT|Ethernet0" || records[1].User != "admin" {
+		t.Errorf("unexpected records: %+v", records)
+	}
+
+	data, err = handler.HandleGet(configJournalPath(map[string]string{"since": "1h"}))
+	if err != nil {
+		t.Fatalf("HandleGet since failed: %v", err)
+	}
+	records = nil
+	json.Unmarshal(data, &records)
+	if len(records) != 1 || records[0].RequestID != "r1" {
+		t.Errorf("unexpected records since 1h: %+v", records)
+	}
+
+	data, err = handler.HandleGet(configJournalPath(map[string]string{"key": "VLAN|"}))
+	if err != nil {
+		t.Fatalf("HandleGet by key failed: %v", err)
+	}
+	if string(data) != "[]" {
+		t.Errorf("expected no records, got %s", data)
+	}
+
+	if _, err := handler.HandleGet(configJournalPath(map[string]string{"since": "yesterday"})); err == nil {
+		t.Error("expected error for invalid since")
+	}
+
+	disabled := &ConfigJournalHandler{journal: func() *configjournal.Journal { return nil }}
+	if _, err := disabled.HandleGet(configJournalPath(nil)); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Errorf("expected disabled error, got %v", err)
+	}
+}
+
+func TestOperationalHandler_ConfigJournalPath(t *testing.T) {
+	for _, key := range []map[string]string{nil, {"key": "INTERFACE|Ethernet0|10.0.0.1/31"}} {
+		if _, err := NewOperationalHandler([]*gnmipb.Path{configJournalPath(key)}, nil); err != nil {
+			t.Errorf("config-journal path %v not supported: %v", key, err)
+		}
+	}
+}
@@ -5,6 +5,8 @@
 //
 // The operational handler supports paths like:
 //   - /sonic/system/filesystem[path=*]/disk-space
+//   - /sonic/system/sessions and /sonic/system/sessions[id=*]
+//   - /sonic/config-journal[since=*]
 //
 // Example usage:
 //
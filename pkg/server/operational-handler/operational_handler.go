@@ -67,6 +67,16 @@ func NewOperationalHandler(paths []*gnmipb.Path, prefix *gnmipb.Path) (Handler,
 		handler.pathHandlers[supportedPath] = diskSpaceHandler
 	}
 
+	sessionsHandler := NewSessionsHandler()
+	for _, supportedPath := range sessionsHandler.SupportedPaths() {
+		handler.pathHandlers[supportedPath] = sessionsHandler
+	}
+
+	journalHandler := NewConfigJournalHandler()
+	for _, supportedPath := range journalHandler.SupportedPaths() {
+		handler.pathHandlers[supportedPath] = journalHandler
+	}
+
 	// Register file listing handler if filesystem/files paths are requested
 	needsFileHandler := false
 	for _, path := range paths {
@@ -151,6 +161,19 @@ func (h *OperationalHandler) pathMatches(requestedPath, supportedPath string) bo
 		return false
 	}
 
+	if supportedPath == "sessions" {
+		// Match paths like "sonic/system/sessions" and "sonic/system/sessions[id=*]"
+		elems := strings.Split(requestedPath, "/")
+		last := elems[len(elems)-1]
+		return last == "sessions" || strings.HasPrefix(last, "sessions[")
+	}
+
+	if supportedPath == "config-journal" {
+		// Match paths like "sonic/config-journal" and "sonic/config-journal[since=*]",
+		// whose key values may contain slashes
+		return strings.HasSuffix(requestedPath, "config-journal") || strings.Contains(requestedPath, "config-journal[")
+	}
+
 	// Legacy support for firmware paths (deprecated, use filesystem/files instead)
 	if supportedPath == "firmware/files" {
 		// Match paths like "firmware[directory=*]/files", "firmware[directory=*]/files/count", etc.
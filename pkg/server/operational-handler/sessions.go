@@ -0,0 +1,59 @@
+package operationalhandler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/session"
+)
+
+// SessionsHandler implements PathHandler for the live view of streaming
+// gNMI and gNOI sessions, e.g. /sonic/system/sessions.
+type SessionsHandler struct {
+	registry *session.Registry
+}
+
+// NewSessionsHandler creates a new SessionsHandler over the server registry.
+func NewSessionsHandler() *SessionsHandler {
+	return &SessionsHandler{
+		registry: session.Default,
+	}
+}
+
+// SupportedPaths returns the list of paths this handler supports.
+func (h *SessionsHandler) SupportedPaths() []string {
+	return []string{
+		"sessions",
+	}
+}
+
+// HandleGet returns all live sessions, or the single session selected by
+// a key like sessions[id=3].
+func (h *SessionsHandler) HandleGet(path *gnmipb.Path) ([]byte, error) {
+	elems := path.GetElem()
+	if len(elems) == 0 {
+		return nil, fmt.Errorf("path elements cannot be empty")
+	}
+	id, hasID := elems[len(elems)-1].GetKey()["id"]
+
+	sessions := h.registry.List()
+	if !hasID {
+		jsonData, err := json.Marshal(sessions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal sessions: %v", err)
+		}
+		return jsonData, nil
+	}
+
+	for _, info := range sessions {
+		if info.ID == id {
+			jsonData, err := json.Marshal(info)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal session %s: %v", id, err)
+			}
+			return jsonData, nil
+		}
+	}
+	return nil, fmt.Errorf("session %s not found", id)
+}
@@ -0,0 +1,82 @@
+package operationalhandler
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/session"
+)
+
+func sessionsPath(key map[string]string) *gnmipb.Path {
+	return &gnmipb.Path{
+		Elem: []*gnmipb.PathElem{
+			{Name: "sonic"},
+			{Name: "system"},
+			{Name: "sessions", Key: key},
+		},
+	}
+}
+
+func TestSessionsHandler_HandleGet(t *testing.T) {
+	registry := session.NewRegistry()
+	handler := &SessionsHandler{registry: registry}
+
+	first := registry.Start("10.0.0.1:5000", "/gnmi.gNMI/Subscribe")
+	first.SetUser("admin", []string{"gnmi_readonly"})
+	first.SetSubscription("COUNTERS_DB", "", "STREAM", []string{"/COUNTERS/Ethernet0"})
+	second := registry.Start("10.0.0.2:6000", "/gnoi.file.File/Get")
+
+	data, err := handler.HandleGet(sessionsPath(nil))
+	if err != nil {
+		t.Fatalf("HandleGet failed: %v", err)
+	}
+	var sessions []session.Info
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		t.Fatalf("failed to unmarshal sessions: %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions))
+	}
+	if sessions[0].User != "admin" || sessions[0].Target != "COUNTERS_DB" || sessions[1].Peer != "10.0.0.2:6000" {
+		t.Errorf("unexpected sessions: %+v", sessions)
+	}
+
+	data, err = handler.HandleGet(sessionsPath(map[string]string{"id": second.ID()}))
+	if err != nil {
+		t.Fatalf("HandleGet by id failed: %v", err)
+	}
+	var info session.Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatalf("failed to unmarshal session: %v", err)
+	}
+	if info.Method != "/gnoi.file.File/Get" {
+		t.Errorf("unexpected session: %+v", info)
+	}
+
+	registry.End(second)
+	if _, err := handler.HandleGet(sessionsPath(map[string]string{"id": second.ID()})); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error for ended session, got %v", err)
+	}
+}
+
+func TestOperationalHandler_Sessions(t *testing.T) {
+	paths := []*gnmipb.Path{sessionsPath(nil)}
+	handler, err := NewOperationalHandler(paths, &gnmipb.Path{Target: "OPERATIONAL"})
+	if err != nil {
+		t.Fatalf("failed to create operational handler: %v", err)
+	}
+
+	values, err := handler.Get(nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(values) != 1 {
+		t.Fatalf("expected 1 value, got %d", len(values))
+	}
+	var sessions []session.Info
+	if err := json.Unmarshal(values[0].Value.GetJsonVal(), &sessions); err != nil {
+		t.Errorf("expected a JSON session list, got %s: %v", values[0].Value.GetJsonVal(), err)
+	}
+}
@@ -0,0 +1,438 @@
+// Package session keeps a live view of the streaming gNMI and gNOI RPCs served
+// by the process: who opened them, from where, what they are streaming and how
+// far along they are. Sessions are listed in memory and can be published to
+// an external store such as STATE_DB.
+package session
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/golang/glog"
+)
+
+// Info is a point-in-time snapshot of a session.
+type Info struct {
+	ID           string    `json:"id"`
+	Peer         string    `json:"peer"`
+	Method       string    `json:"method"`
+	User         string    `json:"user,omitempty"`
+	Roles        []string  `json:"roles,omitempty"`
+	Target       string    `json:"target,omitempty"`
+	Origin       string    `json:"origin,omitempty"`
+	Mode         string    `json:"mode,omitempty"`
+	Paths        []string  `json:"paths,omitempty"`
+	StartTime    time.Time `json:"start-time"`
+	MessagesSent uint64    `json:"messages-sent"`
+	Errors       uint64    `json:"errors"`
+	QueueDepth   int       `json:"queue-depth"`
+}
+
+// Fields returns the snapshot as a flat field map, as stored in a Redis hash.
+func (i Info) Fields() map[string]string {
+	return map[string]string{
+		"peer":          i.Peer,
+		"method":        i.Method,
+		"user":          i.User,
+		"roles":         strings.Join(i.Roles, ","),
+		"target":        i.Target,
+		"origin":        i.Origin,
+		"mode":          i.Mode,
+		"paths":         strings.Join(i.Paths, ","),
+		"start_time":    i.StartTime.UTC().Format(time.RFC3339),
+		"messages_sent": strconv.FormatUint(i.MessagesSent, 10),
+		"errors":        strconv.FormatUint(i.Errors, 10),
+		"queue_depth":   strconv.Itoa(i.QueueDepth),
+	}
+}
+
+// Session is a single streaming RPC. All methods are safe to call on a nil
+// Session so RPC handlers need not care whether sessions are tracked.
+type Session struct {
+	registry  *Registry
+	id        string
+	peer      string
+	method    string
+	startTime time.Time
+
+	sent   atomic.Uint64
+	errors atomic.Uint64
+
+	// pubMu orders store updates so an ended session is never republished.
+	pubMu sync.Mutex
+	ended bool
+	done  chan struct{}
+
+	mu         sync.Mutex
+	user       string
+	roles      []string
+	target     string
+	origin     string
+	mode       string
+	paths      []string
+	queueDepth func() int
+	killFuncs  []func()
+	killed     bool
+}
+
+// ID returns the identifier of the session, unique within the process.
+func (s *Session) ID() string {
+	if s == nil {
+		return ""
+	}
+	return s.id
+}
+
+// SetUser records the authenticated user and roles of the session.
+func (s *Session) SetUser(user string, roles []string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.user = user
+	s.roles = append([]string{}, roles...)
+	s.mu.Unlock()
+	s.registry.publish(s)
+}
+
+// SetSubscription records what the session streams.
+func (s *Session) SetSubscription(target, origin, mode string, paths []string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.target = target
+	s.origin = origin
+	s.mode = mode
+	s.paths = append([]string{}, paths...)
+	s.mu.Unlock()
+	s.registry.publish(s)
+}
+
+// SetQueueDepthFunc makes the session report the result of fn as its queue
+// depth, e.g. the number of updates waiting to be sent.
+func (s *Session) SetQueueDepthFunc(fn func() int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.queueDepth = fn
+	s.mu.Unlock()
+}
+
+// OnKill registers fn to be called when the session is killed, e.g. to
+// cancel the RPC context or close a subscribe client. If the session was
+// already killed fn is called right away.
+func (s *Session) OnKill(fn func()) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.killed {
+		s.killFuncs = append(s.killFuncs, fn)
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	fn()
+}
+
+// Kill terminates the session by calling the registered kill functions once.
+func (s *Session) Kill() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.killed {
+		s.mu.Unlock()
+		return
+	}
+	s.killed = true
+	killFuncs := s.killFuncs
+	s.killFuncs = nil
+	s.mu.Unlock()
+	for _, fn := range killFuncs {
+		fn()
+	}
+}
+
+// Killed reports whether the session was killed.
+func (s *Session) Killed() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.killed
+}
+
+// IncSent counts a message sent to the peer.
+func (s *Session) IncSent() {
+	if s != nil {
+		s.sent.Add(1)
+	}
+}
+
+// IncErrors counts an error seen by the session.
+func (s *Session) IncErrors() {
+	if s != nil {
+		s.errors.Add(1)
+	}
+}
+
+// Info returns a snapshot of the session.
+func (s *Session) Info() Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := Info{
+		ID:           s.id,
+		Peer:         s.peer,
+		Method:       s.method,
+		User:         s.user,
+		Roles:        s.roles,
+		Target:       s.target,
+		Origin:       s.origin,
+		Mode:         s.mode,
+		Paths:        s.paths,
+		StartTime:    s.startTime,
+		MessagesSent: s.sent.Load(),
+		Errors:       s.errors.Load(),
+	}
+	if s.queueDepth != nil {
+		info.QueueDepth = s.queueDepth()
+	}
+	return info
+}
+
+// Store persists session snapshots outside the process.
+type Store interface {
+	// Set creates or replaces the fields of a session.
+	Set(id string, fields map[string]string) error
+	// Delete removes a session.
+	Delete(id string) error
+}
+
+// Registry tracks the live sessions of the process.
+type Registry struct {
+	nextID     atomic.Uint64
+	draining   atomic.Bool
+	retryAfter atomic.Int64
+
+	mu       sync.RWMutex
+	sessions map[string]*Session
+	store    Store
+}
+
+// Default is the registry of the gNMI server.
+var Default = NewRegistry()
+
+// NewRegistry returns an empty registry without a store.
+func NewRegistry() *Registry {
+	return &Registry{sessions: make(map[string]*Session)}
+}
+
+// SetStore makes the registry publish its sessions to store. Sessions already
+// open are published right away.
+func (r *Registry) SetStore(store Store) {
+	r.mu.Lock()
+	r.store = store
+	r.mu.Unlock()
+	r.Refresh()
+}
+
+// Start registers a new session for an RPC of method opened by peer.
+func (r *Registry) Start(peer, method string) *Session {
+	s := &Session{
+		registry:  r,
+		id:        strconv.FormatUint(r.nextID.Add(1), 10),
+		peer:      peer,
+		method:    method,
+		startTime: time.Now(),
+		done:      make(chan struct{}),
+	}
+	r.mu.Lock()
+	r.sessions[s.id] = s
+	r.mu.Unlock()
+	r.publish(s)
+	return s
+}
+
+// End unregisters the session.
+func (r *Registry) End(s *Session) {
+	if s == nil {
+		return
+	}
+	r.mu.Lock()
+	delete(r.sessions, s.id)
+	store := r.store
+	r.mu.Unlock()
+
+	s.pubMu.Lock()
+	defer s.pubMu.Unlock()
+	if s.ended {
+		return
+	}
+	s.ended = true
+	close(s.done)
+	if store != nil {
+		if err := store.Delete(s.id); err != nil {
+			log.V(1).Infof("Failed to delete session %s: %v", s.id, err)
+		}
+	}
+}
+
+func (r *Registry) live() []*Session {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sessions := make([]*Session, 0, len(r.sessions))
+	for _, s := range r.sessions {
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// List returns a snapshot of all live sessions ordered by ID.
+func (r *Registry) List() []Info {
+	sessions := r.live()
+	infos := make([]Info, 0, len(sessions))
+	for _, s := range sessions {
+		infos = append(infos, s.Info())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		a, _ := strconv.ParseUint(infos[i].ID, 10, 64)
+		b, _ := strconv.ParseUint(infos[j].ID, 10, 64)
+		return a < b
+	})
+	return infos
+}
+
+// Refresh publishes the current counters of every live session.
+func (r *Registry) Refresh() {
+	for _, s := range r.live() {
+		r.publish(s)
+	}
+}
+
+// Kill terminates the live sessions for which match returns true and returns
+// their last snapshot.
+func (r *Registry) Kill(match func(Info) bool) []Info {
+	var killed []Info
+	for _, s := range r.live() {
+		if info := s.Info(); match(info) {
+			s.Kill()
+			killed = append(killed, info)
+		}
+	}
+	return killed
+}
+
+// Draining reports whether the registry refuses new sessions.
+func (r *Registry) Draining() bool {
+	return r.draining.Load()
+}
+
+// Undrain makes the registry accept new sessions again after a drain. A
+// drain still waiting for sessions goes on terminating them at its deadline.
+func (r *Registry) Undrain() {
+	r.draining.Store(false)
+}
+
+// SetRetryAfter sets the delay after which the peers of sessions ended by a
+// drain are told to reconnect. Zero gives no hint.
+func (r *Registry) SetRetryAfter(d time.Duration) {
+	r.retryAfter.Store(int64(d))
+}
+
+// RetryAfter returns the delay set by SetRetryAfter.
+func (r *Registry) RetryAfter() time.Duration {
+	return time.Duration(r.retryAfter.Load())
+}
+
+// Drain makes the registry refuse new sessions and waits for the live ones
+// to end. Sessions still running when ctx is done are killed. It returns the
+// number of sessions that ended on their own and the number killed.
+func (r *Registry) Drain(ctx context.Context) (completed, killed int) {
+	return r.DrainNotify(ctx, nil)
+}
+
+// DrainNotify drains the registry like Drain, except that the sessions for
+// which notify returns true are killed right away instead of waited for.
+// It suits streams such as subscriptions, which only end when the peer
+// leaves: their peers learn at once that they must reconnect.
+func (r *Registry) DrainNotify(ctx context.Context, notify func(Info) bool) (completed, killed int) {
+	r.draining.Store(true)
+	var waiting []*Session
+	for _, s := range r.live() {
+		if notify != nil && notify(s.Info()) {
+			s.Kill()
+			killed++
+			continue
+		}
+		waiting = append(waiting, s)
+	}
+	for _, s := range waiting {
+		select {
+		case <-s.done:
+			completed++
+			continue
+		default:
+		}
+		select {
+		case <-s.done:
+			completed++
+		case <-ctx.Done():
+			s.Kill()
+			killed++
+		}
+	}
+	return completed, killed
+}
+
+// Run refreshes the store every interval until ctx is done.
+func (r *Registry) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Refresh()
+		}
+	}
+}
+
+func (r *Registry) publish(s *Session) {
+	r.mu.RLock()
+	store := r.store
+	r.mu.RUnlock()
+	if store == nil {
+		return
+	}
+	s.pubMu.Lock()
+	defer s.pubMu.Unlock()
+	if s.ended {
+		return
+	}
+	if err := store.Set(s.id, s.Info().Fields()); err != nil {
+		log.V(1).Infof("Failed to publish session %s: %v", s.id, err)
+	}
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying s.
+func NewContext(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// FromContext returns the session carried by ctx, or nil.
+func FromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(contextKey{}).(*Session)
+	return s
+}
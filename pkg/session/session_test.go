@@ -0,0 +1,263 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeStore struct {
+	mu      sync.Mutex
+	entries map[string]map[string]string
+	err     error
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{entries: make(map[string]map[string]string)}
+}
+
+func (f *fakeStore) Set(id string, fields map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.entries[id] = fields
+	return nil
+}
+
+func (f *fakeStore) Delete(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.entries, id)
+	return f.err
+}
+
+func (f *fakeStore) get(id string) (map[string]string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fields, ok := f.entries[id]
+	return fields, ok
+}
+
+func TestRegistry_Lifecycle(t *testing.T) {
+	r := NewRegistry()
+	store := newFakeStore()
+	r.SetStore(store)
+
+	s := r.Start("10.0.0.1:5000", "/gnmi.gNMI/Subscribe")
+	if s.ID() != "1" {
+		t.Errorf("Expected first session ID 1, got %q", s.ID())
+	}
+	if _, ok := store.get(s.ID()); !ok {
+		t.Fatal("Expected session to be published on start")
+	}
+
+	s.SetUser("admin", []string{"gnmi_readwrite"})
+	s.SetSubscription("COUNTERS_DB", "", "STREAM", []string{"/COUNTERS/Ethernet0"})
+	depth := 3
+	s.SetQueueDepthFunc(func() int { return depth })
+	s.IncSent()
+	s.IncSent()
+	s.IncErrors()
+	r.Refresh()
+
+	fields, _ := store.get(s.ID())
+	for field, want := range map[string]string{
+		"peer":          "10.0.0.1:5000",
+		"method":        "/gnmi.gNMI/Subscribe",
+		"user":          "admin",
+		"roles":         "gnmi_readwrite",
+		"target":        "COUNTERS_DB",
+		"mode":          "STREAM",
+		"paths":         "/COUNTERS/Ethernet0",
+		"messages_sent": "2",
+		"errors":        "1",
+		"queue_depth":   "3",
+	} {
+		if fields[field] != want {
+			t.Errorf("Field %s: expected %q, got %q", field, want, fields[field])
+		}
+	}
+
+	sessions := r.List()
+	if len(sessions) != 1 || sessions[0].User != "admin" || sessions[0].MessagesSent != 2 {
+		t.Errorf("Unexpected session list: %+v", sessions)
+	}
+
+	r.End(s)
+	if _, ok := store.get(s.ID()); ok {
+		t.Error("Expected session to be deleted on end")
+	}
+	if len(r.List()) != 0 {
+		t.Error("Expected no live sessions after end")
+	}
+
+	// Late updates must not resurrect the session in the store
+	s.SetUser("admin", nil)
+	r.Refresh()
+	if _, ok := store.get(s.ID()); ok {
+		t.Error("Expected ended session to stay deleted")
+	}
+}
+
+func TestRegistry_ListOrder(t *testing.T) {
+	r := NewRegistry()
+	var sessions []*Session
+	for i := 0; i < 12; i++ {
+		sessions = append(sessions, r.Start("peer", "/gnoi.file.File/Get"))
+	}
+	list := r.List()
+	if len(list) != 12 {
+		t.Fatalf("Expected 12 sessions, got %d", len(list))
+	}
+	for i, info := range list {
+		if info.ID != sessions[i].ID() {
+			t.Errorf("Position %d: expected ID %s, got %s", i, sessions[i].ID(), info.ID)
+		}
+	}
+}
+
+func TestRegistry_SetStorePublishesExisting(t *testing.T) {
+	r := NewRegistry()
+	s := r.Start("peer", "/gnmi.gNMI/Subscribe")
+
+	store := newFakeStore()
+	r.SetStore(store)
+	if _, ok := store.get(s.ID()); !ok {
+		t.Error("Expected open session to be published when the store is set")
+	}
+}
+
+func TestRegistry_StoreErrors(t *testing.T) {
+	r := NewRegistry()
+	store := newFakeStore()
+	store.err = errors.New("redis down")
+	r.SetStore(store)
+
+	// Store failures are logged, never surfaced to the RPC
+	s := r.Start("peer", "/gnmi.gNMI/Subscribe")
+	s.SetUser("admin", nil)
+	r.End(s)
+}
+
+func TestNilSession(t *testing.T) {
+	var s *Session
+	s.SetUser("admin", nil)
+	s.SetSubscription("APPL_DB", "", "POLL", nil)
+	s.SetQueueDepthFunc(func() int { return 0 })
+	s.IncSent()
+	s.IncErrors()
+	if s.ID() != "" {
+		t.Errorf("Expected empty ID for nil session, got %q", s.ID())
+	}
+	NewRegistry().End(s)
+}
+
+func TestContext(t *testing.T) {
+	if FromContext(context.Background()) != nil {
+		t.Error("Expected no session in empty context")
+	}
+	s := NewRegistry().Start("peer", "/gnmi.gNMI/Subscribe")
+	if FromContext(NewContext(context.Background(), s)) != s {
+		t.Error("Expected session from context")
+	}
+}
+
+func TestRegistry_Kill(t *testing.T) {
+	r := NewRegistry()
+	a := r.Start("10.0.0.1:5000", "/gnmi.gNMI/Subscribe")
+	a.SetUser("collector", nil)
+	b := r.Start("10.0.0.2:6000", "/gnmi.gNMI/Subscribe")
+	b.SetUser("controller", nil)
+
+	calls := 0
+	a.OnKill(func() { calls++ })
+	a.OnKill(func() { calls++ })
+
+	killed := r.Kill(func(info Info) bool { return info.User == "collector" })
+	if len(killed) != 1 || killed[0].ID != a.ID() {
+		t.Errorf("Expected only the collector session to be killed, got %+v", killed)
+	}
+	a.Kill()
+	if calls != 2 {
+		t.Errorf("Expected each kill function to run once, got %d calls", calls)
+	}
+
+	// Registering after the kill runs right away
+	late := false
+	a.OnKill(func() { late = true })
+	if !late {
+		t.Error("Expected kill function registered after the kill to run")
+	}
+}
+
+func TestRegistry_Drain(t *testing.T) {
+	r := NewRegistry()
+	finishing := r.Start("peer", "/gnoi.file.File/Get")
+	stuck := r.Start("peer", "/gnmi.gNMI/Subscribe")
+	stuckKilled := false
+	stuck.OnKill(func() { stuckKilled = true })
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		r.End(finishing)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	completed, killed := r.Drain(ctx)
+	if completed != 1 || killed != 1 {
+		t.Errorf("Expected 1 completed and 1 killed, got %d and %d", completed, killed)
+	}
+	if !stuckKilled {
+		t.Error("Expected the session running past the deadline to be killed")
+	}
+	if !r.Draining() {
+		t.Error("Expected registry to be draining")
+	}
+	r.Undrain()
+	if r.Draining() {
+		t.Error("Expected registry to accept sessions after Undrain")
+	}
+}
+
+func TestRegistry_DrainNotify(t *testing.T) {
+	r := NewRegistry()
+	transfer := r.Start("peer", "/gnoi.file.File/Get")
+	subscription := r.Start("peer", "/gnmi.gNMI/Subscribe")
+	subscription.OnKill(func() { r.End(subscription) })
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		if !subscription.Killed() {
+			t.Error("Expected the subscription to be killed before the transfer ends")
+		}
+		r.End(transfer)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	completed, killed := r.DrainNotify(ctx, func(info Info) bool {
+		return info.Method == "/gnmi.gNMI/Subscribe"
+	})
+	if completed != 1 || killed != 1 {
+		t.Errorf("Expected 1 completed and 1 killed, got %d and %d", completed, killed)
+	}
+	if transfer.Killed() {
+		t.Error("Expected the transfer to complete")
+	}
+}
+
+func TestRegistry_RetryAfter(t *testing.T) {
+	r := NewRegistry()
+	if r.RetryAfter() != 0 {
+		t.Errorf("Expected no retry delay by default, got %v", r.RetryAfter())
+	}
+	r.SetRetryAfter(30 * time.Second)
+	if r.RetryAfter() != 30*time.Second {
+		t.Errorf("Expected 30s retry delay, got %v", r.RetryAfter())
+	}
+}
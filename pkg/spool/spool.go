@@ -0,0 +1,285 @@
+// Package spool holds the messages that could not be delivered yet, oldest
+// first, so that they can be sent once the receiver is reachable again.
+//
+// A Spool keeps at most a given number of messages and drops the oldest ones
+// past that limit. It is held either in memory, or in a file that survives
+// restarts and only keeps the position of each message in memory.
+package spool
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// headerSize is the size of the file header, holding the offset of the first
+// message not delivered yet.
+const headerSize = 8
+
+// compactSize is how many bytes of delivered messages a file holds before
+// they are removed.
+const compactSize = 1 << 20
+
+// record is the position of a message in the file: its length precedes it.
+type record struct {
+	off int64
+	len int
+}
+
+// Message is a message of a spool.
+type Message struct {
+	// ID numbers the messages in the order they were pushed.
+	ID   uint64
+	Data []byte
+}
+
+// Spool is a bounded FIFO of messages.
+type Spool struct {
+	limit int
+	ready chan struct{}
+
+	mu sync.Mutex
+	// first is the ID of the oldest message.
+	first   uint64
+	mem     [][]byte
+	path    string
+	file    *os.File
+	recs    []record
+	size    int64
+	dropped uint64
+}
+
+// NewMemory returns a spool holding up to limit messages in memory. The
+// limit is at least one message.
+func NewMemory(limit int) *Spool {
+	return &Spool{limit: max(limit, 1), ready: make(chan struct{}, 1)}
+}
+
+// Open returns the spool kept in the file at path, holding up to limit
+// messages. The messages left in the file by a previous process are kept,
+// except the oldest ones past the limit.
+func Open(path string, limit int) (*Spool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s := &Spool{limit: max(limit, 1), ready: make(chan struct{}, 1), path: path, file: f}
+	if err := s.load(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for len(s.recs) > s.limit {
+		s.popLocked()
+		s.dropped++
+	}
+	if len(s.recs) > 0 {
+		s.signal()
+	}
+	return s, nil
+}
+
+// load reads the positions of the messages of the file, ignoring a message
+// partially written when the previous process stopped.
+func (s *Spool) load() error {
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < headerSize {
+		return s.reset()
+	}
+	var header [headerSize]byte
+	if _, err := s.file.ReadAt(header[:], 0); err != nil {
+		return err
+	}
+	off := int64(binary.BigEndian.Uint64(header[:]))
+	if off < headerSize || off > info.Size() {
+		return errors.New("invalid header")
+	}
+	var prefix [4]byte
+	for off+4 <= info.Size() {
+		if _, err := s.file.ReadAt(prefix[:], off); err != nil {
+			return err
+		}
+		n := int(binary.BigEndian.Uint32(prefix[:]))
+		if off+4+int64(n) > info.Size() {
+			break
+		}
+		s.recs = append(s.recs, record{off: off, len: n})
+		off += 4 + int64(n)
+	}
+	s.size = off
+	return s.file.Truncate(off)
+}
+
+// Push appends msg, dropping the oldest message when the spool is full. It
+// returns whether a message was dropped.
+func (s *Spool) Push(msg []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dropped := false
+	if s.count() >= s.limit {
+		if err := s.popLocked(); err != nil {
+			return false, err
+		}
+		s.dropped++
+		dropped = true
+	}
+	if s.file == nil {
+		s.mem = append(s.mem, msg)
+	} else {
+		buf := make([]byte, 4+len(msg))
+		binary.BigEndian.PutUint32(buf, uint32(len(msg)))
+		copy(buf[4:], msg)
+		if _, err := s.file.WriteAt(buf, s.size); err != nil {
+			return dropped, err
+		}
+		s.recs = append(s.recs, record{off: s.size, len: len(msg)})
+		s.size += int64(len(buf))
+	}
+	s.signal()
+	return dropped, nil
+}
+
+// Peek returns the oldest message, or nil when the spool is empty.
+func (s *Spool) Peek() (*Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		if len(s.mem) == 0 {
+			return nil, nil
+		}
+		return &Message{ID: s.first, Data: s.mem[0]}, nil
+	}
+	if len(s.recs) == 0 {
+		return nil, nil
+	}
+	r := s.recs[0]
+	data := make([]byte, r.len)
+	if _, err := s.file.ReadAt(data, r.off+4); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return &Message{ID: s.first, Data: data}, nil
+}
+
+// Pop removes the message id once delivered, unless it was dropped since it
+// was peeked.
+func (s *Spool) Pop(id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count() == 0 || s.first != id {
+		return nil
+	}
+	return s.popLocked()
+}
+
+func (s *Spool) popLocked() error {
+	if s.count() == 0 {
+		return nil
+	}
+	s.first++
+	if s.file == nil {
+		s.mem[0] = nil
+		s.mem = s.mem[1:]
+		return nil
+	}
+	s.recs = s.recs[1:]
+	if len(s.recs) == 0 {
+		return s.reset()
+	}
+	head := s.recs[0].off
+	if head > compactSize && head > s.size/2 {
+		return s.compact()
+	}
+	return s.writeHeader(head)
+}
+
+// reset empties the file.
+func (s *Spool) reset() error {
+	s.recs = nil
+	s.size = headerSize
+	if err := s.file.Truncate(headerSize); err != nil {
+		return err
+	}
+	return s.writeHeader(headerSize)
+}
+
+// compact moves the messages not delivered yet to the start of the file.
+func (s *Spool) compact() error {
+	head := s.recs[0].off
+	data := make([]byte, s.size-head)
+	if _, err := s.file.ReadAt(data, head); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	var header [headerSize]byte
+	binary.BigEndian.PutUint64(header[:], headerSize)
+	if _, err := f.Write(append(header[:], data...)); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	s.file.Close()
+	s.file = f
+	for i := range s.recs {
+		s.recs[i].off -= head - headerSize
+	}
+	s.size -= head - headerSize
+	return nil
+}
+
+func (s *Spool) writeHeader(head int64) error {
+	var header [headerSize]byte
+	binary.BigEndian.PutUint64(header[:], uint64(head))
+	_, err := s.file.WriteAt(header[:], 0)
+	return err
+}
+
+func (s *Spool) signal() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Ready returns a channel receiving a value after messages were pushed.
+func (s *Spool) Ready() <-chan struct{} {
+	return s.ready
+}
+
+func (s *Spool) count() int {
+	if s.file == nil {
+		return len(s.mem)
+	}
+	return len(s.recs)
+}
+
+// Stats returns the number of messages held and dropped so far.
+func (s *Spool) Stats() (held int, dropped uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count(), s.dropped
+}
+
+// Close releases the file of the spool, keeping the messages not delivered
+// yet for the next Open.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
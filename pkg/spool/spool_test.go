@@ -0,0 +1,141 @@
+package spool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// drain pops every message of s and returns their data.
+func drain(t *testing.T, s *Spool) []string {
+	t.Helper()
+	var got []string
+	for {
+		m, err := s.Peek()
+		if err != nil {
+			t.Fatalf("Peek failed: %v", err)
+		}
+		if m == nil {
+			return got
+		}
+		got = append(got, string(m.Data))
+		if err := s.Pop(m.ID); err != nil {
+			t.Fatalf("Pop failed: %v", err)
+		}
+	}
+}
+
+func push(t *testing.T, s *Spool, msgs ...string) {
+	t.Helper()
+	for _, m := range msgs {
+		if _, err := s.Push([]byte(m)); err != nil {
+			t.Fatalf("Push failed: %v", err)
+		}
+	}
+}
+
+func TestSpool(t *testing.T) {
+	for _, mode := range []string{"memory", "file"} {
+		t.Run(mode, func(t *testing.T) {
+			s := NewMemory(3)
+			if mode == "file" {
+				var err error
+				if s, err = Open(filepath.Join(t.TempDir(), "spool"), 3); err != nil {
+					t.Fatalf("Open failed: %v", err)
+				}
+			}
+			defer s.Close()
+
+			if m, err := s.Peek(); m != nil || err != nil {
+				t.Fatalf("Peek of empty spool returned %v, %v", m, err)
+			}
+			push(t, s, "a", "b", "c")
+			select {
+			case <-s.Ready():
+			default:
+				t.Error("Ready not signaled after Push")
+			}
+			if dropped, _ := s.Push([]byte("d")); !dropped {
+				t.Error("Push past the limit did not drop")
+			}
+			if held, dropped := s.Stats(); held != 3 || dropped != 1 {
+				t.Errorf("Stats = %d, %d, want 3, 1", held, dropped)
+			}
+
+			// A message dropped while being delivered is not popped twice
+			m, _ := s.Peek()
+			push(t, s, "e")
+			s.Pop(m.ID)
+			if got := fmt.Sprint(drain(t, s)); got != "[c d e]" {
+				t.Errorf("got %s, want [c d e]", got)
+			}
+		})
+	}
+}
+
+func TestSpool_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool")
+	s, err := Open(path, 10)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	push(t, s, "a", "b", "c")
+	m, _ := s.Peek()
+	s.Pop(m.ID)
+	s.Close()
+
+	// A message partially written is ignored
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.Write([]byte{0, 0, 0, 9, 'x'})
+	f.Close()
+
+	s, err = Open(path, 1)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer s.Close()
+	if _, dropped := s.Stats(); dropped != 1 {
+		t.Errorf("dropped %d messages past the new limit, want 1", dropped)
+	}
+	push(t, s, "d")
+	if got := fmt.Sprint(drain(t, s)); got != "[d]" {
+		t.Errorf("got %s, want [d]", got)
+	}
+
+	if err := os.WriteFile(path, []byte("invalid header"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, 1); err == nil {
+		t.Error("Open of invalid file succeeded")
+	}
+}
+
+func TestSpool_Compact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spool")
+	s, err := Open(path, 100)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer s.Close()
+	big := make([]byte, compactSize/4)
+	for i := 0; i < 8; i++ {
+		push(t, s, string(big))
+	}
+	for i := 0; i < 6; i++ {
+		m, _ := s.Peek()
+		s.Pop(m.ID)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > 5*int64(len(big)) {
+		t.Errorf("file of %d bytes not compacted", info.Size())
+	}
+	push(t, s, "last")
+	got := drain(t, s)
+	if len(got) != 3 || got[2] != "last" {
+		t.Errorf("got %d messages after compaction, want 3", len(got))
+	}
+}
@@ -0,0 +1,335 @@
+// Package tacacs is a minimal TACACS+ client (RFC 8907) for checking user
+// passwords with PAP and reading the authorization attributes of users.
+// Each exchange opens its own connection. Servers are tried in order, moving
+// to the next one only when a server cannot be reached or reports an error.
+package tacacs
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+)
+
+// DefaultPort is the TCP port of TACACS+ servers.
+const DefaultPort = "49"
+
+// ErrRejected is returned when a server denies the credentials or the
+// authorization of a user.
+var ErrRejected = errors.New("rejected by TACACS+ server")
+
+// Packet types
+const (
+	typeAuthen = 0x01
+	typeAuthor = 0x02
+)
+
+// Header flags
+const (
+	flagUnencrypted = 0x01
+)
+
+const (
+	headerLen    = 12
+	maxBodyLen   = 64 * 1024
+	majorVersion = 0xc
+	// PAP authentication uses minor version 1, authorization version 0.
+	versionDefault = majorVersion<<4 | 0x0
+	versionOne     = majorVersion<<4 | 0x1
+)
+
+// Authentication START and REPLY fields
+const (
+	authenActionLogin  = 0x01
+	authenTypePAP      = 0x02
+	authenServiceLogin = 0x01
+	privLvlUser        = 0x01
+	authenMethodTacacs = 0x06
+
+	authenStatusPass  = 0x01
+	authenStatusFail  = 0x02
+	authenStatusError = 0x07
+)
+
+// Authorization RESPONSE status
+const (
+	authorStatusPassAdd  = 0x01
+	authorStatusPassRepl = 0x02
+	authorStatusFail     = 0x10
+	authorStatusError    = 0x11
+)
+
+// Client talks to a list of TACACS+ servers sharing one secret.
+type Client struct {
+	// Servers are host:port addresses, a missing port defaults to 49.
+	Servers []string
+	// Secret obfuscates packet bodies, empty sends them in clear.
+	Secret string
+	// Timeout bounds each exchange with a server.
+	Timeout time.Duration
+	// Port is reported to servers as the port the user connected to.
+	Port string
+}
+
+// packet is a decoded TACACS+ packet.
+type packet struct {
+	version   byte
+	kind      byte
+	seq       byte
+	flags     byte
+	sessionID uint32
+	body      []byte
+}
+
+// Authenticate checks the password of user with PAP. remAddr is the address
+// the user connects from. It returns ErrRejected when the server denies the
+// credentials.
+func (c *Client) Authenticate(ctx context.Context, user, password, remAddr string) error {
+	if len(user) > 255 || len(remAddr) > 255 || len(password) > 255 {
+		return errors.New("TACACS+ field longer than 255 bytes")
+	}
+	body := []byte{authenActionLogin, privLvlUser, authenTypePAP, authenServiceLogin,
+		byte(len(user)), byte(len(c.Port)), byte(len(remAddr)), byte(len(password))}
+	body = append(body, user...)
+	body = append(body, c.Port...)
+	body = append(body, remAddr...)
+	body = append(body, password...)
+
+	reply, err := c.exchange(ctx, typeAuthen, versionOne, body, func(reply *packet) error {
+		if len(reply.body) < 1 || reply.body[0] != authenStatusError {
+			return nil
+		}
+		return fmt.Errorf("authentication error: %s", authenMessage(reply.body))
+	})
+	if err != nil {
+		return err
+	}
+	switch status := reply.body[0]; status {
+	case authenStatusPass:
+		return nil
+	case authenStatusFail:
+		return ErrRejected
+	default:
+		return fmt.Errorf("unsupported TACACS+ authentication status %#x", status)
+	}
+}
+
+// Authorize asks the server for the attributes of user given the request
+// attributes args, e.g. "service=shell". It returns the attributes of the
+// response as attribute to value, or ErrRejected when the server denies the
+// request.
+func (c *Client) Authorize(ctx context.Context, user, remAddr string, args []string) (map[string]string, error) {
+	if len(user) > 255 || len(remAddr) > 255 || len(args) > 255 {
+		return nil, errors.New("TACACS+ field longer than 255 bytes")
+	}
+	body := []byte{authenMethodTacacs, privLvlUser, authenTypePAP, authenServiceLogin,
+		byte(len(user)), byte(len(c.Port)), byte(len(remAddr)), byte(len(args))}
+	for _, arg := range args {
+		if len(arg) > 255 {
+			return nil, fmt.Errorf("TACACS+ argument %q longer than 255 bytes", arg)
+		}
+		body = append(body, byte(len(arg)))
+	}
+	body = append(body, user...)
+	body = append(body, c.Port...)
+	body = append(body, remAddr...)
+	for _, arg := range args {
+		body = append(body, arg...)
+	}
+
+	reply, err := c.exchange(ctx, typeAuthor, versionDefault, body, func(reply *packet) error {
+		if len(reply.body) < 1 || reply.body[0] != authorStatusError {
+			return nil
+		}
+		return errors.New("authorization error")
+	})
+	if err != nil {
+		return nil, err
+	}
+	switch status := reply.body[0]; status {
+	case authorStatusPassAdd, authorStatusPassRepl:
+		return parseAuthorResponse(reply.body)
+	case authorStatusFail:
+		return nil, ErrRejected
+	default:
+		return nil, fmt.Errorf("unsupported TACACS+ authorization status %#x", status)
+	}
+}
+
+// exchange sends one request to the first server that answers it and
+// returns the reply. serverErr reports a reply meaning the server failed,
+// upon which the next server is tried.
+func (c *Client) exchange(ctx context.Context, kind, version byte, body []byte, serverErr func(*packet) error) (*packet, error) {
+	if len(c.Servers) == 0 {
+		return nil, errors.New("no TACACS+ server configured")
+	}
+	var errs []string
+	for _, server := range c.Servers {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(strings.Trim(server, "[]"), DefaultPort)
+		}
+		reply, err := c.exchangeWith(ctx, server, kind, version, body)
+		if err == nil {
+			err = serverErr(reply)
+		}
+		if err == nil {
+			return reply, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", server, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("TACACS+ request failed: %s", strings.Join(errs, "; "))
+}
+
+func (c *Client) exchangeWith(ctx context.Context, server string, kind, version byte, body []byte) (*packet, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	request := &packet{version: version, kind: kind, seq: 1, sessionID: rand.Uint32(), body: body}
+	if err := writePacket(conn, request, c.Secret); err != nil {
+		return nil, err
+	}
+	reply, err := readPacket(conn, c.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if reply.kind != kind || reply.seq != 2 || reply.sessionID != request.sessionID {
+		return nil, errors.New("unexpected reply")
+	}
+	if len(reply.body) == 0 {
+		return nil, errors.New("empty reply")
+	}
+	return reply, nil
+}
+
+// authenMessage returns the server message of an authentication REPLY.
+func authenMessage(body []byte) string {
+	if len(body) < 6 {
+		return ""
+	}
+	n := int(binary.BigEndian.Uint16(body[2:4]))
+	if len(body) < 6+n {
+		return ""
+	}
+	return string(body[6 : 6+n])
+}
+
+// parseAuthorResponse decodes the attributes of an authorization RESPONSE.
+func parseAuthorResponse(body []byte) (map[string]string, error) {
+	if len(body) < 6 {
+		return nil, errors.New("short authorization response")
+	}
+	argCnt := int(body[1])
+	msgLen := int(binary.BigEndian.Uint16(body[2:4]))
+	dataLen := int(binary.BigEndian.Uint16(body[4:6]))
+	offset := 6 + argCnt
+	if len(body) < offset {
+		return nil, errors.New("short authorization response")
+	}
+	lens := body[6:offset]
+	offset += msgLen + dataLen
+	attrs := make(map[string]string, argCnt)
+	for _, n := range lens {
+		if len(body) < offset+int(n) {
+			return nil, errors.New("short authorization response")
+		}
+		arg := string(body[offset : offset+int(n)])
+		offset += int(n)
+		// Mandatory attributes use =, optional ones *
+		if i := strings.IndexAny(arg, "=*"); i > 0 {
+			attrs[arg[:i]] = arg[i+1:]
+		}
+	}
+	return attrs, nil
+}
+
+// obfuscate XORs body in place with the pseudo pad derived from the header
+// fields and secret, which both encrypts and decrypts it.
+func obfuscate(p *packet, secret string) {
+	if secret == "" {
+		return
+	}
+	var sessionID [4]byte
+	binary.BigEndian.PutUint32(sessionID[:], p.sessionID)
+	var prev []byte
+	for i := 0; i < len(p.body); i += md5.Size {
+		h := md5.New()
+		h.Write(sessionID[:])
+		h.Write([]byte(secret))
+		h.Write([]byte{p.version, p.seq})
+		h.Write(prev)
+		prev = h.Sum(nil)
+		for j := 0; j < md5.Size && i+j < len(p.body); j++ {
+			p.body[i+j] ^= prev[j]
+		}
+	}
+}
+
+func writePacket(w io.Writer, p *packet, secret string) error {
+	body := append([]byte(nil), p.body...)
+	flags := p.flags
+	if secret == "" {
+		flags |= flagUnencrypted
+	}
+	out := &packet{version: p.version, kind: p.kind, seq: p.seq, flags: flags, sessionID: p.sessionID, body: body}
+	obfuscate(out, secret)
+
+	buf := make([]byte, headerLen, headerLen+len(body))
+	buf[0], buf[1], buf[2], buf[3] = out.version, out.kind, out.seq, out.flags
+	binary.BigEndian.PutUint32(buf[4:8], out.sessionID)
+	binary.BigEndian.PutUint32(buf[8:12], uint32(len(body)))
+	_, err := w.Write(append(buf, out.body...))
+	return err
+}
+
+func readPacket(r io.Reader, secret string) (*packet, error) {
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0]>>4 != majorVersion {
+		return nil, fmt.Errorf("unsupported TACACS+ version %#x", header[0])
+	}
+	n := binary.BigEndian.Uint32(header[8:12])
+	if n > maxBodyLen {
+		return nil, fmt.Errorf("TACACS+ packet of %d bytes too large", n)
+	}
+	p := &packet{
+		version:   header[0],
+		kind:      header[1],
+		seq:       header[2],
+		flags:     header[3],
+		sessionID: binary.BigEndian.Uint32(header[4:8]),
+		body:      make([]byte, n),
+	}
+	if _, err := io.ReadFull(r, p.body); err != nil {
+		return nil, err
+	}
+	if p.flags&flagUnencrypted == 0 {
+		if secret == "" {
+			return nil, errors.New("obfuscated TACACS+ packet without secret")
+		}
+		obfuscate(p, secret)
+	}
+	return p, nil
+}
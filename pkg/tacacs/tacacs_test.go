@@ -0,0 +1,181 @@
+package tacacs
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubServer answers TACACS+ requests for users with a password and
+// authorization attributes.
+type stubServer struct {
+	t         *testing.T
+	lis       net.Listener
+	secret    string
+	passwords map[string]string
+	attrs     map[string][]string
+	failAll   bool
+}
+
+func newStubServer(t *testing.T, secret string) *stubServer {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	s := &stubServer{
+		t:         t,
+		lis:       lis,
+		secret:    secret,
+		passwords: map[string]string{"admin": "secret"},
+		attrs:     map[string][]string{"admin": {"priv-lvl=15", "roles*gnmi_readwrite,gnoi_readonly"}},
+	}
+	go s.serve()
+	t.Cleanup(func() { lis.Close() })
+	return s
+}
+
+func (s *stubServer) addr() string {
+	return s.lis.Addr().String()
+}
+
+func (s *stubServer) serve() {
+	for {
+		conn, err := s.lis.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *stubServer) handle(conn net.Conn) {
+	defer conn.Close()
+	req, err := readPacket(conn, s.secret)
+	if err != nil {
+		return
+	}
+	reply := &packet{version: req.version, kind: req.kind, seq: req.seq + 1, sessionID: req.sessionID}
+	switch req.kind {
+	case typeAuthen:
+		b := req.body
+		if len(b) < 8 || len(b) != 8+int(b[4])+int(b[5])+int(b[6])+int(b[7]) {
+			return // Garbled, e.g. by another secret
+		}
+		user := string(b[8 : 8+b[4]])
+		password := string(b[8+int(b[4])+int(b[5])+int(b[6]):])
+		status := byte(authenStatusFail)
+		if s.failAll {
+			status = authenStatusError
+		} else if want, ok := s.passwords[user]; ok && want == password && req.version == versionOne {
+			status = authenStatusPass
+		}
+		reply.body = []byte{status, 0, 0, 0, 0, 0}
+	case typeAuthor:
+		b := req.body
+		if len(b) < 8 || len(b) < 8+int(b[7])+int(b[4]) {
+			return
+		}
+		user := string(b[8+int(b[7]) : 8+int(b[7])+int(b[4])])
+		attrs, ok := s.attrs[user]
+		if !ok {
+			reply.body = []byte{authorStatusFail, 0, 0, 0, 0, 0}
+			break
+		}
+		reply.body = []byte{authorStatusPassAdd, byte(len(attrs)), 0, 0, 0, 0}
+		for _, attr := range attrs {
+			reply.body = append(reply.body, byte(len(attr)))
+		}
+		for _, attr := range attrs {
+			reply.body = append(reply.body, attr...)
+		}
+	}
+	writePacket(conn, reply, s.secret)
+}
+
+func TestAuthenticate(t *testing.T) {
+	for _, secret := range []string{"", "tacacs-key"} {
+		server := newStubServer(t, secret)
+		client := &Client{Servers: []string{server.addr()}, Secret: secret, Timeout: time.Second, Port: "gnmi"}
+
+		if err := client.Authenticate(context.Background(), "admin", "secret", "10.0.0.1"); err != nil {
+			t.Errorf("Expected admin to authenticate with secret %q, got %v", secret, err)
+		}
+		if err := client.Authenticate(context.Background(), "admin", "wrong", "10.0.0.1"); !errors.Is(err, ErrRejected) {
+			t.Errorf("Expected wrong password to be rejected with secret %q, got %v", secret, err)
+		}
+	}
+}
+
+func TestAuthenticateWrongSecret(t *testing.T) {
+	server := newStubServer(t, "tacacs-key")
+	client := &Client{Servers: []string{server.addr()}, Secret: "other-key", Timeout: time.Second}
+	if err := client.Authenticate(context.Background(), "admin", "secret", ""); err == nil {
+		t.Error("Expected failure with a secret the server does not share")
+	}
+}
+
+func TestFailover(t *testing.T) {
+	down, _ := net.Listen("tcp", "127.0.0.1:0")
+	downAddr := down.Addr().String()
+	down.Close()
+	broken := newStubServer(t, "key")
+	broken.failAll = true
+	server := newStubServer(t, "key")
+
+	client := &Client{Servers: []string{downAddr, broken.addr(), server.addr()}, Secret: "key", Timeout: time.Second}
+	if err := client.Authenticate(context.Background(), "admin", "secret", ""); err != nil {
+		t.Errorf("Expected the last server to authenticate, got %v", err)
+	}
+
+	// A rejection is final, the next server is not asked
+	client.Servers = []string{server.addr(), downAddr}
+	if err := client.Authenticate(context.Background(), "admin", "wrong", ""); !errors.Is(err, ErrRejected) {
+		t.Errorf("Expected rejection from the first server, got %v", err)
+	}
+
+	client.Servers = []string{downAddr, broken.addr()}
+	err := client.Authenticate(context.Background(), "admin", "secret", "")
+	if err == nil || !strings.Contains(err.Error(), downAddr) || !strings.Contains(err.Error(), broken.addr()) {
+		t.Errorf("Expected an error naming every server, got %v", err)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	server := newStubServer(t, "key")
+	client := &Client{Servers: []string{server.addr()}, Secret: "key", Timeout: time.Second}
+
+	attrs, err := client.Authorize(context.Background(), "admin", "10.0.0.1", []string{"service=sonic-gnmi"})
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	if attrs["priv-lvl"] != "15" || attrs["roles"] != "gnmi_readwrite,gnoi_readonly" {
+		t.Errorf("Unexpected attributes: %v", attrs)
+	}
+	if _, err := client.Authorize(context.Background(), "guest", "", nil); !errors.Is(err, ErrRejected) {
+		t.Errorf("Expected unknown user to be rejected, got %v", err)
+	}
+}
+
+func TestObfuscate(t *testing.T) {
+	body := bytes.Repeat([]byte("0123456789"), 5)
+	p := &packet{version: versionOne, seq: 1, sessionID: 0x12345678, body: append([]byte(nil), body...)}
+	obfuscate(p, "key")
+	if bytes.Equal(p.body, body) {
+		t.Fatal("Expected obfuscated body to differ")
+	}
+	obfuscate(p, "key")
+	if !bytes.Equal(p.body, body) {
+		t.Error("Expected obfuscating twice to restore the body")
+	}
+
+	var buf bytes.Buffer
+	writePacket(&buf, &packet{version: versionDefault, kind: typeAuthor, seq: 1, sessionID: 1, body: body}, "")
+	if header := buf.Bytes(); header[3]&flagUnencrypted == 0 || binary.BigEndian.Uint32(header[8:12]) != uint32(len(body)) {
+		t.Errorf("Unexpected header without secret: %x", header[:headerLen])
+	}
+}
@@ -0,0 +1,200 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OTLP/JSON encoding of spans, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+	BoolValue   *bool   `json:"boolValue,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// OTLP status codes
+const (
+	otlpStatusOk    = 1
+	otlpStatusError = 2
+)
+
+func encodeValue(value interface{}) otlpAnyValue {
+	switch v := value.(type) {
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpAnyValue{IntValue: &s}
+	default:
+		s := fmt.Sprint(v)
+		return otlpAnyValue{StringValue: &s}
+	}
+}
+
+func encodeSpan(data SpanData) otlpSpan {
+	span := otlpSpan{
+		TraceID:           data.TraceID,
+		SpanID:            data.SpanID,
+		ParentSpanID:      data.ParentSpanID,
+		Name:              data.Name,
+		Kind:              data.Kind,
+		StartTimeUnixNano: strconv.FormatInt(data.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(data.End.UnixNano(), 10),
+		Status:            otlpStatus{Code: otlpStatusOk},
+	}
+	for _, attr := range data.Attributes {
+		span.Attributes = append(span.Attributes, otlpKeyValue{Key: attr.Key, Value: encodeValue(attr.Value)})
+	}
+	if data.Failed {
+		span.Status = otlpStatus{Code: otlpStatusError, Message: data.StatusMessage}
+	}
+	return span
+}
+
+func encodeRequest(service string, spans []SpanData) otlpRequest {
+	scope := otlpScopeSpans{Scope: otlpScope{Name: service}}
+	for _, data := range spans {
+		scope.Spans = append(scope.Spans, encodeSpan(data))
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpKeyValue{{Key: "service.name", Value: encodeValue(service)}}},
+		ScopeSpans: []otlpScopeSpans{scope},
+	}}}
+}
+
+// exportTimeout bounds one OTLP export request.
+const exportTimeout = 10 * time.Second
+
+// OTLPExporter posts spans to an OpenTelemetry collector using OTLP/HTTP
+// with JSON encoding.
+type OTLPExporter struct {
+	service string
+	url     string
+	client  *http.Client
+}
+
+// NewOTLPExporter returns an exporter sending the spans of service to
+// endpoint, either host:port of the collector OTLP/HTTP receiver (for example
+// 127.0.0.1:4318) or the full URL of its traces resource.
+func NewOTLPExporter(service, endpoint string) *OTLPExporter {
+	url := endpoint
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = "http://" + endpoint + "/v1/traces"
+	}
+	return &OTLPExporter{service: service, url: url, client: &http.Client{Timeout: exportTimeout}}
+}
+
+// Export sends spans in one request.
+func (e *OTLPExporter) Export(spans []SpanData) error {
+	body, err := json.Marshal(encodeRequest(e.service, spans))
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector %s returned %s", e.url, resp.Status)
+	}
+	return nil
+}
+
+// Close releases idle connections to the collector.
+func (e *OTLPExporter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// FileExporter appends spans to a local file, one OTLP/JSON span per line.
+// It is meant for tests and debugging, the file is never rotated.
+type FileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileExporter opens or creates the file at path for appending.
+func NewFileExporter(path string) (*FileExporter, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file %s: %v", path, err)
+	}
+	return &FileExporter{file: file}, nil
+}
+
+// Export writes spans to the file.
+func (e *FileExporter) Export(spans []SpanData) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, data := range spans {
+		if err := encoder.Encode(encodeSpan(data)); err != nil {
+			return err
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.file.Write(buf.Bytes())
+	return err
+}
+
+// Close closes the file.
+func (e *FileExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.file.Close()
+}
@@ -0,0 +1,216 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/golang/glog"
+)
+
+const (
+	// queueSize bounds the spans waiting for export, newer spans are dropped
+	// when the exporter falls behind.
+	queueSize = 2048
+	// batchSize is the number of spans sent in one export.
+	batchSize = 256
+	// flushInterval is the longest a finished span waits for export.
+	flushInterval = 5 * time.Second
+)
+
+// Exporter sends finished spans to a tracing backend.
+type Exporter interface {
+	Export(spans []SpanData) error
+	Close() error
+}
+
+// Tracer starts server spans and exports finished spans in batches.
+type Tracer struct {
+	service string
+
+	mu       sync.RWMutex
+	exporter Exporter
+	queue    chan *Span
+	flush    chan chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+	dropped  atomic.Uint64
+}
+
+// Default is the tracer of the server, disabled until an exporter is set.
+var Default = NewTracer("sonic-gnmi")
+
+// NewTracer returns a disabled tracer reporting spans for service.
+func NewTracer(service string) *Tracer {
+	return &Tracer{service: service}
+}
+
+// Service returns the service name the spans are reported for.
+func (t *Tracer) Service() string {
+	return t.service
+}
+
+// SetExporter enables the tracer, exporting spans with exporter. A previous
+// exporter is flushed and closed.
+func (t *Tracer) SetExporter(exporter Exporter) {
+	t.Shutdown()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.exporter = exporter
+	t.queue = make(chan *Span, queueSize)
+	t.flush = make(chan chan struct{})
+	t.done = make(chan struct{})
+	t.stopped = make(chan struct{})
+	go t.run(exporter, t.queue, t.flush, t.done, t.stopped)
+}
+
+// Enabled reports whether the tracer has an exporter.
+func (t *Tracer) Enabled() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.exporter != nil
+}
+
+// Flush exports the spans finished so far.
+func (t *Tracer) Flush() {
+	t.mu.RLock()
+	flush, stopped := t.flush, t.stopped
+	t.mu.RUnlock()
+	if flush == nil {
+		return
+	}
+	ack := make(chan struct{})
+	select {
+	case flush <- ack:
+		<-ack
+	case <-stopped:
+	}
+}
+
+// Shutdown exports the remaining spans, closes the exporter and disables the
+// tracer.
+func (t *Tracer) Shutdown() error {
+	t.mu.Lock()
+	exporter, done, stopped := t.exporter, t.done, t.stopped
+	t.exporter, t.queue, t.flush, t.done, t.stopped = nil, nil, nil, nil, nil
+	t.mu.Unlock()
+	if exporter == nil {
+		return nil
+	}
+	close(done)
+	<-stopped
+	return exporter.Close()
+}
+
+// StartServer starts the span of an RPC received by the server. It continues
+// the trace remembered by Extract, or starts a new trace.
+func (t *Tracer) StartServer(ctx context.Context, name string) (context.Context, *Span) {
+	if !t.Enabled() {
+		return ctx, nil
+	}
+	var traceID [16]byte
+	var parentID [8]byte
+	if remote, ok := ctx.Value(remoteKey{}).(spanContext); ok {
+		traceID, parentID = remote.traceID, remote.spanID
+	} else {
+		randomID(traceID[:])
+	}
+	s := t.newSpan(name, KindServer, traceID, parentID)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (t *Tracer) newSpan(name string, kind SpanKind, traceID [16]byte, parentID [8]byte) *Span {
+	s := &Span{tracer: t}
+	s.sc.traceID = traceID
+	randomID(s.sc.spanID[:])
+	s.data = SpanData{
+		TraceID: hex.EncodeToString(traceID[:]),
+		SpanID:  hex.EncodeToString(s.sc.spanID[:]),
+		Name:    name,
+		Kind:    kind,
+		Start:   time.Now(),
+	}
+	if parentID != ([8]byte{}) {
+		s.data.ParentSpanID = hex.EncodeToString(parentID[:])
+	}
+	return s
+}
+
+// enqueue hands a finished span to the export loop, dropping it when the
+// queue is full or the tracer was shut down.
+func (t *Tracer) enqueue(s *Span) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.queue == nil {
+		return
+	}
+	select {
+	case t.queue <- s:
+	default:
+		if dropped := t.dropped.Add(1); dropped%1000 == 1 {
+			log.Warningf("Tracing queue full, %d spans dropped so far", dropped)
+		}
+	}
+}
+
+func (t *Tracer) run(exporter Exporter, queue chan *Span, flush chan chan struct{}, done, stopped chan struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var batch []SpanData
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := exporter.Export(batch); err != nil {
+			log.Errorf("Failed to export %d spans: %v", len(batch), err)
+		}
+		batch = nil
+	}
+	// drain moves the queued spans to the batch, exporting full batches
+	drain := func() {
+		for {
+			select {
+			case s := <-queue:
+				batch = append(batch, s.snapshot())
+				if len(batch) >= batchSize {
+					export()
+				}
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case s := <-queue:
+			batch = append(batch, s.snapshot())
+			if len(batch) >= batchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case ack := <-flush:
+			drain()
+			export()
+			close(ack)
+		case <-done:
+			drain()
+			export()
+			return
+		}
+	}
+}
+
+// snapshot copies the data of an ended span.
+func (s *Span) snapshot() SpanData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := s.data
+	data.Attributes = append([]Attribute(nil), s.data.Attributes...)
+	return data
+}
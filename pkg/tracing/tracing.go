@@ -0,0 +1,217 @@
+// Package tracing records OpenTelemetry compatible spans for the work done by
+// the gNMI server. A server span is started per RPC, continuing the W3C trace
+// context received in the gRPC metadata, and code along the request path adds
+// child spans for the operations worth timing. Spans are exported in batches
+// over OTLP/HTTP or to a local file.
+//
+// Tracing is disabled until an exporter is set, in which case starting a span
+// returns a nil *Span. All Span methods are safe to call on nil, so call sites
+// need no checks.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// SpanKind is the OTLP kind of a span.
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// traceparentHeader is the W3C trace context header, sent as gRPC metadata.
+const traceparentHeader = "traceparent"
+
+// spanContext identifies a span within a trace.
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+}
+
+// Attribute is a key and a string, int64 or bool value.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// SpanData is a finished span as handed to exporters.
+type SpanData struct {
+	TraceID       string
+	SpanID        string
+	ParentSpanID  string
+	Name          string
+	Kind          SpanKind
+	Start         time.Time
+	End           time.Time
+	Attributes    []Attribute
+	Failed        bool
+	StatusMessage string
+}
+
+// Span is an operation being timed.
+type Span struct {
+	tracer *Tracer
+	sc     spanContext
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SetAttribute records a property of the operation. Values other than
+// strings, integers and booleans are recorded as strings.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	switch v := value.(type) {
+	case string, bool, int64:
+	case int:
+		value = int64(v)
+	case int32:
+		value = int64(v)
+	case uint32:
+		value = int64(v)
+	default:
+		value = fmt.Sprint(v)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, Attribute{Key: key, Value: value})
+}
+
+// RecordError marks the span as failed when err is not nil.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Failed = true
+	s.data.StatusMessage = err.Error()
+}
+
+// End finishes the span and queues it for export. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	s.mu.Unlock()
+	s.tracer.enqueue(s)
+}
+
+// TraceID returns the hex encoded ID of the trace of the span.
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.sc.traceID[:])
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// FromContext returns the span carried by ctx, or nil.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// Start starts a child of the span carried by ctx. Without a span in ctx no
+// span is started, so code below an untraced entry point costs nothing.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := FromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	s := parent.tracer.newSpan(name, KindInternal, parent.sc.traceID, parent.sc.spanID)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// Extract returns a copy of ctx remembering the trace context received in the
+// incoming gRPC metadata, so that the next server span continues that trace.
+func Extract(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	values := md.Get(traceparentHeader)
+	if len(values) == 0 {
+		return ctx
+	}
+	sc, ok := parseTraceparent(values[0])
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// Inject returns a copy of ctx whose outgoing gRPC metadata carries the trace
+// context of the span in ctx, so that servers called with it join the trace.
+func Inject(ctx context.Context) context.Context {
+	s := FromContext(ctx)
+	if s == nil {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, traceparentHeader, formatTraceparent(s.sc))
+}
+
+// formatTraceparent encodes sc as a sampled W3C traceparent.
+func formatTraceparent(sc spanContext) string {
+	return fmt.Sprintf("00-%s-%s-01", hex.EncodeToString(sc.traceID[:]), hex.EncodeToString(sc.spanID[:]))
+}
+
+// parseTraceparent decodes a version 00 W3C traceparent. Unsampled and
+// invalid values are rejected.
+func parseTraceparent(value string) (spanContext, bool) {
+	var sc spanContext
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.traceID[:], []byte(parts[1])); err != nil {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.spanID[:], []byte(parts[2])); err != nil {
+		return sc, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || flags[0]&0x01 == 0 {
+		return sc, false
+	}
+	if sc.traceID == ([16]byte{}) || sc.spanID == ([8]byte{}) {
+		return sc, false
+	}
+	return sc, true
+}
+
+func randomID(id []byte) {
+	for {
+		for i := range id {
+			id[i] = byte(rand.Uint32())
+		}
+		for _, b := range id {
+			if b != 0 {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type memoryExporter struct {
+	mu     sync.Mutex
+	spans  []SpanData
+	closed bool
+}
+
+func (m *memoryExporter) Export(spans []SpanData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.spans = append(m.spans, spans...)
+	return nil
+}
+
+func (m *memoryExporter) Close() error {
+	m.closed = true
+	return nil
+}
+
+func (m *memoryExporter) byName() map[string]SpanData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	spans := make(map[string]SpanData)
+	for _, span := range m.spans {
+		spans[span.Name] = span
+	}
+	return spans
+}
+
+func TestDisabled(t *testing.T) {
+	tracer := NewTracer("test")
+	ctx, span := tracer.StartServer(context.Background(), "/gnmi.gNMI/Set")
+	if span != nil || FromContext(ctx) != nil {
+		t.Fatal("Expected no span while tracing is disabled")
+	}
+	if _, child := Start(ctx, "child"); child != nil {
+		t.Error("Expected no child span without a parent")
+	}
+	span.SetAttribute("key", "value")
+	span.RecordError(errors.New("failed"))
+	span.End()
+	if Inject(ctx) != ctx {
+		t.Error("Expected Inject to leave a context without span unchanged")
+	}
+}
+
+func TestSpans(t *testing.T) {
+	tracer := NewTracer("test")
+	exporter := &memoryExporter{}
+	tracer.SetExporter(exporter)
+
+	ctx, server := tracer.StartServer(context.Background(), "/gnmi.gNMI/Set")
+	server.SetAttribute("rpc.system", "grpc")
+	childCtx, child := Start(ctx, "MixedDbClient.Set")
+	child.SetAttribute("count", 3)
+	_, grandchild := Start(childCtx, "dbus ApplyPatchDb")
+	grandchild.RecordError(errors.New("patch rejected"))
+	grandchild.End()
+	child.End()
+	child.End()
+	server.End()
+	tracer.Flush()
+
+	spans := exporter.byName()
+	if len(exporter.spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(exporter.spans))
+	}
+	s, c, g := spans["/gnmi.gNMI/Set"], spans["MixedDbClient.Set"], spans["dbus ApplyPatchDb"]
+	if s.Kind != KindServer || s.ParentSpanID != "" || s.TraceID != server.TraceID() {
+		t.Errorf("Unexpected server span: %+v", s)
+	}
+	if c.TraceID != s.TraceID || c.ParentSpanID != s.SpanID || g.ParentSpanID != c.SpanID {
+		t.Errorf("Expected spans to form one trace: %+v %+v %+v", s, c, g)
+	}
+	if len(c.Attributes) != 1 || c.Attributes[0].Value != int64(3) {
+		t.Errorf("Expected integer attribute, got %v", c.Attributes)
+	}
+	if !g.Failed || g.StatusMessage != "patch rejected" || c.Failed {
+		t.Errorf("Unexpected status: %+v %+v", c, g)
+	}
+	if s.End.Before(s.Start) {
+		t.Errorf("Expected end after start: %+v", s)
+	}
+
+	if err := tracer.Shutdown(); err != nil || !exporter.closed || tracer.Enabled() {
+		t.Errorf("Expected shutdown to close the exporter, got %v", err)
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	tracer := NewTracer("test")
+	exporter := &memoryExporter{}
+	tracer.SetExporter(exporter)
+	defer tracer.Shutdown()
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", traceparent))
+	ctx, span := tracer.StartServer(Extract(ctx), "/gnmi.gNMI/Get")
+	if span.TraceID() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the trace of the caller, got %s", span.TraceID())
+	}
+
+	md, _ := metadata.FromOutgoingContext(Inject(ctx))
+	out := md.Get("traceparent")
+	if len(out) != 1 || !strings.HasPrefix(out[0], "00-4bf92f3577b34da6a3ce929d0e0e4736-") || strings.Contains(out[0], "00f067aa0ba902b7") {
+		t.Errorf("Expected the span to be sent as parent, got %v", out)
+	}
+	span.End()
+	tracer.Flush()
+	if got := exporter.byName()["/gnmi.gNMI/Get"]; got.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("Expected remote parent, got %+v", got)
+	}
+
+	for _, invalid := range []string{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", // Not sampled
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"garbage",
+	} {
+		if _, ok := parseTraceparent(invalid); ok {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	exporter, err := NewFileExporter(path)
+	if err != nil {
+		t.Fatalf("NewFileExporter failed: %v", err)
+	}
+	tracer := NewTracer("test")
+	tracer.SetExporter(exporter)
+	ctx, span := tracer.StartServer(context.Background(), "/gnmi.gNMI/Set")
+	_, child := Start(ctx, "translib.Bulk")
+	child.End()
+	span.End()
+	if err := tracer.Shutdown(); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	file, _ := os.Open(path)
+	defer file.Close()
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var span map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("Invalid span line %q: %v", scanner.Text(), err)
+		}
+		names = append(names, span["name"].(string))
+	}
+	if strings.Join(names, ",") != "translib.Bulk,/gnmi.gNMI/Set" {
+		t.Errorf("Unexpected spans in file: %v", names)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var body []byte
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter("sonic-gnmi", strings.TrimPrefix(collector.URL, "http://"))
+	tracer := NewTracer("sonic-gnmi")
+	tracer.SetExporter(exporter)
+	_, span := tracer.StartServer(context.Background(), "/gnmi.gNMI/Set")
+	span.SetAttribute("rpc.grpc.status_code", int64(0))
+	span.SetAttribute("cached", true)
+	span.RecordError(errors.New("failed"))
+	span.End()
+	tracer.Shutdown()
+
+	var req otlpRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		t.Fatalf("Collector received invalid request %q: %v", body, err)
+	}
+	rs := req.ResourceSpans[0]
+	if *rs.Resource.Attributes[0].Value.StringValue != "sonic-gnmi" {
+		t.Errorf("Unexpected resource: %+v", rs.Resource)
+	}
+	got := rs.ScopeSpans[0].Spans[0]
+	if got.Name != "/gnmi.gNMI/Set" || got.Kind != KindServer || got.Status.Code != otlpStatusError || len(got.TraceID) != 32 {
+		t.Errorf("Unexpected span: %+v", got)
+	}
+	if *got.Attributes[0].Value.IntValue != "0" || !*got.Attributes[1].Value.BoolValue {
+		t.Errorf("Unexpected attributes: %+v", got.Attributes)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	if err := NewOTLPExporter("sonic-gnmi", failing.URL+"/v1/traces").Export([]SpanData{{Name: "span"}}); err == nil {
+		t.Error("Expected error when the collector rejects spans")
+	}
+}
@@ -0,0 +1,248 @@
+// Package tunnel carries gRPC connections over tunnels in the openconfig
+// grpctunnel protocol. A switch opens a tunnel to the tunnel server of a
+// collector, which then dials the gNMI and gNOI services of the switch over
+// it with standard clients, even when the switch is behind NAT.
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/grpctunnel/tunnel"
+	"google.golang.org/grpc"
+
+	tpb "github.com/openconfig/grpctunnel/proto/tunnel"
+)
+
+// TargetType is the type of the targets serving gNMI and gNOI.
+const TargetType = "GNMI_GNOI"
+
+// Target is a target registered over a tunnel, identified by its ID and type.
+type Target = tunnel.Target
+
+// Addr is the address of a connection carried over a tunnel.
+type Addr struct {
+	// Tunnel is the address of the tunnel server.
+	Tunnel string
+	// Session numbers the connections of the tunnel.
+	Session uint64
+}
+
+// Network returns "tunnel".
+func (a Addr) Network() string { return "tunnel" }
+
+func (a Addr) String() string { return fmt.Sprintf("%s#%d", a.Tunnel, a.Session) }
+
+// conn is a connection carried over a tunnel, without deadlines.
+type conn struct {
+	io.ReadWriteCloser
+	local, remote net.Addr
+}
+
+func (c *conn) LocalAddr() net.Addr                { return c.local }
+func (c *conn) RemoteAddr() net.Addr               { return c.remote }
+func (c *conn) SetDeadline(t time.Time) error      { return nil }
+func (c *conn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *conn) SetWriteDeadline(t time.Time) error { return nil }
+
+// Listener accepts the connections that a collector opens to the targets of
+// the switch over a tunnel. The tunnel is opened again when it fails.
+type Listener struct {
+	addr    string
+	targets map[Target]struct{}
+	retry   time.Duration
+	opts    []grpc.DialOption
+
+	sessions atomic.Uint64
+	conns    chan net.Conn
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// Listen returns a listener for the connections to targets over a tunnel to
+// the tunnel server at addr, dialed with opts. It retries every retry until
+// the tunnel can be opened, in the background.
+func Listen(addr string, targets []Target, retry time.Duration, opts ...grpc.DialOption) *Listener {
+	l := &Listener{
+		addr:    addr,
+		targets: make(map[Target]struct{}),
+		retry:   retry,
+		opts:    opts,
+		conns:   make(chan net.Conn),
+	}
+	for _, t := range targets {
+		l.targets[t] = struct{}{}
+	}
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+	go l.run()
+	return l
+}
+
+func (l *Listener) run() {
+	for {
+		err := l.connect()
+		select {
+		case <-l.ctx.Done():
+			return
+		default:
+		}
+		log.V(1).Infof("Tunnel to %s failed: %v, retrying in %v", l.addr, err, l.retry)
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-time.After(l.retry):
+		}
+	}
+}
+
+// connect registers the targets over a new tunnel and accepts the sessions
+// of the tunnel server until the tunnel fails.
+func (l *Listener) connect() error {
+	cc, err := grpc.DialContext(l.ctx, l.addr, l.opts...)
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+	ctx, cancel := context.WithCancel(l.ctx)
+	defer cancel()
+
+	client, err := tunnel.NewClient(tpb.NewTunnelClient(cc), tunnel.ClientConfig{
+		RegisterHandler: func(t Target) error {
+			if _, ok := l.targets[t]; !ok {
+				return fmt.Errorf("unknown target %s of type %s", t.ID, t.Type)
+			}
+			return nil
+		},
+		Handler: func(t Target, rwc io.ReadWriteCloser) error {
+			c := &conn{
+				ReadWriteCloser: rwc,
+				local:           Addr{Tunnel: t.ID},
+				remote:          Addr{Tunnel: l.addr, Session: l.sessions.Add(1)},
+			}
+			select {
+			case l.conns <- c:
+				log.V(2).Infof("Tunnel session %v to %s opened", c.remote, t.ID)
+				return nil
+			case <-ctx.Done():
+				rwc.Close()
+				return ctx.Err()
+			}
+		},
+	}, l.targets)
+	if err != nil {
+		return err
+	}
+	if err := client.Register(ctx); err != nil {
+		return err
+	}
+	log.V(1).Infof("Tunnel to %s opened", l.addr)
+	client.Start(ctx)
+	return client.Error()
+}
+
+// Accept waits for the next connection of the tunnel server.
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.ctx.Done():
+		return nil, net.ErrClosed
+	}
+}
+
+// Close closes the tunnel. The connections already accepted are kept.
+func (l *Listener) Close() error {
+	l.cancel()
+	return nil
+}
+
+// Addr returns the address of the tunnel server.
+func (l *Listener) Addr() net.Addr {
+	return Addr{Tunnel: l.addr}
+}
+
+// Server is the tunnel server of a collector, to which switches open tunnels
+// to register their targets.
+type Server struct {
+	ts *tunnel.Server
+
+	mu      sync.Mutex
+	targets map[Target]struct{}
+}
+
+// NewServer returns a tunnel server, calling added in a new goroutine for
+// every target registered. added may be nil.
+func NewServer(added func(Target)) (*Server, error) {
+	s := &Server{targets: make(map[Target]struct{})}
+	ts, err := tunnel.NewServer(tunnel.ServerConfig{
+		AddTargetHandler: func(t Target) error {
+			s.mu.Lock()
+			s.targets[t] = struct{}{}
+			s.mu.Unlock()
+			log.V(1).Infof("Tunnel target %s of type %s registered", t.ID, t.Type)
+			if added != nil {
+				go added(t)
+			}
+			return nil
+		},
+		DeleteTargetHandler: func(t Target) error {
+			s.mu.Lock()
+			delete(s.targets, t)
+			s.mu.Unlock()
+			log.V(1).Infof("Tunnel target %s of type %s deleted", t.ID, t.Type)
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.ts = ts
+	return s, nil
+}
+
+// Register registers the tunnel service on gs.
+func (s *Server) Register(gs *grpc.Server) {
+	tpb.RegisterTunnelServer(gs, s.ts)
+}
+
+// Targets returns the targets registered, sorted by ID and type.
+func (s *Server) Targets() []Target {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	targets := make([]Target, 0, len(s.targets))
+	for t := range s.targets {
+		targets = append(targets, t)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].ID != targets[j].ID {
+			return targets[i].ID < targets[j].ID
+		}
+		return targets[i].Type < targets[j].Type
+	})
+	return targets
+}
+
+// Dial returns a client connection to target over its tunnel, dialed with
+// opts like a connection to t.ID.
+func (s *Server) Dial(ctx context.Context, t Target, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	var sessions atomic.Uint64
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		rwc, err := s.ts.NewSession(ctx, tunnel.ServerSession{Target: t})
+		if err != nil {
+			return nil, err
+		}
+		return &conn{
+			ReadWriteCloser: rwc,
+			local:           Addr{},
+			remote:          Addr{Tunnel: t.ID, Session: sessions.Add(1)},
+		}, nil
+	}
+	return grpc.DialContext(ctx, t.ID, append(opts, grpc.WithContextDialer(dialer))...)
+}
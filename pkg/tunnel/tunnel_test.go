@@ -0,0 +1,120 @@
+package tunnel
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// gnmiServer answers Get with the address of its peer.
+type gnmiServer struct {
+	gnmipb.UnimplementedGNMIServer
+}
+
+func (s *gnmiServer) Get(ctx context.Context, req *gnmipb.GetRequest) (*gnmipb.GetResponse, error) {
+	p, _ := peer.FromContext(ctx)
+	return &gnmipb.GetResponse{Notification: []*gnmipb.Notification{{
+		Update: []*gnmipb.Update{{Val: &gnmipb.TypedValue{Value: &gnmipb.TypedValue_StringVal{StringVal: p.Addr.String()}}}},
+	}}}, nil
+}
+
+// startServer serves a tunnel server on lis, reporting the targets added.
+func startServer(t *testing.T, lis net.Listener) (*Server, *grpc.Server, chan Target) {
+	added := make(chan Target, 10)
+	s, err := NewServer(func(target Target) { added <- target })
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	gs := grpc.NewServer()
+	s.Register(gs)
+	go gs.Serve(lis)
+	return s, gs, added
+}
+
+func waitTarget(t *testing.T, added chan Target) Target {
+	t.Helper()
+	select {
+	case target := <-added:
+		return target
+	case <-time.After(10 * time.Second):
+		t.Fatal("target not registered")
+	}
+	return Target{}
+}
+
+func get(t *testing.T, s *Server, target Target) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cc, err := s.Dial(ctx, target, grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer cc.Close()
+	resp, err := gnmipb.NewGNMIClient(cc).Get(ctx, &gnmipb.GetRequest{})
+	if err != nil {
+		t.Fatalf("Get over tunnel failed: %v", err)
+	}
+	return resp.GetNotification()[0].GetUpdate()[0].GetVal().GetStringVal()
+}
+
+func TestTunnel(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	s, gs, added := startServer(t, lis)
+
+	switchTarget := Target{ID: "switch1", Type: TargetType}
+	tl := Listen(addr, []Target{switchTarget}, 100*time.Millisecond, grpc.WithInsecure())
+	defer tl.Close()
+	sw := grpc.NewServer()
+	gnmipb.RegisterGNMIServer(sw, &gnmiServer{})
+	go sw.Serve(tl)
+	defer sw.Stop()
+
+	if target := waitTarget(t, added); target != switchTarget {
+		t.Fatalf("registered %v, want %v", target, switchTarget)
+	}
+	if got := s.Targets(); len(got) != 1 || got[0] != switchTarget {
+		t.Errorf("Targets = %v", got)
+	}
+	if got, want := get(t, s, switchTarget), addr+"#1"; got != want {
+		t.Errorf("peer of the session = %s, want %s", got, want)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := s.Dial(ctx, Target{ID: "switch2", Type: TargetType}, grpc.WithInsecure(), grpc.WithBlock()); err == nil {
+		t.Error("Dial of an unknown target succeeded")
+	}
+
+	// The tunnel is opened again once the tunnel server is back
+	gs.Stop()
+	lis, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, gs, added = startServer(t, lis)
+	defer gs.Stop()
+	waitTarget(t, added)
+	if got, want := get(t, s, switchTarget), addr+"#2"; got != want {
+		t.Errorf("peer of the session = %s, want %s", got, want)
+	}
+}
+
+func TestListener_Close(t *testing.T) {
+	tl := Listen("127.0.0.1:1", nil, time.Hour, grpc.WithInsecure())
+	if got := tl.Addr().String(); got != "127.0.0.1:1#0" {
+		t.Errorf("Addr = %s", got)
+	}
+	tl.Close()
+	if _, err := tl.Accept(); err != net.ErrClosed {
+		t.Errorf("Accept after Close returned %v, want %v", err, net.ErrClosed)
+	}
+}
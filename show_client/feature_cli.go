@@ -0,0 +1,146 @@
+package show_client
+
+import (
+	"encoding/json"
+	"strings"
+
+	log "github.com/golang/glog"
+	sdc "github.com/sonic-net/sonic-gnmi/sonic_data_client"
+)
+
+const (
+	featureTable          = "FEATURE"
+	containerStateMissing = "not created"
+)
+
+var (
+	dockerPsCommand = "docker ps -a --format \"{{.Names}}|{{.State}}|{{.Status}}|{{.Image}}\""
+)
+
+type ContainerState struct {
+	Name   string `json:"name"`
+	State  string `json:"state"`
+	Status string `json:"status"`
+	Image  string `json:"image"`
+}
+
+type FeatureStatus struct {
+	Feature        string `json:"feature"`
+	State          string `json:"state"`
+	AutoRestart    string `json:"auto_restart"`
+	SystemState    string `json:"system_state"`
+	UpdateTime     string `json:"update_time"`
+	ContainerId    string `json:"container_id"`
+	Version        string `json:"container_version"`
+	SetOwner       string `json:"set_owner"`
+	CurrentOwner   string `json:"current_owner"`
+	RemoteState    string `json:"remote_state"`
+	ContainerState string `json:"container_state"`
+}
+
+// getContainerStates returns the state of all containers keyed by container name
+func getContainerStates() (map[string]ContainerState, error) {
+	output, err := GetDataFromHostCommand(dockerPsCommand)
+	if err != nil {
+		log.Errorf("Unable to successfully execute command %v, get err %v", dockerPsCommand, err)
+		return nil, err
+	}
+
+	containers := make(map[string]ContainerState)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) != 4 {
+			continue
+		}
+		containers[fields[0]] = ContainerState{
+			Name:   fields[0],
+			State:  fields[1],
+			Status: fields[2],
+			Image:  fields[3],
+		}
+	}
+	return containers, nil
+}
+
+func getServices(options sdc.OptionMap) ([]byte, error) {
+	containers, err := getContainerStates()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(containers))
+	for name := range containers {
+		names = append(names, name)
+	}
+	names = natsortInterfaces(names)
+
+	services := make([]ContainerState, 0, len(names))
+	for _, name := range names {
+		services = append(services, containers[name])
+	}
+	return json.Marshal(services)
+}
+
+func getFeatureStatus(options sdc.OptionMap) ([]byte, error) {
+	featureName, _ := options["feature"].String()
+
+	configQuery := []string{ConfigDB, featureTable}
+	stateQuery := []string{StateDB, featureTable}
+	if featureName != "" {
+		configQuery = append(configQuery, featureName)
+		stateQuery = append(stateQuery, featureName)
+	}
+
+	configData, err := GetMapFromQueries([][]string{configQuery})
+	if err != nil {
+		log.Errorf("Unable to get data from queries %v, got err: %v", configQuery, err)
+		return nil, err
+	}
+	stateData, err := GetMapFromQueries([][]string{stateQuery})
+	if err != nil {
+		log.Errorf("Unable to get data from queries %v, got err: %v", stateQuery, err)
+		return nil, err
+	}
+
+	// Queries with a key return the fields directly, normalize to the table layout
+	if featureName != "" {
+		if len(configData) == 0 {
+			return json.Marshal([]FeatureStatus{})
+		}
+		configData = map[string]interface{}{featureName: configData}
+		stateData = map[string]interface{}{featureName: stateData}
+	}
+
+	containers, err := getContainerStates()
+	if err != nil {
+		return nil, err
+	}
+
+	features := make([]string, 0, len(configData))
+	for name := range configData {
+		features = append(features, name)
+	}
+	features = natsortInterfaces(features)
+
+	result := make([]FeatureStatus, 0, len(features))
+	for _, name := range features {
+		containerState := containerStateMissing
+		if container, ok := containers[name]; ok {
+			containerState = container.State
+		}
+		result = append(result, FeatureStatus{
+			Feature:        name,
+			State:          GetFieldValueString(configData, name, platformValueNA, "state"),
+			AutoRestart:    GetFieldValueString(configData, name, platformValueNA, "auto_restart"),
+			SetOwner:       GetFieldValueString(configData, name, "local", "set_owner"),
+			SystemState:    GetFieldValueString(stateData, name, platformValueNA, "system_state"),
+			UpdateTime:     GetFieldValueString(stateData, name, platformValueNA, "update_time"),
+			ContainerId:    GetFieldValueString(stateData, name, platformValueNA, "container_id"),
+			Version:        GetFieldValueString(stateData, name, platformValueNA, "container_version"),
+			CurrentOwner:   GetFieldValueString(stateData, name, platformValueNA, "current_owner"),
+			RemoteState:    GetFieldValueString(stateData, name, platformValueNA, "remote_state"),
+			ContainerState: containerState,
+		})
+	}
+	return json.Marshal(result)
+}
@@ -106,7 +106,12 @@ func getInterfaceCounters(options sdc.OptionMap) ([]byte, error) {
 		return nil, fmt.Errorf("period value must be <= %v", maxShowCommandPeriod)
 	}
 
-	oldSnapshot, err := getInterfaceCountersSnapshot(ifaces)
+	scope, err := getNamespaceScope(options)
+	if err != nil {
+		return nil, err
+	}
+
+	oldSnapshot, err := getInterfaceCountersSnapshot(ifaces, scope)
 	if err != nil {
 		log.Errorf("Unable to get interfaces counter snapshot due to err: %v", err)
 		return nil, err
@@ -118,7 +123,7 @@ func getInterfaceCounters(options sdc.OptionMap) ([]byte, error) {
 
 	SleepFunc(time.Duration(period) * time.Second)
 
-	newSnapshot, err := getInterfaceCountersSnapshot(ifaces)
+	newSnapshot, err := getInterfaceCountersSnapshot(ifaces, scope)
 	if err != nil {
 		log.Errorf("Unable to get new interface counters snapshot due to err %v", err)
 		return nil, err
@@ -130,7 +135,7 @@ func getInterfaceCounters(options sdc.OptionMap) ([]byte, error) {
 	return json.Marshal(diffSnapshot)
 }
 
-func getInterfaceCountersSnapshot(ifaces []string) (map[string]InterfaceCountersResponse, error) {
+func getInterfaceCountersSnapshot(ifaces []string, scope namespaceScope) (map[string]InterfaceCountersResponse, error) {
 	queries := [][]string{
 		{"COUNTERS_DB", "COUNTERS", "Ethernet*"},
 	}
@@ -143,6 +148,22 @@ func getInterfaceCountersSnapshot(ifaces []string) (map[string]InterfaceCounters
 
 	portCounters := RemapAliasToPortName(aliasCountersOutput)
 
+	// Counters of all ASICs are served together, keep the ports of the requested namespaces
+	namespaces := make(map[string]bool, len(scope.namespaces))
+	for _, namespace := range scope.namespaces {
+		namespaces[namespace] = true
+	}
+	portNamespaces := sdc.PortNameToNamespaceMap()
+	for port := range portCounters {
+		if !namespaces[portNamespaces[port]] {
+			delete(portCounters, port)
+		}
+	}
+	portCounters, err = filterPortsInScope(portCounters, scope)
+	if err != nil {
+		return nil, err
+	}
+
 	queries = [][]string{
 		{"COUNTERS_DB", "RATES", "Ethernet*"},
 	}
@@ -159,7 +180,7 @@ func getInterfaceCountersSnapshot(ifaces []string) (map[string]InterfaceCounters
 		{"APPL_DB", "PORT_TABLE"},
 	}
 
-	portTable, err := GetMapFromQueries(queries)
+	portTable, err := GetMapFromQueriesInNamespaces(queries, scope.namespaces)
 	if err != nil {
 		log.Errorf("Unable to pull data for queries %v, got err %v", queries, err)
 		return nil, err
@@ -265,11 +286,16 @@ func getInterfaceErrors(options sdc.OptionMap) ([]byte, error) {
 		return nil, fmt.Errorf("No interface name passed in as option")
 	}
 
+	scope, err := getNamespaceScope(options)
+	if err != nil {
+		return nil, err
+	}
+
 	// Query Port Operational Errors Table from STATE_DB
 	queries := [][]string{
 		{"STATE_DB", "PORT_OPERR_TABLE", intf},
 	}
-	portErrorsTbl, _ := GetMapFromQueries(queries)
+	portErrorsTbl, _ := GetMapFromQueriesInNamespaces(queries, scope.namespaces)
 	portErrorsTbl = RemapAliasToPortName(portErrorsTbl)
 
 	// Format the port errors data
@@ -298,16 +324,23 @@ func getInterfaceErrors(options sdc.OptionMap) ([]byte, error) {
 	return json.Marshal(portErrors)
 }
 
-func getFrontPanelPorts(intf string) ([]string, error) {
+func getFrontPanelPorts(intf string, scope namespaceScope) ([]string, error) {
 	// Get the front panel ports from the SONiC CONFIG_DB
 	queries := [][]string{
 		{"CONFIG_DB", "PORT"},
 	}
-	frontPanelPorts, err := GetMapFromQueries(queries)
+	frontPanelPorts, err := GetMapFromQueriesInNamespaces(queries, scope.namespaces)
 	if err != nil {
 		log.Errorf("Failed to get front panel ports: %v", err)
 		return nil, err
 	}
+	if scope.frontendOnly {
+		for port, entry := range frontPanelPorts {
+			if !isFrontendPort(entry) {
+				delete(frontPanelPorts, port)
+			}
+		}
+	}
 
 	// If intf is specified, return only that interface
 	if intf != "" {
@@ -328,7 +361,12 @@ func getFrontPanelPorts(intf string) ([]string, error) {
 func getInterfaceFecStatus(options sdc.OptionMap) ([]byte, error) {
 	intf, _ := options["interface"].String()
 
-	ports, err := getFrontPanelPorts(intf)
+	scope, err := getNamespaceScope(options)
+	if err != nil {
+		return nil, err
+	}
+
+	ports, err := getFrontPanelPorts(intf, scope)
 	if err != nil {
 		log.Errorf("Failed to get front panel ports: %v", err)
 		return nil, err
@@ -346,7 +384,7 @@ func getInterfaceFecStatus(options sdc.OptionMap) ([]byte, error) {
 		queries := [][]string{
 			{"APPL_DB", AppDBPortTable, port},
 		}
-		data, err := GetMapFromQueries(queries)
+		data, err := GetMapFromQueriesInNamespaces(queries, scope.namespaces)
 		if err != nil {
 			log.Errorf("Failed to get admin FEC status for port %s: %v", port, err)
 			return nil, err
@@ -366,7 +404,7 @@ func getInterfaceFecStatus(options sdc.OptionMap) ([]byte, error) {
 		queries = [][]string{
 			{"STATE_DB", StateDBPortTable, port},
 		}
-		data, err = GetMapFromQueries(queries)
+		data, err = GetMapFromQueriesInNamespaces(queries, scope.namespaces)
 		if err != nil {
 			log.Errorf("Failed to get oper FEC status for port %s: %v", port, err)
 			return nil, err
@@ -1,6 +1,10 @@
 package show_client
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
 	log "github.com/golang/glog"
 	sdc "github.com/sonic-net/sonic-gnmi/sonic_data_client"
 )
@@ -11,6 +15,11 @@ func getTransceiverErrorStatus(options sdc.OptionMap) ([]byte, error) {
 		intf = v
 	}
 
+	scope, err := getNamespaceScope(options)
+	if err != nil {
+		return nil, err
+	}
+
 	var queries [][]string
 	if intf == "" {
 		queries = [][]string{
@@ -22,10 +31,268 @@ func getTransceiverErrorStatus(options sdc.OptionMap) ([]byte, error) {
 		}
 	}
 
-	data, err := GetDataFromQueries(queries)
+	data, err := GetMapFromQueriesInNamespaces(queries, scope.namespaces)
+	if err != nil {
+		log.Errorf("Unable to get data from queries %v, got err: %v", queries, err)
+		return nil, err
+	}
+	if intf == "" {
+		if data, err = filterPortsInScope(data, scope); err != nil {
+			return nil, err
+		}
+	}
+	return sdc.Msi2Bytes(data)
+}
+
+const (
+	transceiverInfoTable         = "TRANSCEIVER_INFO"
+	transceiverDomSensorTable    = "TRANSCEIVER_DOM_SENSOR"
+	transceiverDomThresholdTable = "TRANSCEIVER_DOM_THRESHOLD"
+	transceiverStatusTable       = "TRANSCEIVER_STATUS"
+	transceiverPmTable           = "TRANSCEIVER_PM"
+
+	transceiverPresent    = "Present"
+	transceiverNotPresent = "Not present"
+)
+
+var (
+	transceiverPmSuffixes = []string{"_min", "_avg", "_max", "_high_alarm", "_low_alarm", "_high_warning", "_low_warning"}
+)
+
+type TransceiverEeprom struct {
+	Presence  string                 `json:"presence"`
+	Info      map[string]interface{} `json:"info,omitempty"`
+	Dom       map[string]interface{} `json:"dom,omitempty"`
+	Threshold map[string]interface{} `json:"threshold,omitempty"`
+}
+
+// getTransceiverTable returns the STATE_DB transceiver table keyed by port,
+// restricted to a single port when intf is not empty.
+func getTransceiverTable(table string, intf string, scope namespaceScope) (map[string]interface{}, error) {
+	queries := [][]string{
+		{StateDB, table},
+	}
+	if intf != "" {
+		queries[0] = append(queries[0], intf)
+	}
+
+	data, err := GetMapFromQueriesInNamespaces(queries, scope.namespaces)
 	if err != nil {
 		log.Errorf("Unable to get data from queries %v, got err: %v", queries, err)
 		return nil, err
 	}
-	return data, nil
+
+	// Queries with a key return the fields directly, normalize to the table layout
+	if intf != "" {
+		if len(data) == 0 {
+			return map[string]interface{}{}, nil
+		}
+		return map[string]interface{}{intf: data}, nil
+	}
+	return filterPortsInScope(data, scope)
+}
+
+func getTransceiverPorts(intf string, scope namespaceScope) ([]string, error) {
+	ports, err := getFrontPanelPorts(intf, scope)
+	if err != nil {
+		return nil, err
+	}
+	return natsortInterfaces(ports), nil
+}
+
+func getTransceiverEeprom(options sdc.OptionMap) ([]byte, error) {
+	intf, _ := options["interface"].String()
+	dom, _ := options["dom"].Bool()
+	verbose, _ := options["verbose"].Bool()
+
+	scope, err := getNamespaceScope(options)
+	if err != nil {
+		return nil, err
+	}
+
+	ports, err := getTransceiverPorts(intf, scope)
+	if err != nil {
+		return nil, err
+	}
+	info, err := getTransceiverTable(transceiverInfoTable, intf, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	var sensors, thresholds map[string]interface{}
+	if dom {
+		if sensors, err = getTransceiverTable(transceiverDomSensorTable, intf, scope); err != nil {
+			return nil, err
+		}
+		if verbose {
+			if thresholds, err = getTransceiverTable(transceiverDomThresholdTable, intf, scope); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := make(map[string]TransceiverEeprom, len(ports))
+	for _, port := range ports {
+		portInfo, ok := info[port].(map[string]interface{})
+		if !ok {
+			result[port] = TransceiverEeprom{Presence: transceiverNotPresent}
+			continue
+		}
+		eeprom := TransceiverEeprom{
+			Presence: transceiverPresent,
+			Info:     portInfo,
+		}
+		if entry, ok := sensors[port].(map[string]interface{}); ok {
+			eeprom.Dom = entry
+		}
+		if entry, ok := thresholds[port].(map[string]interface{}); ok {
+			eeprom.Threshold = entry
+		}
+		result[port] = eeprom
+	}
+	return json.Marshal(result)
+}
+
+func getTransceiverPresence(options sdc.OptionMap) ([]byte, error) {
+	intf, _ := options["interface"].String()
+
+	scope, err := getNamespaceScope(options)
+	if err != nil {
+		return nil, err
+	}
+
+	ports, err := getTransceiverPorts(intf, scope)
+	if err != nil {
+		return nil, err
+	}
+	info, err := getTransceiverTable(transceiverInfoTable, intf, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]string, 0, len(ports))
+	for _, port := range ports {
+		presence := transceiverNotPresent
+		if _, ok := info[port]; ok {
+			presence = transceiverPresent
+		}
+		result = append(result, map[string]string{"Port": port, "Presence": presence})
+	}
+	return json.Marshal(result)
+}
+
+func getTransceiverLpmode(options sdc.OptionMap) ([]byte, error) {
+	intf, _ := options["interface"].String()
+
+	scope, err := getNamespaceScope(options)
+	if err != nil {
+		return nil, err
+	}
+
+	ports, err := getTransceiverPorts(intf, scope)
+	if err != nil {
+		return nil, err
+	}
+	statusTable, err := getTransceiverTable(transceiverStatusTable, intf, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]string, 0, len(ports))
+	for _, port := range ports {
+		lpmode := "N/A"
+		switch strings.ToLower(GetFieldValueString(statusTable, port, "", "lpmode")) {
+		case "true", "on":
+			lpmode = "On"
+		case "false", "off":
+			lpmode = "Off"
+		}
+		result = append(result, map[string]string{"Port": port, "Low-power Mode": lpmode})
+	}
+	return json.Marshal(result)
+}
+
+func getTransceiverStatus(options sdc.OptionMap) ([]byte, error) {
+	intf, _ := options["interface"].String()
+	verbose, _ := options["verbose"].Bool()
+
+	scope, err := getNamespaceScope(options)
+	if err != nil {
+		return nil, err
+	}
+
+	statusTable, err := getTransceiverTable(transceiverStatusTable, intf, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// Threshold flags are only reported in verbose mode
+	if !verbose {
+		for _, entry := range statusTable {
+			fields, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for field := range fields {
+				if isTransceiverThresholdField(field) {
+					delete(fields, field)
+				}
+			}
+		}
+	}
+	return json.Marshal(statusTable)
+}
+
+func getTransceiverPm(options sdc.OptionMap) ([]byte, error) {
+	intf, _ := options["interface"].String()
+	verbose, _ := options["verbose"].Bool()
+
+	scope, err := getNamespaceScope(options)
+	if err != nil {
+		return nil, err
+	}
+
+	pmTable, err := getTransceiverTable(transceiverPmTable, intf, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]map[string]string, len(pmTable))
+	for port, entry := range pmTable {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		params := make(map[string]map[string]string)
+		for field, value := range fields {
+			param, stat := splitTransceiverPmField(field)
+			if !verbose && isTransceiverThresholdField(field) {
+				continue
+			}
+			if _, ok := params[param]; !ok {
+				params[param] = make(map[string]string)
+			}
+			params[param][stat] = fmt.Sprint(value)
+		}
+		result[port] = params
+	}
+	return json.Marshal(result)
+}
+
+// splitTransceiverPmField splits a PM field such as 'prefec_ber_avg' or
+// 'prefec_ber_high_alarm' into the parameter and the statistic it holds.
+func splitTransceiverPmField(field string) (string, string) {
+	for _, suffix := range transceiverPmSuffixes {
+		if strings.HasSuffix(field, suffix) {
+			return strings.TrimSuffix(field, suffix), strings.TrimPrefix(suffix, "_")
+		}
+	}
+	return field, "value"
+}
+
+// isTransceiverThresholdField matches threshold values and flags, which are
+// named differently by each xcvrd version (e.g. 'temphighalarm_flag', 'tx1OutputPowerHAlarm')
+func isTransceiverThresholdField(field string) bool {
+	lower := strings.ToLower(field)
+	return strings.Contains(lower, "alarm") || strings.Contains(lower, "warn")
 }
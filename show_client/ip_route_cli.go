@@ -0,0 +1,262 @@
+package show_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/golang/glog"
+	sdc "github.com/sonic-net/sonic-gnmi/sonic_data_client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	ipFamilyV4 = "ip"
+	ipFamilyV6 = "ipv6"
+
+	// Full routing tables can hold millions of prefixes, cap a single response
+	maxRouteResults     = 10000
+	defaultRouteResults = 1000
+)
+
+var vrfNameRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+type RouteTableResponse struct {
+	TotalCount int                        `json:"total_count"`
+	Offset     int                        `json:"offset"`
+	Count      int                        `json:"count"`
+	Routes     map[string]json.RawMessage `json:"routes"`
+}
+
+type vtyshRouteSummary struct {
+	Routes []struct {
+		Fib  int    `json:"fib"`
+		Rib  int    `json:"rib"`
+		Type string `json:"type"`
+	} `json:"routes"`
+	RoutesTotal    int `json:"routesTotal"`
+	RoutesTotalFib int `json:"routesTotalFib"`
+}
+
+type RouteCount struct {
+	Rib int `json:"rib"`
+	Fib int `json:"fib"`
+}
+
+type RouteSummaryResponse struct {
+	Protocols map[string]RouteCount `json:"protocols"`
+	Total     RouteCount            `json:"total"`
+}
+
+// buildVtyshRouteCommand builds 'show ip[v6] route [vrf VRF] [PREFIX|summary] json'
+// after validating the user provided values, since they end up in a host command.
+func buildVtyshRouteCommand(family string, vrf string, prefix string, summary bool) (string, error) {
+	command := "show " + family + " route"
+	if vrf != "" {
+		if !vrfNameRegex.MatchString(vrf) {
+			return "", status.Errorf(codes.InvalidArgument, "invalid vrf name %v", vrf)
+		}
+		// 'vrf all' changes the layout of the vtysh output, only single VRFs are supported
+		if vrf == "all" {
+			return "", status.Errorf(codes.InvalidArgument, "vrf all is not supported, query each vrf separately")
+		}
+		command += " vrf " + vrf
+	}
+	if prefix != "" {
+		ip, _, err := net.ParseCIDR(prefix)
+		if err != nil {
+			ip = net.ParseIP(prefix)
+		}
+		if ip == nil {
+			return "", status.Errorf(codes.InvalidArgument, "invalid prefix %v", prefix)
+		}
+		if (ip.To4() != nil) != (family == ipFamilyV4) {
+			return "", status.Errorf(codes.InvalidArgument, "prefix %v does not match address family %v", prefix, family)
+		}
+		command += " " + prefix
+	}
+	if summary {
+		command += " summary"
+	}
+	return fmt.Sprintf("vtysh -c \"%s json\"", command), nil
+}
+
+func getIPRoute(options sdc.OptionMap) ([]byte, error) {
+	return getRouteTable(ipFamilyV4, options)
+}
+
+func getIPv6Route(options sdc.OptionMap) ([]byte, error) {
+	return getRouteTable(ipFamilyV6, options)
+}
+
+func getRouteTable(family string, options sdc.OptionMap) ([]byte, error) {
+	vrf, _ := options["vrf"].String()
+	prefix, _ := options["prefix"].String()
+
+	limit := defaultRouteResults
+	if v, ok := options["limit"].Int(); ok {
+		limit = v
+	}
+	if limit <= 0 || limit > maxRouteResults {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %v", maxRouteResults)
+	}
+	offset := 0
+	if v, ok := options["offset"].Int(); ok {
+		offset = v
+	}
+	if offset < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "offset must not be negative")
+	}
+
+	command, err := buildVtyshRouteCommand(family, vrf, prefix, false)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := getNamespaceScope(options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Routes learnt by several ASICs are reported once. Full tables are
+	// large, so only the prefixes are read to select the page, and only the
+	// routes of the page are kept.
+	outputs := make([]string, 0, len(scope.namespaces))
+	seen := make(map[string]bool)
+	for _, namespace := range scope.namespaces {
+		vtyshOutput, err := GetDataFromHostCommandInNamespace(command, bgpContainer, namespace)
+		if err != nil {
+			log.Errorf("Unable to successfully execute command %v, get err %v", command, err)
+			return nil, err
+		}
+		err = forEachRoute(vtyshOutput, func(p string, dec *json.Decoder) error {
+			seen[p] = true
+			return skipJSONValue(dec)
+		})
+		if err != nil {
+			log.Errorf("Unable to create response from vtysh output %v", err)
+			return nil, err
+		}
+		outputs = append(outputs, vtyshOutput)
+	}
+
+	prefixes := make([]string, 0, len(seen))
+	for p := range seen {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+
+	page := make(map[string]bool)
+	for i := offset; i < len(prefixes) && i < offset+limit; i++ {
+		page[prefixes[i]] = true
+	}
+	response := RouteTableResponse{
+		TotalCount: len(prefixes),
+		Offset:     offset,
+		Routes:     make(map[string]json.RawMessage),
+	}
+	for _, vtyshOutput := range outputs {
+		err := forEachRoute(vtyshOutput, func(p string, dec *json.Decoder) error {
+			if !page[p] {
+				return skipJSONValue(dec)
+			}
+			var route json.RawMessage
+			if err := dec.Decode(&route); err != nil {
+				return err
+			}
+			response.Routes[p] = route
+			return nil
+		})
+		if err != nil {
+			log.Errorf("Unable to create response from vtysh output %v", err)
+			return nil, err
+		}
+	}
+	response.Count = len(response.Routes)
+	return json.Marshal(response)
+}
+
+// forEachRoute calls fn with the prefix of every route of the vtysh output,
+// fn then reading the route from dec.
+func forEachRoute(vtyshOutput string, fn func(prefix string, dec *json.Decoder) error) error {
+	dec := json.NewDecoder(strings.NewReader(vtyshOutput))
+	if t, err := dec.Token(); err != nil {
+		return err
+	} else if t != json.Delim('{') {
+		return fmt.Errorf("unexpected vtysh output %v", t)
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := fn(t.(string), dec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipJSONValue reads the next value of dec without keeping it.
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func getIPRouteSummary(options sdc.OptionMap) ([]byte, error) {
+	vrf, _ := options["vrf"].String()
+
+	command, err := buildVtyshRouteCommand(ipFamilyV4, vrf, "", true)
+	if err != nil {
+		return nil, err
+	}
+	scope, err := getNamespaceScope(options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Counts of all ASICs are added up
+	response := RouteSummaryResponse{
+		Protocols: make(map[string]RouteCount),
+	}
+	for _, namespace := range scope.namespaces {
+		vtyshOutput, err := GetDataFromHostCommandInNamespace(command, bgpContainer, namespace)
+		if err != nil {
+			log.Errorf("Unable to successfully execute command %v, get err %v", command, err)
+			return nil, err
+		}
+
+		var summary vtyshRouteSummary
+		if err := json.Unmarshal([]byte(vtyshOutput), &summary); err != nil {
+			log.Errorf("Unable to create response from vtysh output %v", err)
+			return nil, err
+		}
+
+		response.Total.Rib += summary.RoutesTotal
+		response.Total.Fib += summary.RoutesTotalFib
+		for _, route := range summary.Routes {
+			count := response.Protocols[route.Type]
+			count.Rib += route.Rib
+			count.Fib += route.Fib
+			response.Protocols[route.Type] = count
+		}
+	}
+	return json.Marshal(response)
+}
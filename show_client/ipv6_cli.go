@@ -42,17 +42,57 @@ var (
 	vtyshBGPIPv6SummaryCommand = "vtysh -c \"show bgp ipv6 summary json\""
 )
 
+const (
+	bgpContainer              = "bgp"
+	bgpInternalNeighborsTable = "BGP_INTERNAL_NEIGHBOR"
+)
+
 func getIPv6BGPSummary(options sdc.OptionMap) ([]byte, error) {
-	// Get data from vtysh command
-	vtyshOutput, err := GetDataFromHostCommand(vtyshBGPIPv6SummaryCommand)
+	scope, err := getNamespaceScope(options)
 	if err != nil {
-		log.Errorf("Unable to succesfully execute command %v, get err %v", vtyshBGPIPv6SummaryCommand, err)
 		return nil, err
 	}
+
+	// Each ASIC runs its own BGP instance, peers of all instances are merged
+	var response IPv6BGPSummaryResponse
+	for i, namespace := range scope.namespaces {
+		summary, err := getIPv6BGPSummaryInNamespace(namespace, scope.frontendOnly)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			response = summary
+			continue
+		}
+		for ip, peer := range summary.IPv6Unicast.Peers {
+			response.IPv6Unicast.Peers[ip] = peer
+		}
+		response.IPv6Unicast.PeerCount += summary.IPv6Unicast.PeerCount
+	}
+
+	ipv6BGPSummaryJSON, err := json.Marshal(response)
+	if err != nil {
+		log.Errorf("Unable to create json data from modified vtysh response %v, got err %v", response, err)
+		return nil, err
+	}
+	return ipv6BGPSummaryJSON, nil
+}
+
+func getIPv6BGPSummaryInNamespace(namespace string, frontendOnly bool) (IPv6BGPSummaryResponse, error) {
 	var vtyshResponse IPv6BGPSummaryResponse
+
+	// Get data from vtysh command
+	vtyshOutput, err := GetDataFromHostCommandInNamespace(vtyshBGPIPv6SummaryCommand, bgpContainer, namespace)
+	if err != nil {
+		log.Errorf("Unable to succesfully execute command %v, get err %v", vtyshBGPIPv6SummaryCommand, err)
+		return vtyshResponse, err
+	}
 	if err := json.Unmarshal([]byte(vtyshOutput), &vtyshResponse); err != nil {
 		log.Errorf("Unable to create response from vtysh output %v", err)
-		return nil, err
+		return vtyshResponse, err
+	}
+	if vtyshResponse.IPv6Unicast.Peers == nil {
+		vtyshResponse.IPv6Unicast.Peers = make(map[string]Peer)
 	}
 
 	// Fetch neighbor name from CONFIG DB
@@ -60,10 +100,28 @@ func getIPv6BGPSummary(options sdc.OptionMap) ([]byte, error) {
 		{"CONFIG_DB", "BGP_NEIGHBOR"},
 	}
 
-	bgpNeighborTableOutput, err := GetMapFromQueries(queries)
+	bgpNeighborTableOutput, err := GetMapFromQueriesInNamespaces(queries, []string{namespace})
 	if err != nil {
 		log.Errorf("Unable to pull data for queries %v, got err %v", queries, err)
-		return nil, err
+		return vtyshResponse, err
+	}
+
+	// Peers between ASICs of the same device are not shown in frontend mode
+	if frontendOnly {
+		queries = [][]string{
+			{"CONFIG_DB", bgpInternalNeighborsTable},
+		}
+		internalNeighbors, err := GetMapFromQueriesInNamespaces(queries, []string{namespace})
+		if err != nil {
+			log.Errorf("Unable to pull data for queries %v, got err %v", queries, err)
+			return vtyshResponse, err
+		}
+		for ip := range internalNeighbors {
+			if _, ok := vtyshResponse.IPv6Unicast.Peers[ip]; ok {
+				delete(vtyshResponse.IPv6Unicast.Peers, ip)
+				vtyshResponse.IPv6Unicast.PeerCount--
+			}
+		}
 	}
 
 	// Modify vtysh data to use neighbor name from CONFIG DB
@@ -82,11 +140,5 @@ func getIPv6BGPSummary(options sdc.OptionMap) ([]byte, error) {
 		peer.NeighborName = neighborName
 		vtyshResponse.IPv6Unicast.Peers[ip] = peer
 	}
-
-	ipv6BGPSummaryJSON, err := json.Marshal(vtyshResponse)
-	if err != nil {
-		log.Errorf("Unable to create json data from modified vtysh response %v, got err %v", vtyshResponse, err)
-		return nil, err
-	}
-	return ipv6BGPSummaryJSON, nil
+	return vtyshResponse, nil
 }
@@ -0,0 +1,407 @@
+package show_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/golang/glog"
+	sdc "github.com/sonic-net/sonic-gnmi/sonic_data_client"
+	sdcfg "github.com/sonic-net/sonic-gnmi/sonic_db_config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	psuInfoTable         = "PSU_INFO"
+	fanInfoTable         = "FAN_INFO"
+	temperatureInfoTable = "TEMPERATURE_INFO"
+	eepromInfoTable      = "EEPROM_INFO"
+	chassisInfoTable     = "CHASSIS_INFO"
+	deviceMetadataTable  = "DEVICE_METADATA"
+	deviceMetadataKey    = "localhost"
+	chassisInfoKey       = "chassis 1"
+
+	platformStatusOK         = "OK"
+	platformStatusNotOK      = "NOT OK"
+	platformStatusNotPresent = "NOT PRESENT"
+	platformValueNA          = "N/A"
+
+	// Threshold status reported per sensor
+	thresholdStatusOK           = "OK"
+	thresholdStatusHigh         = "HIGH"
+	thresholdStatusLow          = "LOW"
+	thresholdStatusCriticalHigh = "CRITICAL HIGH"
+	thresholdStatusCriticalLow  = "CRITICAL LOW"
+	thresholdStatusUnknown      = "UNKNOWN"
+
+	// TLV codes of the ONIE system EEPROM used by the platform summary
+	eepromCodeProductName   = "0x21"
+	eepromCodeSerialNumber  = "0x23"
+	eepromCodeLabelRevision = "0x27"
+)
+
+type PlatformSummary struct {
+	Platform     string `json:"platform"`
+	HwSKU        string `json:"hwsku"`
+	ASIC         string `json:"asic"`
+	ASICCount    int    `json:"asic_count"`
+	SerialNumber string `json:"serial"`
+	ModelNumber  string `json:"model"`
+	HwRevision   string `json:"revision"`
+}
+
+type PsuStatus struct {
+	Index         int    `json:"index"`
+	Name          string `json:"name"`
+	Presence      string `json:"presence"`
+	Status        string `json:"status"`
+	LedStatus     string `json:"led_status"`
+	Model         string `json:"model"`
+	Serial        string `json:"serial"`
+	Revision      string `json:"revision"`
+	Voltage       string `json:"voltage"`
+	Current       string `json:"current"`
+	Power         string `json:"power"`
+	VoltageStatus string `json:"voltage_status"`
+	Temperature   string `json:"temperature"`
+	TempStatus    string `json:"temperature_status"`
+}
+
+type FanStatus struct {
+	Drawer    string `json:"drawer"`
+	LedStatus string `json:"led_status"`
+	Name      string `json:"name"`
+	Speed     string `json:"speed"`
+	Direction string `json:"direction"`
+	Presence  string `json:"presence"`
+	Status    string `json:"status"`
+	Timestamp string `json:"timestamp"`
+}
+
+type TemperatureStatus struct {
+	Sensor          string `json:"sensor"`
+	Temperature     string `json:"temperature"`
+	HighThreshold   string `json:"high_threshold"`
+	LowThreshold    string `json:"low_threshold"`
+	CriticalHighTH  string `json:"critical_high_threshold"`
+	CriticalLowTH   string `json:"critical_low_threshold"`
+	Warning         string `json:"warning"`
+	ThresholdStatus string `json:"threshold_status"`
+	Timestamp       string `json:"timestamp"`
+}
+
+type EepromTlv struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Length string `json:"length"`
+	Value  string `json:"value"`
+}
+
+type SysEeprom struct {
+	IdString      string      `json:"id_string"`
+	Version       string      `json:"version"`
+	TotalLength   string      `json:"total_length"`
+	Tlvs          []EepromTlv `json:"tlvs"`
+	ChecksumValid bool        `json:"checksum_valid"`
+}
+
+// thresholdStatus classifies a sensor reading against its thresholds. Any
+// threshold that is missing or not numeric is ignored.
+func thresholdStatus(value, low, high, criticalLow, criticalHigh string) string {
+	reading, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return thresholdStatusUnknown
+	}
+	exceeds := func(threshold string, above bool) bool {
+		th, err := strconv.ParseFloat(threshold, 64)
+		if err != nil {
+			return false
+		}
+		if above {
+			return reading >= th
+		}
+		return reading <= th
+	}
+
+	switch {
+	case exceeds(criticalHigh, true):
+		return thresholdStatusCriticalHigh
+	case exceeds(criticalLow, false):
+		return thresholdStatusCriticalLow
+	case exceeds(high, true):
+		return thresholdStatusHigh
+	case exceeds(low, false):
+		return thresholdStatusLow
+	default:
+		return thresholdStatusOK
+	}
+}
+
+func getPlatformTable(db string, table string) (map[string]interface{}, error) {
+	queries := [][]string{
+		{db, table},
+	}
+	data, err := GetMapFromQueries(queries)
+	if err != nil {
+		log.Errorf("Unable to get data from queries %v, got err: %v", queries, err)
+		return nil, err
+	}
+	return data, nil
+}
+
+func getPlatformSummary(options sdc.OptionMap) ([]byte, error) {
+	summary, err := collectPlatformSummary()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(summary)
+}
+
+func collectPlatformSummary() (PlatformSummary, error) {
+	queries := [][]string{
+		{ConfigDB, deviceMetadataTable, deviceMetadataKey},
+	}
+	metadata, err := GetMapFromQueries(queries)
+	if err != nil {
+		log.Errorf("Unable to get data from queries %v, got err: %v", queries, err)
+		return PlatformSummary{}, err
+	}
+
+	queries = [][]string{
+		{StateDB, chassisInfoTable, chassisInfoKey},
+	}
+	chassisInfo, err := GetMapFromQueries(queries)
+	if err != nil {
+		log.Errorf("Unable to get data from queries %v, got err: %v", queries, err)
+		return PlatformSummary{}, err
+	}
+
+	eeprom, err := getPlatformTable(StateDB, eepromInfoTable)
+	if err != nil {
+		return PlatformSummary{}, err
+	}
+
+	asicCount := 1
+	if namespaces, err := sdcfg.GetDbNonDefaultNamespaces(); err == nil && len(namespaces) > 0 {
+		asicCount = len(namespaces)
+	}
+
+	summary := PlatformSummary{
+		Platform:     getStringOrDefault(metadata, "platform", platformValueNA),
+		HwSKU:        getStringOrDefault(metadata, "hwsku", platformValueNA),
+		ASIC:         sdc.GetSonicVersionField("asic_type", platformValueNA),
+		ASICCount:    asicCount,
+		SerialNumber: getStringOrDefault(chassisInfo, "serial", GetFieldValueString(eeprom, eepromCodeSerialNumber, platformValueNA, "Value")),
+		ModelNumber:  getStringOrDefault(chassisInfo, "model", GetFieldValueString(eeprom, eepromCodeProductName, platformValueNA, "Value")),
+		HwRevision:   getStringOrDefault(chassisInfo, "revision", GetFieldValueString(eeprom, eepromCodeLabelRevision, platformValueNA, "Value")),
+	}
+	return summary, nil
+}
+
+func getPlatformPsuStatus(options sdc.OptionMap) ([]byte, error) {
+	psuTable, err := getPlatformTable(StateDB, psuInfoTable)
+	if err != nil {
+		return nil, err
+	}
+
+	psuNames := make([]string, 0, len(psuTable))
+	for name := range psuTable {
+		psuNames = append(psuNames, name)
+	}
+	psuNames = natsortInterfaces(psuNames)
+
+	psus := make([]PsuStatus, 0, len(psuNames))
+	for i, name := range psuNames {
+		presence := GetFieldValueString(psuTable, name, "false", "presence")
+		psuStatus := platformStatusNotPresent
+		if presence == "true" {
+			psuStatus = platformStatusNotOK
+			if GetFieldValueString(psuTable, name, "false", "status") == "true" {
+				psuStatus = platformStatusOK
+			}
+		}
+
+		voltage := GetFieldValueString(psuTable, name, platformValueNA, "voltage")
+		temperature := GetFieldValueString(psuTable, name, platformValueNA, "temp")
+		psus = append(psus, PsuStatus{
+			Index:     i + 1,
+			Name:      name,
+			Presence:  presence,
+			Status:    psuStatus,
+			LedStatus: GetFieldValueString(psuTable, name, platformValueNA, "led_status"),
+			Model:     GetFieldValueString(psuTable, name, platformValueNA, "model"),
+			Serial:    GetFieldValueString(psuTable, name, platformValueNA, "serial"),
+			Revision:  GetFieldValueString(psuTable, name, platformValueNA, "revision"),
+			Voltage:   voltage,
+			Current:   GetFieldValueString(psuTable, name, platformValueNA, "current"),
+			Power:     GetFieldValueString(psuTable, name, platformValueNA, "power"),
+			VoltageStatus: thresholdStatus(
+				voltage,
+				GetFieldValueString(psuTable, name, platformValueNA, "voltage_min_threshold"),
+				GetFieldValueString(psuTable, name, platformValueNA, "voltage_max_threshold"),
+				platformValueNA,
+				platformValueNA,
+			),
+			Temperature: temperature,
+			TempStatus: thresholdStatus(
+				temperature,
+				platformValueNA,
+				GetFieldValueString(psuTable, name, platformValueNA, "temp_threshold"),
+				platformValueNA,
+				platformValueNA,
+			),
+		})
+	}
+	return json.Marshal(psus)
+}
+
+func getPlatformFan(options sdc.OptionMap) ([]byte, error) {
+	fanTable, err := getPlatformTable(StateDB, fanInfoTable)
+	if err != nil {
+		return nil, err
+	}
+
+	fanNames := make([]string, 0, len(fanTable))
+	for name := range fanTable {
+		fanNames = append(fanNames, name)
+	}
+	fanNames = natsortInterfaces(fanNames)
+
+	fans := make([]FanStatus, 0, len(fanNames))
+	for _, name := range fanNames {
+		presence := "Not Present"
+		if GetFieldValueString(fanTable, name, "false", "presence") == "true" {
+			presence = "Present"
+		}
+
+		fanStatus := platformValueNA
+		switch GetFieldValueString(fanTable, name, platformValueNA, "status") {
+		case "true":
+			fanStatus = platformStatusOK
+		case "false":
+			fanStatus = platformStatusNotOK
+		}
+
+		speed := GetFieldValueString(fanTable, name, platformValueNA, "speed")
+		if _, err := strconv.ParseFloat(speed, 64); err == nil {
+			speed = speed + "%"
+		}
+
+		fans = append(fans, FanStatus{
+			Drawer:    GetFieldValueString(fanTable, name, platformValueNA, "drawer_name"),
+			LedStatus: GetFieldValueString(fanTable, name, platformValueNA, "led_status"),
+			Name:      name,
+			Speed:     speed,
+			Direction: GetFieldValueString(fanTable, name, platformValueNA, "direction"),
+			Presence:  presence,
+			Status:    fanStatus,
+			Timestamp: GetFieldValueString(fanTable, name, platformValueNA, "timestamp"),
+		})
+	}
+	return json.Marshal(fans)
+}
+
+func getPlatformTemperature(options sdc.OptionMap) ([]byte, error) {
+	tempTable, err := getPlatformTable(StateDB, temperatureInfoTable)
+	if err != nil {
+		return nil, err
+	}
+
+	sensorNames := make([]string, 0, len(tempTable))
+	for name := range tempTable {
+		sensorNames = append(sensorNames, name)
+	}
+	sensorNames = natsortInterfaces(sensorNames)
+
+	sensors := make([]TemperatureStatus, 0, len(sensorNames))
+	for _, name := range sensorNames {
+		sensor := TemperatureStatus{
+			Sensor:         name,
+			Temperature:    GetFieldValueString(tempTable, name, platformValueNA, "temperature"),
+			HighThreshold:  GetFieldValueString(tempTable, name, platformValueNA, "high_threshold"),
+			LowThreshold:   GetFieldValueString(tempTable, name, platformValueNA, "low_threshold"),
+			CriticalHighTH: GetFieldValueString(tempTable, name, platformValueNA, "critical_high_threshold"),
+			CriticalLowTH:  GetFieldValueString(tempTable, name, platformValueNA, "critical_low_threshold"),
+			Warning:        GetFieldValueString(tempTable, name, platformValueNA, "warning_status"),
+			Timestamp:      GetFieldValueString(tempTable, name, platformValueNA, "timestamp"),
+		}
+		sensor.ThresholdStatus = thresholdStatus(
+			sensor.Temperature,
+			sensor.LowThreshold,
+			sensor.HighThreshold,
+			sensor.CriticalLowTH,
+			sensor.CriticalHighTH,
+		)
+		sensors = append(sensors, sensor)
+	}
+	return json.Marshal(sensors)
+}
+
+func getPlatformSyseeprom(options sdc.OptionMap) ([]byte, error) {
+	eeprom, err := getPlatformTable(StateDB, eepromInfoTable)
+	if err != nil {
+		return nil, err
+	}
+
+	// syseepromd sets State.Initialized once all TLVs are written
+	if GetFieldValueString(eeprom, "State", "0", "Initialized") != "1" {
+		return nil, status.Error(codes.Unavailable, "system EEPROM info is not ready")
+	}
+
+	result := SysEeprom{
+		IdString:      GetFieldValueString(eeprom, "TlvHeader", platformValueNA, "Id String"),
+		Version:       GetFieldValueString(eeprom, "TlvHeader", platformValueNA, "Version"),
+		TotalLength:   GetFieldValueString(eeprom, "TlvHeader", platformValueNA, "Total Length"),
+		ChecksumValid: GetFieldValueString(eeprom, "Checksum", "0", "Valid") == "1",
+		Tlvs:          []EepromTlv{},
+	}
+
+	tlvCodes := make([]string, 0, len(eeprom))
+	for key := range eeprom {
+		if strings.HasPrefix(key, "0x") {
+			tlvCodes = append(tlvCodes, key)
+		}
+	}
+	tlvCodes = natsortInterfaces(tlvCodes)
+
+	for _, code := range tlvCodes {
+		entry, ok := eeprom[code].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// Vendor extensions may hold several values under a single code
+		if num, ok := entry["Num_vendor_ext"]; ok {
+			count, err := strconv.Atoi(fmt.Sprint(num))
+			if err != nil {
+				log.Warningf("Invalid Num_vendor_ext %v for EEPROM code %v", num, code)
+				continue
+			}
+			for i := 0; i < count; i++ {
+				result.Tlvs = append(result.Tlvs, EepromTlv{
+					Code:   code,
+					Name:   GetFieldValueString(eeprom, code, platformValueNA, fmt.Sprintf("Name_%d", i)),
+					Length: GetFieldValueString(eeprom, code, platformValueNA, fmt.Sprintf("Len_%d", i)),
+					Value:  GetFieldValueString(eeprom, code, platformValueNA, fmt.Sprintf("Value_%d", i)),
+				})
+			}
+			continue
+		}
+		result.Tlvs = append(result.Tlvs, EepromTlv{
+			Code:   code,
+			Name:   GetFieldValueString(eeprom, code, platformValueNA, "Name"),
+			Length: GetFieldValueString(eeprom, code, platformValueNA, "Len"),
+			Value:  GetFieldValueString(eeprom, code, platformValueNA, "Value"),
+		})
+	}
+	return json.Marshal(result)
+}
+
+func getStringOrDefault(data map[string]interface{}, field string, defaultValue string) string {
+	value, ok := data[field]
+	if !ok {
+		return defaultValue
+	}
+	return fmt.Sprint(value)
+}
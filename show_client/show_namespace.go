@@ -0,0 +1,166 @@
+package show_client
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/golang/glog"
+	sdc "github.com/sonic-net/sonic-gnmi/sonic_data_client"
+	sdcfg "github.com/sonic-net/sonic-gnmi/sonic_db_config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	namespaceOptionAll    = "all"
+	displayOptionAll      = "all"
+	displayOptionFrontend = "frontend"
+
+	asicNamespacePrefix = "asic"
+
+	// Ports of a multi-asic device carry a role in CONFIG_DB PORT, everything
+	// other than external ports (e.g. Int, Inb, Rec) is internal to the device
+	portRoleField    = "role"
+	portRoleExternal = "Ext"
+)
+
+// namespaceScope holds the namespaces a getter works on and whether backplane
+// ports are hidden, as requested through the namespace and display options
+type namespaceScope struct {
+	namespaces   []string
+	frontendOnly bool
+}
+
+// getNamespaceScope resolves the namespace and display options. Without a
+// namespace, or with namespace=all, every ASIC namespace of a multi-asic
+// device is queried, single-asic devices only have the default namespace.
+func getNamespaceScope(options sdc.OptionMap) (namespaceScope, error) {
+	scope := namespaceScope{frontendOnly: true}
+
+	display, ok := options["display"].String()
+	if ok {
+		switch display {
+		case displayOptionAll:
+			scope.frontendOnly = false
+		case displayOptionFrontend:
+		default:
+			return scope, status.Errorf(codes.InvalidArgument, "invalid display option %v, must be %v or %v", display, displayOptionAll, displayOptionFrontend)
+		}
+	}
+
+	isMultiNamespace, err := sdcfg.CheckDbMultiNamespace()
+	if err != nil {
+		return scope, err
+	}
+	defaultNamespace, _ := sdcfg.GetDbDefaultNamespace()
+	namespace, _ := options["namespace"].String()
+
+	if namespace == "" || namespace == namespaceOptionAll {
+		if !isMultiNamespace {
+			scope.namespaces = []string{defaultNamespace}
+			return scope, nil
+		}
+		scope.namespaces, err = sdcfg.GetDbNonDefaultNamespaces()
+		return scope, err
+	}
+
+	if !isMultiNamespace {
+		return scope, status.Errorf(codes.InvalidArgument, "namespace %v is not supported on a single-asic device", namespace)
+	}
+	if _, ok, err := sdcfg.GetDbNamespaceFromTarget(namespace); err != nil {
+		return scope, err
+	} else if !ok {
+		return scope, status.Errorf(codes.InvalidArgument, "invalid namespace %v", namespace)
+	}
+	scope.namespaces = []string{namespace}
+	return scope, nil
+}
+
+// namespaceQueries points the DB of each query to the given namespace,
+// e.g. [CONFIG_DB PORT] becomes [CONFIG_DB/asic0 PORT]
+func namespaceQueries(queries [][]string, namespace string) [][]string {
+	if defaultNamespace, _ := sdcfg.GetDbDefaultNamespace(); namespace == defaultNamespace {
+		return queries
+	}
+	nsQueries := make([][]string, 0, len(queries))
+	for _, q := range queries {
+		nsQuery := append([]string{}, q...)
+		nsQuery[dbIndex] = q[dbIndex] + "/" + namespace
+		nsQueries = append(nsQueries, nsQuery)
+	}
+	return nsQueries
+}
+
+// GetMapFromQueriesInNamespaces runs the queries against every namespace and
+// merges the results, keys (or fields for keyed queries) are unique per ASIC.
+func GetMapFromQueriesInNamespaces(queries [][]string, namespaces []string) (map[string]interface{}, error) {
+	msi := make(map[string]interface{})
+	for _, namespace := range namespaces {
+		data, err := GetMapFromQueries(namespaceQueries(queries, namespace))
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range data {
+			msi[key] = value
+		}
+	}
+	return msi, nil
+}
+
+// GetDataFromHostCommandInNamespace runs the command inside the instance of
+// container serving the namespace, e.g. bgp0 for asic0. Commands for the
+// default namespace run on the host as is.
+func GetDataFromHostCommandInNamespace(command string, container string, namespace string) (string, error) {
+	if defaultNamespace, _ := sdcfg.GetDbDefaultNamespace(); namespace == defaultNamespace {
+		return GetDataFromHostCommand(command)
+	}
+	asicId := strings.TrimPrefix(namespace, asicNamespacePrefix)
+	return GetDataFromHostCommand(fmt.Sprintf("docker exec -i %s%s %s", container, asicId, command))
+}
+
+func isFrontendPort(portEntry interface{}) bool {
+	entry, ok := portEntry.(map[string]interface{})
+	if !ok {
+		return true
+	}
+	role, ok := entry[portRoleField]
+	return !ok || fmt.Sprint(role) == portRoleExternal
+}
+
+// getBackplanePorts returns the internal ports of the namespaces, which are
+// hidden unless display=all is requested
+func getBackplanePorts(namespaces []string) (map[string]bool, error) {
+	queries := [][]string{
+		{ConfigDB, "PORT"},
+	}
+	portTable, err := GetMapFromQueriesInNamespaces(queries, namespaces)
+	if err != nil {
+		log.Errorf("Unable to get data from queries %v, got err: %v", queries, err)
+		return nil, err
+	}
+	backplanePorts := make(map[string]bool)
+	for port, entry := range portTable {
+		if !isFrontendPort(entry) {
+			backplanePorts[port] = true
+		}
+	}
+	return backplanePorts, nil
+}
+
+// filterPortsInScope drops entries of a port keyed table which are backplane
+// ports hidden by the scope
+func filterPortsInScope(data map[string]interface{}, scope namespaceScope) (map[string]interface{}, error) {
+	if !scope.frontendOnly {
+		return data, nil
+	}
+	backplanePorts, err := getBackplanePorts(scope.namespaces)
+	if err != nil {
+		return nil, err
+	}
+	for port := range data {
+		if backplanePorts[port] {
+			delete(data, port)
+		}
+	}
+	return data, nil
+}
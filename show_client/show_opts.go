@@ -6,7 +6,8 @@ import (
 
 const (
 	showCmdOptionUnimplementedDesc = "UNIMPLEMENTED"
-	showCmdOptionDisplayDesc       = "[display=all] No-op since no-multi-asic support"
+	showCmdOptionDisplayDesc       = "[display=frontend] Display frontend ports only, display=all also shows backplane ports"
+	showCmdOptionNamespaceDesc     = "[namespace=TEXT] Query a single ASIC namespace (e.g. asic0), all namespaces by default"
 	showCmdOptionVerboseDesc       = "[verbose=true] Enable verbose output"
 	showCmdOptionInterfacesDesc    = "[interfaces=TEXT] Filter by interfaces name"
 	showCmdOptionInterfaceDesc     = "[interface=TEXT] Filter by single interface name"
@@ -15,6 +16,11 @@ const (
 	showCmdOptionPeriodDesc        = "[period=INTEGER] Display statistics over a specified period (in seconds)"
 	showCmdOptionJsonDesc          = "[json=true] No-op since response is in json format"
 	showCmdOptionDpuDesc           = "[dpu=TEXT] Filter by DPU module name"
+	showCmdOptionFeatureDesc       = "[feature=TEXT] Filter by feature name"
+	showCmdOptionPrefixDesc        = "[prefix=TEXT] Lookup a single IP address or prefix"
+	showCmdOptionVrfDesc           = "[vrf=TEXT] Show routes of a single VRF"
+	showCmdOptionLimitDesc         = "[limit=INTEGER] Maximum number of entries to return"
+	showCmdOptionOffsetDesc        = "[offset=INTEGER] Number of entries to skip, used for pagination"
 )
 
 var (
@@ -26,7 +32,7 @@ var (
 
 	showCmdOptionNamespace = sdc.NewShowCmdOption(
 		"namespace",
-		showCmdOptionUnimplementedDesc,
+		showCmdOptionNamespaceDesc,
 		sdc.StringValue,
 	)
 
@@ -83,4 +89,34 @@ var (
 		showCmdOptionDpuDesc,
 		sdc.StringValue,
 	)
+
+	showCmdOptionFeature = sdc.NewShowCmdOption(
+		"feature",
+		showCmdOptionFeatureDesc,
+		sdc.StringValue,
+	)
+
+	showCmdOptionPrefix = sdc.NewShowCmdOption(
+		"prefix",
+		showCmdOptionPrefixDesc,
+		sdc.StringValue,
+	)
+
+	showCmdOptionVrf = sdc.NewShowCmdOption(
+		"vrf",
+		showCmdOptionVrfDesc,
+		sdc.StringValue,
+	)
+
+	showCmdOptionLimit = sdc.NewShowCmdOption(
+		"limit",
+		showCmdOptionLimitDesc,
+		sdc.IntValue,
+	)
+
+	showCmdOptionOffset = sdc.NewShowCmdOption(
+		"offset",
+		showCmdOptionOffsetDesc,
+		sdc.IntValue,
+	)
 )
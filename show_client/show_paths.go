@@ -54,14 +54,14 @@ func init() {
 		[]string{"SHOW", "ipv6", "bgp", "summary"},
 		getIPv6BGPSummary,
 		nil,
-		sdc.UnimplementedOption(showCmdOptionNamespace),
+		showCmdOptionNamespace,
 		showCmdOptionDisplay,
 	)
 	sdc.RegisterCliPath(
 		[]string{"SHOW", "interface", "counters"},
 		getInterfaceCounters,
 		nil,
-		sdc.UnimplementedOption(showCmdOptionNamespace),
+		showCmdOptionNamespace,
 		showCmdOptionDisplay,
 		showCmdOptionInterfaces,
 		showCmdOptionPeriod,
@@ -73,12 +73,15 @@ func init() {
 		getInterfaceErrors,
 		nil,
 		sdc.RequiredOption(showCmdOptionInterface),
+		showCmdOptionNamespace,
 	)
 	sdc.RegisterCliPath(
 		[]string{"SHOW", "interface", "fec", "status"},
 		getInterfaceFecStatus,
 		nil,
 		showCmdOptionInterface,
+		showCmdOptionNamespace,
+		showCmdOptionDisplay,
 	)
 	sdc.RegisterCliPath(
 		[]string{"SHOW", "watermark", "telemetry", "interval"},
@@ -90,8 +93,123 @@ func init() {
 		getTransceiverErrorStatus,
 		nil,
 		showCmdOptionVerbose,
-		sdc.UnimplementedOption(showCmdOptionNamespace),
+		showCmdOptionNamespace,
 		sdc.UnimplementedOption(showCmdOptionFetchFromHW),
 		showCmdOptionInterface,
+		showCmdOptionDisplay,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "platform", "summary"},
+		getPlatformSummary,
+		nil,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "platform", "psustatus"},
+		getPlatformPsuStatus,
+		nil,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "platform", "fan"},
+		getPlatformFan,
+		nil,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "platform", "temperature"},
+		getPlatformTemperature,
+		nil,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "platform", "syseeprom"},
+		getPlatformSyseeprom,
+		nil,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "version"},
+		getVersion,
+		nil,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "services"},
+		getServices,
+		nil,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "feature", "status"},
+		getFeatureStatus,
+		nil,
+		showCmdOptionFeature,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "ip", "route"},
+		getIPRoute,
+		map[string]string{
+			"summary": "show/ip/route/summary: Summary of routes per protocol",
+		},
+		showCmdOptionPrefix,
+		showCmdOptionVrf,
+		showCmdOptionLimit,
+		showCmdOptionOffset,
+		showCmdOptionNamespace,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "ip", "route", "summary"},
+		getIPRouteSummary,
+		nil,
+		showCmdOptionVrf,
+		showCmdOptionNamespace,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "ipv6", "route"},
+		getIPv6Route,
+		nil,
+		showCmdOptionPrefix,
+		showCmdOptionVrf,
+		showCmdOptionLimit,
+		showCmdOptionOffset,
+		showCmdOptionNamespace,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "interface", "transceiver", "eeprom"},
+		getTransceiverEeprom,
+		nil,
+		showCmdOptionInterface,
+		showCmdOptionDom,
+		showCmdOptionVerbose,
+		showCmdOptionNamespace,
+		showCmdOptionDisplay,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "interface", "transceiver", "presence"},
+		getTransceiverPresence,
+		nil,
+		showCmdOptionInterface,
+		showCmdOptionNamespace,
+		showCmdOptionDisplay,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "interface", "transceiver", "lpmode"},
+		getTransceiverLpmode,
+		nil,
+		showCmdOptionInterface,
+		showCmdOptionNamespace,
+		showCmdOptionDisplay,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "interface", "transceiver", "status"},
+		getTransceiverStatus,
+		nil,
+		showCmdOptionInterface,
+		showCmdOptionVerbose,
+		showCmdOptionNamespace,
+		showCmdOptionDisplay,
+	)
+	sdc.RegisterCliPath(
+		[]string{"SHOW", "interface", "transceiver", "pm"},
+		getTransceiverPm,
+		nil,
+		showCmdOptionInterface,
+		showCmdOptionVerbose,
+		showCmdOptionNamespace,
+		showCmdOptionDisplay,
 	)
 }
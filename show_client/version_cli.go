@@ -0,0 +1,150 @@
+package show_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/golang/glog"
+	sdc "github.com/sonic-net/sonic-gnmi/sonic_data_client"
+)
+
+const ProcUptimeFilePath = "/proc/uptime"
+
+var (
+	dockerImagesCommand = "docker images --format \"{{.Repository}}|{{.Tag}}|{{.ID}}|{{.Size}}\""
+)
+
+type DockerImage struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	ImageID    string `json:"image_id"`
+	Size       string `json:"size"`
+}
+
+type VersionInfo struct {
+	SoftwareVersion string `json:"sonic_software_version"`
+	OSVersion       string `json:"sonic_os_version"`
+	Distribution    string `json:"distribution"`
+	Kernel          string `json:"kernel"`
+	BuildCommit     string `json:"build_commit"`
+	BuildDate       string `json:"build_date"`
+	BuiltBy         string `json:"built_by"`
+	PlatformSummary
+	Uptime       string        `json:"uptime"`
+	DockerImages []DockerImage `json:"docker_images"`
+}
+
+func getVersion(options sdc.OptionMap) ([]byte, error) {
+	summary, err := collectPlatformSummary()
+	if err != nil {
+		return nil, err
+	}
+
+	dockerImages, err := getDockerImages()
+	if err != nil {
+		log.Errorf("Unable to get docker images, got err: %v", err)
+		return nil, err
+	}
+
+	uptime, err := getUptime()
+	if err != nil {
+		log.Errorf("Unable to get uptime from %v, got err: %v", ProcUptimeFilePath, err)
+		return nil, err
+	}
+
+	version := VersionInfo{
+		SoftwareVersion: prefixedVersionField("SONiC.", "build_version"),
+		OSVersion:       sdc.GetSonicVersionField("sonic_os_version", platformValueNA),
+		Distribution:    prefixedVersionField("Debian ", "debian_version"),
+		Kernel:          sdc.GetSonicVersionField("kernel_version", platformValueNA),
+		BuildCommit:     sdc.GetSonicVersionField("commit_id", platformValueNA),
+		BuildDate:       sdc.GetSonicVersionField("build_date", platformValueNA),
+		BuiltBy:         sdc.GetSonicVersionField("built_by", platformValueNA),
+		PlatformSummary: summary,
+		Uptime:          uptime,
+		DockerImages:    dockerImages,
+	}
+	return json.Marshal(version)
+}
+
+// prefixedVersionField returns the field of the version file after prefix,
+// or N/A for the whole value when the field is missing.
+func prefixedVersionField(prefix string, field string) string {
+	value := sdc.GetSonicVersionField(field, "")
+	if value == "" {
+		return platformValueNA
+	}
+	return prefix + value
+}
+
+func getDockerImages() ([]DockerImage, error) {
+	output, err := GetDataFromHostCommand(dockerImagesCommand)
+	if err != nil {
+		log.Errorf("Unable to successfully execute command %v, get err %v", dockerImagesCommand, err)
+		return nil, err
+	}
+
+	images := []DockerImage{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) != 4 {
+			continue
+		}
+		images = append(images, DockerImage{
+			Repository: fields[0],
+			Tag:        fields[1],
+			ImageID:    fields[2],
+			Size:       fields[3],
+		})
+	}
+	return images, nil
+}
+
+// getUptime returns the host uptime in the same form as 'uptime -p'
+func getUptime() (string, error) {
+	data, err := GetDataFromFile(ProcUptimeFilePath)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty content in %v", ProcUptimeFilePath)
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", err
+	}
+	return formatUptime(int64(seconds)), nil
+}
+
+func formatUptime(seconds int64) string {
+	units := []struct {
+		name    string
+		seconds int64
+	}{
+		{"week", 7 * 24 * 3600},
+		{"day", 24 * 3600},
+		{"hour", 3600},
+		{"minute", 60},
+	}
+
+	var parts []string
+	for _, unit := range units {
+		count := seconds / unit.seconds
+		seconds %= unit.seconds
+		if count == 0 {
+			continue
+		}
+		name := unit.name
+		if count > 1 {
+			name += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", count, name))
+	}
+	if len(parts) == 0 {
+		return "up 0 minutes"
+	}
+	return "up " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,205 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Workiva/go-datastructures/queue"
+	log "github.com/golang/glog"
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/configjournal"
+	spb "github.com/sonic-net/sonic-gnmi/proto"
+)
+
+// ConfigJournalClient streams the journal of CONFIG_DB changes for
+// subscriptions to OPERATIONAL /sonic/config-journal. Each record is an
+// update of the path, timestamped with the time of the change. A STREAM
+// subscription first replays the records selected by the keys of the path
+// when it sets since, then sends the new records as they are journaled.
+type ConfigJournalClient struct {
+	prefix  *gnmipb.Path
+	path    *gnmipb.Path
+	query   configjournal.Query
+	journal *configjournal.Journal
+	q       *queue.PriorityQueue
+}
+
+// NewConfigJournalClient returns a client for the single config-journal path
+// of a subscription.
+func NewConfigJournalClient(paths []*gnmipb.Path, prefix *gnmipb.Path) (Client, error) {
+	if len(paths) != 1 {
+		return nil, fmt.Errorf("config journal subscriptions take a single path, got %d", len(paths))
+	}
+	elems := paths[0].GetElem()
+	if len(elems) == 0 || elems[len(elems)-1].GetName() != "config-journal" {
+		return nil, fmt.Errorf("unsupported OPERATIONAL subscription path %v", paths[0])
+	}
+	journal := configjournal.Default()
+	if journal == nil {
+		return nil, fmt.Errorf("config journal is disabled")
+	}
+	query, err := configjournal.ParseQuery(elems[len(elems)-1].GetKey())
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigJournalClient{prefix: prefix, path: paths[0], query: query, journal: journal}, nil
+}
+
+func (c *ConfigJournalClient) String() string {
+	return fmt.Sprintf("ConfigJournalClient Prefix %v", c.prefix.GetTarget())
+}
+
+// toValue returns the update of a record.
+func (c *ConfigJournalClient) toValue(r configjournal.Record) (*spb.Value, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return &spb.Value{
+		Prefix:    c.prefix,
+		Path:      c.path,
+		Timestamp: r.Time.UnixNano(),
+		Val: &gnmipb.TypedValue{
+			Value: &gnmipb.TypedValue_JsonIetfVal{
+				JsonIetfVal: data,
+			}},
+	}, nil
+}
+
+// sendRecords enqueues the records selected by the query followed by a sync
+// response, and returns the sequence number of the last record.
+func (c *ConfigJournalClient) sendRecords() (uint64, error) {
+	var last uint64
+	records, err := c.journal.Query(c.query)
+	if err != nil {
+		return last, err
+	}
+	for _, r := range records {
+		v, err := c.toValue(r)
+		if err != nil {
+			return last, err
+		}
+		c.q.Put(Value{v})
+		last = r.Seq
+	}
+	c.q.Put(Value{
+		&spb.Value{
+			Timestamp:    time.Now().UnixNano(),
+			SyncResponse: true,
+		},
+	})
+	return last, nil
+}
+
+func (c *ConfigJournalClient) StreamRun(q *queue.PriorityQueue, stop chan struct{}, w *sync.WaitGroup, subscribe *gnmipb.SubscriptionList) {
+	defer w.Done()
+	c.q = q
+
+	// Subscribe before replaying so that no record falls in between
+	records, cancel := c.journal.Subscribe()
+	defer cancel()
+
+	// Records up to the last replayed one were written before the replay
+	// read the journal, so they are either replayed or not selected
+	var replayed uint64
+	if c.query.Since.IsZero() {
+		c.q.Put(Value{
+			&spb.Value{
+				Timestamp:    time.Now().UnixNano(),
+				SyncResponse: true,
+			},
+		})
+	} else {
+		var err error
+		if replayed, err = c.sendRecords(); err != nil {
+			putFatalMsg(c.q, fmt.Sprintf("Failed to read config journal: %v", err))
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-stop:
+			log.V(1).Infof("Stopping %v", c)
+			return
+		case r, ok := <-records:
+			if !ok {
+				putFatalMsg(c.q, "Config journal subscription ended, resubscribe with since to resume")
+				return
+			}
+			if r.Seq <= replayed || !c.query.Match(r) {
+				continue
+			}
+			v, err := c.toValue(r)
+			if err != nil {
+				log.V(2).Infof("Failed to encode config journal record: %v", err)
+				continue
+			}
+			c.q.Put(Value{v})
+		}
+	}
+}
+
+func (c *ConfigJournalClient) PollRun(q *queue.PriorityQueue, poll chan struct{}, w *sync.WaitGroup, subscribe *gnmipb.SubscriptionList) {
+	defer w.Done()
+	c.q = q
+	for {
+		_, more := <-poll
+		if !more {
+			log.V(1).Infof("%v poll channel closed, exiting", c)
+			return
+		}
+		if _, err := c.sendRecords(); err != nil {
+			putFatalMsg(c.q, fmt.Sprintf("Failed to read config journal: %v", err))
+			return
+		}
+	}
+}
+
+func (c *ConfigJournalClient) OnceRun(q *queue.PriorityQueue, once chan struct{}, w *sync.WaitGroup, subscribe *gnmipb.SubscriptionList) {
+	defer w.Done()
+	c.q = q
+	if _, more := <-once; !more {
+		log.V(1).Infof("%v once channel closed, exiting", c)
+		return
+	}
+	if _, err := c.sendRecords(); err != nil {
+		putFatalMsg(c.q, fmt.Sprintf("Failed to read config journal: %v", err))
+	}
+}
+
+func (c *ConfigJournalClient) Get(w *sync.WaitGroup) ([]*spb.Value, error) {
+	records, err := c.journal.Query(c.query)
+	if err != nil {
+		return nil, err
+	}
+	var values []*spb.Value
+	for _, r := range records {
+		v, err := c.toValue(r)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (c *ConfigJournalClient) Set(delete []*gnmipb.Path, replace []*gnmipb.Update, update []*gnmipb.Update) error {
+	return fmt.Errorf("set operations not supported on the config journal")
+}
+
+func (c *ConfigJournalClient) Capabilities() []gnmipb.ModelData {
+	return nil
+}
+
+func (c *ConfigJournalClient) Close() error {
+	return nil
+}
+
+func (c *ConfigJournalClient) SentOne(val *Value) {
+}
+
+func (c *ConfigJournalClient) FailedSend() {
+}
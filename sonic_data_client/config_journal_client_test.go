@@ -0,0 +1,92 @@
+package client
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Workiva/go-datastructures/queue"
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/configjournal"
+)
+
+func configJournalPaths(key map[string]string) []*gnmipb.Path {
+	return []*gnmipb.Path{{Elem: []*gnmipb.PathElem{{Name: "sonic"}, {Name: "config-journal", Key: key}}}}
+}
+
+// nextJournalValue returns the next value of q, failing after a second.
+func nextJournalValue(t *testing.T, q *queue.PriorityQueue) Value {
+	t.Helper()
+	received := make(chan queue.Item, 1)
+	go func() {
+		if items, err := q.Get(1); err == nil && len(items) == 1 {
+			received <- items[0]
+		}
+	}()
+	select {
+	case item := <-received:
+		return item.(Value)
+	case <-time.After(time.Second):
+		t.Fatal("no value received")
+	}
+	return Value{}
+}
+
+func TestConfigJournalClient(t *testing.T) {
+	prefix := &gnmipb.Path{Target: "OPERATIONAL"}
+	if _, err := NewConfigJournalClient(configJournalPaths(nil), prefix); err == nil {
+		t.Fatal("expected error while the journal is disabled")
+	}
+
+	journal, err := configjournal.Open(configjournal.Options{Path: filepath.Join(t.TempDir(), "config_db.txt")})
+	if err != nil {
+		t.Fatalf("failed to open journal: %v", err)
+	}
+	defer journal.Close()
+	configjournal.SetDefault(journal)
+	defer configjournal.SetDefault(nil)
+
+	if _, err := NewConfigJournalClient([]*gnmipb.Path{{Elem: []*gnmipb.PathElem{{Name: "sessions"}}}}, prefix); err == nil {
+		t.Fatal("expected error for a path other than config-journal")
+	}
+
+	past := time.Now().Add(-time.Minute)
+	journal.Write(configjournal.Record{Time: past, Op: "hset", Key: "PORT|Ethernet0"})
+	journal.Write(configjournal.Record{Time: past, Op: "hset", Key: "VLAN|Vlan10"})
+
+	dc, err := NewConfigJournalClient(configJournalPaths(map[string]string{"since": "1h", "key": "PORT|"}), prefix)
+	if err != nil {
+		t.Fatalf("NewConfigJournalClient failed: %v", err)
+	}
+	q := queue.NewPriorityQueue(1, false)
+	stop := make(chan struct{})
+	var w sync.WaitGroup
+	w.Add(1)
+	go dc.StreamRun(q, stop, &w, nil)
+
+	// Replayed records come before the sync response
+	var record configjournal.Record
+	if err := json.Unmarshal(nextJournalValue(t, q).GetVal().GetJsonIetfVal(), &record); err != nil || record.Key != "PORT|Ethernet0" {
+		t.Fatalf("unexpected replayed record %+v: %v", record, err)
+	}
+	if !nextJournalValue(t, q).GetSyncResponse() {
+		t.Fatal("expected sync response after the replay")
+	}
+
+	journal.Write(configjournal.Record{Op: "hset", Key: "VLAN|Vlan20"})
+	journal.Write(configjournal.Record{Op: "del", Key: "PORT|Ethernet0", Author: configjournal.Author{User: "admin"}})
+	if err := json.Unmarshal(nextJournalValue(t, q).GetVal().GetJsonIetfVal(), &record); err != nil || record.Op != "del" || record.User != "admin" {
+		t.Fatalf("unexpected live record %+v: %v", record, err)
+	}
+	// Live records are told apart from the replayed ones by their sequence
+	// number, not their time
+	journal.Write(configjournal.Record{Time: past, Op: "hset", Key: "PORT|Ethernet4"})
+	if err := json.Unmarshal(nextJournalValue(t, q).GetVal().GetJsonIetfVal(), &record); err != nil || record.Key != "PORT|Ethernet4" {
+		t.Fatalf("unexpected live record %+v: %v", record, err)
+	}
+
+	close(stop)
+	w.Wait()
+}
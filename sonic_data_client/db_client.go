@@ -561,6 +561,8 @@ func useRedisTcpClient() error {
 					DB:          int(dbn),
 					DialTimeout: 0,
 				})
+				redisDb.AddHook(redisMetricsHook{db: dbName})
+				redisDb.AddHook(redisTracingHook{db: dbName})
 				Target2RedisDb[dbNamespace][dbName] = redisDb
 			}
 		}
@@ -596,6 +598,8 @@ func initRedisDbClients() {
 					DB:          int(dbn),
 					DialTimeout: 0,
 				})
+				redisDb.AddHook(redisMetricsHook{db: dbName})
+				redisDb.AddHook(redisTracingHook{db: dbName})
 				Target2RedisDb[dbNamespace][dbName] = redisDb
 			}
 		}
@@ -50,7 +50,16 @@ var STATS_ABSOLUTE_KEYS = [...]string{LATENCY}
 
 const STATS_FIELD_NAME = "value"
 
+// STATS_PREFIX is the table of the counters of all the subscribers.
+const STATS_PREFIX = "COUNTERS_EVENTS:"
+
+// The counters of every subscriber are the fields of its own key in a table
+// of their own, such as the missed_by_slow_receiver field of
+// COUNTERS_EVENTS_SUBSCRIBER:10.0.0.1.
+const STATS_SUBSCRIBER_PREFIX = "COUNTERS_EVENTS_SUBSCRIBER:"
+
 const EVENTD_PUBLISHER_SOURCE = "{\"sonic-events-eventd"
+const EVENTD_HEARTBEAT = "{\"sonic-events-eventd:heartbeat"
 
 const TEST_EVENT = "{\"sonic-host:device-test-event"
 
@@ -58,10 +67,23 @@ const TEST_EVENT = "{\"sonic-host:device-test-event"
 const PARAM_HEARTBEAT = "heartbeat"
 const PARAM_QSIZE = "qsize"
 const PARAM_USE_CACHE = "usecache"
+const PARAM_CLIENT = "client"
+
+// CLIENT_NAME_MAX is the maximum length of the client parameter, which names
+// a key of COUNTERS_DB.
+const CLIENT_NAME_MAX = 32
+
+// events_hub delivers the events to all the event clients.
+var events_hub = newEventHub(eventHubOps{
+	open:          C_init_subs,
+	receive:       C_recv_evt,
+	close:         C_deinit_subs,
+	set_heartbeat: Set_heartbeat,
+})
 
 type EventClient struct {
-	prefix *gnmipb.Path
-	path   *gnmipb.Path
+	prefix  *gnmipb.Path
+	filters []*eventFilter
 
 	q       *queue.PriorityQueue
 	pq_max  int
@@ -69,7 +91,12 @@ type EventClient struct {
 
 	wg *sync.WaitGroup // wait for all sub go routines to finish
 
-	subs_handle unsafe.Pointer
+	// subscriber identifies the client across connections, for its cache
+	// and its counters.
+	subscriber string
+	use_cache  bool
+	heartbeat  int
+	last_sent  time.Time
 
 	stopped   int
 	stopMutex sync.RWMutex
@@ -94,22 +121,35 @@ func Set_heartbeat(val int) {
 }
 
 func C_init_subs(use_cache bool) unsafe.Pointer {
+	if evt_ptr == nil {
+		// The buffer of the events received, kept for the process lifetime
+		str_ptr := C.malloc(C.sizeof_char * C.size_t(EVENT_BUFFSZ))
+		evt_ptr = (*C.event_receive_op_C_t)(C.malloc(C.size_t(unsafe.Sizeof(C.event_receive_op_C_t{}))))
+		evt_ptr.event_str = (*C.char)(str_ptr)
+		evt_ptr.event_sz = C.uint32_t(EVENT_BUFFSZ)
+	}
 	return C.events_init_subscriber_wrap(C.bool(use_cache), C.int(SUBSCRIBER_TIMEOUT))
 }
 
-func NewEventClient(paths []*gnmipb.Path, prefix *gnmipb.Path, logLevel int) (Client, error) {
+// NewEventClient returns a client streaming the events selected by paths.
+// identity identifies the gNMI client, which may qualify it with the client
+// key of the paths so that several of its subscribers have their own cache.
+func NewEventClient(paths []*gnmipb.Path, prefix *gnmipb.Path, identity string, logLevel int) (Client, error) {
 	var evtc EventClient
-	use_cache := true
+	evtc.use_cache = true
+	evtc.subscriber = identity
 	evtc.prefix = prefix
 	evtc.pq_max = PQ_DEF_SIZE
 	log.V(4).Infof("Events priority Q max set default = %v", evtc.pq_max)
 
-	for _, path := range paths {
-		// Only one path is expected. Take the last if many
-		evtc.path = path
+	filters, err := newEventFilters(paths)
+	if err != nil {
+		return nil, err
 	}
+	evtc.filters = filters
 
-	for _, e := range evtc.path.GetElem() {
+	// The options may be set on any path, the last one set wins
+	for _, e := range eventPathElems(paths) {
 		keys := e.GetKey()
 		for k, v := range keys {
 			if k == PARAM_HEARTBEAT {
@@ -119,7 +159,7 @@ func NewEventClient(paths []*gnmipb.Path, prefix *gnmipb.Path, logLevel int) (Cl
 						val = HEARTBEAT_MAX
 					}
 					log.V(7).Infof("evtc.heartbeat_interval is set to %d", val)
-					Set_heartbeat(val)
+					evtc.heartbeat = val
 				}
 			} else if k == PARAM_QSIZE {
 				if val, err := strconv.Atoi(v); err == nil {
@@ -138,17 +178,23 @@ func NewEventClient(paths []*gnmipb.Path, prefix *gnmipb.Path, logLevel int) (Cl
 				}
 			} else if k == PARAM_USE_CACHE {
 				if strings.ToLower(v) == "false" {
-					use_cache = false
+					evtc.use_cache = false
 					log.V(7).Infof("Cache use is turned off")
 				}
+			} else if k == PARAM_CLIENT && v != "" {
+				if err := check_client_name(v); err != nil {
+					return nil, err
+				}
+				evtc.subscriber = identity + "/" + v
 			}
 		}
 	}
 
-	C.swssSetLogPriority(C.int(logLevel))
+	if evtc.subscriber == "" {
+		return nil, fmt.Errorf("no identity for the EVENTS subscriber")
+	}
 
-	/* Init subscriber with cache use and defined time out */
-	evtc.subs_handle = C_init_subs(use_cache)
+	C.swssSetLogPriority(C.int(logLevel))
 	evtc.stopped = 0
 
 	/* Init list & counters */
@@ -169,11 +215,34 @@ func NewEventClient(paths []*gnmipb.Path, prefix *gnmipb.Path, logLevel int) (Cl
 	evtc.last_errors = 0
 	evtc.last_latency_full = false
 
-	log.V(7).Infof("NewEventClient constructed. logLevel=%d", logLevel)
+	log.V(7).Infof("NewEventClient constructed for %s. logLevel=%d", evtc.subscriber, logLevel)
 
 	return &evtc, nil
 }
 
+// check_client_name returns an error unless name is a valid client parameter:
+// up to CLIENT_NAME_MAX letters, digits, '.', '_' or '-'.
+func check_client_name(name string) error {
+	if len(name) > CLIENT_NAME_MAX {
+		return fmt.Errorf("client %q is longer than %d characters", name, CLIENT_NAME_MAX)
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '_' || c == '-') {
+			return fmt.Errorf("client %q may only have letters, digits, '.', '_' and '-'", name)
+		}
+	}
+	return nil
+}
+
+// eventPathElems returns the elements of all paths.
+func eventPathElems(paths []*gnmipb.Path) []*gnmipb.PathElem {
+	var all []*gnmipb.PathElem
+	for _, path := range paths {
+		all = append(all, path.GetElem()...)
+	}
+	return all
+}
+
 func compute_latency(evtc *EventClient) {
 	if evtc.last_latency_full {
 		var total uint64 = 0
@@ -243,12 +312,13 @@ func update_stats(evtc *EventClient) {
 			DB:          dbId,
 			DialTimeout: 0,
 		})
+		defer rclient.Close()
 
-		// Init current values for cumulative keys and clear for absolute
-		for _, key := range STATS_CUMULATIVE_KEYS {
-			fv, err := rclient.HGetAll(context.Background(), key).Result()
-			if err != nil {
-				number, errC := strconv.ParseUint(fv[STATS_FIELD_NAME], 10, 64)
+		// Init current values of the subscriber for cumulative keys and clear for absolute
+		fv, err := rclient.HGetAll(context.Background(), evtc.stats_key()).Result()
+		if err == nil {
+			for _, key := range STATS_CUMULATIVE_KEYS {
+				number, errC := strconv.ParseUint(fv[stats_field(key)], 10, 64)
 				if errC == nil {
 					db_counters[key] = number
 				}
@@ -260,25 +330,46 @@ func update_stats(evtc *EventClient) {
 	}
 
 	/* Main running loop that updates DB */
+	added_counters := make(map[string]uint64)
 	for !evtc.isStopped() {
 		tmp_counters := make(map[string]uint64)
+		current_counters := make(map[string]uint64)
 
 		// compute latency
 		compute_latency(evtc)
 
 		evtc.countersMutex.Lock()
-		current_counters := evtc.counters
+		for key, val := range evtc.counters {
+			current_counters[key] = val
+		}
 		evtc.countersMutex.Unlock()
+		current_counters[DROPPED] += evtc.last_errors
 
 		for key, val := range current_counters {
 			tmp_counters[key] = val + db_counters[key]
 		}
 
-		tmp_counters[DROPPED] += evtc.last_errors
-
 		if (wr_counters == nil) || !reflect.DeepEqual(tmp_counters, *wr_counters) {
+			fields := make(map[string]interface{})
 			for key, val := range tmp_counters {
-				sval := strconv.FormatUint(val, 10)
+				fields[stats_field(key)] = strconv.FormatUint(val, 10)
+			}
+			if _, err := rclient.HSet(context.Background(), evtc.stats_key(), fields).Result(); err != nil {
+				log.V(3).Infof("EventClient failed to update COUNTERS key:%s val:%v err:%v", evtc.stats_key(), fields, err)
+			}
+
+			// The counters of all subscribers add up, their latency is the last one
+			for _, key := range STATS_CUMULATIVE_KEYS {
+				if diff := current_counters[key] - added_counters[key]; diff > 0 {
+					if _, err := rclient.HIncrBy(context.Background(), key, STATS_FIELD_NAME, int64(diff)).Result(); err != nil {
+						log.V(3).Infof("EventClient failed to update COUNTERS key:%s val:%v err:%v", key, diff, err)
+						continue
+					}
+					added_counters[key] = current_counters[key]
+				}
+			}
+			for _, key := range STATS_ABSOLUTE_KEYS {
+				sval := strconv.FormatUint(current_counters[key], 10)
 				if _, err := rclient.HSet(context.Background(), key, STATS_FIELD_NAME, sval).Result(); err != nil {
 					log.V(3).Infof("EventClient failed to update COUNTERS key:%s val:%v err:%v", key, sval, err)
 				}
@@ -287,21 +378,32 @@ func update_stats(evtc *EventClient) {
 		}
 		time.Sleep(time.Second)
 	}
+
+	// Only the subscribers using the cache come back for their counters
+	if rclient != nil && !evtc.use_cache {
+		if _, err := rclient.Del(context.Background(), evtc.stats_key()).Result(); err != nil {
+			log.V(3).Infof("EventClient failed to delete COUNTERS key:%s err:%v", evtc.stats_key(), err)
+		}
+	}
+}
+
+// stats_key returns the key of the counters of the subscriber.
+func (evtc *EventClient) stats_key() string {
+	return STATS_SUBSCRIBER_PREFIX + evtc.subscriber
+}
+
+// stats_field returns the field of counter key in the key of a subscriber.
+func stats_field(key string) string {
+	return strings.TrimPrefix(key, STATS_PREFIX)
 }
 
 // String returns the target the client is querying.
 func (evtc *EventClient) String() string {
-	return fmt.Sprintf("EventClient Prefix %v", evtc.prefix.GetTarget())
+	return fmt.Sprintf("EventClient Prefix %v subscriber %s", evtc.prefix.GetTarget(), evtc.subscriber)
 }
 
 var evt_ptr *C.event_receive_op_C_t
 
-type Evt_rcvd struct {
-	Event_str        string
-	Missed_cnt       uint32
-	Publish_epoch_ms int64
-}
-
 func C_recv_evt(h unsafe.Pointer) (int, Evt_rcvd) {
 	var evt Evt_rcvd
 
@@ -317,82 +419,70 @@ func C_deinit_subs(h unsafe.Pointer) {
 	C.events_deinit_subscriber_wrap(h)
 }
 
-func get_events(evtc *EventClient) {
-	defer evtc.wg.Done()
-
-	str_ptr := C.malloc(C.sizeof_char * C.size_t(EVENT_BUFFSZ))
-	defer C.free(unsafe.Pointer(str_ptr))
-
-	evt_ptr = (*C.event_receive_op_C_t)(C.malloc(C.size_t(unsafe.Sizeof(C.event_receive_op_C_t{}))))
-	defer C.free(unsafe.Pointer(evt_ptr))
-
-	evt_ptr.event_str = (*C.char)(str_ptr)
-	evt_ptr.event_sz = C.uint32_t(EVENT_BUFFSZ)
-
-	for {
-
-		rc, evt := C_recv_evt(evtc.subs_handle)
-
-		if rc == 0 {
-			evtc.countersMutex.Lock()
-			current_missed_cnt := evtc.counters[MISSED]
-			evtc.countersMutex.Unlock()
+func (evtc *EventClient) cacheKey() (string, bool) {
+	return evtc.subscriber, evtc.use_cache
+}
 
-			evtc.countersMutex.RLock()
-			evtc.counters[MISSED] = current_missed_cnt + (uint64)(evt.Missed_cnt)
-			evtc.countersMutex.RUnlock()
+func (evtc *EventClient) heartbeatInterval() int {
+	return evtc.heartbeat
+}
 
-			if !strings.HasPrefix(evt.Event_str, TEST_EVENT) {
-				qlen := evtc.q.Len()
+func (evtc *EventClient) missed(n uint64) {
+	evtc.countersMutex.Lock()
+	evtc.counters[MISSED] += n
+	evtc.countersMutex.Unlock()
+}
 
-				if qlen < evtc.pq_max {
-					var fvp map[string]interface{}
-					json.Unmarshal([]byte(evt.Event_str), &fvp)
+// deliver queues evt when the filters of the client select it, and sends
+// the heartbeats at the interval of the client.
+func (evtc *EventClient) deliver(evt Evt_rcvd) error {
+	evtc.missed((uint64)(evt.Missed_cnt))
 
-					jv, err := json.Marshal(fvp)
+	if strings.HasPrefix(evt.Event_str, TEST_EVENT) {
+		return nil
+	}
+	path := filterEvent(evtc.filters, evt.Event_str)
+	if path == nil {
+		return nil
+	}
+	if evtc.heartbeat > 0 && strings.HasPrefix(evt.Event_str, EVENTD_HEARTBEAT) &&
+		time.Since(evtc.last_sent) < time.Duration(evtc.heartbeat)*time.Second*9/10 {
+		return nil
+	}
+	if evtc.q.Len() >= evtc.pq_max {
+		evtc.countersMutex.Lock()
+		evtc.counters[DROPPED] += 1
+		evtc.countersMutex.Unlock()
+		return nil
+	}
 
-					if err == nil {
-						evtTv := &gnmipb.TypedValue{
-							Value: &gnmipb.TypedValue_JsonIetfVal{
-								JsonIetfVal: jv,
-							}}
-						if err := send_event(evtc, evtTv, evt.Publish_epoch_ms); err != nil {
-							break
-						}
-					} else {
-						log.V(1).Infof("Invalid event string: %v", evt.Event_str)
-					}
-				} else {
-					evtc.countersMutex.Lock()
-					dropped_cnt := evtc.counters[DROPPED]
-					evtc.countersMutex.Unlock()
-
-					evtc.countersMutex.RLock()
-					evtc.counters[DROPPED] = dropped_cnt + 1
-					evtc.countersMutex.RUnlock()
-				}
-			}
-		}
-		if evtc.isStopped() {
-			break
-		}
-		// TODO: Record missed count in stats table.
-		// intVar, err := strconv.Atoi(C.GoString((*C.char)(c_mptr)))
+	var fvp map[string]interface{}
+	json.Unmarshal([]byte(evt.Event_str), &fvp)
+	jv, err := json.Marshal(fvp)
+	if err != nil {
+		log.V(1).Infof("Invalid event string: %v", evt.Event_str)
+		return nil
+	}
+	evtTv := &gnmipb.TypedValue{
+		Value: &gnmipb.TypedValue_JsonIetfVal{
+			JsonIetfVal: jv,
+		}}
+	if err := send_event(evtc, path, evtTv, evt.Publish_epoch_ms); err != nil {
+		// set evtc.stopped as the channel was not stopped
+		evtc.stopMutex.Lock()
+		evtc.stopped = 1
+		evtc.stopMutex.Unlock()
+		return err
 	}
-	log.V(1).Infof("%v stop channel closed or send_event err, exiting get_events routine", evtc)
-	C_deinit_subs(evtc.subs_handle)
-	evtc.subs_handle = nil
-	// set evtc.stopped for case where send_event error and channel was not stopped
-	evtc.stopMutex.RLock()
-	evtc.stopped = 1
-	evtc.stopMutex.RUnlock()
+	evtc.last_sent = time.Now()
+	return nil
 }
 
-func send_event(evtc *EventClient, tv *gnmipb.TypedValue,
+func send_event(evtc *EventClient, path *gnmipb.Path, tv *gnmipb.TypedValue,
 	timestamp int64) error {
 	spbv := &spb.Value{
 		Prefix:    evtc.prefix,
-		Path:      evtc.path,
+		Path:      path,
 		Timestamp: timestamp,
 		Val:       tv,
 	}
@@ -412,17 +502,20 @@ func (evtc *EventClient) StreamRun(q *queue.PriorityQueue, stop chan struct{}, w
 	evtc.q = q
 	evtc.channel = stop
 
-	go get_events(evtc)
+	if err := events_hub.add(evtc); err != nil {
+		log.V(1).Infof("%v failed to replay the cached events: %v", evtc, err)
+		return
+	}
+	defer events_hub.remove(evtc)
 	evtc.wg.Add(1)
 	go update_stats(evtc)
-	evtc.wg.Add(1)
 
 	for !evtc.isStopped() {
 		select {
 		case <-evtc.channel:
-			evtc.stopMutex.RLock()
+			evtc.stopMutex.Lock()
 			evtc.stopped = 1
-			evtc.stopMutex.RUnlock()
+			evtc.stopMutex.Unlock()
 			log.V(3).Infof("Channel closed by client")
 			return
 		}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// EVENTS_ALL is the path element selecting every source or tag.
+const EVENTS_ALL = "all"
+
+// eventFilter selects the events of one EVENTS subscription path:
+//
+//	all                                      every event
+//	sonic-events-bgp                         every event of a source
+//	sonic-events-bgp/bgp-state[status=down]  events of a tag with a field value
+//
+// Sources, tags and field values are patterns in the syntax of path.Match,
+// and a field value may list alternatives separated by "|", as in
+// [severity=critical|major]. The heartbeat, qsize, usecache and client keys
+// are options of the client and not field predicates.
+type eventFilter struct {
+	path       *gnmipb.Path
+	source     string
+	tag        string
+	predicates map[string][]string
+}
+
+// newEventFilter returns the filter of path, whose first element may be the
+// EVENTS target.
+func newEventFilter(p *gnmipb.Path) (*eventFilter, error) {
+	f := &eventFilter{path: p, predicates: make(map[string][]string)}
+	elems := p.GetElem()
+	if len(elems) > 0 && elems[0].GetName() == "EVENTS" {
+		elems = elems[1:]
+	}
+	if len(elems) > 2 {
+		return nil, fmt.Errorf("invalid EVENTS path %v: expected a source and a tag", p)
+	}
+	for i, e := range elems {
+		name := e.GetName()
+		if _, err := path.Match(name, ""); err != nil {
+			return nil, fmt.Errorf("invalid EVENTS path %v: %v", p, err)
+		}
+		if name == EVENTS_ALL || name == "*" {
+			name = ""
+		}
+		if i == 0 {
+			f.source = name
+		} else {
+			f.tag = name
+		}
+		for k, v := range e.GetKey() {
+			if k == PARAM_HEARTBEAT || k == PARAM_QSIZE || k == PARAM_USE_CACHE || k == PARAM_CLIENT {
+				continue
+			}
+			for _, alt := range strings.Split(v, "|") {
+				if _, err := path.Match(alt, ""); err != nil {
+					return nil, fmt.Errorf("invalid EVENTS path %v: %v", p, err)
+				}
+				f.predicates[k] = append(f.predicates[k], alt)
+			}
+		}
+	}
+	return f, nil
+}
+
+// matchAll reports whether the filter selects every event.
+func (f *eventFilter) matchAll() bool {
+	return f.source == "" && f.tag == "" && len(f.predicates) == 0
+}
+
+func (f *eventFilter) match(evt *parsedEvent) bool {
+	if f.source != "" {
+		if ok, _ := path.Match(f.source, evt.source); !ok {
+			return false
+		}
+	}
+	if f.tag != "" {
+		if ok, _ := path.Match(f.tag, evt.tag); !ok {
+			return false
+		}
+	}
+	for field, alts := range f.predicates {
+		v, ok := evt.fields[field]
+		if !ok {
+			return false
+		}
+		value := fmt.Sprint(v)
+		matched := false
+		for _, alt := range alts {
+			if ok, _ := path.Match(alt, value); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// parsedEvent is an event published as {"<source>:<tag>": {<fields>}}.
+type parsedEvent struct {
+	source string
+	tag    string
+	fields map[string]interface{}
+}
+
+func parseEvent(s string) (*parsedEvent, error) {
+	var evt map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &evt); err != nil {
+		return nil, err
+	}
+	if len(evt) != 1 {
+		return nil, fmt.Errorf("expected one event, got %d", len(evt))
+	}
+	for key, fields := range evt {
+		source, tag, _ := strings.Cut(key, ":")
+		return &parsedEvent{source: source, tag: tag, fields: fields}, nil
+	}
+	return nil, nil
+}
+
+// newEventFilters returns the filters of paths.
+func newEventFilters(paths []*gnmipb.Path) ([]*eventFilter, error) {
+	filters := make([]*eventFilter, 0, len(paths))
+	for _, p := range paths {
+		f, err := newEventFilter(p)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// filterEvent returns the path of the first of filters selecting the event
+// published as s, nil when none does. The heartbeats of eventd are selected
+// by the first filter, as they tell the subscribers that the publisher is
+// alive.
+func filterEvent(filters []*eventFilter, s string) *gnmipb.Path {
+	if len(filters) == 0 {
+		return nil
+	}
+	if filters[0].matchAll() || strings.HasPrefix(s, EVENTD_PUBLISHER_SOURCE) {
+		return filters[0].path
+	}
+	evt, err := parseEvent(s)
+	for _, f := range filters {
+		if f.matchAll() {
+			return f.path
+		}
+		if err == nil && f.match(evt) {
+			return f.path
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/ygot/ygot"
+)
+
+func TestFilterEvent(t *testing.T) {
+	paths := func(ps ...string) []*gnmipb.Path {
+		var out []*gnmipb.Path
+		for _, p := range ps {
+			path, err := ygot.StringToStructuredPath(p)
+			if err != nil {
+				t.Fatalf("invalid path %s: %v", p, err)
+			}
+			out = append(out, path)
+		}
+		return out
+	}
+	bgpDown := `{"sonic-events-bgp:bgp-state":{"ip":"10.0.0.1","status":"down","timestamp":"2022-08-17T02:39:21.286611Z"}}`
+	bgpUp := `{"sonic-events-bgp:bgp-state":{"ip":"10.0.0.2","status":"up"}}`
+	linkDown := `{"sonic-events-swss:if-state":{"ifname":"Ethernet4","status":"down","severity":"major"}}`
+	heartbeat := `{"sonic-events-eventd:heartbeat":{"timestamp":"2022-08-17T02:39:21.286611Z"}}`
+
+	tests := []struct {
+		desc  string
+		paths []string
+		event string
+		want  string // index of the path selecting the event, "" for none
+	}{
+		{"all", []string{"all[heartbeat=5]"}, "test0", "0"},
+		{"source", []string{"sonic-events-bgp"}, bgpDown, "0"},
+		{"other source", []string{"sonic-events-bgp"}, linkDown, ""},
+		{"target element", []string{"/EVENTS/sonic-events-bgp"}, bgpUp, "0"},
+		{"source pattern", []string{"sonic-events-*"}, linkDown, "0"},
+		{"tag", []string{"sonic-events-bgp/bgp-state"}, bgpUp, "0"},
+		{"other tag", []string{"sonic-events-bgp/notification"}, bgpUp, ""},
+		{"field", []string{"sonic-events-bgp/bgp-state[status=down]"}, bgpDown, "0"},
+		{"other field value", []string{"sonic-events-bgp/bgp-state[status=down]"}, bgpUp, ""},
+		{"field pattern", []string{"all/all[ip=10.0.0.*]"}, bgpUp, "0"},
+		{"missing field", []string{"all[severity=major]"}, bgpDown, ""},
+		{"alternatives", []string{"all[severity=critical|major]"}, linkDown, "0"},
+		{"options are not fields", []string{"sonic-events-bgp[qsize=2000][usecache=false]"}, bgpUp, "0"},
+		{"second path", []string{"sonic-events-bgp[status=down]", "sonic-events-swss/if-state[ifname=Ethernet*]"}, linkDown, "1"},
+		{"first path", []string{"sonic-events-bgp[status=down]", "all"}, bgpDown, "0"},
+		{"heartbeat", []string{"sonic-events-bgp"}, heartbeat, "0"},
+		{"invalid event", []string{"sonic-events-bgp", "sonic-events-swss"}, "test0", ""},
+	}
+	for _, tt := range tests {
+		filters, err := newEventFilters(paths(tt.paths...))
+		if err != nil {
+			t.Fatalf("%s: newEventFilters failed: %v", tt.desc, err)
+		}
+		got := ""
+		if path := filterEvent(filters, tt.event); path != nil {
+			for i, f := range filters {
+				if f.path == path {
+					got = string(rune('0' + i))
+				}
+			}
+		}
+		if got != tt.want {
+			t.Errorf("%s: event selected by path %q, want %q", tt.desc, got, tt.want)
+		}
+	}
+
+	for _, p := range append(paths("a/b/c", "all[status=[down]"), &gnmipb.Path{Elem: []*gnmipb.PathElem{{Name: "sonic-events-[bgp"}}}) {
+		if _, err := newEventFilters([]*gnmipb.Path{p}); err == nil {
+			t.Errorf("newEventFilters(%v) succeeded", p)
+		}
+	}
+}
+
+func TestCheckClientName(t *testing.T) {
+	for _, name := range []string{"collector1", "dc1.example-2_b", strings.Repeat("x", CLIENT_NAME_MAX)} {
+		if err := check_client_name(name); err != nil {
+			t.Errorf("check_client_name(%q) failed: %v", name, err)
+		}
+	}
+	for _, name := range []string{"a:b", "a/b", "a b", "*", strings.Repeat("x", CLIENT_NAME_MAX+1)} {
+		if err := check_client_name(name); err == nil {
+			t.Errorf("check_client_name(%q) succeeded, want error", name)
+		}
+	}
+}
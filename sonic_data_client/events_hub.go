@@ -0,0 +1,222 @@
+package client
+
+import (
+	"strings"
+	"sync"
+	"unsafe"
+
+	log "github.com/golang/glog"
+)
+
+// EVENTS_CACHE_SIZE is the number of events kept for the subscribers using
+// the cache while they are disconnected.
+const EVENTS_CACHE_SIZE = PQ_DEF_SIZE
+
+// EVENTS_CURSORS_MAX is the number of disconnected subscribers whose cursor
+// is kept. Beyond it, the oldest cursor is dropped, and its subscriber gets
+// no replay when it connects again.
+const EVENTS_CURSORS_MAX = 64
+
+type Evt_rcvd struct {
+	Event_str        string
+	Missed_cnt       uint32
+	Publish_epoch_ms int64
+}
+
+// eventSubscriber receives the events of the hub.
+type eventSubscriber interface {
+	// cacheKey identifies the subscriber across connections, and reports
+	// whether the events it misses while disconnected are kept for it.
+	cacheKey() (string, bool)
+	// heartbeatInterval is the heartbeat interval requested in seconds, 0
+	// when none is.
+	heartbeatInterval() int
+	// deliver handles an event. The subscriber is removed when it fails.
+	deliver(evt Evt_rcvd) error
+	// missed counts the events lost before they could be replayed.
+	missed(n uint64)
+}
+
+// eventHubOps are the operations of the events library used by the hub.
+type eventHubOps struct {
+	open          func(use_cache bool) unsafe.Pointer
+	receive       func(h unsafe.Pointer) (int, Evt_rcvd)
+	close         func(h unsafe.Pointer)
+	set_heartbeat func(val int)
+}
+
+type cachedEvent struct {
+	seq uint64
+	evt Evt_rcvd
+}
+
+// eventHub shares one subscriber of the events library between all the
+// EVENTS subscribers, each with its own filters, queue and heartbeat. The
+// subscriber of the library runs while there are subscribers, and uses the
+// offline cache of eventd when one of them does.
+//
+// The cache of eventd is shared, so the hub keeps a cursor per subscriber
+// key using the cache when it disconnects, and the events since the oldest
+// cursor, replayed when the subscriber connects again.
+type eventHub struct {
+	ops eventHubOps
+
+	mu        sync.Mutex
+	subs      map[eventSubscriber]struct{}
+	running   bool
+	heartbeat int
+
+	seq     uint64 // of the last event received
+	cache   []cachedEvent
+	cursors map[string]uint64
+}
+
+func newEventHub(ops eventHubOps) *eventHub {
+	return &eventHub{
+		ops:     ops,
+		subs:    make(map[eventSubscriber]struct{}),
+		cursors: make(map[string]uint64),
+	}
+}
+
+// add replays the events s missed while disconnected, then delivers it the
+// events received.
+func (h *eventHub) add(s eventSubscriber) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key, use_cache := s.cacheKey()
+	if cursor, ok := h.cursors[key]; ok && use_cache {
+		delete(h.cursors, key)
+		err := h.replay(s, cursor)
+		if len(h.cursors) == 0 {
+			h.cache = nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	h.subs[s] = struct{}{}
+	h.update_heartbeat(true)
+
+	if !h.running {
+		handle := h.ops.open(use_cache || len(h.cursors) > 0)
+		h.running = true
+		go h.run(handle)
+	}
+	return nil
+}
+
+// remove stops delivering events to s.
+func (h *eventHub) remove(s eventSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(s)
+}
+
+func (h *eventHub) removeLocked(s eventSubscriber) {
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+	delete(h.subs, s)
+	if key, use_cache := s.cacheKey(); use_cache {
+		h.cursors[key] = h.seq
+		if len(h.cursors) > EVENTS_CURSORS_MAX {
+			h.drop_oldest_cursor()
+		}
+	}
+	h.update_heartbeat(false)
+}
+
+// drop_oldest_cursor removes the cursor of the subscriber disconnected
+// first.
+func (h *eventHub) drop_oldest_cursor() {
+	oldest := ""
+	for key, cursor := range h.cursors {
+		if oldest == "" || cursor < h.cursors[oldest] {
+			oldest = key
+		}
+	}
+	log.V(1).Infof("Events cursor of %v dropped", oldest)
+	delete(h.cursors, oldest)
+}
+
+// replay delivers to s the cached events after cursor.
+func (h *eventHub) replay(s eventSubscriber, cursor uint64) error {
+	first := h.seq + 1
+	if len(h.cache) > 0 {
+		first = h.cache[0].seq
+	}
+	if first > cursor+1 {
+		s.missed(first - cursor - 1)
+	}
+	for _, c := range h.cache {
+		if c.seq <= cursor {
+			continue
+		}
+		if err := s.deliver(c.evt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// update_heartbeat sets the heartbeat of eventd to the smallest interval
+// requested, always when a subscriber is added. Every subscriber is then
+// sent the heartbeats at its own interval.
+func (h *eventHub) update_heartbeat(added bool) {
+	val := 0
+	for s := range h.subs {
+		if hb := s.heartbeatInterval(); hb > 0 && (val == 0 || hb < val) {
+			val = hb
+		}
+	}
+	if val > 0 && (added || val != h.heartbeat) {
+		log.V(7).Infof("Events heartbeat interval set to %d", val)
+		h.ops.set_heartbeat(val)
+		h.heartbeat = val
+	}
+}
+
+// publish caches evt for the disconnected subscribers and delivers it to
+// the others.
+func (h *eventHub) publish(evt Evt_rcvd) {
+	h.seq++
+	if len(h.cursors) == 0 {
+		h.cache = nil
+	} else if !strings.HasPrefix(evt.Event_str, EVENTD_PUBLISHER_SOURCE) &&
+		!strings.HasPrefix(evt.Event_str, TEST_EVENT) {
+		cached := evt
+		cached.Missed_cnt = 0
+		h.cache = append(h.cache, cachedEvent{seq: h.seq, evt: cached})
+		if len(h.cache) > EVENTS_CACHE_SIZE {
+			h.cache = h.cache[1:]
+		}
+	}
+	for s := range h.subs {
+		if err := s.deliver(evt); err != nil {
+			log.V(1).Infof("Events subscriber %v removed: %v", s, err)
+			h.removeLocked(s)
+		}
+	}
+}
+
+// run receives the events until there are no subscribers left.
+func (h *eventHub) run(handle unsafe.Pointer) {
+	for {
+		rc, evt := h.ops.receive(handle)
+		h.mu.Lock()
+		if rc == 0 {
+			h.publish(evt)
+		}
+		if len(h.subs) == 0 {
+			h.ops.close(handle)
+			h.running = false
+			h.heartbeat = 0
+			h.mu.Unlock()
+			log.V(1).Infof("No events subscriber left, events subscriber closed")
+			return
+		}
+		h.mu.Unlock()
+	}
+}
@@ -0,0 +1,229 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// fakeSubscriber records the events delivered.
+type fakeSubscriber struct {
+	key       string
+	use_cache bool
+	heartbeat int
+
+	mu     sync.Mutex
+	events []string
+	lost   uint64
+	fail   bool
+}
+
+func (s *fakeSubscriber) cacheKey() (string, bool) { return s.key, s.use_cache }
+func (s *fakeSubscriber) heartbeatInterval() int   { return s.heartbeat }
+
+func (s *fakeSubscriber) deliver(evt Evt_rcvd) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return fmt.Errorf("queue error")
+	}
+	s.events = append(s.events, evt.Event_str)
+	return nil
+}
+
+func (s *fakeSubscriber) missed(n uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lost += n
+}
+
+func (s *fakeSubscriber) got() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprint(s.events)
+}
+
+// fakeEvents is an events library publishing the events sent to it.
+type fakeEvents struct {
+	events     chan string
+	mu         sync.Mutex
+	opened     int
+	use_cache  bool
+	closed     chan struct{}
+	heartbeats []int
+}
+
+func newFakeHub() (*eventHub, *fakeEvents) {
+	f := &fakeEvents{events: make(chan string), closed: make(chan struct{}, 10)}
+	return newEventHub(eventHubOps{
+		open: func(use_cache bool) unsafe.Pointer {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.opened++
+			f.use_cache = use_cache
+			return nil
+		},
+		receive: func(h unsafe.Pointer) (int, Evt_rcvd) {
+			select {
+			case evt := <-f.events:
+				return 0, Evt_rcvd{Event_str: evt}
+			case <-time.After(10 * time.Millisecond):
+				return -1, Evt_rcvd{}
+			}
+		},
+		close: func(h unsafe.Pointer) { f.closed <- struct{}{} },
+		set_heartbeat: func(val int) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.heartbeats = append(f.heartbeats, val)
+		},
+	}), f
+}
+
+// publish publishes evts, and waits for the hub to handle them.
+func (f *fakeEvents) publish(h *eventHub, evts ...string) {
+	h.mu.Lock()
+	seq := h.seq
+	h.mu.Unlock()
+	for _, evt := range evts {
+		f.events <- evt
+	}
+	for {
+		h.mu.Lock()
+		done := h.seq >= seq+uint64(len(evts))
+		h.mu.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func (f *fakeEvents) state() (int, bool, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.opened, f.use_cache, fmt.Sprint(f.heartbeats)
+}
+
+func (f *fakeEvents) waitClosed(t *testing.T) {
+	t.Helper()
+	select {
+	case <-f.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("events subscriber not closed")
+	}
+}
+
+func TestEventHub(t *testing.T) {
+	h, f := newFakeHub()
+	a := &fakeSubscriber{key: "collector-a", use_cache: true, heartbeat: 10}
+	b := &fakeSubscriber{key: "collector-b", use_cache: true, heartbeat: 5}
+	c := &fakeSubscriber{key: "test", heartbeat: 60}
+
+	for _, s := range []*fakeSubscriber{a, b, c} {
+		if err := h.add(s); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+	if opened, use_cache, heartbeats := f.state(); opened != 1 || !use_cache || heartbeats != "[10 5 5]" {
+		t.Errorf("events subscriber opened %d times, use_cache %v, heartbeats set %s", opened, use_cache, heartbeats)
+	}
+	f.publish(h, "e1")
+
+	// Both collectors receive the events published while they are away
+	h.remove(a)
+	h.remove(b)
+	f.publish(h, "e2")
+	h.remove(c)
+	f.waitClosed(t)
+	if _, _, heartbeats := f.state(); heartbeats != "[10 5 5 60]" {
+		t.Errorf("heartbeats set %s, want [10 5 5 60]", heartbeats)
+	}
+
+	if err := h.add(a); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if opened, use_cache, _ := f.state(); opened != 2 || !use_cache {
+		t.Errorf("events subscriber opened %d times, use_cache %v", opened, use_cache)
+	}
+	f.publish(h, "e3")
+	if err := h.add(b); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	f.publish(h, "e4")
+	for _, s := range []*fakeSubscriber{a, b} {
+		if got := s.got(); got != "[e1 e2 e3 e4]" {
+			t.Errorf("%s received %s, want [e1 e2 e3 e4]", s.key, got)
+		}
+	}
+	if got := c.got(); got != "[e1 e2]" {
+		t.Errorf("%s received %s, want [e1 e2]", c.key, got)
+	}
+	// The cache is dropped once every subscriber is back
+	h.mu.Lock()
+	if len(h.cache) != 0 || len(h.cursors) != 0 {
+		t.Errorf("cache of %d events for %v", len(h.cache), h.cursors)
+	}
+	h.mu.Unlock()
+
+	// Subscribers failing to queue an event are removed
+	b.mu.Lock()
+	b.fail = true
+	b.mu.Unlock()
+	f.publish(h, "e5")
+	h.mu.Lock()
+	if _, ok := h.subs[b]; ok {
+		t.Error("failed subscriber not removed")
+	}
+	h.mu.Unlock()
+	h.remove(a)
+	f.waitClosed(t)
+}
+
+func TestEventHub_CacheSize(t *testing.T) {
+	h, f := newFakeHub()
+	a := &fakeSubscriber{key: "collector-a", use_cache: true}
+	b := &fakeSubscriber{key: "collector-b"}
+	h.add(a)
+	h.add(b)
+	h.remove(a)
+	var evts []string
+	for i := 0; i < EVENTS_CACHE_SIZE+2; i++ {
+		evts = append(evts, fmt.Sprintf("e%d", i))
+	}
+	f.publish(h, evts...)
+	// Heartbeats are not cached
+	f.publish(h, EVENTD_HEARTBEAT+`":{}}`)
+	h.add(a)
+	a.mu.Lock()
+	if a.lost != 2 || len(a.events) != EVENTS_CACHE_SIZE || a.events[0] != "e2" {
+		t.Errorf("replayed %d events, lost %d", len(a.events), a.lost)
+	}
+	a.mu.Unlock()
+	h.remove(a)
+	h.remove(b)
+	f.waitClosed(t)
+}
+
+func TestEventHub_CursorsMax(t *testing.T) {
+	h, f := newFakeHub()
+	b := &fakeSubscriber{key: "collector-b"}
+	h.add(b)
+	for i := 0; i <= EVENTS_CURSORS_MAX; i++ {
+		s := &fakeSubscriber{key: fmt.Sprintf("collector-%d", i), use_cache: true}
+		h.add(s)
+		h.remove(s)
+		f.publish(h, fmt.Sprintf("e%d", i))
+	}
+	h.mu.Lock()
+	_, first := h.cursors["collector-0"]
+	_, last := h.cursors[fmt.Sprintf("collector-%d", EVENTS_CURSORS_MAX)]
+	if len(h.cursors) != EVENTS_CURSORS_MAX || first || !last {
+		t.Errorf("unexpected cursors %v", h.cursors)
+	}
+	h.mu.Unlock()
+	h.remove(b)
+	f.waitClosed(t)
+}
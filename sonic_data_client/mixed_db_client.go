@@ -20,6 +20,7 @@ import (
 	"unsafe"
 
 	"github.com/sonic-net/sonic-gnmi/common_utils"
+	"github.com/sonic-net/sonic-gnmi/pkg/tracing"
 	spb "github.com/sonic-net/sonic-gnmi/proto"
 	sdcfg "github.com/sonic-net/sonic-gnmi/sonic_db_config"
 	ssc "github.com/sonic-net/sonic-gnmi/sonic_service_client"
@@ -96,6 +97,9 @@ type MixedDbClient struct {
 	synced sync.WaitGroup  // Control when to send gNMI sync_response
 	w      *sync.WaitGroup // wait for all sub go routines to finish
 	mu     sync.RWMutex    // Mutex for data protection among routines for DbClient
+
+	// ctx is the context of the RPC using the client, for tracing
+	ctx context.Context
 }
 
 // redis client connected to each DB
@@ -530,6 +534,7 @@ func initRedisDbMap() {
 				DB:          int(dbn),
 				DialTimeout: 0,
 			})
+			redisDb.AddHook(redisTracingHook{db: dbName})
 			RedisDbMap[ns+":"+container+":"+dbName] = redisDb
 		}
 	}
@@ -740,7 +745,7 @@ func (c *MixedDbClient) getDbtablePath(path *gnmipb.Path, value *gnmipb.TypedVal
 	case 1: // only db name provided
 	case 2: // only table name provided
 		if tblPath.operation == opRemove {
-			res, err := redisDb.Keys(context.Background(), tblPath.tableName+"*").Result()
+			res, err := redisDb.Keys(c.rpcContext(), tblPath.tableName+"*").Result()
 			if err != nil || len(res) < 1 {
 				log.V(2).Infof("Invalid db table Path %v %v", c.target, dbPath)
 				return nil, fmt.Errorf("Failed to find %v %v %v %v", c.target, dbPath, err, res)
@@ -749,7 +754,7 @@ func (c *MixedDbClient) getDbtablePath(path *gnmipb.Path, value *gnmipb.TypedVal
 		tblPath.tableKey = ""
 	case 3: // Third element must be table key
 		if tblPath.operation == opRemove {
-			_, err := redisDb.Exists(context.Background(), tblPath.tableName+tblPath.delimitor+mappedKey).Result()
+			_, err := redisDb.Exists(c.rpcContext(), tblPath.tableName+tblPath.delimitor+mappedKey).Result()
 			if err != nil {
 				return nil, fmt.Errorf("redis Exists op failed for %v", dbPath)
 			}
@@ -757,7 +762,7 @@ func (c *MixedDbClient) getDbtablePath(path *gnmipb.Path, value *gnmipb.TypedVal
 		tblPath.tableKey = mappedKey
 	case 4: // Fourth element must be field name
 		if tblPath.operation == opRemove {
-			_, err := redisDb.Exists(context.Background(), tblPath.tableName+tblPath.delimitor+mappedKey).Result()
+			_, err := redisDb.Exists(c.rpcContext(), tblPath.tableName+tblPath.delimitor+mappedKey).Result()
 			if err != nil {
 				return nil, fmt.Errorf("redis Exists op failed for %v", dbPath)
 			}
@@ -766,7 +771,7 @@ func (c *MixedDbClient) getDbtablePath(path *gnmipb.Path, value *gnmipb.TypedVal
 		tblPath.field = stringSlice[3]
 	case 5: // Fifth element must be list index
 		if tblPath.operation == opRemove {
-			_, err := redisDb.Exists(context.Background(), tblPath.tableName+tblPath.delimitor+mappedKey).Result()
+			_, err := redisDb.Exists(c.rpcContext(), tblPath.tableName+tblPath.delimitor+mappedKey).Result()
 			if err != nil {
 				return nil, fmt.Errorf("redis Exists op failed for %v", dbPath)
 			}
@@ -859,7 +864,7 @@ func (c *MixedDbClient) tableData2Msi(tblPath *tablePath, useKey bool, op *strin
 			return fmt.Errorf("Can not read all tables in COUNTERS_DB")
 		}
 		pattern = "*" + tblPath.delimitor + "*"
-		dbkeys, err = redisDb.Keys(context.Background(), pattern).Result()
+		dbkeys, err = redisDb.Keys(c.rpcContext(), pattern).Result()
 		if err != nil {
 			log.V(2).Infof("redis Keys failed for %v, pattern %s", tblPath, pattern)
 			return fmt.Errorf("redis Keys failed for %v, pattern %s %v", tblPath, pattern, err)
@@ -872,7 +877,7 @@ func (c *MixedDbClient) tableData2Msi(tblPath *tablePath, useKey bool, op *strin
 		} else {
 			pattern = tblPath.tableName + tblPath.delimitor + "*"
 		}
-		dbkeys, err = redisDb.Keys(context.Background(), pattern).Result()
+		dbkeys, err = redisDb.Keys(c.rpcContext(), pattern).Result()
 		if err != nil {
 			log.V(2).Infof("redis Keys failed for %v, pattern %s", tblPath, pattern)
 			return fmt.Errorf("redis Keys failed for %v, pattern %s %v", tblPath, pattern, err)
@@ -883,7 +888,7 @@ func (c *MixedDbClient) tableData2Msi(tblPath *tablePath, useKey bool, op *strin
 	}
 
 	for idx, dbkey := range dbkeys {
-		fv, err = redisDb.HGetAll(context.Background(), dbkey).Result()
+		fv, err = redisDb.HGetAll(c.rpcContext(), dbkey).Result()
 		if err != nil {
 			log.V(2).Infof("redis HGetAll failed for  %v, dbkey %s", tblPath, dbkey)
 			return err
@@ -990,7 +995,7 @@ func (c *MixedDbClient) tableData2TypedValue(tblPaths []tablePath, op *string) (
 				// TODO: Use Yang model to identify leaf-list
 				if tblPath.index >= 0 {
 					field := tblPath.field + "@"
-					val, err := redisDb.HGet(context.Background(), key, field).Result()
+					val, err := redisDb.HGet(c.rpcContext(), key, field).Result()
 					if err != nil {
 						log.V(2).Infof("redis HGet failed for %v, data does not exist", tblPath)
 						continue
@@ -1005,7 +1010,7 @@ func (c *MixedDbClient) tableData2TypedValue(tblPaths []tablePath, op *string) (
 						}}, nil, true
 				} else {
 					field := tblPath.field
-					val, err := redisDb.HGet(context.Background(), key, field).Result()
+					val, err := redisDb.HGet(c.rpcContext(), key, field).Result()
 					if err == nil {
 						return &gnmipb.TypedValue{
 							Value: &gnmipb.TypedValue_JsonIetfVal{
@@ -1013,7 +1018,7 @@ func (c *MixedDbClient) tableData2TypedValue(tblPaths []tablePath, op *string) (
 							}}, nil, true
 					}
 					field = field + "@"
-					val, err = redisDb.HGet(context.Background(), key, field).Result()
+					val, err = redisDb.HGet(c.rpcContext(), key, field).Result()
 					if err == nil {
 						var output []byte
 						slice := strings.Split(val, ",")
@@ -1107,7 +1112,7 @@ func (c *MixedDbClient) handleTableData(tblPaths []tablePath) error {
 					pattern = tblPath.tableName + tblPath.delimitor + "*"
 				}
 				// Can't remove entry in temporary state table
-				dbkeys, err = redisDb.Keys(context.Background(), pattern).Result()
+				dbkeys, err = redisDb.Keys(c.rpcContext(), pattern).Result()
 				if err != nil {
 					log.V(2).Infof("redis Keys failed for %v, pattern %s", tblPath, pattern)
 					return fmt.Errorf("redis Keys failed for %v, pattern %s %v", tblPath, pattern, err)
@@ -1277,6 +1282,7 @@ func (c *MixedDbClient) SetIncrementalConfig(delete []*gnmipb.Path, replace []*g
 	if err != nil {
 		return err
 	}
+	ssc.SetContext(sc, c.rpcContext())
 
 	multiNs, err := sdcfg.CheckDbMultiNamespace()
 	if err != nil {
@@ -1454,7 +1460,10 @@ func (c *MixedDbClient) SetFullConfig(delete []*gnmipb.Path, replace []*gnmipb.U
 	}
 
 	PyCodeInGo := fmt.Sprintf(PyCodeForYang, fileName)
+	_, span := tracing.Start(c.rpcContext(), "yang.Validate")
 	err = RunPyCode(PyCodeInGo)
+	span.RecordError(err)
+	span.End()
 	if err != nil {
 		return fmt.Errorf("Yang validation failed!")
 	}
@@ -1475,6 +1484,7 @@ func (c *MixedDbClient) ReplaceFullConfig(delete []*gnmipb.Path, replace []*gnmi
 	if err != nil {
 		return err
 	}
+	ssc.SetContext(sc, c.rpcContext())
 
 	err = sc.ConfigReplace(string(content))
 
@@ -1556,7 +1566,29 @@ func (c *MixedDbClient) SetConfigDB(delete []*gnmipb.Path, replace []*gnmipb.Upd
 	return c.SetIncrementalConfig(delete, replace, update)
 }
 
-func (c *MixedDbClient) Set(delete []*gnmipb.Path, replace []*gnmipb.Update, update []*gnmipb.Update) error {
+// SetContext sets the context of the RPC using the client, so that the work
+// of the client is traced as part of the RPC.
+func (c *MixedDbClient) SetContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+func (c *MixedDbClient) rpcContext() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+func (c *MixedDbClient) Set(delete []*gnmipb.Path, replace []*gnmipb.Update, update []*gnmipb.Update) (err error) {
+	var span *tracing.Span
+	c.ctx, span = tracing.Start(c.rpcContext(), "MixedDbClient.Set")
+	span.SetAttribute("db.target", c.target)
+	span.SetAttribute("gnmi.origin", c.origin)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	if c.target == "CONFIG_DB" {
 		return c.SetConfigDB(delete, replace, update)
 	} else if c.target == DPU_APPL_DB_NAME || c.target == APPL_DB_NAME {
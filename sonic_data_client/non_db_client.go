@@ -50,6 +50,7 @@ type SonicVersionInfo struct {
 type sonicVersionYmlStash struct {
 	once        sync.Once // sync object to make sure file is loaded only once.
 	versionInfo SonicVersionInfo
+	fields      map[string]string // all top level scalar fields of the version file.
 }
 
 // InvalidateVersionFileStash invalidates the cache that keeps version file content.
@@ -299,9 +300,8 @@ func getSysUptime() ([]byte, error) {
 	return b, nil
 }
 
-func getBuildVersion() ([]byte, error) {
-
-	// Load and parse the content of version file
+// loadVersionFileStash loads and parses the content of version file once.
+func loadVersionFileStash() {
 	versionFileStash.once.Do(func() {
 		versionFileStash.versionInfo.BuildVersion = "sonic.NA"
 		versionFileStash.versionInfo.Error = "" // empty string means no error.
@@ -324,7 +324,34 @@ func getBuildVersion() ([]byte, error) {
 		if versionFileStash.versionInfo.BuildVersion != "sonic.NA" {
 			versionFileStash.versionInfo.BuildVersion = "SONiC." + versionFileStash.versionInfo.BuildVersion
 		}
+
+		var raw map[string]interface{}
+		if err := yaml.Unmarshal(fileContent, &raw); err != nil {
+			return
+		}
+		versionFileStash.fields = make(map[string]string, len(raw))
+		for key, value := range raw {
+			switch value.(type) {
+			case map[interface{}]interface{}, []interface{}, nil:
+				continue
+			}
+			versionFileStash.fields[key] = fmt.Sprint(value)
+		}
 	})
+}
+
+// GetSonicVersionField returns a top level field of '/etc/sonic/sonic_version.yml',
+// such as 'asic_type' or 'kernel_version', or defaultValue if it is not present.
+func GetSonicVersionField(field string, defaultValue string) string {
+	loadVersionFileStash()
+	if value, ok := versionFileStash.fields[field]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+func getBuildVersion() ([]byte, error) {
+	loadVersionFileStash()
 
 	b, err := json.Marshal(versionFileStash.versionInfo)
 	if err != nil {
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sonic-net/sonic-gnmi/pkg/metrics"
+)
+
+const redisPipelineCommand = "pipeline"
+
+// redisMetricsHook records the round-trip time of the commands sent by a DB client.
+type redisMetricsHook struct {
+	db string
+}
+
+func (h redisMetricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h redisMetricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !metrics.Default.Enabled() {
+			return next(ctx, cmd)
+		}
+		start := time.Now()
+		err := next(ctx, cmd)
+		metrics.RedisCommandDuration.Observe(time.Since(start).Seconds(), h.db, cmd.Name())
+		return err
+	}
+}
+
+func (h redisMetricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !metrics.Default.Enabled() {
+			return next(ctx, cmds)
+		}
+		start := time.Now()
+		err := next(ctx, cmds)
+		metrics.RedisCommandDuration.Observe(time.Since(start).Seconds(), h.db, redisPipelineCommand)
+		return err
+	}
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sonic-net/sonic-gnmi/pkg/tracing"
+)
+
+// redisTracingHook adds a span per command and per pipeline sent by a DB
+// client with the context of a traced request.
+type redisTracingHook struct {
+	db string
+}
+
+func (h redisTracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h redisTracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := tracing.Start(ctx, "redis "+cmd.Name())
+		if span == nil {
+			return next(ctx, cmd)
+		}
+		span.SetAttribute("db.system", "redis")
+		span.SetAttribute("db.name", h.db)
+		err := next(ctx, cmd)
+		if err != redis.Nil {
+			span.RecordError(err)
+		}
+		span.End()
+		return err
+	}
+}
+
+func (h redisTracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := tracing.Start(ctx, "redis "+redisPipelineCommand)
+		if span == nil {
+			return next(ctx, cmds)
+		}
+		span.SetAttribute("db.system", "redis")
+		span.SetAttribute("db.name", h.db)
+		span.SetAttribute("db.redis.commands", len(cmds))
+		err := next(ctx, cmds)
+		span.RecordError(err)
+		span.End()
+		return err
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/agiledragon/gomonkey/v2"
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/sonic-net/sonic-gnmi/pkg/tracing"
+)
+
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []tracing.SpanData
+}
+
+func (r *spanRecorder) Export(spans []tracing.SpanData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func (r *spanRecorder) Close() error {
+	return nil
+}
+
+func TestMixedDbClientSetRedisSpans(t *testing.T) {
+	mapkey := ":"
+	cleanup := setupMixedDbRedis(t, mapkey)
+	defer cleanup()
+
+	tblPaths := []tablePath{{dbNamespace: "", dbName: "APPL_DB", tableName: "TRACING_TEST_TABLE", delimitor: ":", operation: opRemove}}
+	c := MixedDbClient{mapkey: mapkey, target: APPL_DB_NAME}
+	patches := gomonkey.ApplyPrivateMethod(&c, "getDbtablePath", func(_ *MixedDbClient, _ *gnmipb.Path, _ *gnmipb.TypedValue) ([]tablePath, error) {
+		return tblPaths, nil
+	})
+	defer patches.Reset()
+
+	tracer := tracing.NewTracer("test")
+	recorder := &spanRecorder{}
+	tracer.SetExporter(recorder)
+	defer tracer.Shutdown()
+
+	ctx, root := tracer.StartServer(context.Background(), "/gnmi.gNMI/Set")
+	c.SetContext(ctx)
+	path := &gnmipb.Path{Elem: []*gnmipb.PathElem{{Name: "TRACING_TEST_TABLE"}}}
+	if err := c.Set([]*gnmipb.Path{path}, nil, nil); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	root.End()
+	tracer.Flush()
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	spans := make(map[string]tracing.SpanData)
+	for _, span := range recorder.spans {
+		spans[span.Name] = span
+	}
+	set, ok := spans["MixedDbClient.Set"]
+	if !ok {
+		t.Fatalf("Expected a MixedDbClient.Set span, got %v", recorder.spans)
+	}
+	keys, ok := spans["redis keys"]
+	if !ok {
+		t.Fatalf("Expected a redis keys span, got %v", recorder.spans)
+	}
+	if keys.ParentSpanID != set.SpanID || keys.TraceID != set.TraceID {
+		t.Errorf("Expected redis keys span to be a child of MixedDbClient.Set: %+v %+v", keys, set)
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net"
+
+	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
+	gnmi_extpb "github.com/openconfig/gnmi/proto/gnmi_ext"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// IsNativeOrigin returns true if origin selects the SONiC database paths.
+func IsNativeOrigin(origin string) bool {
+	return origin == "sonic-db"
+}
+
+// NewSubscribeClient returns the data client serving a subscription in mode
+// to paths, chosen by their origin or else by the target of prefix, along
+// with the target authorizing the subscription. It is shared by the dial-in
+// and the dial-out subscriptions.
+func NewSubscribeClient(ctx context.Context, prefix *gnmipb.Path, origin string, paths []*gnmipb.Path, mode gnmipb.SubscriptionList_Mode, extensions []*gnmi_extpb.Extension, logLevel int) (Client, string, error) {
+	var dc Client
+	var err error
+	target := prefix.GetTarget()
+	authTarget := "gnmi"
+	if origin == "openconfig" {
+		dc, err = NewTranslClient(prefix, paths, ctx, extensions, TranslWildcardOption{})
+	} else if IsNativeOrigin(origin) {
+		var targetDbName string
+		dc, err = NewMixedDbClient(paths, prefix, origin, gnmipb.Encoding_JSON_IETF, "", "", &targetDbName)
+		authTarget = "gnmi_" + targetDbName
+	} else if len(origin) != 0 {
+		return nil, "", status.Errorf(codes.Unimplemented, "Unsupported origin: %s", origin)
+	} else if target == "" {
+		// This and subsequent conditions handle target based path identification
+		// when origin == "". As per the spec it should have been treated as "openconfig".
+		// But we take a deviation and stick to legacy logic for backward compatibility
+		return nil, "", status.Errorf(codes.Unimplemented, "Empty target data not supported")
+	} else if target == "OTHERS" {
+		dc, err = NewNonDbClient(paths, prefix)
+		authTarget = "gnmi_others"
+	} else if target == "SHOW" {
+		return nil, "", status.Errorf(codes.Unimplemented, "SHOW does not support subscribe operations")
+	} else if target == "OPERATIONAL" {
+		// Like Get, the OPERATIONAL target is authorized with the gNOI roles
+		dc, err = NewConfigJournalClient(paths, prefix)
+		authTarget = "gnoi"
+	} else if (target == "EVENTS") && (mode == gnmipb.SubscriptionList_STREAM) {
+		dc, err = NewEventClient(paths, prefix, eventsIdentity(ctx), logLevel)
+		authTarget = "gnmi_events"
+	} else if targetDbName, ok, _, _ := IsTargetDb(target); ok {
+		dc, err = NewDbClient(paths, prefix)
+		authTarget = "gnmi_" + targetDbName
+	} else {
+		/* For any other target or no target create new Transl Client. */
+		dc, err = NewTranslClient(prefix, paths, ctx, extensions, TranslWildcardOption{})
+	}
+
+	if err != nil {
+		return nil, "", status.Errorf(codes.NotFound, "%v", err)
+	}
+	return dc, authTarget, nil
+}
+
+// eventsIdentity returns the identity of the EVENTS subscriber of ctx, which
+// keys its cache: the common name of its verified certificate, or else its
+// host address. Subscriptions without a peer, such as dial-out ones, are of
+// the local host.
+func eventsIdentity(ctx context.Context) string {
+	pr, ok := peer.FromContext(ctx)
+	if !ok || pr.Addr == nil {
+		return "localhost"
+	}
+	if info, ok := pr.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 && len(info.State.VerifiedChains[0]) > 0 {
+		if cn := info.State.VerifiedChains[0][0].Subject.CommonName; cn != "" {
+			return cn
+		}
+	}
+	if host, _, err := net.SplitHostPort(pr.Addr.String()); err == nil {
+		return host
+	}
+	return pr.Addr.String()
+}
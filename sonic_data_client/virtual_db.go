@@ -1148,6 +1148,19 @@ func AliasToPortNameMap() map[string]string {
 	return output
 }
 
+func PortNameToNamespaceMap() map[string]string {
+	// Ensure alias map is initialized
+	initAliasMap()
+
+	clearMappingsMu.RLock()
+	defer clearMappingsMu.RUnlock()
+	output := make(map[string]string, len(port2namespaceMap))
+	for portName, namespace := range port2namespaceMap {
+		output[portName] = namespace
+	}
+	return output
+}
+
 // Populate real data paths from paths like
 // [COUNTERS_DB PERIODIC_WATERMARKS Ethernet* PriorityGroups] or
 // [COUNTERS_DB PERIODIC_WATERMARKS Ethernet64 PriorityGroups]
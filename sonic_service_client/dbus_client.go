@@ -1,6 +1,7 @@
 package host_service
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -10,6 +11,8 @@ import (
 	"github.com/godbus/dbus/v5"
 	log "github.com/golang/glog"
 	"github.com/sonic-net/sonic-gnmi/common_utils"
+	"github.com/sonic-net/sonic-gnmi/pkg/metrics"
+	"github.com/sonic-net/sonic-gnmi/pkg/tracing"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -56,6 +59,8 @@ type DbusClient struct {
 	busPathPrefix string
 	intNamePrefix string
 	channel       chan struct{}
+	// ctx is the context of the RPC using the client, for tracing
+	ctx context.Context
 }
 
 func NewDbusClient() (Service, error) {
@@ -80,8 +85,36 @@ func (c *DbusClient) Close() error {
 	return nil
 }
 
-func DbusApi(busName string, busPath string, intName string, timeout int, args ...interface{}) (interface{}, error) {
+// SetContext makes the D-Bus calls of s part of the trace of the RPC in ctx.
+// Services other than DbusClient are left as they are.
+func SetContext(s Service, ctx context.Context) {
+	if c, ok := s.(*DbusClient); ok {
+		c.ctx = ctx
+	}
+}
+
+// dbusApi calls DbusApi in a span of the trace of the client context.
+func (c *DbusClient) dbusApi(busName string, busPath string, intName string, timeout int, args ...interface{}) (interface{}, error) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, span := tracing.Start(ctx, "dbus "+intName)
+	result, err := DbusApi(busName, busPath, intName, timeout, args...)
+	span.RecordError(err)
+	span.End()
+	return result, err
+}
+
+func DbusApi(busName string, busPath string, intName string, timeout int, args ...interface{}) (_ interface{}, err error) {
 	common_utils.IncCounter(common_utils.DBUS)
+	start := time.Now()
+	defer func() {
+		metrics.DbusCallDuration.Observe(time.Since(start).Seconds(), intName)
+		if err != nil {
+			metrics.DbusCallErrors.Inc(intName)
+		}
+	}()
 	conn, err := dbus.SystemBus()
 	if err != nil {
 		log.V(2).Infof("Failed to connect to system bus: %v", err)
@@ -144,7 +177,7 @@ func (c *DbusClient) ConfigReload(config string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".reload"
-	_, err := DbusApi(busName, busPath, intName, 60, config)
+	_, err := c.dbusApi(busName, busPath, intName, 60, config)
 	return err
 }
 
@@ -154,7 +187,7 @@ func (c *DbusClient) ConfigReplace(config string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".replace_db"
-	_, err := DbusApi(busName, busPath, intName, 600, config)
+	_, err := c.dbusApi(busName, busPath, intName, 600, config)
 	return err
 }
 
@@ -164,7 +197,7 @@ func (c *DbusClient) ConfigSave(fileName string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".save"
-	_, err := DbusApi(busName, busPath, intName, 60, fileName)
+	_, err := c.dbusApi(busName, busPath, intName, 60, fileName)
 	return err
 }
 
@@ -174,7 +207,7 @@ func (c *DbusClient) ApplyPatchYang(patch string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".apply_patch_yang"
-	_, err := DbusApi(busName, busPath, intName, 600, patch)
+	_, err := c.dbusApi(busName, busPath, intName, 600, patch)
 	return err
 }
 
@@ -184,7 +217,7 @@ func (c *DbusClient) ApplyPatchDb(patch string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".apply_patch_db"
-	_, err := DbusApi(busName, busPath, intName, 600, patch)
+	_, err := c.dbusApi(busName, busPath, intName, 600, patch)
 	return err
 }
 
@@ -194,7 +227,7 @@ func (c *DbusClient) CreateCheckPoint(fileName string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".create_checkpoint"
-	_, err := DbusApi(busName, busPath, intName, 60, fileName)
+	_, err := c.dbusApi(busName, busPath, intName, 60, fileName)
 	return err
 }
 
@@ -204,7 +237,7 @@ func (c *DbusClient) DeleteCheckPoint(fileName string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".delete_checkpoint"
-	_, err := DbusApi(busName, busPath, intName, 60, fileName)
+	_, err := c.dbusApi(busName, busPath, intName, 60, fileName)
 	return err
 }
 
@@ -214,7 +247,7 @@ func (c *DbusClient) StopService(service string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".stop_service"
-	_, err := DbusApi(busName, busPath, intName, 240, service)
+	_, err := c.dbusApi(busName, busPath, intName, 240, service)
 	return err
 }
 
@@ -224,7 +257,7 @@ func (c *DbusClient) RestartService(service string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".restart_service"
-	_, err := DbusApi(busName, busPath, intName, 240, service)
+	_, err := c.dbusApi(busName, busPath, intName, 240, service)
 	return err
 }
 
@@ -234,7 +267,7 @@ func (c *DbusClient) GetFileStat(path string) (map[string]string, error) {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".get_file_stat"
-	result, err := DbusApi(busName, busPath, intName, 60, path)
+	result, err := c.dbusApi(busName, busPath, intName, 60, path)
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +281,7 @@ func (c *DbusClient) DownloadFile(hostname, username, password, remotePath, loca
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".download"
-	_, err := DbusApi(busName, busPath, intName, 900, hostname, username, password, remotePath, localPath, protocol)
+	_, err := c.dbusApi(busName, busPath, intName, 900, hostname, username, password, remotePath, localPath, protocol)
 	return err
 }
 
@@ -258,7 +291,7 @@ func (c *DbusClient) RemoveFile(path string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".remove"
-	_, err := DbusApi(busName, busPath, intName, 60, path)
+	_, err := c.dbusApi(busName, busPath, intName, 60, path)
 	return err
 }
 
@@ -268,7 +301,7 @@ func (c *DbusClient) DownloadImage(url string, save_as string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".download"
-	_, err := DbusApi(busName, busPath, intName /*timeout=*/, 900, url, save_as)
+	_, err := c.dbusApi(busName, busPath, intName /*timeout=*/, 900, url, save_as)
 	return err
 }
 
@@ -278,7 +311,7 @@ func (c *DbusClient) InstallImage(where string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".install"
-	_, err := DbusApi(busName, busPath, intName /*timeout=*/, 900, where)
+	_, err := c.dbusApi(busName, busPath, intName /*timeout=*/, 900, where)
 	return err
 }
 
@@ -288,7 +321,7 @@ func (c *DbusClient) ListImages() (string, error) {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".list_images"
-	result, err := DbusApi(busName, busPath, intName /*timeout=*/, 60)
+	result, err := c.dbusApi(busName, busPath, intName /*timeout=*/, 60)
 	if err != nil {
 		return "", err
 	}
@@ -306,7 +339,7 @@ func (c *DbusClient) ActivateImage(image string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".set_next_boot"
-	_, err := DbusApi(busName, busPath, intName, 60, image)
+	_, err := c.dbusApi(busName, busPath, intName, 60, image)
 	return err
 }
 
@@ -316,7 +349,7 @@ func (c *DbusClient) LoadDockerImage(image string) error {
 	busName := c.busNamePrefix + modName
 	busPath := c.busPathPrefix + modName
 	intName := c.intNamePrefix + modName + ".load"
-	_, err := DbusApi(busName, busPath, intName /*timeout=*/, 180, image)
+	_, err := c.dbusApi(busName, busPath, intName /*timeout=*/, 180, image)
 	return err
 }
 
@@ -327,7 +360,7 @@ func (c *DbusClient) FactoryReset(cmd string) (string, error) {
 	intName := c.intNamePrefix + modName + ".issue_reset"
 
 	common_utils.IncCounter(common_utils.GNOI_FACTORY_RESET)
-	result, err := DbusApi(busName, busPath, intName, 10, cmd)
+	result, err := c.dbusApi(busName, busPath, intName, 10, cmd)
 	if err != nil {
 		return "", err
 	}
@@ -349,7 +382,7 @@ func (c *DbusClient) InstallOS(req string) (string, error) {
 	log.Infof("InstallOS: DbusClient called")
 	osMu.Lock()
 	defer osMu.Unlock()
-	result, err := DbusApi(busName, busPath, intName /*timeout=*/, 60, req)
+	result, err := c.dbusApi(busName, busPath, intName /*timeout=*/, 60, req)
 	if err != nil {
 		if strings.Contains(err.Error(), "ERROR_UNIMPLEMENTED") {
 			log.Infof("InstallOS: Error %v", err)
@@ -378,7 +411,7 @@ func (c *DbusClient) HealthzCheck(req string) (string, error) {
 	intName := c.intNamePrefix + modName + ".check"
 
 	common_utils.IncCounter(common_utils.GNOI_HEALTHZ_CHECK)
-	result, err := DbusApi(busName, busPath, intName /*timeout=*/, 10, req)
+	result, err := c.dbusApi(busName, busPath, intName /*timeout=*/, 10, req)
 	if err != nil {
 		return "", err
 	}
@@ -396,7 +429,7 @@ func (c *DbusClient) HealthzCollect(req string) (string, error) {
 	intName := c.intNamePrefix + modName + ".collect"
 
 	common_utils.IncCounter(common_utils.GNOI_HEALTHZ_COLLECT)
-	result, err := DbusApi(busName, busPath, intName /*timeout=*/, 10, req)
+	result, err := c.dbusApi(busName, busPath, intName /*timeout=*/, 10, req)
 	if err != nil {
 		return "", err
 	}
@@ -414,7 +447,7 @@ func (c *DbusClient) HealthzAck(req string) (string, error) {
 	intName := c.intNamePrefix + modName + ".ack"
 
 	common_utils.IncCounter(common_utils.GNOI_HEALTHZ_ACK)
-	result, err := DbusApi(busName, busPath, intName /*timeout=*/, 10, req)
+	result, err := c.dbusApi(busName, busPath, intName /*timeout=*/, 10, req)
 	if err != nil {
 		return "", err
 	}
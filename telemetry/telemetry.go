@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/sha512"
 	"crypto/tls"
 	"crypto/x509"
@@ -20,7 +21,12 @@ import (
 	"time"
 
 	gnmi "github.com/sonic-net/sonic-gnmi/gnmi_server"
+	"github.com/sonic-net/sonic-gnmi/pkg/audit"
 	"github.com/sonic-net/sonic-gnmi/pkg/interceptors"
+	"github.com/sonic-net/sonic-gnmi/pkg/jwtkeys"
+	"github.com/sonic-net/sonic-gnmi/pkg/metrics"
+	"github.com/sonic-net/sonic-gnmi/pkg/tacacs"
+	"github.com/sonic-net/sonic-gnmi/pkg/tracing"
 	testcert "github.com/sonic-net/sonic-gnmi/testdata/tls"
 
 	"github.com/fsnotify/fsnotify"
@@ -37,6 +43,7 @@ const (
 	ServerStop    ServerControlValue = iota // 0
 	ServerStart   ServerControlValue = iota // 1
 	ServerRestart ServerControlValue = iota // 2
+	ServerReload  ServerControlValue = iota // 3
 )
 
 type TelemetryConfig struct {
@@ -55,6 +62,9 @@ type TelemetryConfig struct {
 	AllowNoClientCert        *bool
 	JwtRefInt                *uint64
 	JwtValInt                *uint64
+	JwtKeyDir                *string
+	JwtKeyAlgorithm          *string
+	JwtKeyRotation           *uint64
 	GnmiTranslibWrite        *bool
 	GnmiNativeWrite          *bool
 	Threshold                *int
@@ -63,6 +73,8 @@ type TelemetryConfig struct {
 	WithMasterArbitration    *bool
 	WithSaveOnSet            *bool
 	IdleConnDuration         *int
+	DrainTimeout             *int
+	DrainRetryAfter          *int
 	GnmiVrf                  *string
 	Vrf                      *string
 	BindAddress              *string
@@ -81,6 +93,24 @@ type TelemetryConfig struct {
 	EnableStreamMultiplexing *bool
 	MaxRecvMsgSize           *int
 	MaxSendMsgSize           *int
+	MetricsAddress           *string
+	AuditSyslog              *bool
+	OtlpEndpoint             *string
+	TraceFile                *string
+	EnableConfigReload       *bool
+	AuditLogFile             *string
+	AuditLogMaxSize          *int
+	AuditLogBackups          *int
+	AuthBackend              *string
+	AuthFile                 *string
+	TacacsServers            *string
+	TacacsSecretFile         *string
+	TacacsTimeout            *int
+	TunnelAddress            *string
+	TunnelTarget             *string
+	EnableQuota              *bool
+	JournalMaxSize           *int64
+	JournalMaxBackups        *int
 }
 
 func main() {
@@ -112,6 +142,36 @@ func runTelemetry(args []string) error {
 	// enable swss-common debug level
 	swsscommon.LoggerLinkToDbNative("telemetry")
 
+	if *telemetryCfg.MetricsAddress != "" {
+		metricsServer, err := metrics.Serve(*telemetryCfg.MetricsAddress)
+		if err != nil {
+			return err
+		}
+		defer metricsServer.Close()
+	}
+
+	if err := setupAuditLog(telemetryCfg); err != nil {
+		return err
+	}
+	defer audit.Default.Close()
+
+	if err := setupTracing(telemetryCfg); err != nil {
+		return err
+	}
+	defer tracing.Default.Shutdown()
+
+	if *telemetryCfg.JwtKeyDir != "" {
+		keys, err := setupJwtKeys(telemetryCfg)
+		if err != nil {
+			return err
+		}
+		if *telemetryCfg.JwtKeyRotation > 0 {
+			rotateCtx, stopRotation := context.WithCancel(context.Background())
+			defer stopRotation()
+			go keys.Run(rotateCtx, time.Duration(*telemetryCfg.JwtKeyRotation)*time.Second)
+		}
+	}
+
 	var wg sync.WaitGroup
 	// serverControlSignal channel is a channel that will be used to notify gnmi server to start, stop, restart, depending of syscall or cert updates
 	var serverControlSignal = make(chan ServerControlValue, 1)
@@ -121,7 +181,7 @@ func runTelemetry(args []string) error {
 
 	wg.Add(1)
 
-	go signalHandler(serverControlSignal, sigchannel, stopSignalHandler, &wg)
+	go signalHandler(serverControlSignal, sigchannel, stopSignalHandler, *telemetryCfg.EnableConfigReload, &wg)
 
 	wg.Add(1)
 
@@ -170,6 +230,59 @@ func parseOSArgs() ([]string, []string) {
 	return glogFlags, telemetryFlags
 }
 
+// setupAuditLog adds the configured sinks to the audit logger. Syslog being
+// unavailable is not fatal, an unusable audit log file is.
+func setupAuditLog(telemetryCfg *TelemetryConfig) error {
+	if *telemetryCfg.AuditSyslog {
+		sink, err := audit.NewSyslogSink()
+		if err != nil {
+			log.Warningf("Audit records will not be sent to syslog: %v", err)
+		} else {
+			audit.Default.AddSink(sink)
+		}
+	}
+	if *telemetryCfg.AuditLogFile != "" {
+		sink, err := audit.NewFileSink(*telemetryCfg.AuditLogFile,
+			int64(*telemetryCfg.AuditLogMaxSize)*1024*1024, *telemetryCfg.AuditLogBackups)
+		if err != nil {
+			return err
+		}
+		audit.Default.AddSink(sink)
+	}
+	return nil
+}
+
+// setupJwtKeys makes the server sign tokens with the keys of jwt_key_dir. A
+// rotated key keeps validating the tokens it signed until they expire.
+func setupJwtKeys(telemetryCfg *TelemetryConfig) (*jwtkeys.KeyRing, error) {
+	dir := *telemetryCfg.JwtKeyDir
+	keys, err := jwtkeys.Open(dir, *telemetryCfg.JwtKeyAlgorithm, gnmi.JwtValidInt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JWT keys in %s: %v", dir, err)
+	}
+	revoked, err := jwtkeys.OpenRevocationList(filepath.Join(dir, "revoked.json"))
+	if err != nil {
+		return nil, err
+	}
+	gnmi.SetJwtKeys(keys, revoked)
+	return keys, nil
+}
+
+// setupTracing enables the tracer with the configured exporter.
+func setupTracing(telemetryCfg *TelemetryConfig) error {
+	switch {
+	case *telemetryCfg.TraceFile != "":
+		exporter, err := tracing.NewFileExporter(*telemetryCfg.TraceFile)
+		if err != nil {
+			return err
+		}
+		tracing.Default.SetExporter(exporter)
+	case *telemetryCfg.OtlpEndpoint != "":
+		tracing.Default.SetExporter(tracing.NewOTLPExporter(tracing.Default.Service(), *telemetryCfg.OtlpEndpoint))
+	}
+	return nil
+}
+
 func setupFlags(fs *flag.FlagSet) (*TelemetryConfig, *gnmi.Config, error) {
 	telemetryCfg := &TelemetryConfig{
 		UserAuth:                 gnmi.AuthTypes{"password": false, "cert": false, "jwt": false},
@@ -184,12 +297,17 @@ func setupFlags(fs *flag.FlagSet) (*TelemetryConfig, *gnmi.Config, error) {
 		AllowNoClientCert:        fs.Bool("allow_no_client_auth", false, "When set, telemetry server will request but not require a client certificate."),
 		JwtRefInt:                fs.Uint64("jwt_refresh_int", 900, "Seconds before JWT expiry the token can be refreshed."),
 		JwtValInt:                fs.Uint64("jwt_valid_int", 3600, "Seconds that JWT token is valid for."),
+		JwtKeyDir:                fs.String("jwt_key_dir", "", "Directory keeping the JWT signing keys and revoked tokens. Empty signs with a key valid until restart."),
+		JwtKeyAlgorithm:          fs.String("jwt_key_algorithm", "ES256", "Algorithm of the JWT signing keys in jwt_key_dir: RS256 or ES256"),
+		JwtKeyRotation:           fs.Uint64("jwt_key_rotation", 7*24*3600, "Seconds between rotations of the JWT signing key in jwt_key_dir, 0 disables rotation"),
 		GnmiTranslibWrite:        fs.Bool("gnmi_translib_write", gnmi.ENABLE_TRANSLIB_WRITE, "Enable gNMI translib write for management framework"),
 		GnmiNativeWrite:          fs.Bool("gnmi_native_write", gnmi.ENABLE_NATIVE_WRITE, "Enable gNMI native write"),
 		Threshold:                fs.Int("threshold", 100, "max number of client connections"),
 		WithMasterArbitration:    fs.Bool("with-master-arbitration", false, "Enables master arbitration policy."),
 		WithSaveOnSet:            fs.Bool("with-save-on-set", false, "Enables save-on-set."),
 		IdleConnDuration:         fs.Int("idle_conn_duration", 5, "Seconds before server closes idle connections"),
+		DrainTimeout:             fs.Int("drain_timeout", 5, "Seconds RPCs get to complete when the server stops, 0 closes connections at once"),
+		DrainRetryAfter:          fs.Int("drain_retry_after", 30, "Seconds after which subscribers ended by a stop are told to reconnect"),
 		GnmiVrf:                  fs.String("gnmi_vrf", "", "VRF name for gNMI server binding."),
 		Vrf:                      fs.String("vrf", "", "VRF name for ZMQ client binding."),
 		BindAddress:              fs.String("bind_address", "", "Address to bind the gRPC TCP listener. Empty binds all interfaces. Use 127.0.0.1 to restrict to localhost."),
@@ -211,6 +329,24 @@ func setupFlags(fs *flag.FlagSet) (*TelemetryConfig, *gnmi.Config, error) {
 		EnableStreamMultiplexing: fs.Bool("enable_stream_multiplexing", false, "Allow multiple Subscribe RPCs on a single TCP connection via HTTP/2 stream multiplexing"),
 		MaxRecvMsgSize:           fs.Int("max_recv_msg_size", 4*1024*1024, "Maximum message size in bytes that the server can receive"),
 		MaxSendMsgSize:           fs.Int("max_send_msg_size", 4*1024*1024, "Maximum message size in bytes that the server can send"),
+		MetricsAddress:           fs.String("metrics_address", "", "Serve Prometheus metrics on /metrics at this address, host:port (use a localhost address) or unix:/path. Empty disables metrics."),
+		EnableConfigReload:       fs.Bool("enable_config_reload", false, "Apply client_auth, threshold, idle_conn_duration, log_level and write settings from the GNMI|gnmi entry of CONFIG_DB when it changes or on SIGHUP"),
+		OtlpEndpoint:             fs.String("otlp_endpoint", "", "Export traces with OTLP/HTTP to the collector at this address, host:port (use a local collector) or URL. Empty disables tracing unless trace_file is set."),
+		TraceFile:                fs.String("trace_file", "", "Write traces to this local file, one JSON span per line, instead of otlp_endpoint. For testing and debugging."),
+		AuditSyslog:              fs.Bool("audit_syslog", true, "Write an audit record of every write-class RPC to syslog"),
+		AuditLogFile:             fs.String("audit_log_file", "", "Also write audit records to this local file. Empty disables the file."),
+		AuditLogMaxSize:          fs.Int("audit_log_max_size", 10, "Size in MB at which the audit log file is rotated"),
+		AuditLogBackups:          fs.Int("audit_log_backups", 3, "Number of rotated audit log files to keep"),
+		AuthBackend:              fs.String("auth_backend", "pam", "Backend checking passwords of client_auth password and jwt: pam, file or tacacs"),
+		AuthFile:                 fs.String("auth_file", "", "Credentials file of auth_backend file, one user:bcrypt-hash:roles per line"),
+		TacacsServers:            fs.String("tacacs_servers", "", "Comma separated host[:port] of the TACACS+ servers of auth_backend tacacs, tried in order"),
+		TacacsSecretFile:         fs.String("tacacs_secret_file", "", "File holding the secret shared with the TACACS+ servers"),
+		TacacsTimeout:            fs.Int("tacacs_timeout", 5, "Seconds to wait for a TACACS+ server"),
+		TunnelAddress:            fs.String("tunnel_address", "", "Comma separated host:port of collector tunnel servers to open gRPC tunnels to, serving gNMI and gNOI over them. Empty disables tunnels."),
+		TunnelTarget:             fs.String("tunnel_target", "", "Target registered over the tunnels, the hostname when empty"),
+		EnableQuota:              fs.Bool("enable_quota", false, "Enforce per-user and per-role quotas from the GNMI_QUOTA table of CONFIG_DB"),
+		JournalMaxSize:           fs.Int64("config_db_journal_max_size", gnmi.DefaultJournalMaxSize, "Size in bytes past which the config db journal is rotated, 0 never rotates it"),
+		JournalMaxBackups:        fs.Int("config_db_journal_max_backups", gnmi.DefaultJournalMaxBackups, "Number of compressed backups of the config db journal to keep"),
 	}
 
 	fs.Var(&telemetryCfg.UserAuth, "client_auth", "Client auth mode(s) - none,cert,password")
@@ -255,6 +391,20 @@ func setupFlags(fs *flag.FlagSet) (*TelemetryConfig, *gnmi.Config, error) {
 		return nil, nil, fmt.Errorf("idle_conn_duration must be >= 0, 0 meaning inf")
 	}
 
+	switch {
+	case *telemetryCfg.DrainTimeout < 0:
+		return nil, nil, fmt.Errorf("drain_timeout must be >= 0")
+	case *telemetryCfg.DrainRetryAfter < 0:
+		return nil, nil, fmt.Errorf("drain_retry_after must be >= 0")
+	}
+
+	switch {
+	case *telemetryCfg.JournalMaxSize < 0:
+		return nil, nil, fmt.Errorf("config_db_journal_max_size must be >= 0")
+	case *telemetryCfg.JournalMaxBackups < 0:
+		return nil, nil, fmt.Errorf("config_db_journal_max_backups must be >= 0")
+	}
+
 	switch {
 	case *telemetryCfg.LogLevel < 0:
 		*telemetryCfg.LogLevel = 2
@@ -282,6 +432,7 @@ func setupFlags(fs *flag.FlagSet) (*TelemetryConfig, *gnmi.Config, error) {
 	cfg.LogLevel = int(*telemetryCfg.LogLevel)
 	cfg.Threshold = int(*telemetryCfg.Threshold)
 	cfg.IdleConnDuration = int(*telemetryCfg.IdleConnDuration)
+	cfg.EnableConfigReload = *telemetryCfg.EnableConfigReload
 	cfg.ConfigTableName = *telemetryCfg.ConfigTableName
 	cfg.GnmiVrf = *telemetryCfg.GnmiVrf
 	cfg.Vrf = *telemetryCfg.Vrf
@@ -336,9 +487,64 @@ func setupFlags(fs *flag.FlagSet) (*TelemetryConfig, *gnmi.Config, error) {
 	cfg.AuthzPolicy = *telemetryCfg.AuthPolicyEnabled && !*telemetryCfg.Insecure
 	cfg.AuthzPolicyFile = string(*telemetryCfg.AuthzPolicyFile)
 	cfg.EnableStreamMultiplexing = *telemetryCfg.EnableStreamMultiplexing
+	cfg.EnableQuota = *telemetryCfg.EnableQuota
+	cfg.ConfigDbJournalMaxSize = *telemetryCfg.JournalMaxSize
+	cfg.ConfigDbJournalMaxBackups = *telemetryCfg.JournalMaxBackups
+	for _, addr := range strings.Split(*telemetryCfg.TunnelAddress, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			cfg.TunnelAddresses = append(cfg.TunnelAddresses, addr)
+		}
+	}
+	if len(cfg.TunnelAddresses) > 0 && (cfg.Port <= 0 || *telemetryCfg.NoTLS) {
+		return nil, nil, fmt.Errorf("tunnel_address requires port > 0 and TLS, tunnels are served like the TCP listener")
+	}
+	cfg.TunnelTarget = *telemetryCfg.TunnelTarget
+	authenticator, err := newAuthenticator(telemetryCfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg.Authenticator = authenticator
 	return telemetryCfg, cfg, nil
 }
 
+// newAuthenticator returns the password backend selected by auth_backend,
+// nil for the default PAM backend.
+func newAuthenticator(telemetryCfg *TelemetryConfig) (gnmi.Authenticator, error) {
+	switch *telemetryCfg.AuthBackend {
+	case "pam":
+		return nil, nil
+	case "file":
+		if *telemetryCfg.AuthFile == "" {
+			return nil, fmt.Errorf("auth_backend file requires auth_file")
+		}
+		authenticator, err := gnmi.NewFileAuthenticator(*telemetryCfg.AuthFile)
+		if err != nil {
+			return nil, err
+		}
+		return authenticator, nil
+	case "tacacs":
+		client := &tacacs.Client{Timeout: time.Duration(*telemetryCfg.TacacsTimeout) * time.Second, Port: "gnmi"}
+		for _, server := range strings.Split(*telemetryCfg.TacacsServers, ",") {
+			if server = strings.TrimSpace(server); server != "" {
+				client.Servers = append(client.Servers, server)
+			}
+		}
+		if len(client.Servers) == 0 {
+			return nil, fmt.Errorf("auth_backend tacacs requires tacacs_servers")
+		}
+		if *telemetryCfg.TacacsSecretFile != "" {
+			secret, err := os.ReadFile(*telemetryCfg.TacacsSecretFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read tacacs_secret_file: %v", err)
+			}
+			client.Secret = strings.TrimSpace(string(secret))
+		}
+		return gnmi.NewTacacsAuthenticator(client), nil
+	default:
+		return nil, fmt.Errorf("unknown auth_backend %q, expected pam, file or tacacs", *telemetryCfg.AuthBackend)
+	}
+}
+
 func isFlagPassed(fs *flag.FlagSet, name string) bool {
 	found := false
 	fs.Visit(func(f *flag.Flag) {
@@ -416,15 +622,24 @@ func iNotifyCertMonitoring(watcher *fsnotify.Watcher, telemetryCfg *TelemetryCon
 	log.V(6).Infof("Closing cert rotation monitoring")
 }
 
-func signalHandler(serverControlSignal chan<- ServerControlValue, sigchannel <-chan os.Signal, stopSignalHandler <-chan bool, wg *sync.WaitGroup) {
+// signalHandler stops the server on a signal, except for SIGHUP which
+// reloads its configuration when reload is enabled.
+func signalHandler(serverControlSignal chan<- ServerControlValue, sigchannel <-chan os.Signal, stopSignalHandler <-chan bool, reload bool, wg *sync.WaitGroup) {
 	defer wg.Done()
-	select {
-	case <-sigchannel:
-		log.V(6).Infof("Sending signal stop to server because of syscall received")
-		serverControlSignal <- ServerStop
-		return
-	case <-stopSignalHandler:
-		return
+	for {
+		select {
+		case sig := <-sigchannel:
+			if sig == syscall.SIGHUP && reload {
+				log.V(6).Infof("Sending signal reload to server because of SIGHUP received")
+				serverControlSignal <- ServerReload
+				continue
+			}
+			log.V(6).Infof("Sending signal stop to server because of syscall received")
+			serverControlSignal <- ServerStop
+			return
+		case <-stopSignalHandler:
+			return
+		}
 	}
 }
 
@@ -439,6 +654,8 @@ func startGNMIServer(telemetryCfg *TelemetryConfig, cfg *gnmi.Config, serverCont
 		}
 	}()
 
+	// idle_conn_duration can be reloaded, it applies when the server restarts
+	idleConnDuration := *telemetryCfg.IdleConnDuration
 	for {
 		// Close previous chain before creating new one on restart
 		if currentServerChain != nil {
@@ -547,10 +764,19 @@ func startGNMIServer(telemetryCfg *TelemetryConfig, cfg *gnmi.Config, serverCont
 				}
 			}
 
+			// Tunnels present the server certificate to the collectors,
+			// verified with the CA certificate of the clients
+			cfg.TunnelDialOptions = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+				Certificates:       tlsCfg.Certificates,
+				RootCAs:            tlsCfg.ClientCAs,
+				InsecureSkipVerify: *telemetryCfg.Insecure,
+				MinVersion:         tls.VersionTLS12,
+			}))}
+
 			atomic.StoreInt32(&certLoaded, 1) // Certs have loaded
 
 			keep_alive_params := keepalive.ServerParameters{
-				MaxConnectionIdle: time.Duration(*telemetryCfg.IdleConnDuration) * time.Second, // duration in which idle connection will be closed, default is inf
+				MaxConnectionIdle: time.Duration(idleConnDuration) * time.Second, // duration in which idle connection will be closed, default is inf
 			}
 
 			// Allow clients (e.g. DPU proxy) to send keepalive pings at a
@@ -566,11 +792,13 @@ func startGNMIServer(telemetryCfg *TelemetryConfig, cfg *gnmi.Config, serverCont
 
 			tlsOpts = []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsCfg))}
 
-			if *telemetryCfg.IdleConnDuration > 0 { // non inf case
+			if idleConnDuration > 0 { // non inf case
 				commonOpts = append(commonOpts, grpc.KeepaliveParams(keep_alive_params))
 			}
 
-			gnmi.GenerateJwtSecretKey()
+			if *telemetryCfg.JwtKeyDir == "" {
+				gnmi.GenerateJwtSecretKey()
+			}
 		}
 
 		commonOpts = append(commonOpts,
@@ -612,9 +840,21 @@ func startGNMIServer(telemetryCfg *TelemetryConfig, cfg *gnmi.Config, serverCont
 		}()
 
 		serverControlValue := <-serverControlSignal
+		for serverControlValue == ServerReload {
+			if err := s.Reload(); err != nil {
+				log.Errorf("Failed to reload server configuration: %v", err)
+			}
+			serverControlValue = <-serverControlSignal
+		}
+		// Keep a reloaded idle_conn_duration across the restart
+		idleConnDuration = s.Config().IdleConnDuration
 		log.V(1).Infof("Received signal for gnmi server to close")
 		if serverControlValue == ServerStop {
-			s.ForceStop() // No graceful stop
+			if *telemetryCfg.DrainTimeout > 0 {
+				s.Drain(time.Duration(*telemetryCfg.DrainTimeout)*time.Second, time.Duration(*telemetryCfg.DrainRetryAfter)*time.Second)
+			} else {
+				s.ForceStop() // No graceful stop
+			}
 			stopSignalHandler <- true
 			log.Flush()
 			return
@@ -1431,6 +1431,35 @@ func TestSignalHandler(t *testing.T) {
 	testHandlerSyscall(t, nil) // Test that ServerStop should make signalHandler exit
 }
 
+func TestSignalHandlerReload(t *testing.T) {
+	serverControlSignal := make(chan ServerControlValue, 1)
+	stopSignalHandler := make(chan bool, 1)
+	testSigChan := make(chan os.Signal, 1)
+	wg := &sync.WaitGroup{}
+
+	wg.Add(1)
+	go signalHandler(serverControlSignal, testSigChan, stopSignalHandler, true, wg)
+
+	// SIGHUP reloads and keeps the handler running
+	for i := 0; i < 2; i++ {
+		testSigChan <- syscall.SIGHUP
+		select {
+		case val := <-serverControlSignal:
+			if val != ServerReload {
+				t.Errorf("Expected ServerReload from serverControlSignal, got %d", val)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected a value from serverControlSignal, but got none")
+		}
+	}
+
+	testSigChan <- syscall.SIGTERM
+	if val := <-serverControlSignal; val != ServerStop {
+		t.Errorf("Expected ServerStop from serverControlSignal, got %d", val)
+	}
+	wg.Wait()
+}
+
 func testHandlerSyscall(t *testing.T, signal os.Signal) {
 	timeoutInterval := 1
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutInterval)*time.Second)
@@ -1443,7 +1472,7 @@ func testHandlerSyscall(t *testing.T, signal os.Signal) {
 
 	wg.Add(1)
 
-	go signalHandler(serverControlSignal, testSigChan, stopSignalHandler, wg)
+	go signalHandler(serverControlSignal, testSigChan, stopSignalHandler, false, wg)
 
 	if signal == nil {
 		stopSignalHandler <- true
@@ -1550,3 +1579,80 @@ func TestMain(m *testing.M) {
 	defer test_utils.MemLeakCheck()
 	m.Run()
 }
+
+func TestFlagsTunnel(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	fs := flag.NewFlagSet("testFlagsTunnel", flag.ContinueOnError)
+	os.Args = []string{"cmd", "-port", "8080", "-insecure", "-tunnel_address", "10.0.0.1:4000, 10.0.0.2:4000", "-tunnel_target", "switch1"}
+	_, cfg, err := setupFlags(fs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(cfg.TunnelAddresses) != "[10.0.0.1:4000 10.0.0.2:4000]" || cfg.TunnelTarget != "switch1" {
+		t.Errorf("tunnel addresses %v, target %q", cfg.TunnelAddresses, cfg.TunnelTarget)
+	}
+
+	// Tunnels are served with TLS by the TCP listener
+	for _, args := range [][]string{
+		{"cmd", "-port", "0", "-insecure", "-tunnel_address", "10.0.0.1:4000"},
+		{"cmd", "-port", "8080", "-noTLS", "-bind_address", "127.0.0.1", "-tunnel_address", "10.0.0.1:4000"},
+	} {
+		fs := flag.NewFlagSet("testFlagsTunnel", flag.ContinueOnError)
+		os.Args = args
+		if _, _, err := setupFlags(fs); err == nil || !strings.Contains(err.Error(), "tunnel_address") {
+			t.Errorf("setupFlags(%v) error = %v", args[1:], err)
+		}
+	}
+}
+
+func TestFlagsQuota(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	for _, want := range []bool{false, true} {
+		fs := flag.NewFlagSet("testFlagsQuota", flag.ContinueOnError)
+		os.Args = []string{"cmd", "-port", "8080", "-insecure"}
+		if want {
+			os.Args = append(os.Args, "-enable_quota")
+		}
+		_, cfg, err := setupFlags(fs)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.EnableQuota != want {
+			t.Errorf("setupFlags(%v) EnableQuota = %v, want %v", os.Args[1:], cfg.EnableQuota, want)
+		}
+	}
+}
+
+func TestFlagsConfigDbJournal(t *testing.T) {
+	originalArgs := os.Args
+	defer func() { os.Args = originalArgs }()
+
+	fs := flag.NewFlagSet("testFlagsConfigDbJournal", flag.ContinueOnError)
+	os.Args = []string{"cmd", "-port", "8080", "-insecure"}
+	_, cfg, err := setupFlags(fs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.ConfigDbJournalMaxSize != gnmi.DefaultJournalMaxSize || cfg.ConfigDbJournalMaxBackups != gnmi.DefaultJournalMaxBackups {
+		t.Errorf("default journal retention %d bytes, %d backups", cfg.ConfigDbJournalMaxSize, cfg.ConfigDbJournalMaxBackups)
+	}
+
+	fs = flag.NewFlagSet("testFlagsConfigDbJournal", flag.ContinueOnError)
+	os.Args = []string{"cmd", "-port", "8080", "-insecure", "-config_db_journal_max_size", "10000000", "-config_db_journal_max_backups", "5"}
+	if _, cfg, err = setupFlags(fs); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.ConfigDbJournalMaxSize != 10000000 || cfg.ConfigDbJournalMaxBackups != 5 {
+		t.Errorf("journal retention %d bytes, %d backups, want 10000000 and 5", cfg.ConfigDbJournalMaxSize, cfg.ConfigDbJournalMaxBackups)
+	}
+
+	fs = flag.NewFlagSet("testFlagsConfigDbJournal", flag.ContinueOnError)
+	os.Args = []string{"cmd", "-port", "8080", "-insecure", "-config_db_journal_max_backups", "-1"}
+	if _, _, err = setupFlags(fs); err == nil || !strings.Contains(err.Error(), "config_db_journal_max_backups") {
+		t.Errorf("setupFlags error = %v", err)
+	}
+}
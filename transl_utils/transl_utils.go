@@ -15,6 +15,7 @@ import (
 	gnmipb "github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/ygot/ygot"
 	"github.com/sonic-net/sonic-gnmi/common_utils"
+	"github.com/sonic-net/sonic-gnmi/pkg/tracing"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -328,6 +329,17 @@ func TranslProcessUpdate(prefix *gnmipb.Path, entry *gnmipb.Update, ctx context.
 
 // TranslProcessBulk - Process Bulk Set request
 func TranslProcessBulk(delete []*gnmipb.Path, replace []*gnmipb.Update, update []*gnmipb.Update, prefix *gnmipb.Path, ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "translib.Bulk")
+	span.SetAttribute("translib.deletes", len(delete))
+	span.SetAttribute("translib.replaces", len(replace))
+	span.SetAttribute("translib.updates", len(update))
+	err := translProcessBulk(delete, replace, update, prefix, ctx)
+	span.RecordError(err)
+	span.End()
+	return err
+}
+
+func translProcessBulk(delete []*gnmipb.Path, replace []*gnmipb.Update, update []*gnmipb.Update, prefix *gnmipb.Path, ctx context.Context) error {
 
 	var uri string
 	var err error